@@ -2,16 +2,12 @@ package main
 
 import (
 	"context"
-	"log"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 
-	"sudooom.im.access/internal/config"
-	"sudooom.im.access/internal/nats"
-	imRedis "sudooom.im.access/internal/redis"
-	"sudooom.im.access/internal/server"
+	"sudooom.im.access/pkg/app"
 )
 
 func main() {
@@ -21,54 +17,15 @@ func main() {
 	}))
 	slog.SetDefault(logger)
 
-	// 加载配置
-	cfg, err := config.Load("configs/config.yaml")
-	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
-	}
-
-	// 创建上下文
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// 收到退出信号后取消上下文，触发优雅关闭
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	// 初始化 NATS 客户端
-	natsClient, err := nats.NewClient(cfg.NATS)
-	if err != nil {
-		logger.Error("Failed to connect to NATS", "error", err)
+	if err := app.Run(ctx, app.Options{
+		ConfigPath: "configs/config.yaml",
+		Logger:     logger,
+	}); err != nil {
+		logger.Error("Access server exited", "error", err)
 		os.Exit(1)
 	}
-	defer natsClient.Close()
-	logger.Info("Connected to NATS", "url", cfg.NATS.URL)
-
-	// 初始化 Redis 客户端
-	redisClient := imRedis.NewClient(cfg.Redis, cfg.Server.NodeID)
-	defer func() {
-		if err := redisClient.Close(); err != nil {
-			logger.Error("Failed to close Redis client", "error", err)
-		}
-	}()
-	logger.Info("Connected to Redis", "addr", cfg.Redis.Addr)
-
-	// 创建并启动服务器
-	srv := server.New(cfg, natsClient, redisClient, logger)
-	go func() {
-		if err := srv.Start(ctx); err != nil {
-			logger.Error("Server failed", "error", err)
-			os.Exit(1)
-		}
-	}()
-
-	logger.Info("Access server started",
-		"addr", cfg.Server.Addr,
-		"node_id", cfg.Server.NodeID)
-
-	// 优雅关闭
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	logger.Info("Shutting down server...")
-	cancel()
-	srv.Shutdown()
-	logger.Info("Server stopped")
 }
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+	"github.com/quic-go/webtransport-go"
+	"sudooom.im.access/internal/handler"
+	im_protocol "sudooom.im.access/pkg/flatbuf/im/protocol"
+)
+
+const frameHeaderSize = 5
+
+var (
+	errTimeout = errors.New("timeout")
+	errClosed  = errors.New("session closed")
+)
+
+// responseError 服务端以非 SUCCESS 错误码回复
+type responseError struct {
+	code im_protocol.ErrorCode
+}
+
+func (e *responseError) Error() string { return e.code.String() }
+
+// errorReason 错误归类，用于报告中的错误计数
+func errorReason(err error) string {
+	var respErr *responseError
+	switch {
+	case errors.As(err, &respErr):
+		return respErr.code.String()
+	case errors.Is(err, errTimeout), errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, errClosed):
+		return "closed"
+	default:
+		return "transport"
+	}
+}
+
+// pushHandler 处理服务端主动推送（reqId 无对应的待响应请求）
+type pushHandler func(resp *im_protocol.ClientResponse)
+
+// client 一个已认证的 WebTransport 会话
+type client struct {
+	userID   int64
+	session  *webtransport.Session
+	stream   *webtransport.Stream
+	interval time.Duration
+
+	writeMu sync.Mutex
+	nextReq atomic.Int64
+	pending sync.Map // reqId -> chan *im_protocol.ClientResponse
+	onPush  atomic.Pointer[pushHandler]
+	done    chan struct{}
+	once    sync.Once
+}
+
+// dial 建立会话、打开双向流并完成认证
+func dial(ctx context.Context, dialer *webtransport.Dialer, url string, cred credential) (*client, error) {
+	_, session, err := dialer.Dial(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := session.OpenStreamSync(ctx)
+	if err != nil {
+		session.CloseWithError(0, "open stream failed")
+		return nil, err
+	}
+
+	c := &client{userID: cred.UserID, session: session, stream: stream, done: make(chan struct{})}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = stream.SetDeadline(deadline)
+	}
+	if err := c.authenticate(cred); err != nil {
+		c.close()
+		return nil, err
+	}
+	_ = stream.SetDeadline(time.Time{})
+	return c, nil
+}
+
+func (c *client) authenticate(cred credential) error {
+	builder := flatbuffers.NewBuilder(256)
+	tokenOffset := builder.CreateString(cred.Token)
+	deviceOffset := builder.CreateString(cred.DeviceID)
+	versionOffset := builder.CreateString("imbench")
+	im_protocol.AuthRequestStart(builder)
+	im_protocol.AuthRequestAddToken(builder, tokenOffset)
+	im_protocol.AuthRequestAddDeviceId(builder, deviceOffset)
+	im_protocol.AuthRequestAddPlatform(builder, im_protocol.PlatformWEB)
+	im_protocol.AuthRequestAddAppVersion(builder, versionOffset)
+	builder.Finish(im_protocol.AuthRequestEnd(builder))
+
+	if err := c.writeFrame(handler.FrameTypeAuth, builder.FinishedBytes()); err != nil {
+		return err
+	}
+
+	frameType, body, err := c.readFrame()
+	if err != nil {
+		return err
+	}
+	if frameType != handler.FrameTypeAuthAck {
+		return fmt.Errorf("unexpected frame type %d", frameType)
+	}
+	ack := im_protocol.GetRootAsAuthAck(body, 0)
+	if ack.Code() != im_protocol.ErrorCodeSUCCESS {
+		return &responseError{code: ack.Code()}
+	}
+	if uid, err := strconv.ParseInt(string(ack.UserId()), 10, 64); err == nil && uid > 0 {
+		c.userID = uid
+	}
+	c.interval = time.Duration(ack.HeartbeatIntervalMs()) * time.Millisecond
+	return nil
+}
+
+// start 启动读循环与心跳
+func (c *client) start(ctx context.Context) {
+	go c.readLoop()
+	go c.heartbeatLoop(ctx)
+}
+
+// setPushHandler 设置推送处理器（切换负载时替换）
+func (c *client) setPushHandler(h pushHandler) {
+	c.onPush.Store(&h)
+}
+
+func (c *client) readLoop() {
+	defer c.close()
+	for {
+		frameType, body, err := c.readFrame()
+		if err != nil {
+			return
+		}
+		if frameType != handler.FrameTypeResponse {
+			continue
+		}
+		resp := im_protocol.GetRootAsClientResponse(body, 0)
+		if reqID := string(resp.ReqId()); reqID != "" {
+			if ch, ok := c.pending.LoadAndDelete(reqID); ok {
+				ch.(chan *im_protocol.ClientResponse) <- resp
+				continue
+			}
+		}
+		if h := c.onPush.Load(); h != nil {
+			(*h)(resp)
+		}
+	}
+}
+
+func (c *client) heartbeatLoop(ctx context.Context) {
+	if c.interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.done:
+			return
+		case <-ticker.C:
+			builder := flatbuffers.NewBuilder(32)
+			im_protocol.HeartbeatReqStart(builder)
+			im_protocol.HeartbeatReqAddClientTime(builder, time.Now().UnixMilli())
+			builder.Finish(im_protocol.HeartbeatReqEnd(builder))
+			_, _ = c.send(im_protocol.RequestPayloadHeartbeatReq, builder.FinishedBytes())
+		}
+	}
+}
+
+// send 发送请求，不等待响应
+func (c *client) send(payloadType im_protocol.RequestPayload, payload []byte) (string, error) {
+	reqID := strconv.FormatInt(c.userID, 10) + "-" + strconv.FormatInt(c.nextReq.Add(1), 10)
+	return reqID, c.writeRequest(reqID, payloadType, payload)
+}
+
+// call 发送请求并等待相同 reqId 的响应，非 SUCCESS 错误码返回 responseError
+func (c *client) call(ctx context.Context, payloadType im_protocol.RequestPayload, payload []byte, timeout time.Duration) (*im_protocol.ClientResponse, error) {
+	reqID := strconv.FormatInt(c.userID, 10) + "-" + strconv.FormatInt(c.nextReq.Add(1), 10)
+	ch := make(chan *im_protocol.ClientResponse, 1)
+	c.pending.Store(reqID, ch)
+	defer c.pending.Delete(reqID)
+
+	if err := c.writeRequest(reqID, payloadType, payload); err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case resp := <-ch:
+		if resp.Code() != im_protocol.ErrorCodeSUCCESS {
+			return resp, &responseError{code: resp.Code()}
+		}
+		return resp, nil
+	case <-timer.C:
+		return nil, errTimeout
+	case <-c.done:
+		return nil, errClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *client) writeRequest(reqID string, payloadType im_protocol.RequestPayload, payload []byte) error {
+	builder := flatbuffers.NewBuilder(64 + len(payload))
+	reqIDOffset := builder.CreateString(reqID)
+	payloadOffset := builder.CreateByteVector(payload)
+	im_protocol.ClientRequestStart(builder)
+	im_protocol.ClientRequestAddReqId(builder, reqIDOffset)
+	im_protocol.ClientRequestAddTimestamp(builder, time.Now().UnixMilli())
+	im_protocol.ClientRequestAddPayloadType(builder, payloadType)
+	im_protocol.ClientRequestAddPayload(builder, payloadOffset)
+	builder.Finish(im_protocol.ClientRequestEnd(builder))
+	return c.writeFrame(handler.FrameTypeRequest, builder.FinishedBytes())
+}
+
+func (c *client) writeFrame(frameType byte, body []byte) error {
+	frame := make([]byte, frameHeaderSize+len(body))
+	binary.BigEndian.PutUint32(frame[:4], uint32(len(body)))
+	frame[4] = frameType
+	copy(frame[frameHeaderSize:], body)
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := c.stream.Write(frame)
+	return err
+}
+
+func (c *client) readFrame() (byte, []byte, error) {
+	var header [frameHeaderSize]byte
+	if _, err := io.ReadFull(c.stream, header[:]); err != nil {
+		return 0, nil, err
+	}
+	body := make([]byte, binary.BigEndian.Uint32(header[:4]))
+	if _, err := io.ReadFull(c.stream, body); err != nil {
+		return 0, nil, err
+	}
+	return header[4], body, nil
+}
+
+func (c *client) close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.stream.Close()
+		c.session.CloseWithError(0, "imbench done")
+	})
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/quic-go/webtransport-go"
+	"sudooom.im.access/internal/handler"
+	im_protocol "sudooom.im.access/pkg/flatbuf/im/protocol"
+)
+
+// startFakeAccess 启动最小化的 Access：认证成功后对 ChatSendReq 回复 ACK 并把消息原样推送回发送者
+func startFakeAccess(t *testing.T) string {
+	t.Helper()
+	cert, err := tls.LoadX509KeyPair("../../localhost.crt", "../../localhost.key")
+	if err != nil {
+		t.Skipf("加载测试证书失败: %v", err)
+	}
+	udpConn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Skipf("无法监听 UDP: %v", err)
+	}
+
+	server := &webtransport.Server{
+		H3: http3.Server{
+			TLSConfig:  &tls.Config{Certificates: []tls.Certificate{cert}, NextProtos: []string{"h3"}},
+			QUICConfig: &quic.Config{EnableDatagrams: true},
+		},
+		CheckOrigin: func(*http.Request) bool { return true },
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/webtransport", func(w http.ResponseWriter, r *http.Request) {
+		session, err := server.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		stream, err := session.AcceptStream(context.Background())
+		if err != nil {
+			return
+		}
+		serveFakeStream(stream)
+	})
+	server.H3.Handler = mux
+
+	go server.Serve(udpConn)
+	t.Cleanup(func() { server.Close() })
+	return udpConn.LocalAddr().String()
+}
+
+func serveFakeStream(stream *webtransport.Stream) {
+	write := func(frameType byte, build func(b *flatbuffers.Builder) flatbuffers.UOffsetT) {
+		b := flatbuffers.NewBuilder(128)
+		b.Finish(build(b))
+		body := b.FinishedBytes()
+		frame := make([]byte, frameHeaderSize+len(body))
+		binary.BigEndian.PutUint32(frame[:4], uint32(len(body)))
+		frame[4] = frameType
+		copy(frame[frameHeaderSize:], body)
+		stream.Write(frame)
+	}
+	respond := func(reqID string, payloadType im_protocol.ResponsePayload, payload []byte) {
+		write(handler.FrameTypeResponse, func(b *flatbuffers.Builder) flatbuffers.UOffsetT {
+			reqIDOffset := b.CreateString(reqID)
+			payloadOffset := b.CreateByteVector(payload)
+			im_protocol.ClientResponseStart(b)
+			im_protocol.ClientResponseAddReqId(b, reqIDOffset)
+			im_protocol.ClientResponseAddPayloadType(b, payloadType)
+			im_protocol.ClientResponseAddPayload(b, payloadOffset)
+			return im_protocol.ClientResponseEnd(b)
+		})
+	}
+
+	for {
+		var header [frameHeaderSize]byte
+		if _, err := io.ReadFull(stream, header[:]); err != nil {
+			return
+		}
+		body := make([]byte, binary.BigEndian.Uint32(header[:4]))
+		if _, err := io.ReadFull(stream, body); err != nil {
+			return
+		}
+
+		switch header[4] {
+		case handler.FrameTypeAuth:
+			write(handler.FrameTypeAuthAck, func(b *flatbuffers.Builder) flatbuffers.UOffsetT {
+				userIDOffset := b.CreateString("42")
+				im_protocol.AuthAckStart(b)
+				im_protocol.AuthAckAddUserId(b, userIDOffset)
+				return im_protocol.AuthAckEnd(b)
+			})
+		case handler.FrameTypeRequest:
+			req := im_protocol.GetRootAsClientRequest(body, 0)
+			if req.PayloadType() != im_protocol.RequestPayloadChatSendReq {
+				continue
+			}
+			chat := im_protocol.GetRootAsChatSendReq(req.PayloadBytes(), 0)
+
+			ack := flatbuffers.NewBuilder(64)
+			msgIDOffset := ack.CreateString("1")
+			im_protocol.ChatSendAckStart(ack)
+			im_protocol.ChatSendAckAddMsgId(ack, msgIDOffset)
+			ack.Finish(im_protocol.ChatSendAckEnd(ack))
+			respond(string(req.ReqId()), im_protocol.ResponsePayloadChatSendAck, ack.FinishedBytes())
+
+			push := flatbuffers.NewBuilder(128)
+			contentOffset := push.CreateString(string(chat.Content()))
+			targetOffset := push.CreateString(string(chat.TargetId()))
+			im_protocol.ChatPushStart(push)
+			im_protocol.ChatPushAddChatType(push, chat.ChatType())
+			im_protocol.ChatPushAddTargetId(push, targetOffset)
+			im_protocol.ChatPushAddContent(push, contentOffset)
+			push.Finish(im_protocol.ChatPushEnd(push))
+			respond("", im_protocol.ResponsePayloadChatPush, push.FinishedBytes())
+		}
+	}
+}
+
+// TestClientCallAndPush 测试认证、按 reqId 关联响应以及推送延迟解析
+func TestClientCallAndPush(t *testing.T) {
+	addr := startFakeAccess(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	dialer := &webtransport.Dialer{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true, NextProtos: []string{"h3"}},
+		QUICConfig:      &quic.Config{EnableDatagrams: true},
+	}
+	c, err := dial(ctx, dialer, "https://"+addr+"/webtransport", credential{UserID: 1, Token: "t", DeviceID: "d"})
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer c.close()
+	if c.userID != 42 {
+		t.Fatalf("应使用 AuthAck 返回的用户 ID，实际 %d", c.userID)
+	}
+
+	pushed := make(chan time.Time, 1)
+	c.setPushHandler(func(resp *im_protocol.ClientResponse) {
+		if _, sent, ok := parseChatPush(resp); ok {
+			pushed <- sent
+		}
+	})
+	c.start(ctx)
+
+	sent := time.Now()
+	resp, err := c.call(ctx, im_protocol.RequestPayloadChatSendReq, buildChatSend(im_protocol.ChatTypePRIVATE, 7, sent), time.Second)
+	if err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if resp.PayloadType() != im_protocol.ResponsePayloadChatSendAck {
+		t.Fatalf("响应类型不正确: %v", resp.PayloadType())
+	}
+
+	select {
+	case got := <-pushed:
+		if !got.Equal(time.Unix(0, sent.UnixNano())) {
+			t.Fatalf("推送中的发送时间不正确: %v, want %v", got, sent)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("未收到推送")
+	}
+}
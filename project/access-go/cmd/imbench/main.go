@@ -0,0 +1,246 @@
+// imbench IM 协议压测工具
+//
+// 建立 N 个 WebTransport 会话并完成认证，依次运行脚本化负载，报告建连速率、
+// 发送到推送的延迟分位（p50/p99）与错误数。面向本地单节点环境（同一台机器的时钟），
+// 推送延迟由消息内容中携带的发送时间计算。
+//
+// 令牌来源：
+//   - redis：直接在 Redis 中写入测试用户的令牌（无需 web-go 与数据库）
+//   - web：通过 web-go 注册/登录获取令牌
+//
+// 例：
+//
+//	go run ./cmd/imbench -users 200 -workloads dm,room -duration 30s
+//	go run ./cmd/imbench -tokens web -web http://localhost:8082 -users 50 -workloads group -group 123
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/webtransport-go"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// options 命令行参数
+type options struct {
+	addr        string
+	users       int
+	concurrency int
+	workloads   string
+	duration    time.Duration
+	timeout     time.Duration
+	jsonOutput  bool
+
+	tokens        string
+	webURL        string
+	userPrefix    string
+	password      string
+	redisAddr     string
+	redisPassword string
+	redisDB       int
+	userBase      int64
+
+	groupID  int64
+	senders  int
+	rate     float64
+	roomSize int
+}
+
+func parseFlags() *options {
+	o := &options{}
+	flag.StringVar(&o.addr, "addr", "localhost:8081", "Access WebTransport 地址")
+	flag.IntVar(&o.users, "users", 100, "会话数（每个会话一个用户）")
+	flag.IntVar(&o.concurrency, "concurrency", 50, "建连并发数")
+	flag.StringVar(&o.workloads, "workloads", "dm", "负载列表，逗号分隔：dm（单聊乒乓）、group（群聊扇出）、room（房间创建/加入）")
+	flag.DurationVar(&o.duration, "duration", 30*time.Second, "每个负载的运行时长")
+	flag.DurationVar(&o.timeout, "timeout", 5*time.Second, "单次请求/推送的等待超时")
+	flag.BoolVar(&o.jsonOutput, "json", false, "以 JSON 输出报告")
+
+	flag.StringVar(&o.tokens, "tokens", "redis", "令牌来源：redis（直接写入 Redis）或 web（通过 web-go 登录）")
+	flag.StringVar(&o.webURL, "web", "http://localhost:8082", "web-go 地址（-tokens web）")
+	flag.StringVar(&o.userPrefix, "user-prefix", "imbench", "测试用户名前缀（-tokens web）")
+	flag.StringVar(&o.password, "password", "imbench123", "测试用户密码（-tokens web）")
+	flag.StringVar(&o.redisAddr, "redis", "localhost:6379", "Redis 地址（-tokens redis）")
+	flag.StringVar(&o.redisPassword, "redis-password", "", "Redis 密码（-tokens redis）")
+	flag.IntVar(&o.redisDB, "redis-db", 0, "Redis DB（-tokens redis）")
+	flag.Int64Var(&o.userBase, "user-base", 9_000_000_000, "测试用户起始 ID（-tokens redis）")
+
+	flag.Int64Var(&o.groupID, "group", 0, "群 ID（group 负载必需，测试用户需为群成员）")
+	flag.IntVar(&o.senders, "senders", 1, "群聊发送者数（group）")
+	flag.Float64Var(&o.rate, "rate", 10, "每个发送者每秒消息数（group）")
+	flag.IntVar(&o.roomSize, "room-size", 4, "每个房间的人数（room）")
+	flag.Parse()
+	return o
+}
+
+func main() {
+	o := parseFlags()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	workloads, err := parseWorkloads(o)
+	if err != nil {
+		logger.Error("Invalid workloads", "error", err)
+		os.Exit(2)
+	}
+
+	creds, err := provisionCredentials(ctx, o)
+	if err != nil {
+		logger.Error("Failed to provision credentials", "source", o.tokens, "error", err)
+		os.Exit(1)
+	}
+	logger.Info("Credentials ready", "source", o.tokens, "users", len(creds))
+
+	report := &report{Addr: o.addr, Users: o.users}
+
+	clients, connect := connectAll(ctx, o, creds, logger)
+	report.Connect = connect
+	defer func() {
+		for _, c := range clients {
+			c.close()
+		}
+	}()
+	logger.Info("Sessions connected", "ok", connect.OK, "failed", connect.Failed, "rate", fmt.Sprintf("%.1f/s", connect.Rate))
+
+	for _, w := range workloads {
+		if ctx.Err() != nil {
+			break
+		}
+		logger.Info("Running workload", "name", w.name, "duration", o.duration)
+		wctx, wcancel := context.WithTimeout(ctx, o.duration)
+		stats := w.run(wctx, clients)
+		wcancel()
+		report.Workloads = append(report.Workloads, stats...)
+	}
+
+	if o.jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+		return
+	}
+	report.print(os.Stdout)
+}
+
+// workload 一种脚本化负载
+type workload struct {
+	name string
+	run  func(ctx context.Context, clients []*client) []*summary
+}
+
+func parseWorkloads(o *options) ([]workload, error) {
+	var workloads []workload
+	for _, name := range strings.Split(o.workloads, ",") {
+		switch strings.TrimSpace(name) {
+		case "dm":
+			workloads = append(workloads, workload{"dm", func(ctx context.Context, clients []*client) []*summary {
+				return runDirectPingPong(ctx, clients, o.timeout)
+			}})
+		case "group":
+			if o.groupID <= 0 {
+				return nil, fmt.Errorf("group workload requires -group")
+			}
+			workloads = append(workloads, workload{"group", func(ctx context.Context, clients []*client) []*summary {
+				return runGroupFanout(ctx, clients, o.groupID, o.senders, o.rate, o.timeout)
+			}})
+		case "room":
+			if o.roomSize < 1 {
+				return nil, fmt.Errorf("room workload requires -room-size >= 1")
+			}
+			workloads = append(workloads, workload{"room", func(ctx context.Context, clients []*client) []*summary {
+				return runRoomCreateJoin(ctx, clients, o.roomSize, o.timeout)
+			}})
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown workload %q", name)
+		}
+	}
+	if len(workloads) == 0 {
+		return nil, fmt.Errorf("no workloads")
+	}
+	return workloads, nil
+}
+
+// provisionCredentials 按令牌来源准备测试用户
+func provisionCredentials(ctx context.Context, o *options) ([]credential, error) {
+	switch o.tokens {
+	case "redis":
+		rdb := goredis.NewClient(&goredis.Options{Addr: o.redisAddr, Password: o.redisPassword, DB: o.redisDB})
+		defer rdb.Close()
+		return seedRedisTokens(ctx, rdb, o.userBase, o.users, 24*time.Hour)
+	case "web":
+		return loginWebUsers(ctx, o.webURL, o.userPrefix, o.password, o.users, o.concurrency)
+	default:
+		return nil, fmt.Errorf("unknown token source %q", o.tokens)
+	}
+}
+
+// connectAll 以固定并发建立全部会话并完成认证
+func connectAll(ctx context.Context, o *options, creds []credential, logger *slog.Logger) ([]*client, *summary) {
+	dialer := &webtransport.Dialer{
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true, // 本地自签名证书
+			NextProtos:         []string{"h3"},
+		},
+		QUICConfig: &quic.Config{
+			MaxIdleTimeout:  60 * time.Second,
+			KeepAlivePeriod: 15 * time.Second,
+			EnableDatagrams: true,
+		},
+	}
+	url := "https://" + o.addr + "/webtransport"
+
+	rec := newRecorder("connect")
+	results := make([]*client, len(creds))
+	sem := make(chan struct{}, max(o.concurrency, 1))
+	var wg sync.WaitGroup
+
+	start := time.Now()
+	for i, cred := range creds {
+		if ctx.Err() != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, cred credential) {
+			defer func() { <-sem; wg.Done() }()
+			dialCtx, cancel := context.WithTimeout(ctx, o.timeout)
+			defer cancel()
+
+			began := time.Now()
+			c, err := dial(dialCtx, dialer, url, cred)
+			if err != nil {
+				rec.fail(errorReason(err))
+				logger.Debug("Connect failed", "userId", cred.UserID, "error", err)
+				return
+			}
+			rec.observe(time.Since(began))
+			c.start(ctx)
+			results[i] = c
+		}(i, cred)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	clients := make([]*client, 0, len(results))
+	for _, c := range results {
+		if c != nil {
+			clients = append(clients, c)
+		}
+	}
+	return clients, rec.summarize(elapsed)
+}
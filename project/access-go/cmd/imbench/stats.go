@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// recorder 记录一类操作的耗时样本与错误
+type recorder struct {
+	name    string
+	mu      sync.Mutex
+	samples []time.Duration
+	errors  map[string]int
+}
+
+func newRecorder(name string) *recorder {
+	return &recorder{name: name, errors: make(map[string]int)}
+}
+
+func (r *recorder) observe(d time.Duration) {
+	r.mu.Lock()
+	r.samples = append(r.samples, d)
+	r.mu.Unlock()
+}
+
+func (r *recorder) fail(reason string) {
+	r.mu.Lock()
+	r.errors[reason]++
+	r.mu.Unlock()
+}
+
+// summary 一类操作的统计结果
+type summary struct {
+	Name   string         `json:"name"`
+	OK     int            `json:"ok"`
+	Failed int            `json:"failed"`
+	Errors map[string]int `json:"errors,omitempty"`
+	Rate   float64        `json:"rate"` // 成功次数/秒
+	P50    time.Duration  `json:"p50"`
+	P99    time.Duration  `json:"p99"`
+	Max    time.Duration  `json:"max"`
+}
+
+// summarize 汇总样本，elapsed 为统计窗口时长
+func (r *recorder) summarize(elapsed time.Duration) *summary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sorted := append([]time.Duration(nil), r.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	s := &summary{Name: r.name, OK: len(sorted), Errors: make(map[string]int, len(r.errors))}
+	for reason, n := range r.errors {
+		s.Errors[reason] = n
+		s.Failed += n
+	}
+	if elapsed > 0 {
+		s.Rate = float64(s.OK) / elapsed.Seconds()
+	}
+	if len(sorted) > 0 {
+		s.P50 = percentile(sorted, 0.50)
+		s.P99 = percentile(sorted, 0.99)
+		s.Max = sorted[len(sorted)-1]
+	}
+	return s
+}
+
+// percentile 取已排序样本的分位值（最近秩法）
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p*float64(len(sorted))+0.5) - 1
+	rank = min(max(rank, 0), len(sorted)-1)
+	return sorted[rank]
+}
+
+// report 压测报告
+type report struct {
+	Addr      string     `json:"addr"`
+	Users     int        `json:"users"`
+	Connect   *summary   `json:"connect"`
+	Workloads []*summary `json:"workloads"`
+}
+
+func (r *report) print(w io.Writer) {
+	fmt.Fprintf(w, "imbench %s users=%d\n\n", r.Addr, r.Users)
+	fmt.Fprintf(w, "%-14s %8s %8s %10s %10s %10s %10s  %s\n", "METRIC", "OK", "FAILED", "RATE/s", "P50", "P99", "MAX", "ERRORS")
+	for _, s := range append([]*summary{r.Connect}, r.Workloads...) {
+		fmt.Fprintf(w, "%-14s %8d %8d %10.1f %10s %10s %10s  %s\n",
+			s.Name, s.OK, s.Failed, s.Rate, round(s.P50), round(s.P99), round(s.Max), formatErrors(s.Errors))
+	}
+}
+
+func round(d time.Duration) time.Duration {
+	if d >= time.Millisecond {
+		return d.Round(10 * time.Microsecond)
+	}
+	return d.Round(time.Microsecond)
+}
+
+func formatErrors(errors map[string]int) string {
+	if len(errors) == 0 {
+		return "-"
+	}
+	reasons := make([]string, 0, len(errors))
+	for reason := range errors {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	parts := make([]string, len(reasons))
+	for i, reason := range reasons {
+		parts[i] = fmt.Sprintf("%s=%d", reason, errors[reason])
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecorderSummarize(t *testing.T) {
+	rec := newRecorder("test")
+	for i := 100; i >= 1; i-- {
+		rec.observe(time.Duration(i) * time.Millisecond)
+	}
+	rec.fail("timeout")
+	rec.fail("timeout")
+	rec.fail("ROOM_FULL")
+
+	s := rec.summarize(10 * time.Second)
+	if s.OK != 100 || s.Failed != 3 || s.Errors["timeout"] != 2 {
+		t.Fatalf("计数不正确: %+v", s)
+	}
+	if s.P50 != 50*time.Millisecond || s.P99 != 99*time.Millisecond || s.Max != 100*time.Millisecond {
+		t.Fatalf("分位不正确: p50=%v p99=%v max=%v", s.P50, s.P99, s.Max)
+	}
+	if s.Rate != 10 {
+		t.Fatalf("速率 = %v, want 10", s.Rate)
+	}
+
+	if empty := newRecorder("empty").summarize(time.Second); empty.P99 != 0 || empty.OK != 0 {
+		t.Fatalf("空样本统计不正确: %+v", empty)
+	}
+}
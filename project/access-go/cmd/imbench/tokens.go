@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	imRedis "sudooom.im.access/internal/redis"
+	sharedRedis "sudooom.im.shared/redis"
+)
+
+// benchPlatform 压测会话使用的平台（与 AuthRequest 的 PlatformWEB 对应）
+const benchPlatform = "web"
+
+// credential 一个测试用户的认证信息
+type credential struct {
+	UserID   int64
+	Token    string
+	DeviceID string
+}
+
+// seedRedisTokens 直接在 Redis 中写入测试用户令牌，格式与 web-go 登录时写入的一致
+func seedRedisTokens(ctx context.Context, rdb *goredis.Client, base int64, n int, ttl time.Duration) ([]credential, error) {
+	creds := make([]credential, n)
+	pipe := rdb.Pipeline()
+	for i := range creds {
+		token, err := randomToken()
+		if err != nil {
+			return nil, err
+		}
+		cred := credential{UserID: base + int64(i), Token: "imbench-" + token, DeviceID: "imbench-" + strconv.Itoa(i)}
+		info, err := json.Marshal(&imRedis.UserTokenInfo{UserID: cred.UserID, DeviceID: cred.DeviceID, Platform: benchPlatform})
+		if err != nil {
+			return nil, err
+		}
+		pipe.Set(ctx, sharedRedis.BuildTokenInfoKey(cred.Token), info, ttl)
+		pipe.Set(ctx, sharedRedis.BuildUserTokenKey(cred.UserID, benchPlatform), cred.Token, ttl)
+		creds[i] = cred
+
+		if pipe.Len() >= 1000 {
+			if _, err := pipe.Exec(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+	return creds, nil
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// webResponse web-go 统一响应
+type webResponse struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// loginWebUsers 通过 web-go 注册（已存在则忽略）并登录测试用户
+func loginWebUsers(ctx context.Context, baseURL, prefix, password string, n, concurrency int) ([]credential, error) {
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	creds := make([]credential, n)
+	errs := make([]error, n)
+	sem := make(chan struct{}, max(concurrency, 1))
+	var wg sync.WaitGroup
+
+	for i := range creds {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() { <-sem; wg.Done() }()
+			username := fmt.Sprintf("%s%d", prefix, i)
+			deviceID := "imbench-" + strconv.Itoa(i)
+
+			// 注册失败（通常是用户名已存在）不影响后续登录
+			_, _ = postJSON(ctx, httpClient, baseURL+"/api/v1/auth/register", map[string]string{
+				"username": username,
+				"password": password,
+				"nickname": username,
+			})
+
+			data, err := postJSON(ctx, httpClient, baseURL+"/api/v1/auth/login", map[string]string{
+				"username": username,
+				"password": password,
+				"deviceId": deviceID,
+				"platform": benchPlatform,
+			})
+			if err != nil {
+				errs[i] = fmt.Errorf("login %s: %w", username, err)
+				return
+			}
+			var login struct {
+				UserID      int64  `json:"userId,string"`
+				AccessToken string `json:"accessToken"`
+			}
+			if err := json.Unmarshal(data, &login); err != nil {
+				errs[i] = fmt.Errorf("login %s: %w", username, err)
+				return
+			}
+			creds[i] = credential{UserID: login.UserID, Token: login.AccessToken, DeviceID: deviceID}
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return creds, nil
+}
+
+func postJSON(ctx context.Context, httpClient *http.Client, url string, body interface{}) (json.RawMessage, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result webResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("status %d: %w", resp.StatusCode, err)
+	}
+	if result.Code != 0 {
+		return nil, fmt.Errorf("code %d: %s", result.Code, result.Message)
+	}
+	return result.Data, nil
+}
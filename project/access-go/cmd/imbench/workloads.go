@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+	im_protocol "sudooom.im.access/pkg/flatbuf/im/protocol"
+)
+
+// contentPrefix 压测消息内容前缀，后接发送时间（UnixNano），接收方据此计算推送延迟
+const contentPrefix = "imbench:"
+
+// buildChatSend 构建携带发送时间的 ChatSendReq
+func buildChatSend(chatType im_protocol.ChatType, targetID int64, sent time.Time) []byte {
+	builder := flatbuffers.NewBuilder(128)
+	targetOffset := builder.CreateString(strconv.FormatInt(targetID, 10))
+	contentOffset := builder.CreateString(contentPrefix + strconv.FormatInt(sent.UnixNano(), 10))
+	im_protocol.ChatSendReqStart(builder)
+	im_protocol.ChatSendReqAddChatType(builder, chatType)
+	im_protocol.ChatSendReqAddTargetId(builder, targetOffset)
+	im_protocol.ChatSendReqAddMsgType(builder, im_protocol.MsgTypeTEXT)
+	im_protocol.ChatSendReqAddContent(builder, contentOffset)
+	builder.Finish(im_protocol.ChatSendReqEnd(builder))
+	return builder.FinishedBytes()
+}
+
+// parseChatPush 解析压测消息的推送，返回聊天类型、目标 ID 与发送时间
+func parseChatPush(resp *im_protocol.ClientResponse) (*im_protocol.ChatPush, time.Time, bool) {
+	if resp.PayloadType() != im_protocol.ResponsePayloadChatPush {
+		return nil, time.Time{}, false
+	}
+	push := im_protocol.GetRootAsChatPush(resp.PayloadBytes(), 0)
+	content, ok := strings.CutPrefix(string(push.Content()), contentPrefix)
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	nanos, err := strconv.ParseInt(content, 10, 64)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	return push, time.Unix(0, nanos), true
+}
+
+// resetPushHandlers 负载结束后清除推送处理器
+func resetPushHandlers(clients []*client) {
+	for _, c := range clients {
+		c.setPushHandler(func(*im_protocol.ClientResponse) {})
+	}
+}
+
+// runDirectPingPong 单聊乒乓：客户端两两配对，轮流向对方发送消息，收到推送后再发下一条
+func runDirectPingPong(ctx context.Context, clients []*client, timeout time.Duration) []*summary {
+	ack := newRecorder("dm.ack")
+	push := newRecorder("dm.push")
+
+	arrived := make(map[*client]chan struct{}, len(clients))
+	for _, c := range clients {
+		ch := make(chan struct{}, 1)
+		arrived[c] = ch
+		c.setPushHandler(func(resp *im_protocol.ClientResponse) {
+			p, sent, ok := parseChatPush(resp)
+			if !ok || p.ChatType() != im_protocol.ChatTypePRIVATE {
+				return
+			}
+			push.observe(time.Since(sent))
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		})
+	}
+	defer resetPushHandlers(clients)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i+1 < len(clients); i += 2 {
+		pair := [2]*client{clients[i], clients[i+1]}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for round := 0; ctx.Err() == nil; round++ {
+				from, to := pair[round%2], pair[1-round%2]
+
+				// 丢弃上一轮超时后迟到的推送信号
+				select {
+				case <-arrived[to]:
+				default:
+				}
+
+				sent := time.Now()
+				if _, err := from.call(ctx, im_protocol.RequestPayloadChatSendReq, buildChatSend(im_protocol.ChatTypePRIVATE, to.userID, sent), timeout); err != nil {
+					if ctx.Err() == nil {
+						ack.fail(errorReason(err))
+					}
+					continue
+				}
+				ack.observe(time.Since(sent))
+
+				select {
+				case <-arrived[to]:
+				case <-time.After(timeout):
+					push.fail("timeout")
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	return []*summary{ack.summarize(elapsed), push.summarize(elapsed)}
+}
+
+// runGroupFanout 群聊扇出：前 senders 个客户端按固定速率向群发送消息，所有在线群成员记录推送延迟
+// 发送为同步等待 ACK，ACK 变慢时实际速率低于 rate
+func runGroupFanout(ctx context.Context, clients []*client, groupID int64, senders int, rate float64, timeout time.Duration) []*summary {
+	ack := newRecorder("group.ack")
+	push := newRecorder("group.push")
+
+	for _, c := range clients {
+		c.setPushHandler(func(resp *im_protocol.ClientResponse) {
+			p, sent, ok := parseChatPush(resp)
+			if !ok || p.ChatType() != im_protocol.ChatTypeGROUP || string(p.TargetId()) != strconv.FormatInt(groupID, 10) {
+				return
+			}
+			push.observe(time.Since(sent))
+		})
+	}
+	defer resetPushHandlers(clients)
+
+	interval := time.Second
+	if rate > 0 {
+		interval = time.Duration(float64(time.Second) / rate)
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, sender := range clients[:min(max(senders, 1), len(clients))] {
+		wg.Add(1)
+		go func(sender *client) {
+			defer wg.Done()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+				sent := time.Now()
+				if _, err := sender.call(ctx, im_protocol.RequestPayloadChatSendReq, buildChatSend(im_protocol.ChatTypeGROUP, groupID, sent), timeout); err != nil {
+					if ctx.Err() == nil {
+						ack.fail(errorReason(err))
+					}
+					continue
+				}
+				ack.observe(time.Since(sent))
+			}
+		}(sender)
+	}
+	wg.Wait()
+
+	// 等待最后一批扇出推送到达
+	time.Sleep(min(timeout, time.Second))
+	elapsed := time.Since(start)
+
+	return []*summary{ack.summarize(elapsed), push.summarize(elapsed)}
+}
+
+// buildRoomReq 构建房间请求
+func buildRoomReq(action im_protocol.RoomAction, roomID string) []byte {
+	builder := flatbuffers.NewBuilder(128)
+	roomIDOffset := builder.CreateString(roomID)
+	var configOffset flatbuffers.UOffsetT
+	if action == im_protocol.RoomActionCREATE {
+		configOffset = builder.CreateString(`{"roomName":"imbench"}`)
+	}
+	im_protocol.RoomReqStart(builder)
+	im_protocol.RoomReqAddAction(builder, action)
+	im_protocol.RoomReqAddGameType(builder, im_protocol.GameTypeHT_MAHJONG)
+	im_protocol.RoomReqAddRoomId(builder, roomIDOffset)
+	if configOffset != 0 {
+		im_protocol.RoomReqAddRoomConfig(builder, configOffset)
+	}
+	builder.Finish(im_protocol.RoomReqEnd(builder))
+	return builder.FinishedBytes()
+}
+
+// runRoomCreateJoin 房间创建/加入：客户端按 roomSize 分组，组内首个客户端创建房间，其余加入，随后全部离开，循环往复
+// 延迟为请求到对应 RoomResp 的往返时间
+func runRoomCreateJoin(ctx context.Context, clients []*client, roomSize int, timeout time.Duration) []*summary {
+	create := newRecorder("room.create")
+	join := newRecorder("room.join")
+	leave := newRecorder("room.leave")
+
+	// roomCall 发送房间请求并记录结果，返回房间 ID
+	roomCall := func(rec *recorder, c *client, action im_protocol.RoomAction, roomID string) (string, bool) {
+		sent := time.Now()
+		resp, err := c.call(ctx, im_protocol.RequestPayloadRoomReq, buildRoomReq(action, roomID), timeout)
+		if err != nil {
+			if ctx.Err() == nil {
+				rec.fail(errorReason(err))
+			}
+			return "", false
+		}
+		rec.observe(time.Since(sent))
+		if resp.PayloadType() != im_protocol.ResponsePayloadRoomResp {
+			return roomID, true
+		}
+		return string(im_protocol.GetRootAsRoomResp(resp.PayloadBytes(), 0).RoomId()), true
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i+roomSize <= len(clients); i += roomSize {
+		members := clients[i : i+roomSize]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				owner := members[0]
+				roomID, ok := roomCall(create, owner, im_protocol.RoomActionCREATE, "")
+				if !ok || roomID == "" {
+					// 失败时稍作等待，避免错误风暴
+					select {
+					case <-ctx.Done():
+					case <-time.After(100 * time.Millisecond):
+					}
+					continue
+				}
+
+				joined := make([]bool, len(members))
+				var jwg sync.WaitGroup
+				for j := 1; j < len(members); j++ {
+					jwg.Add(1)
+					go func(j int) {
+						defer jwg.Done()
+						_, joined[j] = roomCall(join, members[j], im_protocol.RoomActionJOIN, roomID)
+					}(j)
+				}
+				jwg.Wait()
+
+				// 成员先离开，房主最后离开
+				for j := len(members) - 1; j >= 1; j-- {
+					if joined[j] {
+						roomCall(leave, members[j], im_protocol.RoomActionLEAVE, roomID)
+					}
+				}
+				roomCall(leave, owner, im_protocol.RoomActionLEAVE, roomID)
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	return []*summary{create.summarize(elapsed), join.summarize(elapsed), leave.summarize(elapsed)}
+}
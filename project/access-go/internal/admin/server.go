@@ -0,0 +1,451 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"sudooom.im.access/internal/config"
+	"sudooom.im.access/internal/connection"
+	"sudooom.im.access/internal/handler"
+	im_protocol "sudooom.im.access/pkg/flatbuf/im/protocol"
+	sharedErrors "sudooom.im.shared/errors"
+	"sudooom.im.shared/proto"
+)
+
+const (
+	defaultListLimit = 1000
+	maxListLimit     = 10000
+	maxBodySize      = 64 * 1024
+	logicTimeout     = 5 * time.Second // 转发给 Logic 的请求超时
+)
+
+// Response 统一响应结构（与 web-go 一致）
+type Response struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data"`
+}
+
+// ConnectionInfo 连接信息
+type ConnectionInfo struct {
+	ConnID     int64   `json:"conn_id"`
+	UserID     int64   `json:"user_id"`
+	DeviceID   string  `json:"device_id"`
+	Platform   string  `json:"platform"`
+	Transport  string  `json:"transport"`
+	RemoteAddr string  `json:"remote_addr"`
+	RTTMs      float64 `json:"rtt_ms"` // 0 表示传输层不支持
+	AgeSec     int64   `json:"age_sec"`
+	IdleSec    int64   `json:"idle_sec"`
+}
+
+// KickRequest 踢人请求：指定 conn_id 时只踢该连接，否则踢用户（可按平台过滤）的所有连接
+// reason_code 为 KickReason 名称（NEW_LOGIN / LOGOUT / BANNED / ADMIN），默认 ADMIN
+type KickRequest struct {
+	UserID     int64  `json:"user_id"`
+	Platform   string `json:"platform"`
+	ConnID     int64  `json:"conn_id"`
+	ReasonCode string `json:"reason_code"`
+	Reason     string `json:"reason"`
+}
+
+// PushRequest 系统推送请求：broadcast 为 true 时推送给本节点所有已认证连接，否则推送给指定用户
+type PushRequest struct {
+	UserID    int64  `json:"user_id"`
+	Platform  string `json:"platform"`
+	Broadcast bool   `json:"broadcast"`
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	Level     string `json:"level"`
+	ActionURL string `json:"action_url"`
+}
+
+// AnnounceRequest 系统公告请求：经 Logic 投递到所有节点，ttl_seconds 大于 0 时持久化，有效期内上线的用户也会收到
+// 目标为 user_ids 指定的用户，或 all 为 true 时的所有人；platform 可按平台过滤
+type AnnounceRequest struct {
+	UserIDs    []int64 `json:"user_ids"`
+	Platform   string  `json:"platform"`
+	All        bool    `json:"all"`
+	Title      string  `json:"title"`
+	Content    string  `json:"content"`
+	Level      string  `json:"level"`
+	ActionURL  string  `json:"action_url"`
+	TTLSeconds int64   `json:"ttl_seconds"`
+}
+
+// Server Access 节点管理接口，供值班排障使用：查看连接、踢人、系统推送
+type Server struct {
+	cfg          config.AdminConfig
+	connMgr      *connection.Manager
+	handler      *handler.Handler
+	backpressure *connection.Backpressure
+	logger       *slog.Logger
+	httpServer   *http.Server
+}
+
+// New 创建管理接口
+func New(cfg config.AdminConfig, connMgr *connection.Manager, h *handler.Handler, backpressure *connection.Backpressure, logger *slog.Logger) *Server {
+	s := &Server{
+		cfg:          cfg,
+		connMgr:      connMgr,
+		handler:      h,
+		backpressure: backpressure,
+		logger:       logger,
+	}
+	s.httpServer = &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return s
+}
+
+// Handler 返回带认证的路由
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /admin/connections", s.listConnections)
+	mux.HandleFunc("GET /admin/stats", s.stats)
+	mux.HandleFunc("POST /admin/kick", s.kick)
+	mux.HandleFunc("POST /admin/push", s.push)
+	mux.HandleFunc("POST /admin/announce", s.announce)
+	mux.HandleFunc("GET /admin/nodes", s.nodes)
+	return s.auth(mux)
+}
+
+// Start 监听管理端口（非阻塞）
+func (s *Server) Start() error {
+	if s.cfg.Token == "" {
+		return errors.New("admin.token is required when admin.addr is set")
+	}
+
+	listener, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("Admin server error", "error", err)
+		}
+	}()
+
+	s.logger.Info("Admin server started", "addr", s.cfg.Addr)
+	return nil
+}
+
+// Close 关闭管理接口
+func (s *Server) Close() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		s.logger.Error("Failed to shutdown admin server", "error", err)
+	}
+}
+
+// auth 校验 Authorization: Bearer <token>
+func (s *Server) auth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || s.cfg.Token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.Token)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, Response{Code: sharedErrors.CodeTokenInvalid, Message: "unauthorized"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// listConnections 列出连接，支持 user_id / platform 过滤与 limit
+func (s *Server) listConnections(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var userID int64
+	if v := query.Get("user_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			invalidParams(w, "invalid user_id")
+			return
+		}
+		userID = id
+	}
+	platform := query.Get("platform")
+
+	limit := defaultListLimit
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			invalidParams(w, "invalid limit")
+			return
+		}
+		limit = min(n, maxListLimit)
+	}
+
+	var conns []*connection.Connection
+	if userID > 0 {
+		conns = s.connMgr.GetByUserID(userID)
+	} else {
+		conns = s.connMgr.GetAllConnections()
+	}
+	sort.Slice(conns, func(i, j int) bool { return conns[i].ID() < conns[j].ID() })
+
+	now := time.Now()
+	list := make([]ConnectionInfo, 0, min(len(conns), limit))
+	for _, c := range conns {
+		if platform != "" && !strings.EqualFold(c.Platform(), platform) {
+			continue
+		}
+		if len(list) >= limit {
+			break
+		}
+		list = append(list, connectionInfo(c, now))
+	}
+
+	success(w, map[string]interface{}{
+		"total": len(conns),
+		"list":  list,
+	})
+}
+
+// stats 连接统计：总数、按平台与传输类型分布、写队列背压计数
+func (s *Server) stats(w http.ResponseWriter, _ *http.Request) {
+	conns := s.connMgr.GetAllConnections()
+
+	authenticated := 0
+	byPlatform := make(map[string]int)
+	byTransport := make(map[string]int)
+	for _, c := range conns {
+		byTransport[c.Transport()]++
+		if c.UserID() > 0 {
+			authenticated++
+			byPlatform[c.Platform()]++
+		}
+	}
+
+	success(w, map[string]interface{}{
+		"total":         len(conns),
+		"authenticated": authenticated,
+		"by_platform":   byPlatform,
+		"by_transport":  byTransport,
+		"backpressure":  s.backpressure.Stats(),
+	})
+}
+
+// kick 踢掉用户或单个连接
+func (s *Server) kick(w http.ResponseWriter, r *http.Request) {
+	var req KickRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.ConnID <= 0 && req.UserID <= 0 {
+		invalidParams(w, "user_id or conn_id is required")
+		return
+	}
+	reasonCode := im_protocol.KickReasonADMIN
+	if req.ReasonCode != "" {
+		code, ok := im_protocol.EnumValuesKickReason[strings.ToUpper(req.ReasonCode)]
+		if !ok {
+			invalidParams(w, "invalid reason_code")
+			return
+		}
+		reasonCode = code
+	}
+	if req.Reason == "" {
+		req.Reason = "kicked by admin"
+	}
+
+	targets := s.targets(req.ConnID, req.UserID, req.Platform)
+	for _, c := range targets {
+		s.handler.Kick(c, reasonCode, req.Reason)
+	}
+
+	s.logger.Warn("Admin kick",
+		"remote_addr", r.RemoteAddr,
+		"user_id", req.UserID,
+		"conn_id", req.ConnID,
+		"platform", req.Platform,
+		"reason_code", reasonCode.String(),
+		"reason", req.Reason,
+		"kicked", len(targets))
+	success(w, map[string]interface{}{"kicked": len(targets)})
+}
+
+// push 向用户或本节点所有连接推送 SystemPush
+func (s *Server) push(w http.ResponseWriter, r *http.Request) {
+	var req PushRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if !req.Broadcast && req.UserID <= 0 {
+		invalidParams(w, "user_id is required unless broadcast is true")
+		return
+	}
+	if req.Title == "" && req.Content == "" {
+		invalidParams(w, "title or content is required")
+		return
+	}
+
+	var targets []*connection.Connection
+	if req.Broadcast {
+		for _, c := range s.connMgr.GetAllConnections() {
+			if c.UserID() > 0 && (req.Platform == "" || strings.EqualFold(c.Platform(), req.Platform)) {
+				targets = append(targets, c)
+			}
+		}
+	} else {
+		targets = s.targets(0, req.UserID, req.Platform)
+	}
+
+	notice := handler.SystemNotice{
+		Title:     req.Title,
+		Content:   req.Content,
+		Level:     strings.ToUpper(req.Level),
+		ActionURL: req.ActionURL,
+	}
+	delivered := 0
+	for _, c := range targets {
+		if err := s.handler.PushSystem(c, notice); err != nil {
+			s.logger.Warn("Admin push failed", "conn_id", c.ID(), "error", err)
+			continue
+		}
+		delivered++
+	}
+
+	s.logger.Info("Admin push",
+		"remote_addr", r.RemoteAddr,
+		"user_id", req.UserID,
+		"broadcast", req.Broadcast,
+		"targets", len(targets),
+		"delivered", delivered)
+	success(w, map[string]interface{}{
+		"targets":   len(targets),
+		"delivered": delivered,
+	})
+}
+
+// announce 发布全局系统公告（转发给 Logic）
+func (s *Server) announce(w http.ResponseWriter, r *http.Request) {
+	var req AnnounceRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if !req.All && len(req.UserIDs) == 0 {
+		invalidParams(w, "user_ids is required unless all is true")
+		return
+	}
+	if req.Title == "" && req.Content == "" {
+		invalidParams(w, "title or content is required")
+		return
+	}
+
+	result, err := s.handler.Announce(&proto.SystemAnnouncement{
+		UserIds:    req.UserIDs,
+		Platform:   req.Platform,
+		All:        req.All,
+		Title:      req.Title,
+		Content:    req.Content,
+		Level:      strings.ToUpper(req.Level),
+		ActionUrl:  req.ActionURL,
+		TTLSeconds: req.TTLSeconds,
+	}, logicTimeout)
+	if err != nil {
+		s.logger.Error("Admin announce failed", "error", err)
+		writeJSON(w, http.StatusServiceUnavailable, Response{Code: sharedErrors.CodeServerError, Message: "logic unavailable: " + err.Error()})
+		return
+	}
+	if result.Error != "" {
+		invalidParams(w, result.Error)
+		return
+	}
+
+	s.logger.Info("Admin announce",
+		"remote_addr", r.RemoteAddr,
+		"all", req.All,
+		"users", len(req.UserIDs),
+		"platform", req.Platform,
+		"ttl_seconds", req.TTLSeconds,
+		"announcement_id", result.AnnouncementId)
+	success(w, map[string]interface{}{
+		"announcement_id": result.AnnouncementId,
+		"delivered":       result.Delivered,
+	})
+}
+
+// nodes 查询存活的 Access 节点（由 Logic 根据节点心跳维护）
+func (s *Server) nodes(w http.ResponseWriter, r *http.Request) {
+	nodes, err := s.handler.AccessNodes(logicTimeout)
+	if err != nil {
+		s.logger.Error("Admin list nodes failed", "error", err)
+		writeJSON(w, http.StatusServiceUnavailable, Response{Code: sharedErrors.CodeServerError, Message: "logic unavailable: " + err.Error()})
+		return
+	}
+	success(w, map[string]interface{}{
+		"total": len(nodes),
+		"list":  nodes,
+	})
+}
+
+// targets 按连接 ID 或 用户+平台 查找已认证连接
+func (s *Server) targets(connID, userID int64, platform string) []*connection.Connection {
+	if connID > 0 {
+		c := s.connMgr.Get(connID)
+		if c == nil || (userID > 0 && c.UserID() != userID) {
+			return nil
+		}
+		return []*connection.Connection{c}
+	}
+
+	var targets []*connection.Connection
+	for _, c := range s.connMgr.GetByUserID(userID) {
+		if platform == "" || strings.EqualFold(c.Platform(), platform) {
+			targets = append(targets, c)
+		}
+	}
+	return targets
+}
+
+func connectionInfo(c *connection.Connection, now time.Time) ConnectionInfo {
+	info := ConnectionInfo{
+		ConnID:    c.ID(),
+		UserID:    c.UserID(),
+		DeviceID:  c.DeviceID(),
+		Platform:  c.Platform(),
+		Transport: c.Transport(),
+		RTTMs:     float64(c.RTT().Microseconds()) / 1000,
+		AgeSec:    int64(now.Sub(c.CreateTime()).Seconds()),
+		IdleSec:   int64(now.Sub(c.LastActiveTime()).Seconds()),
+	}
+	if addr := c.Session().RemoteAddr(); addr != nil {
+		info.RemoteAddr = addr.String()
+	}
+	return info
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(v); err != nil {
+		invalidParams(w, "invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func success(w http.ResponseWriter, data interface{}) {
+	writeJSON(w, http.StatusOK, Response{Code: sharedErrors.CodeSuccess, Message: "success", Data: data})
+}
+
+func invalidParams(w http.ResponseWriter, msg string) {
+	writeJSON(w, http.StatusBadRequest, Response{Code: sharedErrors.CodeInvalidParams, Message: msg})
+}
+
+func writeJSON(w http.ResponseWriter, status int, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
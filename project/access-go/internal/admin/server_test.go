@@ -0,0 +1,197 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"sudooom.im.access/internal/config"
+	"sudooom.im.access/internal/connection"
+	"sudooom.im.access/internal/handler"
+)
+
+// fakeSession 记录关闭码的测试会话
+type fakeSession struct {
+	closed chan uint32
+}
+
+func (s *fakeSession) AcceptStream(ctx context.Context) (connection.Stream, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (s *fakeSession) CloseWithError(code uint32, _ string) error {
+	s.closed <- code
+	return nil
+}
+
+func (s *fakeSession) RemoteAddr() net.Addr {
+	return &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 5000}
+}
+
+// captureStream 记录写入帧的测试流
+type captureStream struct {
+	frames chan []byte
+}
+
+func (s *captureStream) Read([]byte) (int, error) { return 0, io.EOF }
+func (s *captureStream) Write(p []byte) (int, error) {
+	s.frames <- append([]byte(nil), p...)
+	return len(p), nil
+}
+func (s *captureStream) Close() error { return nil }
+
+type testEnv struct {
+	server  *Server
+	connMgr *connection.Manager
+}
+
+func newTestEnv() *testEnv {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	connMgr := connection.NewManager()
+	resumeStore := connection.NewResumeStore(0, 0, logger)
+	rateLimiter := connection.NewRateLimiter(nil, 0, 0)
+	h := handler.NewHandler(connMgr, resumeStore, rateLimiter, nil, nil, "access-test", 0, 0, logger, nil)
+	s := New(config.AdminConfig{Addr: "127.0.0.1:0", Token: "secret"}, connMgr, h, connection.NewBackpressure(0, nil), logger)
+	return &testEnv{server: s, connMgr: connMgr}
+}
+
+// addConn 添加已认证连接
+func (e *testEnv) addConn(userID int64, platform string) (*connection.Connection, *fakeSession, *captureStream) {
+	session := &fakeSession{closed: make(chan uint32, 1)}
+	stream := &captureStream{frames: make(chan []byte, 8)}
+	c := connection.New(session, connection.TransportTCP, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	c.SetClientStream(stream)
+	c.BindSession(&connection.SessionInfo{UserID: userID, DeviceID: "device", Platform: platform})
+	e.connMgr.Add(c)
+	e.connMgr.BindUser(c.ID(), userID, platform)
+	return c, session, stream
+}
+
+func (e *testEnv) do(t *testing.T, method, path, token string, body interface{}) (int, Response) {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		reader = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	e.server.Handler().ServeHTTP(rec, req)
+
+	var resp Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("响应不是 JSON: %s", rec.Body.String())
+	}
+	return rec.Code, resp
+}
+
+// TestAdminAuth 测试未携带或携带错误令牌时拒绝访问
+func TestAdminAuth(t *testing.T) {
+	env := newTestEnv()
+	for _, token := range []string{"", "wrong"} {
+		if status, _ := env.do(t, http.MethodGet, "/admin/stats", token, nil); status != http.StatusUnauthorized {
+			t.Errorf("令牌 %q 应被拒绝，实际状态码 %d", token, status)
+		}
+	}
+	if status, _ := env.do(t, http.MethodGet, "/admin/stats", "secret", nil); status != http.StatusOK {
+		t.Errorf("正确令牌应放行，实际状态码 %d", status)
+	}
+}
+
+// TestAdminStatsAndList 测试连接统计与列表，平台过滤不区分大小写
+func TestAdminStatsAndList(t *testing.T) {
+	env := newTestEnv()
+	env.addConn(1, "WEB")
+	env.addConn(1, "IOS")
+	env.addConn(2, "WEB")
+
+	_, resp := env.do(t, http.MethodGet, "/admin/stats", "secret", nil)
+	data := resp.Data.(map[string]interface{})
+	byPlatform := data["by_platform"].(map[string]interface{})
+	if data["total"].(float64) != 3 || byPlatform["WEB"].(float64) != 2 || byPlatform["IOS"].(float64) != 1 {
+		t.Fatalf("统计不正确: %+v", data)
+	}
+
+	_, resp = env.do(t, http.MethodGet, "/admin/connections?user_id=1&platform=ios", "secret", nil)
+	list := resp.Data.(map[string]interface{})["list"].([]interface{})
+	if len(list) != 1 || list[0].(map[string]interface{})["platform"] != "IOS" {
+		t.Fatalf("列表过滤不正确: %+v", list)
+	}
+
+	if status, _ := env.do(t, http.MethodGet, "/admin/connections?limit=abc", "secret", nil); status != http.StatusBadRequest {
+		t.Fatalf("非法参数应返回 400，实际 %d", status)
+	}
+}
+
+// TestAdminKick 测试按用户踢下线
+func TestAdminKick(t *testing.T) {
+	env := newTestEnv()
+	_, webSession, _ := env.addConn(1, "WEB")
+	_, iosSession, _ := env.addConn(1, "IOS")
+
+	_, resp := env.do(t, http.MethodPost, "/admin/kick", "secret", KickRequest{UserID: 1, Platform: "web", Reason: "maintenance"})
+	if resp.Data.(map[string]interface{})["kicked"].(float64) != 1 {
+		t.Fatalf("应踢掉 1 个连接: %+v", resp)
+	}
+
+	select {
+	case code := <-webSession.closed:
+		if code != connection.CloseCodeKicked {
+			t.Fatalf("关闭码不正确: %d", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("连接未关闭")
+	}
+	select {
+	case <-iosSession.closed:
+		t.Fatalf("其他平台的连接不应被踢")
+	default:
+	}
+
+	if status, _ := env.do(t, http.MethodPost, "/admin/kick", "secret", KickRequest{}); status != http.StatusBadRequest {
+		t.Fatalf("缺少目标应返回 400，实际 %d", status)
+	}
+}
+
+// TestAdminPush 测试按平台广播系统推送
+func TestAdminPush(t *testing.T) {
+	env := newTestEnv()
+	_, _, stream1 := env.addConn(1, "WEB")
+	_, _, stream2 := env.addConn(2, "WEB")
+	_, _, iosStream := env.addConn(3, "IOS")
+
+	_, resp := env.do(t, http.MethodPost, "/admin/push", "secret", PushRequest{Broadcast: true, Platform: "web", Title: "维护通知", Content: "今晚 23:00 维护"})
+	if resp.Data.(map[string]interface{})["delivered"].(float64) != 2 {
+		t.Fatalf("应推送给 2 个连接: %+v", resp)
+	}
+	for _, stream := range []*captureStream{stream1, stream2} {
+		select {
+		case frame := <-stream.frames:
+			if frame[4] != handler.FrameTypeResponse {
+				t.Fatalf("帧类型不正确: %d", frame[4])
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("未收到推送")
+		}
+	}
+	select {
+	case <-iosStream.frames:
+		t.Fatalf("其他平台的连接不应收到推送")
+	default:
+	}
+
+	if status, _ := env.do(t, http.MethodPost, "/admin/push", "secret", PushRequest{Title: "x"}); status != http.StatusBadRequest {
+		t.Fatalf("未指定用户且非广播应返回 400，实际 %d", status)
+	}
+}
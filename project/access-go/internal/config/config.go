@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strings"
 	"time"
 
 	sharedConfig "sudooom.im.shared/config"
@@ -10,24 +11,71 @@ import (
 )
 
 type Config struct {
-	Server  ServerConfig  `yaml:"server"`
-	QUIC    QUICConfig    `yaml:"quic"`
-	NATS    NATSConfig    `yaml:"nats"`
-	Redis   RedisConfig   `yaml:"redis"`
-	Auth    AuthConfig    `yaml:"auth"`
-	Logging LoggingConfig `yaml:"logging"`
+	Server       ServerConfig       `yaml:"server"`
+	Backpressure BackpressureConfig `yaml:"backpressure"`
+	RateLimit    RateLimitConfig    `yaml:"rate_limit"`
+	Admin        AdminConfig        `yaml:"admin"`
+	QUIC         QUICConfig         `yaml:"quic"`
+	NATS         NATSConfig         `yaml:"nats"`
+	Redis        RedisConfig        `yaml:"redis"`
+	Auth         AuthConfig         `yaml:"auth"`
+	Logging      LoggingConfig      `yaml:"logging"`
 }
 
 type ServerConfig struct {
 	Addr                   string        `yaml:"addr"`
+	WSAddr                 string        `yaml:"ws_addr"`         // WebSocket 监听地址（降级传输），为空则不启用
+	WSPath                 string        `yaml:"ws_path"`         // WebSocket 路径，默认 /ws
+	TCPAddr                string        `yaml:"tcp_addr"`        // TCP/TLS 长连接监听地址（原生移动端），为空则不启用
+	AllowedOrigins         []string      `yaml:"allowed_origins"` // 允许建立 WebTransport/WebSocket 的浏览器 Origin，"*" 允许全部，为空时 WebSocket 只允许同源、WebTransport 不校验
 	NodeID                 string        `yaml:"node_id"`
-	MaxConnections         int           `yaml:"max_connections"`
+	MaxConnections         int           `yaml:"max_connections"`          // 全局连接上限（含未认证会话），0 表示不限制
+	MaxConnectionsPerIP    int           `yaml:"max_connections_per_ip"`   // 单 IP 连接上限，0 表示不限制
+	AuthTimeout            time.Duration `yaml:"auth_timeout"`             // 建连后完成首包认证的期限，默认 10s
+	MaxFrameSize           int           `yaml:"max_frame_size"`           // 单帧消息体上限（字节），默认 64KB
 	HeartbeatTimeout       time.Duration `yaml:"heartbeat_timeout"`        // 心跳超时时间，默认 90s
 	HeartbeatCheckInterval time.Duration `yaml:"heartbeat_check_interval"` // 检测间隔，默认 30s
+	HeartbeatInterval      time.Duration `yaml:"heartbeat_interval"`       // 下发给客户端的心跳间隔，默认为超时时间的 1/3
+	ResumeGracePeriod      time.Duration `yaml:"resume_grace_period"`      // 断线后会话保留时长（可携带恢复令牌重连），默认 60s
+	ResumeBufferSize       int           `yaml:"resume_buffer_size"`       // 每个会话缓冲的下行帧数，默认 128
 	WorkerPoolSize         int           `yaml:"worker_pool_size"`         // Worker Pool 大小，默认 1000
 	WorkerQueueSize        int           `yaml:"worker_queue_size"`        // Worker 任务队列大小，默认 10000
 }
 
+// BackpressureConfig 连接写队列背压配置，按载荷类别设置溢出策略
+type BackpressureConfig struct {
+	QueueSize int                  `yaml:"queue_size"` // 每连接写队列长度，默认 256
+	Control   OverflowPolicyConfig `yaml:"control"`    // AuthAck、补发帧等控制类帧
+	Chat      OverflowPolicyConfig `yaml:"chat"`       // ChatPush、ChatSendAck
+	Room      OverflowPolicyConfig `yaml:"room"`       // RoomPush
+	Game      OverflowPolicyConfig `yaml:"game"`       // GamePush
+}
+
+type OverflowPolicyConfig struct {
+	Policy  string        `yaml:"policy"`  // wait / drop_oldest / disconnect，默认 wait
+	Timeout time.Duration `yaml:"timeout"` // wait 策略下帧在溢出区的最长等待时间，默认 1s
+}
+
+// RateLimitConfig 每连接按请求类别的令牌桶限流配置
+type RateLimitConfig struct {
+	Chat            TokenBucketConfig `yaml:"chat"`             // ChatSendReq
+	Room            TokenBucketConfig `yaml:"room"`             // RoomReq
+	Game            TokenBucketConfig `yaml:"game"`             // GameReq
+	MaxViolations   int               `yaml:"max_violations"`   // 窗口内超限次数达到该值时断开连接，0 表示不断开
+	ViolationWindow time.Duration     `yaml:"violation_window"` // 超限计数窗口，默认 10s
+}
+
+type TokenBucketConfig struct {
+	Rate  float64 `yaml:"rate"`  // 每秒请求数，0 表示不限制
+	Burst int     `yaml:"burst"` // 突发容量，默认等于 rate
+}
+
+// AdminConfig 管理接口配置
+type AdminConfig struct {
+	Addr  string `yaml:"addr"`  // 监听地址，为空则不启用（应仅监听内网地址）
+	Token string `yaml:"token"` // 访问令牌，通过 Authorization: Bearer <token> 传入，启用时必填
+}
+
 type QUICConfig struct {
 	MaxIdleTimeout        time.Duration `yaml:"max_idle_timeout"`
 	KeepAlivePeriod       time.Duration `yaml:"keep_alive_period"`
@@ -42,6 +90,7 @@ type NATSConfig struct {
 	URL           string        `yaml:"url"`
 	MaxReconnects int           `yaml:"max_reconnects"`
 	ReconnectWait time.Duration `yaml:"reconnect_wait"`
+	Codec         string        `yaml:"codec"` // 上行消息编码：json（默认）/ binary，接收方自动识别
 }
 
 type RedisConfig struct {
@@ -51,6 +100,9 @@ type RedisConfig struct {
 	Password string `yaml:"password"`
 	DB       int    `yaml:"db"`
 	PoolSize int    `yaml:"pool_size"`
+
+	LocationRefreshInterval time.Duration `yaml:"location_refresh_interval"` // 心跳续期的用户位置批量刷新 TTL 的周期，默认 10s
+	LocationRefreshBatch    int           `yaml:"location_refresh_batch"`    // 单个 pipeline 的续期命令数，默认 500
 }
 
 type AuthConfig struct {
@@ -86,6 +138,7 @@ func (c *Config) applyEnv() {
 	c.NATS.URL = sharedConfig.GetEnv("NATS_URL", c.NATS.URL)
 	c.NATS.MaxReconnects = sharedConfig.GetEnvInt("NATS_MAX_RECONNECTS", c.NATS.MaxReconnects)
 	c.NATS.ReconnectWait = sharedConfig.GetEnvDuration("NATS_RECONNECT_WAIT", c.NATS.ReconnectWait)
+	c.NATS.Codec = sharedConfig.GetEnv("NATS_CODEC", c.NATS.Codec)
 
 	// Redis
 	c.Redis.Host = sharedConfig.GetEnv("REDIS_HOST", c.Redis.Host)
@@ -105,6 +158,19 @@ func (c *Config) applyEnv() {
 	// Auth/JWT
 	c.Auth.TokenSecret = sharedConfig.GetEnv("JWT_SECRET", c.Auth.TokenSecret)
 
+	// WebSocket
+	c.Server.WSAddr = sharedConfig.GetEnv("WS_ADDR", c.Server.WSAddr)
+	if origins := sharedConfig.GetEnv("ALLOWED_ORIGINS", ""); origins != "" {
+		c.Server.AllowedOrigins = strings.Split(origins, ",")
+	}
+
+	// TCP
+	c.Server.TCPAddr = sharedConfig.GetEnv("TCP_ADDR", c.Server.TCPAddr)
+
+	// Admin
+	c.Admin.Addr = sharedConfig.GetEnv("ADMIN_ADDR", c.Admin.Addr)
+	c.Admin.Token = sharedConfig.GetEnv("ADMIN_TOKEN", c.Admin.Token)
+
 	// TLS
 	c.QUIC.CertFile = sharedConfig.GetEnv("TLS_CERT_FILE", c.QUIC.CertFile)
 	c.QUIC.KeyFile = sharedConfig.GetEnv("TLS_KEY_FILE", c.QUIC.KeyFile)
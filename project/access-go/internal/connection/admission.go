@@ -0,0 +1,81 @@
+package connection
+
+import (
+	"errors"
+	"net"
+	"sync"
+)
+
+var (
+	ErrServerFull  = errors.New("server connection limit reached")
+	ErrIPLimitFull = errors.New("per-ip connection limit reached")
+)
+
+// Admission 连接准入控制：全局连接上限与单 IP 连接上限（含未认证会话）
+type Admission struct {
+	maxConns      int // 0 表示不限制
+	maxConnsPerIP int // 0 表示不限制
+
+	mu    sync.Mutex
+	total int
+	perIP map[string]int
+}
+
+// NewAdmission 创建准入控制，maxConns、maxConnsPerIP 小于等于 0 时不限制
+func NewAdmission(maxConns, maxConnsPerIP int) *Admission {
+	return &Admission{
+		maxConns:      maxConns,
+		maxConnsPerIP: maxConnsPerIP,
+		perIP:         make(map[string]int),
+	}
+}
+
+// Acquire 为来自 ip 的新会话占用名额，成功后必须调用 Release 归还
+func (a *Admission) Acquire(ip string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.maxConns > 0 && a.total >= a.maxConns {
+		return ErrServerFull
+	}
+	if a.maxConnsPerIP > 0 && a.perIP[ip] >= a.maxConnsPerIP {
+		return ErrIPLimitFull
+	}
+	a.total++
+	a.perIP[ip]++
+	return nil
+}
+
+// Release 归还 ip 占用的名额
+func (a *Admission) Release(ip string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if n := a.perIP[ip]; n > 1 {
+		a.perIP[ip] = n - 1
+	} else {
+		delete(a.perIP, ip)
+	}
+	if a.total > 0 {
+		a.total--
+	}
+}
+
+// Count 返回当前占用的连接数
+func (a *Admission) Count() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.total
+}
+
+// RemoteIP 提取客户端地址中的 IP 部分，用作单 IP 限制的键
+func RemoteIP(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
@@ -0,0 +1,57 @@
+package connection
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+// TestAdmission 测试全局与单 IP 连接上限
+func TestAdmission(t *testing.T) {
+	a := NewAdmission(3, 2)
+
+	steps := []struct {
+		ip   string
+		want error
+	}{
+		{"10.0.0.1", nil},
+		{"10.0.0.1", nil},
+		{"10.0.0.1", ErrIPLimitFull},
+		{"10.0.0.2", nil},
+		{"10.0.0.3", ErrServerFull},
+	}
+	for i, step := range steps {
+		if err := a.Acquire(step.ip); !errors.Is(err, step.want) {
+			t.Fatalf("第 %d 步 %s: 期望 %v，实际 %v", i, step.ip, step.want, err)
+		}
+	}
+	if a.Count() != 3 {
+		t.Fatalf("连接数不正确: %d", a.Count())
+	}
+
+	// 归还名额后可以再次接入
+	a.Release("10.0.0.1")
+	if err := a.Acquire("10.0.0.3"); err != nil {
+		t.Fatalf("归还后应可接入: %v", err)
+	}
+	if err := a.Acquire("10.0.0.1"); !errors.Is(err, ErrServerFull) {
+		t.Fatalf("应超过全局上限: %v", err)
+	}
+}
+
+// TestRemoteIP 测试提取客户端 IP
+func TestRemoteIP(t *testing.T) {
+	tests := []struct {
+		addr net.Addr
+		want string
+	}{
+		{&net.TCPAddr{IP: net.ParseIP("192.168.1.2"), Port: 5000}, "192.168.1.2"},
+		{&net.UDPAddr{IP: net.ParseIP("::1"), Port: 443}, "::1"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := RemoteIP(tt.addr); got != tt.want {
+			t.Errorf("RemoteIP(%v) = %q，期望 %q", tt.addr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package connection
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultWriteQueueSize = 256
+	defaultSendWait       = time.Second
+)
+
+var (
+	ErrSendTimeout  = errors.New("send queue wait timeout")
+	ErrFrameDropped = errors.New("frame dropped by backpressure")
+	ErrSlowConsumer = errors.New("slow consumer disconnected")
+)
+
+// PayloadClass 下行载荷类别，不同类别可配置不同的写队列溢出策略
+type PayloadClass int
+
+const (
+	ClassControl PayloadClass = iota // AuthAck、补发帧等控制类帧
+	ClassChat                        // ChatPush、ChatSendAck
+	ClassRoom                        // RoomPush
+	ClassGame                        // GamePush
+	numPayloadClasses
+)
+
+func (c PayloadClass) String() string {
+	switch c {
+	case ClassChat:
+		return "chat"
+	case ClassRoom:
+		return "room"
+	case ClassGame:
+		return "game"
+	default:
+		return "control"
+	}
+}
+
+// OverflowPolicy 写队列满时的处理策略
+type OverflowPolicy string
+
+const (
+	OverflowWait       OverflowPolicy = "wait"        // 帧进入溢出区限时等待队列空位（发送方不阻塞），超时后丢弃
+	OverflowDropOldest OverflowPolicy = "drop_oldest" // 丢弃队列中最旧的同类帧，适合可被新状态覆盖的游戏帧
+	OverflowDisconnect OverflowPolicy = "disconnect"  // 断开连接，适合不允许丢失的聊天消息（客户端重连后补发）
+)
+
+// SendPolicy 单个载荷类别的溢出策略
+type SendPolicy struct {
+	Overflow OverflowPolicy
+	Timeout  time.Duration // wait 策略下帧在溢出区的最长等待时间
+}
+
+// BackpressureStats 单个载荷类别的计数快照
+type BackpressureStats struct {
+	Class       string `json:"class"`
+	Enqueued    int64  `json:"enqueued"`    // 成功入队
+	Dropped     int64  `json:"dropped"`     // drop_oldest 丢弃的帧
+	Timeouts    int64  `json:"timeouts"`    // wait 超时或溢出区已满丢弃的帧
+	Disconnects int64  `json:"disconnects"` // 因溢出断开的连接
+}
+
+type classCounters struct {
+	enqueued    atomic.Int64
+	dropped     atomic.Int64
+	timeouts    atomic.Int64
+	disconnects atomic.Int64
+}
+
+// Backpressure 写队列背压配置与计数，由所有连接共享
+type Backpressure struct {
+	queueSize int
+	policies  [numPayloadClasses]SendPolicy
+	counters  [numPayloadClasses]classCounters
+}
+
+// NewBackpressure 创建背压策略，queueSize 为每连接写队列长度
+// 未配置的类别默认限时等待：控制类帧与聊天消息不应被静默丢弃
+func NewBackpressure(queueSize int, policies map[PayloadClass]SendPolicy) *Backpressure {
+	// 设置默认值
+	if queueSize <= 0 {
+		queueSize = defaultWriteQueueSize
+	}
+
+	b := &Backpressure{queueSize: queueSize}
+	for class := range b.policies {
+		p, ok := policies[PayloadClass(class)]
+		switch p.Overflow {
+		case OverflowWait, OverflowDropOldest, OverflowDisconnect:
+		default:
+			ok = false
+		}
+		if !ok {
+			p = SendPolicy{Overflow: OverflowWait, Timeout: defaultSendWait}
+		}
+		if p.Overflow == OverflowWait && p.Timeout <= 0 {
+			p.Timeout = defaultSendWait
+		}
+		b.policies[class] = p
+	}
+	return b
+}
+
+// Policy 返回载荷类别的溢出策略
+func (b *Backpressure) Policy(class PayloadClass) SendPolicy {
+	return b.policies[class]
+}
+
+// Stats 返回各载荷类别的计数快照
+func (b *Backpressure) Stats() []BackpressureStats {
+	stats := make([]BackpressureStats, numPayloadClasses)
+	for i := range b.counters {
+		c := &b.counters[i]
+		stats[i] = BackpressureStats{
+			Class:       PayloadClass(i).String(),
+			Enqueued:    c.enqueued.Load(),
+			Dropped:     c.dropped.Load(),
+			Timeouts:    c.timeouts.Load(),
+			Disconnects: c.disconnects.Load(),
+		}
+	}
+	return stats
+}
@@ -0,0 +1,195 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSession 记录关闭码的测试会话
+type fakeSession struct {
+	closed chan uint32
+}
+
+func (s *fakeSession) AcceptStream(ctx context.Context) (Stream, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (s *fakeSession) CloseWithError(code uint32, _ string) error {
+	s.closed <- code
+	return nil
+}
+
+func (s *fakeSession) RemoteAddr() net.Addr {
+	return &net.TCPAddr{}
+}
+
+// gatedStream 写入阻塞直到 release 关闭，用于模拟慢消费者
+type gatedStream struct {
+	release chan struct{}
+	mu      sync.Mutex
+	written []string
+}
+
+func (s *gatedStream) Read([]byte) (int, error) {
+	select {}
+}
+
+func (s *gatedStream) Write(p []byte) (int, error) {
+	<-s.release
+	s.mu.Lock()
+	s.written = append(s.written, string(p))
+	s.mu.Unlock()
+	return len(p), nil
+}
+
+func (s *gatedStream) Close() error {
+	return nil
+}
+
+func (s *gatedStream) frames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.written...)
+}
+
+// newSlowConn 创建写队列长度为 2 的连接，首帧被写协程取走后阻塞，随后两帧填满队列
+func newSlowConn(t *testing.T, class PayloadClass, policy SendPolicy) (*Connection, *fakeSession, *gatedStream) {
+	t.Helper()
+	session := &fakeSession{closed: make(chan uint32, 1)}
+	stream := &gatedStream{release: make(chan struct{})}
+	bp := NewBackpressure(2, map[PayloadClass]SendPolicy{class: policy})
+	c := New(session, TransportTCP, bp, slog.Default())
+	c.SetClientStream(stream)
+
+	if err := c.SendClass([]byte("0"), class); err != nil {
+		t.Fatalf("发送失败: %v", err)
+	}
+	// 等待写协程取走首帧
+	deadline := time.Now().Add(time.Second)
+	for {
+		c.queueMu.Lock()
+		empty := c.queueLen == 0
+		c.queueMu.Unlock()
+		if empty {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("写协程未取走首帧")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for _, data := range []string{"1", "2"} {
+		if err := c.SendClass([]byte(data), class); err != nil {
+			t.Fatalf("发送失败: %v", err)
+		}
+	}
+	return c, session, stream
+}
+
+// waitFrames 等待写入指定数量的帧
+func waitFrames(t *testing.T, stream *gatedStream, n int) []string {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		frames := stream.frames()
+		if len(frames) >= n {
+			return frames
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("期望写入 %d 帧，实际: %q", n, frames)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestSendDropOldest 测试队列满时丢弃最旧的同类帧
+func TestSendDropOldest(t *testing.T) {
+	c, _, stream := newSlowConn(t, ClassGame, SendPolicy{Overflow: OverflowDropOldest})
+	defer c.Close()
+
+	if err := c.SendClass([]byte("3"), ClassGame); err != nil {
+		t.Fatalf("drop_oldest 不应返回错误: %v", err)
+	}
+	close(stream.release)
+
+	frames := waitFrames(t, stream, 3)
+	if frames[0] != "0" || frames[1] != "2" || frames[2] != "3" {
+		t.Fatalf("应丢弃帧 1，实际写入: %q", frames)
+	}
+	if st := c.backpressure.Stats()[ClassGame]; st.Dropped != 1 || st.Enqueued != 4 {
+		t.Fatalf("计数不正确: %+v", st)
+	}
+}
+
+// TestSendWait 测试队列满时帧进入溢出区等待，发送方不阻塞，超时的帧在进入写队列时丢弃
+func TestSendWait(t *testing.T) {
+	timeout := 50 * time.Millisecond
+	c, _, stream := newSlowConn(t, ClassRoom, SendPolicy{Overflow: OverflowWait, Timeout: timeout})
+	defer c.Close()
+
+	// 阻塞的发送方至少等待 timeout，每次发送都应立即返回
+	send := func(data string) error {
+		start := time.Now()
+		err := c.SendClass([]byte(data), ClassRoom)
+		if elapsed := time.Since(start); elapsed >= timeout {
+			t.Fatalf("发送帧 %s 阻塞 %v", data, elapsed)
+		}
+		return err
+	}
+
+	if err := send("3"); err != nil {
+		t.Fatalf("进入溢出区不应返回错误: %v", err)
+	}
+	time.Sleep(timeout + 10*time.Millisecond)
+	if err := send("4"); err != nil {
+		t.Fatalf("进入溢出区不应返回错误: %v", err)
+	}
+	// 溢出区与写队列等长，已满时立即返回
+	if err := send("5"); !errors.Is(err, ErrSendTimeout) {
+		t.Fatalf("溢出区满时应返回超时，实际: %v", err)
+	}
+
+	// 帧 3 超过等待期限后才有空位，应被丢弃
+	close(stream.release)
+	frames := waitFrames(t, stream, 4)
+	if frames[1] != "1" || frames[2] != "2" || frames[3] != "4" {
+		t.Fatalf("写入顺序不正确: %q", frames)
+	}
+	if st := c.backpressure.Stats()[ClassRoom]; st.Timeouts != 2 {
+		t.Fatalf("计数不正确: %+v", st)
+	}
+}
+
+// TestSendDisconnect 测试队列满时断开慢消费者
+func TestSendDisconnect(t *testing.T) {
+	c, session, stream := newSlowConn(t, ClassChat, SendPolicy{Overflow: OverflowDisconnect})
+	defer close(stream.release)
+
+	if err := c.SendClass([]byte("3"), ClassChat); !errors.Is(err, ErrSlowConsumer) {
+		t.Fatalf("应断开慢消费者，实际: %v", err)
+	}
+
+	select {
+	case code := <-session.closed:
+		if code != CloseCodeSlowConsumer {
+			t.Fatalf("关闭码不正确: %d", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("会话未关闭")
+	}
+	if c.CloseCode() != CloseCodeSlowConsumer {
+		t.Fatalf("连接关闭码不正确: %d", c.CloseCode())
+	}
+	if err := c.SendClass([]byte("4"), ClassChat); !errors.Is(err, ErrConnectionClosed) {
+		t.Fatalf("断开后发送应失败，实际: %v", err)
+	}
+	if st := c.backpressure.Stats()[ClassChat]; st.Disconnects != 1 {
+		t.Fatalf("计数不正确: %+v", st)
+	}
+}
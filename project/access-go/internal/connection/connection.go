@@ -5,8 +5,6 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
-
-	"github.com/quic-go/webtransport-go"
 )
 
 var connIDCounter int64
@@ -17,44 +15,73 @@ type Connection struct {
 	userID     int64
 	deviceID   string
 	platform   string
-	session    *webtransport.Session
+	transport  string
+	session    Session
 	sessInfo   *SessionInfo
 	logger     *slog.Logger
-	writeChan  chan []byte
 	closeChan  chan struct{}
 	closeOnce  sync.Once
+	closeCode  atomic.Uint32
 	createTime time.Time
+	lastActive atomic.Int64 // 最后活跃时间（UnixNano），由读协程更新、心跳检测器读取
+	online     atomic.Bool  // 已发布上线事件且尚未发布下线事件
+
+	// 写队列：有界环形缓冲，满时按载荷类别的背压策略处理
+	// 超出 queueSize 的部分为 wait 策略的溢出区，发送方不阻塞，帧在溢出区等待进入写队列
+	backpressure *Backpressure
+	queueMu      sync.Mutex
+	queue        []queuedFrame
+	queueHead    int
+	queueLen     int
+	queueReady   chan struct{} // 有新帧时通知写协程
+
+	// 请求限流状态（由 RateLimiter 维护）
+	rate rateState
 
 	// 流复用优化：使用客户端创建的双向流
-	clientStream *webtransport.Stream // 客户端创建的双向流，用于发送消息
+	clientStream Stream // 客户端创建的双向流，用于发送消息
 	streamMutex  sync.Mutex
 }
 
+type queuedFrame struct {
+	data     []byte
+	class    PayloadClass
+	deadline int64 // 溢出区帧进入写队列的期限（UnixNano），0 表示已在写队列内
+}
+
 // SessionInfo 表示会话状态
 type SessionInfo struct {
-	UserID         int64
-	DeviceID       string
-	Platform       string
-	LoginTime      time.Time
-	LastActiveTime time.Time
+	UserID    int64
+	DeviceID  string
+	Platform  string
+	LoginTime time.Time
 }
 
-func NewFromWebTransport(session *webtransport.Session, logger *slog.Logger) *Connection {
+// New 基于传输层会话创建连接，transport 为传输类型（webtransport / websocket）
+// backpressure 为写队列背压策略，为 nil 时使用默认策略
+func New(session Session, transport string, backpressure *Backpressure, logger *slog.Logger) *Connection {
+	if backpressure == nil {
+		backpressure = NewBackpressure(0, nil)
+	}
 	id := atomic.AddInt64(&connIDCounter, 1)
 	c := &Connection{
-		id:         id,
-		session:    session,
-		logger:     logger,
-		writeChan:  make(chan []byte, 256),
-		closeChan:  make(chan struct{}),
-		createTime: time.Now(),
+		id:           id,
+		transport:    transport,
+		session:      session,
+		logger:       logger,
+		closeChan:    make(chan struct{}),
+		createTime:   time.Now(),
+		backpressure: backpressure,
+		queue:        make([]queuedFrame, backpressure.queueSize),
+		queueReady:   make(chan struct{}, 1),
 	}
+	c.lastActive.Store(c.createTime.UnixNano())
 	go c.writeLoop()
 	return c
 }
 
 // SetClientStream 设置客户端创建的双向流用于发送消息
-func (c *Connection) SetClientStream(stream *webtransport.Stream) {
+func (c *Connection) SetClientStream(stream Stream) {
 	c.streamMutex.Lock()
 	defer c.streamMutex.Unlock()
 	c.clientStream = stream
@@ -83,47 +110,184 @@ func (c *Connection) BindSession(sessInfo *SessionInfo) {
 	c.deviceID = sessInfo.DeviceID
 	c.platform = sessInfo.Platform
 	sessInfo.LoginTime = time.Now()
-	sessInfo.LastActiveTime = time.Now()
+	c.UpdateActive()
 }
 
 func (c *Connection) SessionInfo() *SessionInfo {
 	return c.sessInfo
 }
 
-func (c *Connection) WebTransportSession() *webtransport.Session {
+func (c *Connection) Session() Session {
 	return c.session
 }
 
+// RTT 返回连接往返时延，传输层不支持时返回 0
+func (c *Connection) RTT() time.Duration {
+	if r, ok := c.session.(rttReporter); ok {
+		return r.RTT()
+	}
+	return 0
+}
+
+// Transport 返回传输类型
+func (c *Connection) Transport() string {
+	return c.transport
+}
+
+// Send 以控制类帧发送
 func (c *Connection) Send(data []byte) error {
+	return c.SendClass(data, ClassControl)
+}
+
+// SendClass 将帧写入写队列，队列满时按载荷类别的背压策略处理，不会阻塞调用方
+// 下行分发在消息总线的订阅回调中串行执行，任一慢连接阻塞发送方都会拖慢本节点所有用户的投递
+func (c *Connection) SendClass(data []byte, class PayloadClass) error {
+	policy := c.backpressure.Policy(class)
+	counters := &c.backpressure.counters[class]
+	limit := c.backpressure.queueSize
+
+	c.queueMu.Lock()
 	select {
-	case c.writeChan <- data:
-		return nil
 	case <-c.closeChan:
+		c.queueMu.Unlock()
 		return ErrConnectionClosed
+	default:
+	}
+
+	if c.queueLen < limit {
+		c.pushLocked(queuedFrame{data: data, class: class})
+		c.queueMu.Unlock()
+		counters.enqueued.Add(1)
+		return nil
+	}
+
+	switch policy.Overflow {
+	case OverflowDropOldest:
+		if !c.removeOldestLocked(class) {
+			// 队列中没有同类帧可丢弃，丢弃当前帧
+			c.queueMu.Unlock()
+			counters.dropped.Add(1)
+			return ErrFrameDropped
+		}
+		c.pushLocked(queuedFrame{data: data, class: class})
+		c.queueMu.Unlock()
+		counters.dropped.Add(1)
+		counters.enqueued.Add(1)
+		return nil
+
+	case OverflowDisconnect:
+		c.queueMu.Unlock()
+		counters.disconnects.Add(1)
+		c.logger.Warn("Write queue overflow, disconnecting slow consumer",
+			"conn_id", c.id, "user_id", c.userID, "class", class.String())
+		// 关闭会话可能阻塞在传输层写入，异步执行；closeChan 在 Do 内立即关闭，后续发送直接失败
+		go c.CloseWithError(CloseCodeSlowConsumer, "slow consumer")
+		return ErrSlowConsumer
+
+	default: // OverflowWait
+		// 溢出区与写队列等长，溢出区也满时视为等待超时
+		if c.queueLen >= 2*limit {
+			c.queueMu.Unlock()
+			counters.timeouts.Add(1)
+			return ErrSendTimeout
+		}
+		c.pushLocked(queuedFrame{data: data, class: class, deadline: time.Now().Add(policy.Timeout).UnixNano()})
+		c.queueMu.Unlock()
+		counters.enqueued.Add(1)
+		return nil
+	}
+}
+
+// pushLocked 写入队尾并通知写协程（调用方持锁且未超过溢出区上限），环形缓冲满时扩容
+func (c *Connection) pushLocked(f queuedFrame) {
+	if c.queueLen == len(c.queue) {
+		c.growLocked()
+	}
+	c.queue[(c.queueHead+c.queueLen)%len(c.queue)] = f
+	c.queueLen++
+	select {
+	case c.queueReady <- struct{}{}:
+	default:
+	}
+}
+
+// growLocked 将环形缓冲扩容到写队列与溢出区的总长度（调用方持锁），只有慢连接才会分配溢出区
+func (c *Connection) growLocked() {
+	queue := make([]queuedFrame, 2*c.backpressure.queueSize)
+	for i := 0; i < c.queueLen; i++ {
+		queue[i] = c.queue[(c.queueHead+i)%len(c.queue)]
+	}
+	c.queue = queue
+	c.queueHead = 0
+}
+
+// removeOldestLocked 移除队列中最旧的指定类别帧，保持其余帧顺序（调用方持锁）
+func (c *Connection) removeOldestLocked(class PayloadClass) bool {
+	for i := 0; i < c.queueLen; i++ {
+		if c.queue[(c.queueHead+i)%len(c.queue)].class == class {
+			c.removeAtLocked(i)
+			c.admitLocked()
+			return true
+		}
+	}
+	return false
+}
+
+// removeAtLocked 移除队列中第 i 帧，后续帧前移一位（调用方持锁）
+func (c *Connection) removeAtLocked(i int) {
+	size := len(c.queue)
+	for j := i; j < c.queueLen-1; j++ {
+		c.queue[(c.queueHead+j)%size] = c.queue[(c.queueHead+j+1)%size]
 	}
+	c.queueLen--
+	c.queue[(c.queueHead+c.queueLen)%size] = queuedFrame{}
+}
+
+// admitLocked 写队列腾出一个位置后，溢出区首帧进入写队列，超过等待期限的帧直接丢弃（调用方持锁）
+func (c *Connection) admitLocked() {
+	limit := c.backpressure.queueSize
+	now := time.Now().UnixNano()
+	for c.queueLen >= limit {
+		f := &c.queue[(c.queueHead+limit-1)%len(c.queue)]
+		if f.deadline == 0 {
+			return
+		}
+		if now <= f.deadline {
+			f.deadline = 0
+			return
+		}
+		c.backpressure.counters[f.class].timeouts.Add(1)
+		c.removeAtLocked(limit - 1)
+	}
+}
+
+// pop 取出队首帧，溢出区首帧随之进入写队列
+func (c *Connection) pop() ([]byte, bool) {
+	c.queueMu.Lock()
+	defer c.queueMu.Unlock()
+
+	if c.queueLen == 0 {
+		return nil, false
+	}
+	f := c.queue[c.queueHead]
+	c.queue[c.queueHead] = queuedFrame{}
+	c.queueHead = (c.queueHead + 1) % len(c.queue)
+	c.queueLen--
+	c.admitLocked()
+	return f.data, true
 }
 
 func (c *Connection) writeLoop() {
 	for {
 		select {
-		case data := <-c.writeChan:
-			c.streamMutex.Lock()
-
-			// 使用客户端创建的双向流发送消息
-			if c.clientStream == nil {
-				c.logger.Error("Client stream not set, cannot send message", "conn_id", c.id)
-				c.streamMutex.Unlock()
-				continue
+		case <-c.queueReady:
+			for {
+				data, ok := c.pop()
+				if !ok {
+					break
+				}
+				c.write(data)
 			}
-
-			// 直接写入客户端的流，不关闭流（通过帧头区分消息）
-			if _, err := c.clientStream.Write(data); err != nil {
-				c.logger.Error("Failed to write to client stream", "error", err)
-				// 出错时重置流
-				c.clientStream = nil
-			}
-
-			c.streamMutex.Unlock()
 		case <-c.closeChan:
 			// 关闭时清理流（不需要close，已经由 HandleStream defer 处理）
 			c.streamMutex.Lock()
@@ -134,19 +298,65 @@ func (c *Connection) writeLoop() {
 	}
 }
 
+func (c *Connection) write(data []byte) {
+	c.streamMutex.Lock()
+	defer c.streamMutex.Unlock()
+
+	// 使用客户端创建的双向流发送消息
+	if c.clientStream == nil {
+		c.logger.Error("Client stream not set, cannot send message", "conn_id", c.id)
+		return
+	}
+
+	// 直接写入客户端的流，不关闭流（通过帧头区分消息）
+	if _, err := c.clientStream.Write(data); err != nil {
+		c.logger.Error("Failed to write to client stream", "error", err)
+		// 出错时重置流
+		c.clientStream = nil
+	}
+}
+
 func (c *Connection) Close() {
+	c.CloseWithError(CloseCodeNormal, "connection closed")
+}
+
+// CloseWithError 携带应用关闭码关闭连接，重复调用时以首次为准
+func (c *Connection) CloseWithError(code uint32, msg string) {
 	c.closeOnce.Do(func() {
+		c.closeCode.Store(code)
 		close(c.closeChan)
-		if err := c.session.CloseWithError(0, "connection closed"); err != nil {
-			c.logger.Error("Failed to close WebTransport session", "error", err, "conn_id", c.id)
+		if err := c.session.CloseWithError(code, msg); err != nil {
+			c.logger.Error("Failed to close session", "error", err, "conn_id", c.id, "transport", c.transport)
 		}
 	})
 }
 
-func (c *Connection) UpdateActive() {
-	if c.sessInfo != nil {
-		c.sessInfo.LastActiveTime = time.Now()
+// CloseWithFrame 先写出最后一帧（如踢下线通知）再携带关闭码关闭连接
+// 最后一帧不经写队列直接写入流，写阻塞超过 timeout 时放弃并直接关闭
+func (c *Connection) CloseWithFrame(frame []byte, code uint32, msg string, timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		c.write(frame)
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		c.logger.Warn("Final frame write timed out", "conn_id", c.id)
 	}
+	c.CloseWithError(code, msg)
+}
+
+// CloseCode 返回关闭连接时使用的应用关闭码
+func (c *Connection) CloseCode() uint32 {
+	return c.closeCode.Load()
+}
+
+func (c *Connection) UpdateActive() {
+	c.lastActive.Store(time.Now().UnixNano())
 }
 
 func (c *Connection) CreateTime() time.Time {
@@ -155,8 +365,15 @@ func (c *Connection) CreateTime() time.Time {
 
 // LastActiveTime 返回最后活跃时间
 func (c *Connection) LastActiveTime() time.Time {
-	if c.sessInfo != nil {
-		return c.sessInfo.LastActiveTime
-	}
-	return c.createTime
+	return time.Unix(0, c.lastActive.Load())
+}
+
+// MarkOnline 记录已发布上线事件
+func (c *Connection) MarkOnline() {
+	c.online.Store(true)
+}
+
+// TakeOnline 清除上线标记，返回 true 表示调用方负责发布唯一一次下线事件
+func (c *Connection) TakeOnline() bool {
+	return c.online.CompareAndSwap(true, false)
 }
@@ -3,20 +3,27 @@ package connection
 import (
 	"context"
 	"log/slog"
+	"sync"
 	"time"
 )
 
 // HeartbeatChecker 心跳超时检测器
+// 连接按截止时间（最后活跃时间 + 超时时间）放入以检测间隔为粒度的时间桶，每次检测只处理已到期的桶：
+// 仍活跃的连接按新的截止时间重新入桶，真正超时的连接被关闭，单次检测的开销与到期连接数成正比
+// 超时连接最迟在截止时间后两个检测间隔内被关闭；检测器只负责关闭连接，位置注销与下线事件统一由会话清理执行，保证每个连接只处理一次
 type HeartbeatChecker struct {
-	manager       *Manager
 	timeout       time.Duration
 	checkInterval time.Duration
 	logger        *slog.Logger
-	onTimeout     func(conn *Connection) // 超时回调
+
+	mu      sync.Mutex
+	buckets map[int64]map[int64]*Connection // 桶序号 -> connID -> 连接
+	slots   map[int64]int64                 // connID -> 所在桶序号
+	cursor  int64                           // 已处理到的桶序号
 }
 
 // NewHeartbeatChecker 创建心跳检测器
-func NewHeartbeatChecker(manager *Manager, timeout, checkInterval time.Duration, logger *slog.Logger, onTimeout func(conn *Connection)) *HeartbeatChecker {
+func NewHeartbeatChecker(timeout, checkInterval time.Duration, logger *slog.Logger) *HeartbeatChecker {
 	// 设置默认值
 	if timeout <= 0 {
 		timeout = 90 * time.Second
@@ -25,13 +32,52 @@ func NewHeartbeatChecker(manager *Manager, timeout, checkInterval time.Duration,
 		checkInterval = 30 * time.Second
 	}
 
-	return &HeartbeatChecker{
-		manager:       manager,
+	h := &HeartbeatChecker{
 		timeout:       timeout,
 		checkInterval: checkInterval,
 		logger:        logger,
-		onTimeout:     onTimeout,
+		buckets:       make(map[int64]map[int64]*Connection),
+		slots:         make(map[int64]int64),
+	}
+	h.cursor = h.slotOf(time.Now()) - 1
+	return h
+}
+
+// Add 开始检测连接（认证成功后调用）
+func (h *HeartbeatChecker) Add(conn *Connection) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.scheduleLocked(conn)
+}
+
+// Remove 停止检测连接（连接关闭时调用）
+func (h *HeartbeatChecker) Remove(connID int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	slot, ok := h.slots[connID]
+	if !ok {
+		return
 	}
+	delete(h.slots, connID)
+	h.unlinkLocked(slot, connID)
+}
+
+// unlinkLocked 从时间桶中移除连接，空桶一并删除（调用方持锁）
+func (h *HeartbeatChecker) unlinkLocked(slot, connID int64) {
+	if bucket := h.buckets[slot]; bucket != nil {
+		delete(bucket, connID)
+		if len(bucket) == 0 {
+			delete(h.buckets, slot)
+		}
+	}
+}
+
+// Len 返回检测中的连接数
+func (h *HeartbeatChecker) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.slots)
 }
 
 // Start 启动心跳检测（阻塞，应在 goroutine 中调用）
@@ -48,44 +94,80 @@ func (h *HeartbeatChecker) Start(ctx context.Context) {
 		case <-ctx.Done():
 			h.logger.Info("Heartbeat checker stopped")
 			return
-		case <-ticker.C:
-			h.checkConnections()
+		case now := <-ticker.C:
+			h.expire(now)
 		}
 	}
 }
 
-// checkConnections 检查所有连接的心跳是否超时
-func (h *HeartbeatChecker) checkConnections() {
-	conns := h.manager.GetAllConnections()
-	now := time.Now()
-	timeoutCount := 0
-
-	for _, conn := range conns {
-		lastActive := conn.LastActiveTime()
-		if now.Sub(lastActive) > h.timeout {
-			timeoutCount++
-			h.logger.Debug("Connection heartbeat timeout",
-				"conn_id", conn.ID(),
-				"user_id", conn.UserID(),
-				"last_active", lastActive,
-				"timeout", h.timeout)
-
-			// 调用超时回调
-			if h.onTimeout != nil {
-				h.onTimeout(conn)
-			}
-
-			// 关闭连接
-			conn.Close()
+// expire 处理截止时间不晚于 now 的时间桶，返回被关闭的连接数
+func (h *HeartbeatChecker) expire(now time.Time) int {
+	var expired []*Connection
+	rescheduled := 0
 
-			// 从 Manager 移除
-			h.manager.Remove(conn.ID())
+	h.mu.Lock()
+	// 截止时间落在 now 所在桶内但尚未到达的连接重新入桶，因此只处理到 now 的前一个桶
+	last := h.slotOf(now) - 1
+	for ; h.cursor < last; h.cursor++ {
+		slot := h.cursor + 1
+		bucket, ok := h.buckets[slot]
+		if !ok {
+			continue
 		}
+		delete(h.buckets, slot)
+		for connID, conn := range bucket {
+			delete(h.slots, connID)
+			if now.Sub(conn.LastActiveTime()) > h.timeout {
+				expired = append(expired, conn)
+				continue
+			}
+			h.scheduleLocked(conn)
+			rescheduled++
+		}
+	}
+	h.mu.Unlock()
+
+	for _, conn := range expired {
+		h.logger.Debug("Connection heartbeat timeout",
+			"conn_id", conn.ID(),
+			"user_id", conn.UserID(),
+			"last_active", conn.LastActiveTime(),
+			"timeout", h.timeout)
+		// 关闭会话可能阻塞在传输层写入，异步执行
+		go conn.CloseWithError(CloseCodeHeartbeatTimeout, "heartbeat timeout")
 	}
 
-	if timeoutCount > 0 {
+	if len(expired) > 0 {
 		h.logger.Info("Heartbeat check completed",
-			"total", len(conns),
-			"timeout", timeoutCount)
+			"timeout", len(expired),
+			"rescheduled", rescheduled)
+	}
+	return len(expired)
+}
+
+// scheduleLocked 按截止时间将连接放入时间桶（调用方持锁）
+func (h *HeartbeatChecker) scheduleLocked(conn *Connection) {
+	slot := h.slotOf(conn.LastActiveTime().Add(h.timeout))
+	if slot <= h.cursor {
+		slot = h.cursor + 1
 	}
+
+	if old, ok := h.slots[conn.ID()]; ok {
+		if old == slot {
+			return
+		}
+		h.unlinkLocked(old, conn.ID())
+	}
+	bucket := h.buckets[slot]
+	if bucket == nil {
+		bucket = make(map[int64]*Connection)
+		h.buckets[slot] = bucket
+	}
+	bucket[conn.ID()] = conn
+	h.slots[conn.ID()] = slot
+}
+
+// slotOf 返回时间所在桶序号
+func (h *HeartbeatChecker) slotOf(t time.Time) int64 {
+	return t.UnixNano() / int64(h.checkInterval)
 }
@@ -0,0 +1,62 @@
+package connection
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func newIdleConn() (*Connection, *fakeSession) {
+	session := &fakeSession{closed: make(chan uint32, 1)}
+	return New(session, TransportTCP, nil, slog.Default()), session
+}
+
+// TestHeartbeatExpire 测试只关闭超时连接，仍活跃的连接按新的截止时间重新入桶
+func TestHeartbeatExpire(t *testing.T) {
+	const timeout, interval = 90 * time.Second, 30 * time.Second
+	h := NewHeartbeatChecker(timeout, interval, slog.Default())
+
+	idle, idleSession := newIdleConn()
+	active, activeSession := newIdleConn()
+	removed, _ := newIdleConn()
+	for _, c := range []*Connection{idle, active, removed} {
+		h.Add(c)
+	}
+	h.Remove(removed.ID())
+
+	start := time.Now()
+	if n := h.expire(start.Add(interval)); n != 0 {
+		t.Fatalf("未到截止时间不应关闭连接，实际 %d", n)
+	}
+
+	// 活跃连接在截止时间前有心跳
+	active.lastActive.Store(start.Add(timeout).UnixNano())
+
+	if n := h.expire(start.Add(timeout + 2*interval)); n != 1 {
+		t.Fatalf("应关闭 1 个超时连接，实际 %d", n)
+	}
+	select {
+	case code := <-idleSession.closed:
+		if code != CloseCodeHeartbeatTimeout {
+			t.Fatalf("关闭码不正确: %d", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("超时连接未关闭")
+	}
+	select {
+	case <-activeSession.closed:
+		t.Fatalf("活跃连接不应被关闭")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if h.Len() != 1 {
+		t.Fatalf("活跃连接应重新入桶，检测中连接数 %d", h.Len())
+	}
+
+	// 活跃连接随后不再有心跳
+	if n := h.expire(start.Add(2*timeout + 2*interval)); n != 1 {
+		t.Fatalf("应关闭重新入桶后超时的连接，实际 %d", n)
+	}
+	if h.Len() != 0 {
+		t.Fatalf("检测中连接数应为 0，实际 %d", h.Len())
+	}
+}
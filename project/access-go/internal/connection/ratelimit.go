@@ -0,0 +1,115 @@
+package connection
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// RateDecision 限流判定结果
+type RateDecision int
+
+const (
+	RateAllowed RateDecision = iota // 放行
+	RateLimited                     // 超过令牌桶限制，拒绝本次请求
+	RateAbuse                       // 窗口内超限次数过多，应断开连接
+)
+
+// RateLimit 单个请求类别的令牌桶参数
+type RateLimit struct {
+	Rate  float64 // 每秒补充的令牌数，<= 0 表示不限制
+	Burst int     // 桶容量，<= 0 时取 Rate 向上取整
+}
+
+// RateLimiter 每连接、按请求类别的令牌桶限流配置，由所有连接共享，桶状态保存在连接上
+type RateLimiter struct {
+	limits          map[string]RateLimit
+	maxViolations   int           // 窗口内超限次数达到该值时判定为滥用，0 表示不断开
+	violationWindow time.Duration // 超限计数窗口
+}
+
+// rateState 连接上的限流状态
+type rateState struct {
+	mu          sync.Mutex
+	buckets     map[string]*tokenBucket
+	violations  int
+	windowStart time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter 创建限流器，limits 的键为请求类别（如 chat / room / game），未配置的类别不限流
+func NewRateLimiter(limits map[string]RateLimit, maxViolations int, violationWindow time.Duration) *RateLimiter {
+	// 设置默认值
+	if violationWindow <= 0 {
+		violationWindow = 10 * time.Second
+	}
+
+	normalized := make(map[string]RateLimit, len(limits))
+	for class, limit := range limits {
+		if limit.Rate <= 0 {
+			continue
+		}
+		if limit.Burst <= 0 {
+			limit.Burst = int(math.Ceil(limit.Rate))
+		}
+		normalized[class] = limit
+	}
+
+	return &RateLimiter{
+		limits:          normalized,
+		maxViolations:   maxViolations,
+		violationWindow: violationWindow,
+	}
+}
+
+// Allow 判定连接的一次请求是否放行
+func (l *RateLimiter) Allow(c *Connection, class string, now time.Time) RateDecision {
+	limit, ok := l.limits[class]
+	if !ok {
+		return RateAllowed
+	}
+
+	st := &c.rate
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.buckets == nil {
+		st.buckets = make(map[string]*tokenBucket)
+	}
+	b, ok := st.buckets[class]
+	if !ok {
+		b = &tokenBucket{tokens: float64(limit.Burst), last: now}
+		st.buckets[class] = b
+	}
+	if b.take(limit, now) {
+		return RateAllowed
+	}
+
+	// 记录超限次数
+	if now.Sub(st.windowStart) > l.violationWindow {
+		st.windowStart = now
+		st.violations = 0
+	}
+	st.violations++
+	if l.maxViolations > 0 && st.violations >= l.maxViolations {
+		return RateAbuse
+	}
+	return RateLimited
+}
+
+// take 按流逝时间补充令牌后尝试取出一个
+func (b *tokenBucket) take(limit RateLimit, now time.Time) bool {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed.Seconds()*limit.Rate)
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
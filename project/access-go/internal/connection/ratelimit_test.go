@@ -0,0 +1,63 @@
+package connection
+
+import (
+	"testing"
+	"time"
+)
+
+// TestRateLimiter 测试令牌桶放行、补充与按类别隔离
+func TestRateLimiter(t *testing.T) {
+	l := NewRateLimiter(map[string]RateLimit{"chat": {Rate: 2, Burst: 2}}, 0, 0)
+	c := &Connection{}
+	now := time.Unix(1000, 0)
+
+	steps := []struct {
+		name  string
+		class string
+		at    time.Duration
+		want  RateDecision
+	}{
+		{"突发第 1 次", "chat", 0, RateAllowed},
+		{"突发第 2 次", "chat", 0, RateAllowed},
+		{"桶已空", "chat", 0, RateLimited},
+		{"未配置类别不限流", "game", 0, RateAllowed},
+		{"0.5s 补充 1 个令牌", "chat", 500 * time.Millisecond, RateAllowed},
+		{"再次耗尽", "chat", 500 * time.Millisecond, RateLimited},
+		{"补充不超过桶容量", "chat", 10 * time.Second, RateAllowed},
+		{"桶容量为 2", "chat", 10 * time.Second, RateAllowed},
+		{"桶容量耗尽", "chat", 10 * time.Second, RateLimited},
+	}
+	for _, step := range steps {
+		if got := l.Allow(c, step.class, now.Add(step.at)); got != step.want {
+			t.Fatalf("%s: 期望 %d，实际 %d", step.name, step.want, got)
+		}
+	}
+}
+
+// TestRateLimiterAbuse 测试窗口内持续超限判定为滥用
+func TestRateLimiterAbuse(t *testing.T) {
+	l := NewRateLimiter(map[string]RateLimit{"room": {Rate: 1, Burst: 1}}, 3, time.Second)
+	c := &Connection{}
+	now := time.Unix(1000, 0)
+
+	if got := l.Allow(c, "room", now); got != RateAllowed {
+		t.Fatalf("首次请求应放行，实际 %d", got)
+	}
+	for i := 0; i < 2; i++ {
+		if got := l.Allow(c, "room", now); got != RateLimited {
+			t.Fatalf("第 %d 次超限应拒绝，实际 %d", i+1, got)
+		}
+	}
+	if got := l.Allow(c, "room", now); got != RateAbuse {
+		t.Fatalf("第 3 次超限应判定为滥用，实际 %d", got)
+	}
+
+	// 窗口过期后重新计数
+	later := now.Add(1500 * time.Millisecond)
+	if got := l.Allow(c, "room", later); got != RateAllowed {
+		t.Fatalf("补充令牌后应放行，实际 %d", got)
+	}
+	if got := l.Allow(c, "room", later); got != RateLimited {
+		t.Fatalf("新窗口内首次超限应只拒绝，实际 %d", got)
+	}
+}
@@ -0,0 +1,343 @@
+package connection
+
+import (
+	"context"
+	"crypto/rand"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	defaultResumeBufferSize  = 128
+	defaultResumeGracePeriod = 60 * time.Second
+)
+
+// ResumeSession 可恢复会话：按 用户+平台 维护下行序号与最近下发帧的环形缓冲
+// 连接断开后会话保留一个宽限期，期间的下行帧继续写入缓冲，客户端携带恢复令牌重连后补发
+type ResumeSession struct {
+	token    string
+	userID   int64
+	platform string
+	deviceID string
+
+	mu         sync.Mutex
+	connID     int64 // 当前绑定的连接，断开后保留最后一个连接 ID
+	attached   bool
+	detachedAt time.Time
+	seq        int64         // 最新下行序号
+	frames     []resumeFrame // 环形缓冲
+	head       int           // 最旧帧位置
+	count      int
+
+	// send 在 mu 之外按序号顺序调用，慢连接的写入不会阻塞 Attach/Detach
+	sendMu   sync.Mutex
+	sendCond *sync.Cond
+	sent     int64 // 已完成发送（或无需发送）的最新序号
+}
+
+type resumeFrame struct {
+	seq   int64
+	frame []byte
+}
+
+func (rs *ResumeSession) Token() string {
+	return rs.token
+}
+
+func (rs *ResumeSession) UserID() int64 {
+	return rs.userID
+}
+
+func (rs *ResumeSession) Platform() string {
+	return rs.platform
+}
+
+// ConnID 返回最后绑定的连接 ID
+func (rs *ResumeSession) ConnID() int64 {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.connID
+}
+
+// Push 分配下行序号、构建帧并写入缓冲
+// 会话已绑定连接时调用 send（参数为分配序号时绑定的连接 ID），断开期间只缓冲
+// send 在会话锁外调用，并按序号依次进入连接写队列，保证客户端按序号顺序收到
+func (rs *ResumeSession) Push(build func(seq int64) []byte, send func(connID int64, frame []byte) error) error {
+	rs.mu.Lock()
+	rs.seq++
+	seq := rs.seq
+	frame := build(seq)
+	rs.append(seq, frame)
+	attached, connID := rs.attached, rs.connID
+	rs.mu.Unlock()
+
+	rs.sendMu.Lock()
+	defer rs.sendMu.Unlock()
+	for rs.sent != seq-1 {
+		rs.sendCond.Wait()
+	}
+	defer func() {
+		rs.sent = seq
+		rs.sendCond.Broadcast()
+	}()
+
+	if !attached {
+		return nil
+	}
+	return send(connID, frame)
+}
+
+// append 写入环形缓冲，满时覆盖最旧帧（调用方持锁）
+func (rs *ResumeSession) append(seq int64, frame []byte) {
+	if len(rs.frames) == 0 {
+		return
+	}
+	if rs.count < len(rs.frames) {
+		rs.frames[(rs.head+rs.count)%len(rs.frames)] = resumeFrame{seq: seq, frame: frame}
+		rs.count++
+		return
+	}
+	rs.frames[rs.head] = resumeFrame{seq: seq, frame: frame}
+	rs.head = (rs.head + 1) % len(rs.frames)
+}
+
+// missedSince 返回序号大于 lastSeq 的缓冲帧，缓冲已无法覆盖时返回 false（调用方持锁）
+func (rs *ResumeSession) missedSince(lastSeq int64) ([][]byte, bool) {
+	if lastSeq > rs.seq || lastSeq < 0 {
+		return nil, false
+	}
+	if lastSeq == rs.seq {
+		return nil, true
+	}
+	if rs.count == 0 || rs.frames[rs.head].seq > lastSeq+1 {
+		return nil, false
+	}
+
+	missed := make([][]byte, 0, rs.seq-lastSeq)
+	for i := 0; i < rs.count; i++ {
+		f := rs.frames[(rs.head+i)%len(rs.frames)]
+		if f.seq > lastSeq {
+			missed = append(missed, f.frame)
+		}
+	}
+	return missed, true
+}
+
+// ResumeRequest 客户端认证时携带的恢复参数
+type ResumeRequest struct {
+	Token   string
+	LastSeq int64
+}
+
+// AttachResult 连接绑定会话的结果
+type AttachResult struct {
+	Token   string   // 恢复令牌
+	Seq     int64    // 当前最新下行序号
+	Missed  [][]byte // 需要补发的帧（按序号升序）
+	Resumed bool     // 是否恢复了旧会话
+	// 处于宽限期、被本次绑定恢复或替换的会话最后绑定的连接 ID（0 表示没有）
+	// 该连接的下线处理被推迟且不会再由会话过期触发，调用方负责补发其下线事件
+	ReplacedConnID int64
+}
+
+// ResumeStore 可恢复会话存储
+type ResumeStore struct {
+	bufferSize  int
+	gracePeriod time.Duration
+	logger      *slog.Logger
+
+	mu      sync.Mutex
+	byToken map[string]*ResumeSession
+	byUser  map[int64]map[string]*ResumeSession // userID -> platform -> session
+}
+
+// NewResumeStore 创建会话恢复存储，bufferSize 为每个会话缓冲的下行帧数，gracePeriod 为断线后保留时长
+func NewResumeStore(bufferSize int, gracePeriod time.Duration, logger *slog.Logger) *ResumeStore {
+	// 设置默认值
+	if bufferSize <= 0 {
+		bufferSize = defaultResumeBufferSize
+	}
+	if gracePeriod <= 0 {
+		gracePeriod = defaultResumeGracePeriod
+	}
+
+	return &ResumeStore{
+		bufferSize:  bufferSize,
+		gracePeriod: gracePeriod,
+		logger:      logger,
+		byToken:     make(map[string]*ResumeSession),
+		byUser:      make(map[int64]map[string]*ResumeSession),
+	}
+}
+
+// GracePeriod 返回断线后会话保留时长
+func (s *ResumeStore) GracePeriod() time.Duration {
+	return s.gracePeriod
+}
+
+// Attach 将连接绑定到会话：恢复令牌有效（同用户、同平台、同设备且缓冲覆盖 lastSeq）时恢复旧会话，否则新建会话
+// fn 在会话锁内调用，用于发送 AuthAck 与补发帧，期间新的下行帧会等待，保证顺序；fn 内不能调用 Push
+func (s *ResumeStore) Attach(req ResumeRequest, userID int64, platform, deviceID string, connID int64, fn func(res AttachResult)) {
+	s.mu.Lock()
+	rs := s.byToken[req.Token]
+	if req.Token == "" || rs == nil || rs.userID != userID || rs.platform != platform || rs.deviceID != deviceID {
+		rs = nil
+	}
+
+	if rs != nil {
+		rs.mu.Lock()
+		missed, ok := rs.missedSince(req.LastSeq)
+		expired := !rs.attached && time.Since(rs.detachedAt) > s.gracePeriod
+		if ok && !expired {
+			s.mu.Unlock()
+			var replaced int64
+			if !rs.attached && rs.connID != connID {
+				replaced = rs.connID
+			}
+			rs.connID = connID
+			rs.attached = true
+			rs.detachedAt = time.Time{}
+			fn(AttachResult{Token: rs.token, Seq: rs.seq, Missed: missed, Resumed: true, ReplacedConnID: replaced})
+			rs.mu.Unlock()
+			return
+		}
+		rs.mu.Unlock()
+	}
+
+	// 新建会话，替换该用户该平台的旧会话
+	rs = &ResumeSession{
+		token:    rand.Text(),
+		userID:   userID,
+		platform: platform,
+		deviceID: deviceID,
+		connID:   connID,
+		attached: true,
+		frames:   make([]resumeFrame, s.bufferSize),
+	}
+	rs.sendCond = sync.NewCond(&rs.sendMu)
+	var replaced int64
+	if platforms, ok := s.byUser[userID]; ok {
+		if old, exists := platforms[platform]; exists {
+			delete(s.byToken, old.token)
+			old.mu.Lock()
+			if !old.attached && old.connID != connID {
+				replaced = old.connID
+			}
+			old.mu.Unlock()
+		}
+	} else {
+		s.byUser[userID] = make(map[string]*ResumeSession)
+	}
+	s.byUser[userID][platform] = rs
+	s.byToken[rs.token] = rs
+
+	rs.mu.Lock()
+	s.mu.Unlock()
+	fn(AttachResult{Token: rs.token, ReplacedConnID: replaced})
+	rs.mu.Unlock()
+}
+
+// Get 获取用户在指定平台的会话
+func (s *ResumeStore) Get(userID int64, platform string) *ResumeSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	platforms, ok := s.byUser[userID]
+	if !ok {
+		return nil
+	}
+	return platforms[platform]
+}
+
+// Detach 连接断开时解绑会话，会话进入宽限期
+// 仅当会话最后绑定的是该连接时生效（重复调用幂等），返回 true 表示会话被保留（调用方应推迟下线处理）
+func (s *ResumeStore) Detach(userID int64, platform string, connID int64) bool {
+	rs := s.Get(userID, platform)
+	if rs == nil {
+		return false
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.connID != connID {
+		return false
+	}
+	if rs.attached {
+		rs.attached = false
+		rs.detachedAt = time.Now()
+	}
+	return true
+}
+
+// Remove 移除会话，不进入宽限期（如慢消费者被断开时缓冲已不可靠）
+// 仅当会话最后绑定的是该连接时生效，避免误删已被新连接恢复的会话
+func (s *ResumeStore) Remove(userID int64, platform string, connID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	platforms, ok := s.byUser[userID]
+	if !ok {
+		return false
+	}
+	rs, ok := platforms[platform]
+	if !ok || rs.ConnID() != connID {
+		return false
+	}
+	delete(platforms, platform)
+	delete(s.byToken, rs.token)
+	if len(platforms) == 0 {
+		delete(s.byUser, userID)
+	}
+	return true
+}
+
+// Start 定期清理超过宽限期的会话（阻塞，应在 goroutine 中调用）
+// onExpire 在会话过期时调用，用于执行被推迟的下线处理
+func (s *ResumeStore) Start(ctx context.Context, onExpire func(rs *ResumeSession)) {
+	ticker := time.NewTicker(s.gracePeriod / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, rs := range s.removeExpired() {
+				if onExpire != nil {
+					onExpire(rs)
+				}
+			}
+		}
+	}
+}
+
+// removeExpired 移除超过宽限期的断开会话
+func (s *ResumeStore) removeExpired() []*ResumeSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expired []*ResumeSession
+	now := time.Now()
+	for userID, platforms := range s.byUser {
+		for platform, rs := range platforms {
+			rs.mu.Lock()
+			isExpired := !rs.attached && now.Sub(rs.detachedAt) > s.gracePeriod
+			rs.mu.Unlock()
+			if !isExpired {
+				continue
+			}
+			delete(platforms, platform)
+			delete(s.byToken, rs.token)
+			expired = append(expired, rs)
+		}
+		if len(platforms) == 0 {
+			delete(s.byUser, userID)
+		}
+	}
+
+	if len(expired) > 0 {
+		s.logger.Debug("Resume sessions expired", "count", len(expired))
+	}
+	return expired
+}
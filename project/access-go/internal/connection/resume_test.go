@@ -0,0 +1,233 @@
+package connection
+
+import (
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// pushN 向会话推送 n 帧，帧内容为序号字符串
+func pushN(t *testing.T, rs *ResumeSession, n int, sent *[]string) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		err := rs.Push(func(seq int64) []byte {
+			return []byte(fmt.Sprintf("%d", seq))
+		}, func(connID int64, frame []byte) error {
+			*sent = append(*sent, string(frame))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("推送失败: %v", err)
+		}
+	}
+}
+
+// TestResumeStoreResume 测试宽限期内携带令牌重连后补发缺失帧
+func TestResumeStoreResume(t *testing.T) {
+	store := NewResumeStore(4, time.Minute, slog.Default())
+
+	var first AttachResult
+	store.Attach(ResumeRequest{}, 1, "web", "device-1", 100, func(res AttachResult) {
+		first = res
+	})
+	if first.Resumed || first.Token == "" {
+		t.Fatalf("首次认证应新建会话并下发令牌: %+v", first)
+	}
+
+	rs := store.Get(1, "web")
+	var sent []string
+	pushN(t, rs, 2, &sent)
+	if len(sent) != 2 {
+		t.Fatalf("绑定连接时应直接发送，实际发送 %d 帧", len(sent))
+	}
+
+	// 断开后继续推送，只缓冲不发送
+	if !store.Detach(1, "web", 100) {
+		t.Fatalf("会话应进入宽限期")
+	}
+	pushN(t, rs, 2, &sent)
+	if len(sent) != 2 {
+		t.Fatalf("断开期间不应发送，实际发送 %d 帧", len(sent))
+	}
+
+	// 客户端已收到 seq=2，重连后应补发 3、4
+	var second AttachResult
+	store.Attach(ResumeRequest{Token: first.Token, LastSeq: 2}, 1, "web", "device-1", 101, func(res AttachResult) {
+		second = res
+	})
+	if !second.Resumed || second.Token != first.Token || second.Seq != 4 {
+		t.Fatalf("应恢复旧会话: %+v", second)
+	}
+	if len(second.Missed) != 2 || string(second.Missed[0]) != "3" || string(second.Missed[1]) != "4" {
+		t.Fatalf("补发帧不正确: %q", second.Missed)
+	}
+
+	// 旧连接的断开不影响已恢复的会话
+	if store.Detach(1, "web", 100) {
+		t.Fatalf("旧连接不应解绑已恢复的会话")
+	}
+}
+
+// TestResumeStoreFallback 测试无法恢复时新建会话
+func TestResumeStoreFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string // 为空时使用首次认证下发的令牌
+		lastSeq  int64
+		deviceID string
+	}{
+		{"缓冲已覆盖", "", 1, "device-1"},
+		{"序号超前", "", 6, "device-1"},
+		{"设备不一致", "", 4, "device-2"},
+		{"令牌无效", "unknown", 4, "device-1"},
+	}
+
+	for _, tt := range tests {
+		store := NewResumeStore(2, time.Minute, slog.Default())
+
+		var first AttachResult
+		store.Attach(ResumeRequest{}, 1, "web", "device-1", 100, func(res AttachResult) {
+			first = res
+		})
+		store.Detach(1, "web", 100)
+
+		var sent []string
+		pushN(t, store.Get(1, "web"), 5, &sent)
+
+		token := tt.token
+		if token == "" {
+			token = first.Token
+		}
+		var res AttachResult
+		store.Attach(ResumeRequest{Token: token, LastSeq: tt.lastSeq}, 1, "web", tt.deviceID, 101, func(r AttachResult) {
+			res = r
+		})
+		if res.Resumed || res.Token == first.Token || res.Seq != 0 {
+			t.Errorf("%s: 应新建会话，实际: %+v", tt.name, res)
+		}
+	}
+}
+
+// TestResumeStoreExpire 测试超过宽限期的会话被清理
+func TestResumeStoreExpire(t *testing.T) {
+	store := NewResumeStore(4, 10*time.Millisecond, slog.Default())
+
+	store.Attach(ResumeRequest{}, 1, "web", "device-1", 100, func(AttachResult) {})
+	store.Attach(ResumeRequest{}, 2, "web", "device-2", 200, func(AttachResult) {})
+	store.Detach(1, "web", 100)
+
+	time.Sleep(20 * time.Millisecond)
+
+	expired := store.removeExpired()
+	if len(expired) != 1 || expired[0].UserID() != 1 {
+		t.Fatalf("应只清理断开的会话，实际: %d", len(expired))
+	}
+	if store.Get(1, "web") != nil {
+		t.Fatalf("过期会话应被移除")
+	}
+	if store.Get(2, "web") == nil {
+		t.Fatalf("在线会话不应被清理")
+	}
+}
+
+// TestResumeStoreReplacedConn 测试宽限期中的会话被恢复或替换时返回旧连接 ID，由调用方补发下线事件
+func TestResumeStoreReplacedConn(t *testing.T) {
+	store := NewResumeStore(4, time.Minute, slog.Default())
+	attach := func(token string, connID int64) AttachResult {
+		var res AttachResult
+		store.Attach(ResumeRequest{Token: token}, 1, "web", "device-1", connID, func(r AttachResult) { res = r })
+		return res
+	}
+
+	first := attach("", 100)
+
+	// 会话仍绑定旧连接：旧连接自行下线，不需要补发
+	if res := attach(first.Token, 101); !res.Resumed || res.ReplacedConnID != 0 {
+		t.Fatalf("绑定中的会话被恢复时不应返回旧连接: %+v", res)
+	}
+
+	// 宽限期中被恢复
+	store.Detach(1, "web", 101)
+	if res := attach(first.Token, 102); !res.Resumed || res.ReplacedConnID != 101 {
+		t.Fatalf("宽限期中的会话被恢复时应返回旧连接: %+v", res)
+	}
+
+	// 宽限期中被不带令牌的新登录替换
+	store.Detach(1, "web", 102)
+	if res := attach("", 103); res.Resumed || res.ReplacedConnID != 102 {
+		t.Fatalf("宽限期中的会话被替换时应返回旧连接: %+v", res)
+	}
+
+	// 被替换的会话不会再过期触发下线
+	store.Detach(1, "web", 103)
+	if res := attach("", 104); res.ReplacedConnID != 103 {
+		t.Fatalf("应返回最后绑定的连接: %+v", res)
+	}
+	if expired := store.removeExpired(); len(expired) != 0 {
+		t.Fatalf("被替换的会话不应过期: %d", len(expired))
+	}
+}
+
+// TestResumePushSlowSend 测试 send 阻塞时不占用会话锁，后续帧仍按序号顺序发送
+func TestResumePushSlowSend(t *testing.T) {
+	store := NewResumeStore(8, time.Minute, slog.Default())
+	store.Attach(ResumeRequest{}, 1, "web", "device-1", 100, func(AttachResult) {})
+	rs := store.Get(1, "web")
+
+	release := make(chan struct{})
+	sent := make(chan string, 8)
+	send := func(connID int64, frame []byte) error {
+		if string(frame) == "1" {
+			<-release
+		}
+		sent <- string(frame)
+		return nil
+	}
+	build := func(seq int64) []byte { return []byte(fmt.Sprintf("%d", seq)) }
+
+	// waitSeq 等待会话分配到指定序号
+	waitSeq := func(seq int64) {
+		deadline := time.Now().Add(time.Second)
+		for {
+			rs.mu.Lock()
+			cur := rs.seq
+			rs.mu.Unlock()
+			if cur >= seq {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("未分配序号 %d", seq)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	go rs.Push(build, send)
+	waitSeq(1)
+	go rs.Push(build, send)
+	waitSeq(2)
+
+	// 首帧阻塞期间会话锁可用，解绑不被写入拖住
+	detached := make(chan bool)
+	go func() { detached <- store.Detach(1, "web", 100) }()
+	select {
+	case ok := <-detached:
+		if !ok {
+			t.Fatalf("会话应进入宽限期")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("send 阻塞时 Detach 被拖住")
+	}
+
+	close(release)
+	for _, want := range []string{"1", "2"} {
+		select {
+		case got := <-sent:
+			if got != want {
+				t.Fatalf("发送顺序不正确: got %s, want %s", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("未发送帧 %s", want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package connection
+
+import (
+	"context"
+	"net"
+	"sync"
+)
+
+// tcpSession TCP/TLS 长连接会话适配器（原生移动端 SDK）
+// 连接本身即唯一的双向流，AcceptStream 首次调用返回该连接，之后阻塞直到会话关闭
+type tcpSession struct {
+	conn      net.Conn
+	accepted  bool
+	acceptMu  sync.Mutex
+	closeChan chan struct{}
+	closeOnce sync.Once
+}
+
+// NewTCPSession 将 TCP/TLS 连接包装为 Session
+func NewTCPSession(conn net.Conn) Session {
+	return &tcpSession{
+		conn:      conn,
+		closeChan: make(chan struct{}),
+	}
+}
+
+func (s *tcpSession) AcceptStream(ctx context.Context) (Stream, error) {
+	s.acceptMu.Lock()
+	if !s.accepted {
+		s.accepted = true
+		s.acceptMu.Unlock()
+		return tcpStream{conn: s.conn}, nil
+	}
+	s.acceptMu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.closeChan:
+		return nil, ErrConnectionClosed
+	}
+}
+
+// CloseWithError TCP 没有应用层关闭码，直接关闭连接（错误原因已通过 ClientResponse 告知客户端）
+func (s *tcpSession) CloseWithError(_ uint32, _ string) error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.closeChan)
+		err = s.conn.Close()
+	})
+	return err
+}
+
+func (s *tcpSession) RemoteAddr() net.Addr {
+	return s.conn.RemoteAddr()
+}
+
+// tcpStream 流关闭由会话统一处理，避免 HandleStream 退出时提前断开连接
+type tcpStream struct {
+	conn net.Conn
+}
+
+func (s tcpStream) Read(p []byte) (int, error) {
+	return s.conn.Read(p)
+}
+
+func (s tcpStream) Write(p []byte) (int, error) {
+	return s.conn.Write(p)
+}
+
+func (s tcpStream) Close() error {
+	return nil
+}
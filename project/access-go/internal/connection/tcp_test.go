@@ -0,0 +1,92 @@
+package connection
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// TestTCPSessionFraming 帧被拆成多次写入或多帧合并写入时都能按序还原，会话关闭后 AcceptStream 返回 ErrConnectionClosed
+func TestTCPSessionFraming(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer listener.Close()
+
+	client, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer client.Close()
+	serverConn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	session := NewTCPSession(serverConn)
+
+	frames := [][]byte{
+		encodeFrame(1, []byte("auth")),
+		encodeFrame(2, bytes.Repeat([]byte("x"), 300)),
+		encodeFrame(3, nil),
+		encodeFrame(4, []byte("chat")),
+	}
+	go func() {
+		// 第一帧逐字节写入，第二帧从消息体中间拆开，其余帧与第二帧后半部分合并写入
+		for i := range frames[0] {
+			_, _ = client.Write(frames[0][i : i+1])
+			time.Sleep(time.Millisecond)
+		}
+		_, _ = client.Write(frames[1][:100])
+		time.Sleep(10 * time.Millisecond)
+		rest := append(append(append([]byte{}, frames[1][100:]...), frames[2]...), frames[3]...)
+		_, _ = client.Write(rest)
+	}()
+
+	stream, err := session.AcceptStream(context.Background())
+	if err != nil {
+		t.Fatalf("AcceptStream failed: %v", err)
+	}
+	for i, frame := range frames {
+		frameType, body := readFrame(t, stream)
+		if frameType != frame[4] || !bytes.Equal(body, frame[5:]) {
+			t.Fatalf("第 %d 帧不正确: type=%d len=%d", i, frameType, len(body))
+		}
+	}
+
+	// 唯一的流关闭不断开连接，下行写入原样到达客户端
+	_ = stream.Close()
+	if _, err := stream.Write(frames[3]); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	got := make([]byte, len(frames[3]))
+	_ = client.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := io.ReadFull(client, got); err != nil || !bytes.Equal(got, frames[3]) {
+		t.Fatalf("下行数据不正确: %v, %v", got, err)
+	}
+
+	// 会话关闭后再次 AcceptStream 返回 ErrConnectionClosed
+	accepted := make(chan error, 1)
+	go func() {
+		_, err := session.AcceptStream(context.Background())
+		accepted <- err
+	}()
+	if err := session.CloseWithError(0, ""); err != nil {
+		t.Fatalf("CloseWithError failed: %v", err)
+	}
+	select {
+	case err := <-accepted:
+		if !errors.Is(err, ErrConnectionClosed) {
+			t.Fatalf("AcceptStream err = %v, want ErrConnectionClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("会话关闭后 AcceptStream 未返回")
+	}
+	if _, err := client.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+		t.Fatalf("会话关闭后客户端应读到 EOF，got %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package connection
+
+import (
+	"context"
+	"io"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/webtransport-go"
+)
+
+// 传输类型
+const (
+	TransportWebTransport = "webtransport"
+	TransportWebSocket    = "websocket"
+	TransportTCP          = "tcp"
+)
+
+// 应用层关闭码（WebSocket 要求自定义码在 4000-4999）
+const (
+	CloseCodeNormal           uint32 = 0
+	CloseCodeAuthFailed       uint32 = 4001 // 认证失败
+	CloseCodeSlowConsumer     uint32 = 4002 // 写队列溢出，客户端消费过慢
+	CloseCodeOverloaded       uint32 = 4003 // 超过全局或单 IP 连接上限，客户端应退避后重试其他节点
+	CloseCodeAuthTimeout      uint32 = 4004 // 未在期限内完成首包认证
+	CloseCodeRateLimited      uint32 = 4005 // 持续超过请求频率限制
+	CloseCodeFrameTooLarge    uint32 = 4006 // 帧长度超过上限
+	CloseCodeKicked           uint32 = 4007 // 被管理员或其他节点踢下线，客户端不应自动重连
+	CloseCodeHeartbeatTimeout uint32 = 4008 // 心跳超时，会话进入恢复宽限期
+)
+
+// Stream 双向字节流
+// 帧格式统一为 [4 bytes length] + [1 byte frameType] + [FlatBuffers body]，与具体传输无关
+type Stream interface {
+	io.Reader
+	io.Writer
+	Close() error
+}
+
+// Session 传输层会话，屏蔽 WebTransport / WebSocket 等传输差异
+type Session interface {
+	// AcceptStream 等待客户端打开双向流（首个流必须携带认证请求）
+	AcceptStream(ctx context.Context) (Stream, error)
+	// CloseWithError 携带应用错误码关闭会话
+	CloseWithError(code uint32, msg string) error
+	// RemoteAddr 客户端地址
+	RemoteAddr() net.Addr
+}
+
+// rttReporter 可报告往返时延的会话（未实现的传输 RTT 视为未知）
+type rttReporter interface {
+	RTT() time.Duration
+}
+
+// webTransportSession WebTransport 会话适配器
+type webTransportSession struct {
+	session *webtransport.Session
+	conn    *quic.Conn // 底层 QUIC 连接，用于读取 RTT 等统计，可为 nil
+}
+
+// NewWebTransportSession 将 WebTransport 会话包装为 Session，conn 为承载该会话的 QUIC 连接（可为 nil）
+func NewWebTransportSession(session *webtransport.Session, conn *quic.Conn) Session {
+	return &webTransportSession{session: session, conn: conn}
+}
+
+func (s *webTransportSession) AcceptStream(ctx context.Context) (Stream, error) {
+	stream, err := s.session.AcceptStream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+func (s *webTransportSession) CloseWithError(code uint32, msg string) error {
+	return s.session.CloseWithError(webtransport.SessionErrorCode(code), msg)
+}
+
+func (s *webTransportSession) RemoteAddr() net.Addr {
+	return s.session.RemoteAddr()
+}
+
+// RTT 返回 QUIC 平滑往返时延
+func (s *webTransportSession) RTT() time.Duration {
+	if s.conn == nil {
+		return 0
+	}
+	return s.conn.ConnectionStats().SmoothedRTT
+}
@@ -0,0 +1,129 @@
+package connection
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsCloseTimeout 发送关闭帧的写超时
+const wsCloseTimeout = time.Second
+
+// webSocketSession WebSocket 会话适配器
+// WebSocket 只有一条双向通道，AcceptStream 首次调用返回该通道，之后阻塞直到会话关闭
+type webSocketSession struct {
+	conn      *websocket.Conn
+	stream    *webSocketStream
+	accepted  bool
+	acceptMu  sync.Mutex
+	closeChan chan struct{}
+	closeOnce sync.Once
+}
+
+// NewWebSocketSession 将 WebSocket 连接包装为 Session
+func NewWebSocketSession(conn *websocket.Conn) Session {
+	return &webSocketSession{
+		conn:      conn,
+		stream:    &webSocketStream{conn: conn},
+		closeChan: make(chan struct{}),
+	}
+}
+
+func (s *webSocketSession) AcceptStream(ctx context.Context) (Stream, error) {
+	s.acceptMu.Lock()
+	if !s.accepted {
+		s.accepted = true
+		s.acceptMu.Unlock()
+		return s.stream, nil
+	}
+	s.acceptMu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-s.closeChan:
+		return nil, ErrConnectionClosed
+	}
+}
+
+func (s *webSocketSession) CloseWithError(code uint32, msg string) error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.closeChan)
+		// WebSocket 关闭码：0 视为正常关闭，其余原样透传（应用自定义码应在 4000-4999）
+		closeCode := websocket.CloseNormalClosure
+		if code != 0 {
+			closeCode = int(code)
+		}
+		// 写协程阻塞在慢消费者上时无法获取写锁，跳过关闭帧直接断开底层连接
+		if s.stream.writeMu.TryLock() {
+			writeErr := s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, msg), time.Now().Add(wsCloseTimeout))
+			s.stream.writeMu.Unlock()
+			if writeErr != nil && !errors.Is(writeErr, websocket.ErrCloseSent) {
+				err = writeErr
+			}
+		}
+		if closeErr := s.conn.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	})
+	return err
+}
+
+func (s *webSocketSession) RemoteAddr() net.Addr {
+	return s.conn.RemoteAddr()
+}
+
+// webSocketStream 将 WebSocket 二进制消息适配为字节流
+// 写：每次 Write 发送一条二进制消息（调用方应一次写入完整帧）
+// 读：按顺序拼接二进制消息内容，忽略文本消息
+type webSocketStream struct {
+	conn    *websocket.Conn
+	reader  io.Reader
+	writeMu sync.Mutex // gorilla/websocket 只允许单个并发写
+}
+
+func (s *webSocketStream) Read(p []byte) (int, error) {
+	for {
+		if s.reader == nil {
+			msgType, reader, err := s.conn.NextReader()
+			if err != nil {
+				return 0, err
+			}
+			if msgType != websocket.BinaryMessage {
+				continue
+			}
+			s.reader = reader
+		}
+
+		n, err := s.reader.Read(p)
+		if errors.Is(err, io.EOF) {
+			// 当前消息读完，切换到下一条
+			s.reader = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (s *webSocketStream) Write(p []byte) (int, error) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if err := s.conn.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close 流关闭由会话统一处理，WebSocket 只有一条通道
+func (s *webSocketStream) Close() error {
+	return nil
+}
@@ -0,0 +1,107 @@
+package connection
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// encodeFrame 按帧协议编码：[4B 长度 BE][1B 类型][消息体]
+func encodeFrame(frameType byte, body []byte) []byte {
+	frame := make([]byte, 5+len(body))
+	binary.BigEndian.PutUint32(frame, uint32(len(body)))
+	frame[4] = frameType
+	copy(frame[5:], body)
+	return frame
+}
+
+// readFrame 与 Handler 相同的方式从字节流读取一帧
+func readFrame(t *testing.T, r io.Reader) (byte, []byte) {
+	t.Helper()
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r, header); err != nil {
+		t.Fatalf("读取帧头失败: %v", err)
+	}
+	body := make([]byte, binary.BigEndian.Uint32(header))
+	if _, err := io.ReadFull(r, body); err != nil {
+		t.Fatalf("读取消息体失败: %v", err)
+	}
+	return header[4], body
+}
+
+// TestWebSocketStreamFraming 帧跨多条 WebSocket 消息拆分或多帧合并为一条消息时都能按序还原，写入的帧以单条消息下发
+func TestWebSocketStreamFraming(t *testing.T) {
+	sessions := make(chan Session, 1)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Upgrade failed: %v", err)
+			return
+		}
+		sessions <- NewWebSocketSession(conn)
+	}))
+	defer srv.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer client.Close()
+	session := <-sessions
+	defer session.CloseWithError(0, "")
+
+	frames := [][]byte{
+		encodeFrame(1, []byte("auth")),
+		encodeFrame(2, bytes.Repeat([]byte("x"), 300)),
+		encodeFrame(3, nil),
+		encodeFrame(4, []byte("chat")),
+	}
+	stream := append(append(append(append([]byte{}, frames[0]...), frames[1]...), frames[2]...), frames[3]...)
+	split := len(frames[0]) + 100 // 第二帧从消息体中间拆开
+
+	messages := []struct {
+		msgType int
+		data    []byte
+	}{
+		{websocket.BinaryMessage, stream[:3]}, // 帧头跨消息
+		{websocket.BinaryMessage, stream[3:split]},
+		{websocket.TextMessage, []byte("ignored")},
+		{websocket.BinaryMessage, nil},
+		{websocket.BinaryMessage, stream[split:]}, // 剩余部分与后两帧合并
+	}
+	for _, m := range messages {
+		if err := client.WriteMessage(m.msgType, m.data); err != nil {
+			t.Fatalf("WriteMessage failed: %v", err)
+		}
+	}
+
+	s, err := session.AcceptStream(context.Background())
+	if err != nil {
+		t.Fatalf("AcceptStream failed: %v", err)
+	}
+	for i, frame := range frames {
+		frameType, body := readFrame(t, s)
+		if frameType != frame[4] || !bytes.Equal(body, frame[5:]) {
+			t.Fatalf("第 %d 帧不正确: type=%d len=%d", i, frameType, len(body))
+		}
+	}
+
+	// 下行：每次 Write 对应一条二进制消息
+	if _, err := s.Write(frames[1]); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	_ = client.SetReadDeadline(time.Now().Add(time.Second))
+	msgType, data, err := client.ReadMessage()
+	if err != nil || msgType != websocket.BinaryMessage || !bytes.Equal(data, frames[1]) {
+		t.Fatalf("下行消息不正确: type=%d len=%d err=%v", msgType, len(data), err)
+	}
+}
@@ -6,19 +6,20 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
-	// Added by user instruction
-	// Added by user instruction
-	"github.com/quic-go/webtransport-go"
 	"sudooom.im.access/internal/connection"
 	im_protocol "sudooom.im.access/pkg/flatbuf/im/protocol"
 	"sudooom.im.shared/proto"
 )
 
+// newLoginKickMsg 同平台新登录踢掉旧连接时的提示
+const newLoginKickMsg = "logged in on another device"
+
 // HandleFirstStream 处理首个数据流，必须是认证请求
 // 返回 error 表示认证失败，调用方应关闭连接
 // 认证成功后流保持打开，调用方应继续在此流上处理后续消息
-func (h *Handler) HandleFirstStream(ctx context.Context, conn *connection.Connection, stream *webtransport.Stream) error {
+func (h *Handler) HandleFirstStream(ctx context.Context, conn *connection.Connection, stream connection.Stream) error {
 	// 注意：不再 defer close，因为要复用这个流
 
 	// 读取帧头
@@ -33,10 +34,16 @@ func (h *Handler) HandleFirstStream(ctx context.Context, conn *connection.Connec
 	// 检查首包必须是 Auth 请求
 	if frameType != FrameTypeAuth {
 		h.logger.Warn("First frame must be auth request", "conn_id", conn.ID(), "frameType", frameType)
-		h.sendClientResponse(stream, "", im_protocol.ErrorCodeAUTH_FAILED, "auth required", im_protocol.ResponsePayloadNONE, nil)
+		h.sendAuthAck(stream, im_protocol.ErrorCodeAUTH_FAILED, "auth required")
 		return fmt.Errorf("first frame is not auth request")
 	}
 
+	if length > h.maxFrameSize {
+		h.logger.Warn("Auth frame too large", "conn_id", conn.ID(), "length", length, "max", h.maxFrameSize)
+		h.sendAuthAck(stream, im_protocol.ErrorCodePARAM_ERROR, "frame too large")
+		return fmt.Errorf("auth frame too large: %d", length)
+	}
+
 	// 读取消息体
 	body := make([]byte, length)
 	if _, err := io.ReadFull(stream, body); err != nil {
@@ -52,10 +59,15 @@ func (h *Handler) HandleFirstStream(ctx context.Context, conn *connection.Connec
 }
 
 // handleAuth 处理认证请求，返回 error 表示认证失败
-func (h *Handler) handleAuth(ctx context.Context, conn *connection.Connection, stream *webtransport.Stream, body []byte) error {
+func (h *Handler) handleAuth(ctx context.Context, conn *connection.Connection, stream connection.Stream, body []byte) error {
 	// Auth request processing
 
 	// 解析 FlatBuffers AuthRequest
+	if err := VerifyAuthRequest(body); err != nil {
+		h.logger.Warn("Invalid auth request", "conn_id", conn.ID(), "error", err)
+		h.sendAuthAck(stream, im_protocol.ErrorCodePARAM_ERROR, err.Error())
+		return fmt.Errorf("invalid auth request: %w", err)
+	}
 	authReq := im_protocol.GetRootAsAuthRequest(body, 0)
 
 	token := string(authReq.Token())
@@ -66,28 +78,28 @@ func (h *Handler) handleAuth(ctx context.Context, conn *connection.Connection, s
 	userInfo, err := h.redisClient.GetUserInfoByToken(ctx, token)
 	if err != nil {
 		h.logger.Error("Failed to get user info from Redis", "error", err)
-		h.sendClientResponse(stream, "", im_protocol.ErrorCodeUNKNOWN_ERROR, "internal error", im_protocol.ResponsePayloadNONE, nil)
+		h.sendAuthAck(stream, im_protocol.ErrorCodeUNKNOWN_ERROR, "internal error")
 		return fmt.Errorf("redis error: %w", err)
 	}
 
 	// 验证 token 是否存在
 	if userInfo == nil {
 		h.logger.Warn("Token not found", "conn_id", conn.ID())
-		h.sendClientResponse(stream, "", im_protocol.ErrorCodeAUTH_FAILED, "invalid token", im_protocol.ResponsePayloadNONE, nil)
+		h.sendAuthAck(stream, im_protocol.ErrorCodeAUTH_FAILED, "invalid token")
 		return fmt.Errorf("token not found")
 	}
 
 	// 比对 deviceId
 	if userInfo.DeviceID != deviceID {
 		h.logger.Warn("DeviceID mismatch", "conn_id", conn.ID(), "expected", userInfo.DeviceID, "got", deviceID)
-		h.sendClientResponse(stream, "", im_protocol.ErrorCodeAUTH_FAILED, "device mismatch", im_protocol.ResponsePayloadNONE, nil)
+		h.sendAuthAck(stream, im_protocol.ErrorCodeAUTH_FAILED, "device mismatch")
 		return fmt.Errorf("device mismatch")
 	}
 
 	// 比对 platform（不区分大小写，FlatBuffers 返回大写，Redis 存储小写）
 	if !strings.EqualFold(userInfo.Platform, platform.String()) {
 		h.logger.Warn("Platform mismatch", "conn_id", conn.ID(), "expected", userInfo.Platform, "got", platform.String())
-		h.sendClientResponse(stream, "", im_protocol.ErrorCodeAUTH_FAILED, "platform mismatch", im_protocol.ResponsePayloadNONE, nil)
+		h.sendAuthAck(stream, im_protocol.ErrorCodeAUTH_FAILED, "platform mismatch")
 		return fmt.Errorf("platform mismatch")
 	}
 
@@ -95,12 +107,12 @@ func (h *Handler) handleAuth(ctx context.Context, conn *connection.Connection, s
 	isCurrent, err := h.redisClient.IsTokenCurrent(ctx, userInfo.UserID, userInfo.Platform, token)
 	if err != nil {
 		h.logger.Error("Failed to check token validity", "error", err)
-		h.sendClientResponse(stream, "", im_protocol.ErrorCodeUNKNOWN_ERROR, "internal error", im_protocol.ResponsePayloadNONE, nil)
+		h.sendAuthAck(stream, im_protocol.ErrorCodeUNKNOWN_ERROR, "internal error")
 		return fmt.Errorf("redis error: %w", err)
 	}
 	if !isCurrent {
 		h.logger.Warn("Token is not current", "conn_id", conn.ID(), "user_id", userInfo.UserID)
-		h.sendClientResponse(stream, "", im_protocol.ErrorCodeAUTH_FAILED, "token expired or replaced", im_protocol.ResponsePayloadNONE, nil)
+		h.sendAuthAck(stream, im_protocol.ErrorCodeAUTH_FAILED, "token expired or replaced")
 		return fmt.Errorf("token is not current")
 	}
 
@@ -116,33 +128,114 @@ func (h *Handler) handleAuth(ctx context.Context, conn *connection.Connection, s
 	// 如果该用户在同一平台已有连接，会返回旧连接
 	oldConn := h.connMgr.BindUser(conn.ID(), sessInfo.UserID, sessInfo.Platform)
 	if oldConn != nil {
-		// 踢掉同平台的旧连接
-		h.logger.Info("Kicking old connection on same platform",
+		// 踢掉同平台的旧连接，旧连接会在 server 的 defer 中自动清理
+		go h.Kick(oldConn, im_protocol.KickReasonNEW_LOGIN, newLoginKickMsg)
+	}
+
+	// 先设置客户端流，AuthAck 与补发帧统一经连接写协程按序发送
+	conn.SetClientStream(stream)
+
+	// 绑定可恢复会话：携带有效恢复令牌时补发断线期间缺失的下行帧，否则新建会话
+	resumeReq := connection.ResumeRequest{
+		Token:   string(authReq.ResumeToken()),
+		LastSeq: authReq.LastSeq(),
+	}
+	var resumed bool
+	var replacedConnID int64
+	h.resumeStore.Attach(resumeReq, sessInfo.UserID, sessInfo.Platform, sessInfo.DeviceID, conn.ID(), func(res connection.AttachResult) {
+		resumed = res.Resumed
+		replacedConnID = res.ReplacedConnID
+		if err := conn.Send(h.buildAuthAckFrame(conn, res)); err != nil {
+			h.logger.Error("Failed to send auth ack", "conn_id", conn.ID(), "error", err)
+			return
+		}
+		for _, frame := range res.Missed {
+			if err := conn.Send(frame); err != nil {
+				h.logger.Error("Failed to replay missed frame", "conn_id", conn.ID(), "error", err)
+				return
+			}
+		}
+		if res.Resumed {
+			h.logger.Info("Session resumed", "conn_id", conn.ID(), "user_id", sessInfo.UserID, "last_seq", resumeReq.LastSeq, "replayed", len(res.Missed))
+		}
+	})
+
+	// 注册用户位置到 Redis（包含 connId），旧位置在其他节点时通知该节点踢掉旧连接
+	previous, err := h.redisClient.RegisterUserLocation(ctx, sessInfo.UserID, sessInfo.Platform, conn.ID())
+	if err != nil {
+		h.logger.Error("Failed to register user location", "error", err)
+	} else if previous != nil && previous.AccessNodeId != h.nodeID {
+		h.logger.Info("Kicking old connection on another node",
 			"user_id", sessInfo.UserID,
 			"platform", sessInfo.Platform,
-			"old_conn_id", oldConn.ID(),
+			"old_node_id", previous.AccessNodeId,
+			"old_conn_id", previous.ConnId,
 			"new_conn_id", conn.ID())
-		// 旧连接会在 server 的 defer 中自动清理
-		oldConn.Close()
+		if err := h.kickRemote(previous.AccessNodeId, sessInfo.UserID, sessInfo.Platform, previous.ConnId, im_protocol.KickReasonNEW_LOGIN, newLoginKickMsg); err != nil {
+			h.logger.Error("Failed to publish remote kick", "node_id", previous.AccessNodeId, "error", err)
+		}
 	}
 
-	// 注册用户位置到 Redis（包含 connId）
-	if err := h.redisClient.RegisterUserLocation(ctx, sessInfo.UserID, sessInfo.Platform, conn.ID()); err != nil {
-		h.logger.Error("Failed to register user location", "error", err)
+	// 宽限期中的旧会话被恢复或替换：旧连接被推迟的下线事件不会再由会话过期触发，先于本连接上线补发
+	if replacedConnID > 0 {
+		h.publishUserOffline(sessInfo.UserID, replacedConnID, sessInfo.Platform)
 	}
 
 	// 发送上线通知到 Logic
 	h.sendUserOnlineToLogic(conn, sessInfo)
 
-	// 发送认证成功响应
-	h.sendClientResponse(stream, "", im_protocol.ErrorCodeSUCCESS, "success", im_protocol.ResponsePayloadNONE, nil)
-	h.logger.Info("User authenticated", "conn_id", conn.ID(), "user_id", userInfo.UserID)
+	h.logger.Info("User authenticated", "conn_id", conn.ID(), "user_id", userInfo.UserID, "resumed", resumed)
 
 	return nil
 }
 
+// sendAuthAck 发送认证失败响应（成功响应经 buildAuthAckFrame 由连接写协程发送）
+func (h *Handler) sendAuthAck(stream connection.Stream, code im_protocol.ErrorCode, msg string) {
+	builder := getBuilder()
+	defer putBuilder(builder)
+
+	msgOffset := builder.CreateString(msg)
+
+	im_protocol.AuthAckStart(builder)
+	im_protocol.AuthAckAddCode(builder, code)
+	im_protocol.AuthAckAddMsg(builder, msgOffset)
+	im_protocol.AuthAckAddServerTime(builder, time.Now().UnixMilli())
+	ackOffset := im_protocol.AuthAckEnd(builder)
+	builder.Finish(ackOffset)
+
+	h.sendFrame(stream, FrameTypeAuthAck, builder)
+}
+
+// buildAuthAckFrame 构建认证成功的 AuthAck 帧，携带会话参数与恢复令牌
+func (h *Handler) buildAuthAckFrame(conn *connection.Connection, res connection.AttachResult) []byte {
+	builder := getBuilder()
+	defer putBuilder(builder)
+
+	msgOffset := builder.CreateString("success")
+	userIdOffset := createInt64String(builder, conn.UserID())
+	connIdOffset := createInt64String(builder, conn.ID())
+	resumeTokenOffset := builder.CreateString(res.Token)
+
+	im_protocol.AuthAckStart(builder)
+	im_protocol.AuthAckAddCode(builder, im_protocol.ErrorCodeSUCCESS)
+	im_protocol.AuthAckAddMsg(builder, msgOffset)
+	im_protocol.AuthAckAddUserId(builder, userIdOffset)
+	im_protocol.AuthAckAddConnId(builder, connIdOffset)
+	im_protocol.AuthAckAddServerTime(builder, time.Now().UnixMilli())
+	im_protocol.AuthAckAddHeartbeatIntervalMs(builder, int32(h.heartbeatInterval.Milliseconds()))
+	im_protocol.AuthAckAddResumeToken(builder, resumeTokenOffset)
+	im_protocol.AuthAckAddResumed(builder, res.Resumed)
+	im_protocol.AuthAckAddSeq(builder, res.Seq)
+	ackOffset := im_protocol.AuthAckEnd(builder)
+	builder.Finish(ackOffset)
+
+	return buildFrame(FrameTypeAuthAck, builder.FinishedBytes())
+}
+
 // sendUserOnlineToLogic 发送用户上线事件到 Logic
+// 每个连接的上线事件与下线事件一一对应：标记后由 SendUserOfflineToLogic 发布唯一一次下线事件
 func (h *Handler) sendUserOnlineToLogic(conn *connection.Connection, sessInfo *connection.SessionInfo) {
+	conn.MarkOnline()
 	msg := h.buildUpstreamMessage(conn, proto.UpstreamPayload{
 		UserOnline: &proto.UserOnline{
 			UserId:   sessInfo.UserID,
@@ -157,20 +250,34 @@ func (h *Handler) sendUserOnlineToLogic(conn *connection.Connection, sessInfo *c
 	}
 }
 
-// SendUserOfflineToLogic 发送用户下线通知
+// SendUserOfflineToLogic 发送用户下线通知，未发布过上线事件或已发布过下线事件时忽略
 func (h *Handler) SendUserOfflineToLogic(conn *connection.Connection) {
-	if conn.UserID() == 0 {
+	if conn.UserID() == 0 || !conn.TakeOnline() {
 		return
 	}
+	h.publishUserOffline(conn.UserID(), conn.ID(), conn.Platform())
+}
+
+// ReleaseSession 执行可恢复会话被推迟的下线处理（宽限期过期或被踢下线）
+// 仅注销仍属于该连接的位置，已被新连接覆盖的位置保持不变
+func (h *Handler) ReleaseSession(ctx context.Context, userID int64, platform string, connID int64) {
+	if err := h.redisClient.UnregisterUserLocation(ctx, userID, platform, connID); err != nil {
+		h.logger.Error("Failed to unregister user location", "user_id", userID, "conn_id", connID, "error", err)
+	}
+	h.publishUserOffline(userID, connID, platform)
+}
 
+// publishUserOffline 发布下线事件
+func (h *Handler) publishUserOffline(userID, connID int64, platform string) {
 	// 注意：这里不能使用 buildUpstreamMessage，因为连接可能已经不存在了
 	// 所以手动构建，只填充必需的字段
 	msg := &proto.UpstreamMessage{
 		AccessNodeId: h.nodeID,
 		Payload: proto.UpstreamPayload{
 			UserOffline: &proto.UserOffline{
-				UserId: conn.UserID(),
-				ConnId: conn.ID(),
+				UserId:   userID,
+				ConnId:   connID,
+				Platform: platform,
 			},
 		},
 	}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/binary"
+	"strconv"
+	"sync"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// maxPooledBuilderSize 归还对象池的 Builder 缓冲上限，构建超大消息（如大体积 GamePush）后的 Builder 直接丢弃，避免池中长期占用内存
+const maxPooledBuilderSize = 64 * 1024
+
+// builderPool FlatBuffers Builder 对象池
+var builderPool = sync.Pool{
+	New: func() any {
+		return flatbuffers.NewBuilder(512)
+	},
+}
+
+// getBuilder 从对象池获取已重置的 Builder
+// FinishedBytes 返回的切片引用 Builder 内部缓冲，putBuilder 之前必须已被复制或写出
+func getBuilder() *flatbuffers.Builder {
+	builder := builderPool.Get().(*flatbuffers.Builder)
+	builder.Reset()
+	return builder
+}
+
+// putBuilder 归还 Builder
+func putBuilder(builder *flatbuffers.Builder) {
+	if cap(builder.Bytes) > maxPooledBuilderSize {
+		return
+	}
+	builderPool.Put(builder)
+}
+
+// buildFrame 构建帧：header + body，只分配一次，帧头直接写入目标缓冲
+// 用于需要保留帧的场景（连接写队列、恢复缓冲），调用方拥有返回的切片
+func buildFrame(frameType byte, body []byte) []byte {
+	frame := make([]byte, FrameHeaderSize+len(body))
+	putFrameHeader(frame, frameType, len(body))
+	copy(frame[FrameHeaderSize:], body)
+	return frame
+}
+
+// frameInPlace 在已 Finish 的 Builder 缓冲中紧邻消息体之前写入帧头，返回引用 Builder 缓冲的完整帧，无需分配与复制
+// FlatBuffers 从缓冲尾部向前构建，头部通常留有空闲空间；空间不足时退化为 buildFrame
+// 返回的切片只能在归还 Builder 之前使用（如同步写入流）
+func frameInPlace(builder *flatbuffers.Builder, frameType byte) []byte {
+	head := int(builder.Head())
+	if head < FrameHeaderSize {
+		return buildFrame(frameType, builder.FinishedBytes())
+	}
+	frame := builder.Bytes[head-FrameHeaderSize:]
+	putFrameHeader(frame, frameType, len(frame)-FrameHeaderSize)
+	return frame
+}
+
+// putFrameHeader 写入帧头：4 字节大端长度 + 1 字节帧类型
+func putFrameHeader(frame []byte, frameType byte, bodyLen int) {
+	binary.BigEndian.PutUint32(frame[:4], uint32(bodyLen))
+	frame[4] = frameType
+}
+
+// createInt64String 以十进制字符串写入 int64，避免中间字符串分配
+func createInt64String(builder *flatbuffers.Builder, v int64) flatbuffers.UOffsetT {
+	var buf [20]byte
+	return builder.CreateByteString(strconv.AppendInt(buf[:0], v, 10))
+}
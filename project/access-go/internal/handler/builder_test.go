@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"encoding/binary"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+	im_protocol "sudooom.im.access/pkg/flatbuf/im/protocol"
+	"sudooom.im.shared/proto"
+)
+
+func benchPushMessage() *proto.PushMessage {
+	return &proto.PushMessage{
+		ServerMsgId: 1234567890123456789,
+		FromUserId:  10001,
+		ToUserId:    10002,
+		ChatType:    "PRIVATE",
+		MsgType:     int32(im_protocol.MsgTypeTEXT),
+		Content:     []byte("hello, this is a typical chat message"),
+		Timestamp:   time.Now().UnixMilli(),
+		SenderInfo:  &proto.UserInfo{UserId: 10001, Nickname: "alice", Avatar: "https://example.com/a.png"},
+	}
+}
+
+// parseChatPushFrame 解析 ChatPush 帧，校验帧头
+func parseChatPushFrame(t testing.TB, frame []byte) (*im_protocol.ClientResponse, *im_protocol.ChatPush) {
+	t.Helper()
+	if frame[4] != FrameTypeResponse || int(binary.BigEndian.Uint32(frame[:4])) != len(frame)-FrameHeaderSize {
+		t.Fatalf("帧头不正确: % x", frame[:FrameHeaderSize])
+	}
+	resp := im_protocol.GetRootAsClientResponse(frame[FrameHeaderSize:], 0)
+	return resp, im_protocol.GetRootAsChatPush(resp.PayloadBytes(), 0)
+}
+
+// TestPooledFrames 池化 Builder 复用后，已返回的帧不受影响
+func TestPooledFrames(t *testing.T) {
+	h, _ := newTestHandler()
+
+	build := func(msgID int64, content string) []byte {
+		builder := getBuilder()
+		defer putBuilder(builder)
+		msg := benchPushMessage()
+		msg.ServerMsgId = msgID
+		msg.Content = []byte(content)
+		return h.buildClientResponseFrame("", im_protocol.ErrorCodeSUCCESS, "", im_protocol.ResponsePayloadChatPush, buildChatPush(builder, msg), 7)
+	}
+
+	first := build(1, "first")
+	second := build(2, "second message")
+
+	for _, tt := range []struct {
+		frame   []byte
+		msgID   string
+		content string
+	}{{first, "1", "first"}, {second, "2", "second message"}} {
+		resp, push := parseChatPushFrame(t, tt.frame)
+		if resp.Seq() != 7 || string(push.MsgId()) != tt.msgID || string(push.Content()) != tt.content {
+			t.Fatalf("帧内容不正确: seq=%d msgId=%s content=%s", resp.Seq(), push.MsgId(), push.Content())
+		}
+		if string(push.SenderId()) != "10001" || string(push.TargetId()) != "10002" || string(push.SenderInfo(nil).Nickname()) != "alice" {
+			t.Fatalf("发送者/目标不正确: sender=%s target=%s", push.SenderId(), push.TargetId())
+		}
+	}
+}
+
+// TestFrameInPlace 帧头直接写入 Builder 缓冲中消息体之前的空闲空间
+func TestFrameInPlace(t *testing.T) {
+	builder := getBuilder()
+	defer putBuilder(builder)
+	buildClientResponse(builder, "req-1", im_protocol.ErrorCodeSUCCESS, "", im_protocol.ResponsePayloadHeartbeatResp, nil, 0)
+
+	body := builder.FinishedBytes()
+	frame := frameInPlace(builder, FrameTypeResponse)
+	if &frame[FrameHeaderSize] != &body[0] {
+		t.Fatalf("帧应直接引用 Builder 缓冲")
+	}
+	if frame[4] != FrameTypeResponse || int(binary.BigEndian.Uint32(frame[:4])) != len(body) {
+		t.Fatalf("帧头不正确: % x", frame[:FrameHeaderSize])
+	}
+
+}
+
+// legacyChatPushFrame 池化前的实现：每条消息新建两个 Builder，并把响应再复制进帧
+func legacyChatPushFrame(pushMsg *proto.PushMessage) []byte {
+	builder := flatbuffers.NewBuilder(512)
+	msgIdOffset := builder.CreateString(strconv.FormatInt(pushMsg.ServerMsgId, 10))
+	senderIdOffset := builder.CreateString(strconv.FormatInt(pushMsg.FromUserId, 10))
+	targetIdOffset := builder.CreateString(strconv.FormatInt(pushMsg.ToUserId, 10))
+	contentOffset := builder.CreateString(string(pushMsg.Content))
+	senderInfoOffset := buildUserInfo(builder, pushMsg.SenderInfo)
+	im_protocol.ChatPushStart(builder)
+	im_protocol.ChatPushAddMsgId(builder, msgIdOffset)
+	im_protocol.ChatPushAddSenderId(builder, senderIdOffset)
+	im_protocol.ChatPushAddSenderInfo(builder, senderInfoOffset)
+	im_protocol.ChatPushAddChatType(builder, im_protocol.ChatTypePRIVATE)
+	im_protocol.ChatPushAddTargetId(builder, targetIdOffset)
+	im_protocol.ChatPushAddMsgType(builder, im_protocol.MsgType(pushMsg.MsgType))
+	im_protocol.ChatPushAddContent(builder, contentOffset)
+	im_protocol.ChatPushAddSendTime(builder, pushMsg.Timestamp)
+	builder.Finish(im_protocol.ChatPushEnd(builder))
+	payload := builder.FinishedBytes()
+
+	resp := flatbuffers.NewBuilder(256 + len(payload))
+	buildClientResponse(resp, "", im_protocol.ErrorCodeSUCCESS, "", im_protocol.ResponsePayloadChatPush, payload, 1)
+	return buildFrame(FrameTypeResponse, resp.FinishedBytes())
+}
+
+// BenchmarkChatPushFrame 典型 ChatPush 帧构建：池化 Builder 后每帧只剩帧本身一次分配
+//
+//	go test ./internal/handler -run '^$' -bench ChatPushFrame -benchmem
+func BenchmarkChatPushFrame(b *testing.B) {
+	h, _ := newTestHandler()
+	pushMsg := benchPushMessage()
+
+	b.Run("legacy", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			legacyChatPushFrame(pushMsg)
+		}
+	})
+
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			builder := getBuilder()
+			h.buildClientResponseFrame("", im_protocol.ErrorCodeSUCCESS, "", im_protocol.ResponsePayloadChatPush, buildChatPush(builder, pushMsg), 1)
+			putBuilder(builder)
+		}
+	})
+}
+
+// discardStream 丢弃写入的测试流
+type discardStream struct{}
+
+func (discardStream) Read([]byte) (int, error)    { return 0, io.EOF }
+func (discardStream) Write(p []byte) (int, error) { return len(p), nil }
+func (discardStream) Close() error                { return nil }
+
+// BenchmarkSendClientResponse 同步写流的响应（心跳、请求失败等）：帧头写入 Builder 缓冲，无需分配
+func BenchmarkSendClientResponse(b *testing.B) {
+	h, _ := newTestHandler()
+	stream := discardStream{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		h.sendClientResponse(stream, "req-1", im_protocol.ErrorCodeRATE_LIMITED, "rate limited", im_protocol.ResponsePayloadNONE, nil)
+	}
+}
@@ -4,14 +4,14 @@ import (
 	"context"
 	"strconv"
 
-	"github.com/quic-go/webtransport-go"
 	"sudooom.im.access/internal/connection"
 	im_protocol "sudooom.im.access/pkg/flatbuf/im/protocol"
 	"sudooom.im.shared/proto"
 )
 
 // handleChatSend 处理聊天发送请求
-func (h *Handler) handleChatSend(_ctx context.Context, conn *connection.Connection, _stream *webtransport.Stream, reqID string, payload []byte) {
+// 无法转发给 Logic 时立即回复失败的 ChatSendAck，Logic 拒绝的消息由 Logic 回复
+func (h *Handler) handleChatSend(_ctx context.Context, conn *connection.Connection, stream connection.Stream, reqID string, payload []byte) {
 	// Chat send request
 
 	// 解析 ChatSendReq
@@ -19,15 +19,33 @@ func (h *Handler) handleChatSend(_ctx context.Context, conn *connection.Connecti
 
 	// 解析 targetId 为 int64
 	targetIdStr := string(chatReq.TargetId())
-	targetId, _ := strconv.ParseInt(targetIdStr, 10, 64)
+	targetId, err := strconv.ParseInt(targetIdStr, 10, 64)
+	if err != nil || targetId <= 0 {
+		h.sendChatSendFailure(stream, reqID, im_protocol.ErrorCodePARAM_ERROR, "invalid target id")
+		return
+	}
+
+	// 解析扩展属性
+	var ext []proto.KeyValue
+	if n := chatReq.ExtLength(); n > 0 {
+		ext = make([]proto.KeyValue, 0, n)
+		kv := new(im_protocol.KeyValue)
+		for i := 0; i < n; i++ {
+			if chatReq.Ext(kv, i) {
+				ext = append(ext, proto.KeyValue{Key: string(kv.Key()), Value: string(kv.Value())})
+			}
+		}
+	}
 
 	// 封装上行消息到 Logic
 	msg := h.buildUpstreamMessage(conn, proto.UpstreamPayload{
 		UserMessage: &proto.UserMessage{
 			FromUserId:  conn.UserID(),
 			ClientMsgId: reqID,
+			ChatType:    chatReq.ChatType().String(),
 			MsgType:     int32(chatReq.MsgType()),
 			Content:     chatReq.Content(),
+			Ext:         ext,
 			Timestamp:   0,
 		},
 	})
@@ -38,18 +56,29 @@ func (h *Handler) handleChatSend(_ctx context.Context, conn *connection.Connecti
 		msg.Payload.UserMessage.ToUserId = targetId
 	case im_protocol.ChatTypeGROUP:
 		msg.Payload.UserMessage.ToGroupId = targetId
+	default:
+		h.sendChatSendFailure(stream, reqID, im_protocol.ErrorCodePARAM_ERROR, "unsupported chat type")
+		return
 	}
 
 	// Forward to logic
 	if err := h.publishUpstream(msg); err != nil {
 		h.logger.Error("Failed to publish to NATS", "error", err)
+		h.sendChatSendFailure(stream, reqID, im_protocol.ErrorCodeSERVICE_UNAVAILABLE, "message service unavailable")
 		return
 	}
 	// Message published
 }
 
+// sendChatSendFailure 回复发送失败的 ChatSendAck（reqId 为客户端消息 ID，code/msg 携带原因）
+func (h *Handler) sendChatSendFailure(stream connection.Stream, reqID string, code im_protocol.ErrorCode, msg string) {
+	builder := getBuilder()
+	defer putBuilder(builder)
+	h.sendClientResponse(stream, reqID, code, msg, im_protocol.ResponsePayloadChatSendAck, buildChatSendAck(builder, 0, 0))
+}
+
 // handleConversationRead 处理会话已读请求
-func (h *Handler) handleConversationRead(conn *connection.Connection, stream *webtransport.Stream, reqID string, payload []byte) {
+func (h *Handler) handleConversationRead(conn *connection.Connection, stream connection.Stream, reqID string, payload []byte) {
 	// Conversation read request
 
 	// 解析 ConversationReadReq
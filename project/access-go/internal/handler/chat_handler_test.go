@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+	im_protocol "sudooom.im.access/pkg/flatbuf/im/protocol"
+	"sudooom.im.shared/proto"
+)
+
+func buildChatSendReqTo(chatType im_protocol.ChatType, targetId string) []byte {
+	builder := flatbuffers.NewBuilder(64)
+	targetOffset := builder.CreateString(targetId)
+	contentOffset := builder.CreateByteVector([]byte("hi"))
+	im_protocol.ChatSendReqStart(builder)
+	im_protocol.ChatSendReqAddChatType(builder, chatType)
+	im_protocol.ChatSendReqAddTargetId(builder, targetOffset)
+	im_protocol.ChatSendReqAddContent(builder, contentOffset)
+	builder.Finish(im_protocol.ChatSendReqEnd(builder))
+	return builder.FinishedBytes()
+}
+
+// TestHandleChatSendFailure 测试无法转发给 Logic 的消息立即回复携带原因的 ChatSendAck
+func TestHandleChatSendFailure(t *testing.T) {
+	h, connMgr := newTestHandler()
+	stream := addTestConn(connMgr, 1, "WEB")
+	conn := connMgr.GetByUserIDAndPlatform(1, "WEB")
+
+	tests := []struct {
+		name     string
+		chatType im_protocol.ChatType
+		targetId string
+		code     im_protocol.ErrorCode
+	}{
+		{"目标无效", im_protocol.ChatTypePRIVATE, "abc", im_protocol.ErrorCodePARAM_ERROR},
+		{"NATS 不可用", im_protocol.ChatTypeGROUP, "100", im_protocol.ErrorCodeSERVICE_UNAVAILABLE},
+	}
+
+	for _, tt := range tests {
+		h.handleChatSend(context.Background(), conn, stream, "msg-"+tt.name, buildChatSendReqTo(tt.chatType, tt.targetId))
+
+		select {
+		case frame := <-stream.frames:
+			resp := im_protocol.GetRootAsClientResponse(frame[5:], 0)
+			if string(resp.ReqId()) != "msg-"+tt.name || resp.Code() != tt.code || len(resp.Msg()) == 0 {
+				t.Fatalf("%s: 响应头不正确: reqId=%s code=%v msg=%s", tt.name, resp.ReqId(), resp.Code(), resp.Msg())
+			}
+			if resp.PayloadType() != im_protocol.ResponsePayloadChatSendAck {
+				t.Fatalf("%s: 载荷类型不正确: %v", tt.name, resp.PayloadType())
+			}
+			if ack := im_protocol.GetRootAsChatSendAck(resp.PayloadBytes(), 0); len(ack.MsgId()) != 0 {
+				t.Fatalf("%s: 失败的 ACK 不应携带 msgId: %s", tt.name, ack.MsgId())
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: 未收到 ChatSendAck", tt.name)
+		}
+	}
+}
+
+// TestHandleMessageAck 测试 Logic 回复的 ACK 映射为 ChatSendAck，拒绝的消息携带错误码
+func TestHandleMessageAck(t *testing.T) {
+	h, connMgr := newTestHandler()
+	stream := addTestConn(connMgr, 1, "WEB")
+	conn := connMgr.GetByUserIDAndPlatform(1, "WEB")
+
+	tests := []struct {
+		name  string
+		ack   proto.MessageAck
+		code  im_protocol.ErrorCode
+		msgId string
+	}{
+		{"成功", proto.MessageAck{ClientMsgId: "c1", ServerMsgId: 42, ToUserId: 1, Timestamp: 1000}, im_protocol.ErrorCodeSUCCESS, "42"},
+		{"非群成员", proto.MessageAck{ClientMsgId: "c2", ToUserId: 1, Timestamp: 1000, Code: "NOT_GROUP_MEMBER", Msg: "您不是该群成员"}, im_protocol.ErrorCodeNOT_GROUP_MEMBER, ""},
+	}
+
+	for _, tt := range tests {
+		h.HandleDownstream(mustMarshal(t, &proto.DownstreamMessage{
+			UserId:   1,
+			ConnId:   conn.ID(),
+			Platform: "WEB",
+			Payload:  proto.DownstreamPayload{MessageAck: &tt.ack},
+		}))
+
+		select {
+		case frame := <-stream.frames:
+			resp := im_protocol.GetRootAsClientResponse(frame[5:], 0)
+			if string(resp.ReqId()) != tt.ack.ClientMsgId || resp.Code() != tt.code || string(resp.Msg()) != tt.ack.Msg {
+				t.Fatalf("%s: 响应头不正确: reqId=%s code=%v msg=%s", tt.name, resp.ReqId(), resp.Code(), resp.Msg())
+			}
+			ack := im_protocol.GetRootAsChatSendAck(resp.PayloadBytes(), 0)
+			if string(ack.MsgId()) != tt.msgId || ack.SendTime() != tt.ack.Timestamp {
+				t.Fatalf("%s: ChatSendAck 不正确: msgId=%s sendTime=%d", tt.name, ack.MsgId(), ack.SendTime())
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: 未收到 ChatSendAck", tt.name)
+		}
+	}
+}
@@ -1,9 +1,8 @@
 package handler
 
 import (
-	"encoding/binary"
-	"encoding/json"
-	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	flatbuffers "github.com/google/flatbuffers/go"
@@ -18,19 +17,30 @@ func (h *Handler) HandleDownstream(data []byte) {
 
 	// 解析下行消息
 	var msg proto.DownstreamMessage
-	if err := json.Unmarshal(data, &msg); err != nil {
+	if err := proto.UnmarshalDownstream(data, &msg); err != nil {
 		h.logger.Error("Failed to unmarshal downstream message", "error", err)
 		return
 	}
 
+	// 踢下线针对具体连接，不按用户的最新连接路由
+	if msg.Payload.Kick != nil {
+		h.handleKick(&msg)
+		return
+	}
+
 	// 优先使用 ConnId 直接路由
 	if msg.ConnId > 0 {
 		conn := h.connMgr.Get(msg.ConnId)
-		if conn == nil {
-			h.logger.Warn("Connection not found for downstream message", "connId", msg.ConnId)
+		if conn != nil {
+			h.sendToConnection(newDownstreamTarget(conn), &msg)
 			return
 		}
-		h.sendToConnection(conn, &msg)
+		// 连接已断开：该用户该平台仍有会话时交给会话投递（已恢复的新连接，或宽限期内缓冲等待恢复）
+		if msg.UserId > 0 && msg.Platform != "" && h.resumeStore.Get(msg.UserId, msg.Platform) != nil {
+			h.sendToConnection(downstreamTarget{userID: msg.UserId, platform: msg.Platform}, &msg)
+			return
+		}
+		h.logger.Warn("Connection not found for downstream message", "connId", msg.ConnId)
 		return
 	}
 
@@ -44,7 +54,9 @@ func (h *Handler) HandleDownstream(data []byte) {
 	if msg.Platform != "" {
 		conn := h.connMgr.GetByUserIDAndPlatform(msg.UserId, msg.Platform)
 		if conn != nil {
-			h.sendToConnection(conn, &msg)
+			h.sendToConnection(newDownstreamTarget(conn), &msg)
+		} else if h.resumeStore.Get(msg.UserId, msg.Platform) != nil {
+			h.sendToConnection(downstreamTarget{userID: msg.UserId, platform: msg.Platform}, &msg)
 		}
 		return
 	}
@@ -52,75 +64,414 @@ func (h *Handler) HandleDownstream(data []byte) {
 	// 没有 Platform，推送到所有平台
 	conns := h.connMgr.GetByUserID(msg.UserId)
 	for _, conn := range conns {
-		h.sendToConnection(conn, &msg)
+		h.sendToConnection(newDownstreamTarget(conn), &msg)
 	}
 }
 
+// HandleBroadcast 处理广播消息（Logic 推送到所有 Access 节点），Platform 非空时只投递该平台的已认证连接
+func (h *Handler) HandleBroadcast(data []byte) {
+	var msg proto.DownstreamMessage
+	if err := proto.UnmarshalDownstream(data, &msg); err != nil {
+		h.logger.Error("Failed to unmarshal broadcast message", "error", err)
+		return
+	}
+
+	for _, conn := range h.connMgr.GetAllConnections() {
+		if conn.UserID() == 0 || (msg.Platform != "" && !strings.EqualFold(conn.Platform(), msg.Platform)) {
+			continue
+		}
+		h.sendToConnection(newDownstreamTarget(conn), &msg)
+	}
+}
+
+// downstreamTarget 下行推送目标，conn 为 nil 表示连接已断开、由会话缓冲
+type downstreamTarget struct {
+	conn     *connection.Connection
+	userID   int64
+	platform string
+}
+
+func newDownstreamTarget(conn *connection.Connection) downstreamTarget {
+	return downstreamTarget{conn: conn, userID: conn.UserID(), platform: conn.Platform()}
+}
+
 // sendToConnection 发送消息到指定连接
-func (h *Handler) sendToConnection(conn *connection.Connection, msg *proto.DownstreamMessage) {
+func (h *Handler) sendToConnection(target downstreamTarget, msg *proto.DownstreamMessage) {
 	if msg.Payload.PushMessage != nil {
-		h.handlePushMessage(conn, msg.Payload.PushMessage)
+		h.handlePushMessage(target, msg.Payload.PushMessage)
 	} else if msg.Payload.MessageAck != nil {
-		h.handleMessageAck(conn, msg.Payload.MessageAck)
+		h.handleMessageAck(target, msg.Payload.MessageAck)
+	} else if msg.Payload.RoomPush != nil {
+		h.handleRoomPush(target, msg.Payload.RoomPush)
+	} else if msg.Payload.RoomResp != nil {
+		h.handleRoomResp(target, msg.Payload.RoomResp)
+	} else if msg.Payload.GamePush != nil {
+		h.handleGamePush(target, msg.Payload.GamePush)
+	} else if msg.Payload.SystemPush != nil {
+		h.handleSystemPush(target, msg.Payload.SystemPush)
+	}
+}
+
+// pushToClient 推送 ClientResponse 帧
+// 用户有可恢复会话时由会话分配下行序号并缓冲，断线重连后可补发；否则直接写入连接
+func (h *Handler) pushToClient(target downstreamTarget, reqID string, code im_protocol.ErrorCode, msg string, payloadType im_protocol.ResponsePayload, payload []byte) error {
+	build := func(seq int64) []byte {
+		return h.buildClientResponseFrame(reqID, code, msg, payloadType, payload, seq)
+	}
+
+	class := payloadClass(payloadType)
+
+	rs := h.resumeStore.Get(target.userID, target.platform)
+	if rs == nil {
+		if target.conn == nil {
+			return connection.ErrConnectionClosed
+		}
+		return target.conn.SendClass(build(0), class)
+	}
+
+	return rs.Push(build, func(connID int64, frame []byte) error {
+		conn := h.connMgr.Get(connID)
+		if conn == nil {
+			return connection.ErrConnectionClosed
+		}
+		return conn.SendClass(frame, class)
+	})
+}
+
+// payloadClass 下行载荷对应的背压类别
+func payloadClass(payloadType im_protocol.ResponsePayload) connection.PayloadClass {
+	switch payloadType {
+	case im_protocol.ResponsePayloadChatPush, im_protocol.ResponsePayloadChatSendAck:
+		return connection.ClassChat
+	case im_protocol.ResponsePayloadRoomPush:
+		return connection.ClassRoom
+	case im_protocol.ResponsePayloadGamePush:
+		return connection.ClassGame
+	default:
+		return connection.ClassControl
+	}
+}
+
+func (h *Handler) handlePushMessage(target downstreamTarget, pushMsg *proto.PushMessage) {
+	builder := getBuilder()
+	defer putBuilder(builder)
+
+	payload := buildChatPush(builder, pushMsg)
+
+	// 构建 ClientResponse 并发送
+	err := h.pushToClient(target, "", im_protocol.ErrorCodeSUCCESS, "", im_protocol.ResponsePayloadChatPush, payload)
+	if err != nil {
+		h.logger.Error("Failed to send push message to user", "userId", target.userID, "error", err)
 	}
 }
 
-func (h *Handler) handlePushMessage(conn *connection.Connection, pushMsg *proto.PushMessage) {
-	// 使用 FlatBuffers 构建 ChatPush
-	builder := flatbuffers.NewBuilder(512)
+// buildChatPush 在 builder 中构建 ChatPush，返回的载荷引用 builder 缓冲
+func buildChatPush(builder *flatbuffers.Builder, pushMsg *proto.PushMessage) []byte {
+	chatType, ok := im_protocol.EnumValuesChatType[pushMsg.ChatType]
+	if !ok {
+		// 兼容未携带 ChatType 的旧版 Logic
+		chatType = im_protocol.ChatTypePRIVATE
+		if pushMsg.ToGroupId > 0 {
+			chatType = im_protocol.ChatTypeGROUP
+		}
+	}
 
-	msgIdOffset := builder.CreateString(fmt.Sprintf("%d", pushMsg.ServerMsgId))
-	senderIdOffset := builder.CreateString(fmt.Sprintf("%d", pushMsg.FromUserId))
-	targetIdOffset := builder.CreateString(fmt.Sprintf("%d", pushMsg.ToUserId))
-	contentOffset := builder.CreateString(string(pushMsg.Content)) // content 是 string 类型
+	// 群聊的目标为群 ID，单聊为接收者 ID
+	targetId := pushMsg.ToUserId
+	if chatType == im_protocol.ChatTypeGROUP {
+		targetId = pushMsg.ToGroupId
+	}
+
+	// 子对象需先于父表创建
+	msgIdOffset := createInt64String(builder, pushMsg.ServerMsgId)
+	senderIdOffset := createInt64String(builder, pushMsg.FromUserId)
+	targetIdOffset := createInt64String(builder, targetId)
+	contentOffset := builder.CreateByteString(pushMsg.Content) // content 是 string 类型
+
+	var senderInfoOffset flatbuffers.UOffsetT
+	if pushMsg.SenderInfo != nil {
+		senderInfoOffset = buildUserInfo(builder, pushMsg.SenderInfo)
+	}
+
+	var extOffset flatbuffers.UOffsetT
+	if len(pushMsg.Ext) > 0 {
+		extOffset = buildChatPushExt(builder, pushMsg.Ext)
+	}
 
 	im_protocol.ChatPushStart(builder)
 	im_protocol.ChatPushAddMsgId(builder, msgIdOffset)
 	im_protocol.ChatPushAddSenderId(builder, senderIdOffset)
-	im_protocol.ChatPushAddChatType(builder, im_protocol.ChatTypePRIVATE)
+	if senderInfoOffset != 0 {
+		im_protocol.ChatPushAddSenderInfo(builder, senderInfoOffset)
+	}
+	im_protocol.ChatPushAddChatType(builder, chatType)
 	im_protocol.ChatPushAddTargetId(builder, targetIdOffset)
 	im_protocol.ChatPushAddMsgType(builder, im_protocol.MsgType(pushMsg.MsgType))
 	im_protocol.ChatPushAddContent(builder, contentOffset)
 	im_protocol.ChatPushAddSendTime(builder, pushMsg.Timestamp)
-	chatPushOffset := im_protocol.ChatPushEnd(builder)
-	builder.Finish(chatPushOffset)
+	if extOffset != 0 {
+		im_protocol.ChatPushAddExt(builder, extOffset)
+	}
+	builder.Finish(im_protocol.ChatPushEnd(builder))
 
-	payload := builder.FinishedBytes()
+	return builder.FinishedBytes()
+}
+
+func (h *Handler) handleMessageAck(target downstreamTarget, ack *proto.MessageAck) {
+	// 使用 FlatBuffers 构建 ChatSendAck
+	builder := getBuilder()
+	defer putBuilder(builder)
+	payload := buildChatSendAck(builder, ack.ServerMsgId, ack.Timestamp)
+
+	// Logic 拒绝的消息携带错误码，ChatSendAck 不含服务端消息 ID
+	code := im_protocol.ErrorCodeSUCCESS
+	if ack.Code != "" {
+		code = mapErrorCode(ack.Code)
+	}
 
 	// 构建 ClientResponse 并发送
-	respFrame := h.buildClientResponseFrame("", im_protocol.ErrorCodeSUCCESS, "", im_protocol.ResponsePayloadChatPush, payload)
-	err := conn.Send(respFrame)
-	if err != nil {
-		h.logger.Error("Failed to send push message to user", "userId", conn.UserID(), "error", err)
+	// reqId 使用 ClientMsgId，让客户端可以关联请求
+	if err := h.pushToClient(target, ack.ClientMsgId, code, ack.Msg, im_protocol.ResponsePayloadChatSendAck, payload); err != nil {
+		h.logger.Error("Failed to send ACK to user", "userId", target.userID, "error", err)
 	}
 }
 
-func (h *Handler) handleMessageAck(conn *connection.Connection, ack *proto.MessageAck) {
-	// 使用 FlatBuffers 构建 ChatSendAck
-	builder := flatbuffers.NewBuilder(128)
-
-	msgIdOffset := builder.CreateString(fmt.Sprintf("%d", ack.ServerMsgId))
+// buildChatSendAck 在 builder 中构建 ChatSendAck，serverMsgId 为 0（发送失败）时不写入 msg_id
+func buildChatSendAck(builder *flatbuffers.Builder, serverMsgId int64, sendTime int64) []byte {
+	var msgIdOffset flatbuffers.UOffsetT
+	if serverMsgId != 0 {
+		msgIdOffset = createInt64String(builder, serverMsgId)
+	}
 
 	im_protocol.ChatSendAckStart(builder)
-	im_protocol.ChatSendAckAddMsgId(builder, msgIdOffset)
-	im_protocol.ChatSendAckAddSendTime(builder, ack.Timestamp)
-	ackOffset := im_protocol.ChatSendAckEnd(builder)
-	builder.Finish(ackOffset)
+	if msgIdOffset != 0 {
+		im_protocol.ChatSendAckAddMsgId(builder, msgIdOffset)
+	}
+	if sendTime != 0 {
+		im_protocol.ChatSendAckAddSendTime(builder, sendTime)
+	}
+	builder.Finish(im_protocol.ChatSendAckEnd(builder))
+	return builder.FinishedBytes()
+}
+
+func (h *Handler) handleRoomPush(target downstreamTarget, roomPush *proto.RoomPush) {
+	event, ok := im_protocol.EnumValuesRoomEvent[roomPush.Event]
+	if !ok {
+		h.logger.Warn("Unknown room event", "event", roomPush.Event, "roomId", roomPush.RoomId)
+		return
+	}
+
+	// ERROR 事件的错误码与错误信息由 ClientResponse 的 code/msg 携带
+	code := im_protocol.ErrorCodeSUCCESS
+	msg := ""
+	if event == im_protocol.RoomEventERROR {
+		code = mapErrorCode(roomPush.ErrorCode)
+		msg = roomPush.ErrorMsg
+	}
+
+	// 使用 FlatBuffers 构建 RoomPush
+	builder := getBuilder()
+	defer putBuilder(builder)
+
+	roomIdOffset := builder.CreateString(roomPush.RoomId)
+	var userIdOffset flatbuffers.UOffsetT
+	if roomPush.UserId > 0 {
+		userIdOffset = createInt64String(builder, roomPush.UserId)
+	}
+	var roomInfoOffset flatbuffers.UOffsetT
+	if roomPush.RoomInfo != nil {
+		roomInfoOffset = buildRoomInfo(builder, roomPush.RoomInfo)
+	}
+
+	im_protocol.RoomPushStart(builder)
+	im_protocol.RoomPushAddEvent(builder, event)
+	im_protocol.RoomPushAddRoomId(builder, roomIdOffset)
+	if userIdOffset != 0 {
+		im_protocol.RoomPushAddUserId(builder, userIdOffset)
+	}
+	if roomInfoOffset != 0 {
+		im_protocol.RoomPushAddRoomInfo(builder, roomInfoOffset)
+	}
+	roomPushOffset := im_protocol.RoomPushEnd(builder)
+	builder.Finish(roomPushOffset)
 
 	payload := builder.FinishedBytes()
 
-	// 构建 ClientResponse 并发送
-	// reqId 使用 ClientMsgId，让客户端可以关联请求
-	respFrame := h.buildClientResponseFrame(ack.ClientMsgId, im_protocol.ErrorCodeSUCCESS, "", im_protocol.ResponsePayloadChatSendAck, payload)
-	if err := conn.Send(respFrame); err != nil {
-		h.logger.Error("Failed to send ACK to user", "userId", conn.UserID(), "error", err)
+	if err := h.pushToClient(target, "", code, msg, im_protocol.ResponsePayloadRoomPush, payload); err != nil {
+		h.logger.Error("Failed to send room push to user", "userId", target.userID, "roomId", roomPush.RoomId, "error", err)
 	}
 }
 
-// buildClientResponseFrame 构建完整的 ClientResponse 帧（用于 conn.Send 推送）
-func (h *Handler) buildClientResponseFrame(reqID string, code im_protocol.ErrorCode, msg string, payloadType im_protocol.ResponsePayload, payload []byte) []byte {
-	builder := flatbuffers.NewBuilder(256 + len(payload))
+// handleRoomResp 回复房间操作结果，reqId 与客户端请求对应，失败时由 ClientResponse 的 code/msg 携带错误
+func (h *Handler) handleRoomResp(target downstreamTarget, roomResp *proto.RoomResp) {
+	code := im_protocol.ErrorCodeSUCCESS
+	if roomResp.Code != "" {
+		code = mapErrorCode(roomResp.Code)
+	}
+
+	builder := getBuilder()
+	defer putBuilder(builder)
 
+	var roomIdOffset flatbuffers.UOffsetT
+	if roomResp.RoomId != "" {
+		roomIdOffset = builder.CreateString(roomResp.RoomId)
+	}
+	var roomInfoOffset flatbuffers.UOffsetT
+	if roomResp.RoomInfo != nil {
+		roomInfoOffset = buildRoomInfo(builder, roomResp.RoomInfo)
+	}
+
+	im_protocol.RoomRespStart(builder)
+	if roomIdOffset != 0 {
+		im_protocol.RoomRespAddRoomId(builder, roomIdOffset)
+	}
+	if roomInfoOffset != 0 {
+		im_protocol.RoomRespAddRoomInfo(builder, roomInfoOffset)
+	}
+	im_protocol.RoomRespAddAction(builder, im_protocol.EnumValuesRoomAction[roomResp.Action])
+	roomRespOffset := im_protocol.RoomRespEnd(builder)
+	builder.Finish(roomRespOffset)
+
+	payload := builder.FinishedBytes()
+
+	if err := h.pushToClient(target, roomResp.ReqId, code, roomResp.Msg, im_protocol.ResponsePayloadRoomResp, payload); err != nil {
+		h.logger.Error("Failed to send room response to user", "userId", target.userID, "reqId", roomResp.ReqId, "error", err)
+	}
+}
+
+func (h *Handler) handleGamePush(target downstreamTarget, gamePush *proto.GamePush) {
+	gameType, ok := im_protocol.EnumValuesGameType[gamePush.GameType]
+	if !ok {
+		h.logger.Warn("Unknown game type in game push", "gameType", gamePush.GameType, "roomId", gamePush.RoomId)
+		return
+	}
+	payloadType, ok := im_protocol.EnumValuesGamePayload[gamePush.GamePayloadType]
+	if !ok {
+		h.logger.Warn("Unknown game payload type", "payloadType", gamePush.GamePayloadType, "roomId", gamePush.RoomId)
+		return
+	}
+
+	// 使用 FlatBuffers 构建 GamePush
+	builder := getBuilder()
+	defer putBuilder(builder)
+
+	roomIdOffset := builder.CreateString(gamePush.RoomId)
+	var gamePayloadOffset flatbuffers.UOffsetT
+	if len(gamePush.GamePayload) > 0 {
+		gamePayloadOffset = builder.CreateByteVector(gamePush.GamePayload)
+	}
+
+	im_protocol.GamePushStart(builder)
+	im_protocol.GamePushAddRoomId(builder, roomIdOffset)
+	im_protocol.GamePushAddGameType(builder, gameType)
+	im_protocol.GamePushAddGamePayloadType(builder, payloadType)
+	if gamePayloadOffset != 0 {
+		im_protocol.GamePushAddGamePayload(builder, gamePayloadOffset)
+	}
+	gamePushOffset := im_protocol.GamePushEnd(builder)
+	builder.Finish(gamePushOffset)
+
+	payload := builder.FinishedBytes()
+
+	if err := h.pushToClient(target, "", im_protocol.ErrorCodeSUCCESS, "", im_protocol.ResponsePayloadGamePush, payload); err != nil {
+		h.logger.Error("Failed to send game push to user", "userId", target.userID, "roomId", gamePush.RoomId, "error", err)
+	}
+}
+
+// buildRoomInfo 构建 FlatBuffers RoomInfo（子对象需先于父表创建）
+func buildRoomInfo(builder *flatbuffers.Builder, info *proto.RoomInfo) flatbuffers.UOffsetT {
+	playerOffsets := make([]flatbuffers.UOffsetT, len(info.Players))
+	for i, p := range info.Players {
+		userIdOffset := builder.CreateString(strconv.FormatInt(p.UserId, 10))
+		nicknameOffset := builder.CreateString(p.Nickname)
+		avatarOffset := builder.CreateString(p.Avatar)
+
+		im_protocol.UserInfoStart(builder)
+		im_protocol.UserInfoAddUserId(builder, userIdOffset)
+		im_protocol.UserInfoAddNickname(builder, nicknameOffset)
+		im_protocol.UserInfoAddAvatar(builder, avatarOffset)
+		userOffset := im_protocol.UserInfoEnd(builder)
+
+		im_protocol.RoomPlayerStart(builder)
+		im_protocol.RoomPlayerAddUserId(builder, userIdOffset)
+		im_protocol.RoomPlayerAddUser(builder, userOffset)
+		im_protocol.RoomPlayerAddIsReady(builder, p.IsReady)
+		im_protocol.RoomPlayerAddSeatIndex(builder, p.SeatIndex)
+		im_protocol.RoomPlayerAddIsOnline(builder, p.IsOnline)
+		playerOffsets[i] = im_protocol.RoomPlayerEnd(builder)
+	}
+
+	im_protocol.RoomInfoStartPlayersVector(builder, len(playerOffsets))
+	for i := len(playerOffsets) - 1; i >= 0; i-- {
+		builder.PrependUOffsetT(playerOffsets[i])
+	}
+	playersOffset := builder.EndVector(len(playerOffsets))
+
+	roomIdOffset := builder.CreateString(info.RoomId)
+	ownerIdOffset := builder.CreateString(strconv.FormatInt(info.OwnerId, 10))
+
+	im_protocol.RoomInfoStart(builder)
+	im_protocol.RoomInfoAddRoomId(builder, roomIdOffset)
+	im_protocol.RoomInfoAddOwnerId(builder, ownerIdOffset)
+	im_protocol.RoomInfoAddMaxPlayers(builder, info.MaxPlayers)
+	im_protocol.RoomInfoAddCurrentPlayers(builder, info.CurrentPlayers)
+	im_protocol.RoomInfoAddPlayers(builder, playersOffset)
+	im_protocol.RoomInfoAddStatus(builder, im_protocol.EnumValuesRoomStatus[info.Status])
+	return im_protocol.RoomInfoEnd(builder)
+}
+
+// buildUserInfo 构建 FlatBuffers UserInfo
+func buildUserInfo(builder *flatbuffers.Builder, info *proto.UserInfo) flatbuffers.UOffsetT {
+	userIdOffset := builder.CreateString(strconv.FormatInt(info.UserId, 10))
+	nicknameOffset := builder.CreateString(info.Nickname)
+	avatarOffset := builder.CreateString(info.Avatar)
+
+	im_protocol.UserInfoStart(builder)
+	im_protocol.UserInfoAddUserId(builder, userIdOffset)
+	im_protocol.UserInfoAddNickname(builder, nicknameOffset)
+	im_protocol.UserInfoAddAvatar(builder, avatarOffset)
+	return im_protocol.UserInfoEnd(builder)
+}
+
+// buildChatPushExt 构建 ChatPush 的 ext（KeyValue 向量）
+func buildChatPushExt(builder *flatbuffers.Builder, kvs []proto.KeyValue) flatbuffers.UOffsetT {
+	offsets := make([]flatbuffers.UOffsetT, len(kvs))
+	for i, kv := range kvs {
+		keyOffset := builder.CreateString(kv.Key)
+		valueOffset := builder.CreateString(kv.Value)
+
+		im_protocol.KeyValueStart(builder)
+		im_protocol.KeyValueAddKey(builder, keyOffset)
+		im_protocol.KeyValueAddValue(builder, valueOffset)
+		offsets[i] = im_protocol.KeyValueEnd(builder)
+	}
+
+	im_protocol.ChatPushStartExtVector(builder, len(offsets))
+	for i := len(offsets) - 1; i >= 0; i-- {
+		builder.PrependUOffsetT(offsets[i])
+	}
+	return builder.EndVector(len(offsets))
+}
+
+// mapErrorCode 将 Logic 的错误码字符串映射为 FlatBuffers ErrorCode，未定义的统一为 UNKNOWN_ERROR
+func mapErrorCode(code string) im_protocol.ErrorCode {
+	if errorCode, ok := im_protocol.EnumValuesErrorCode[code]; ok {
+		return errorCode
+	}
+	return im_protocol.ErrorCodeUNKNOWN_ERROR
+}
+
+// buildClientResponseFrame 构建完整的 ClientResponse 帧（用于 conn.Send 推送），seq 为下行序号
+func (h *Handler) buildClientResponseFrame(reqID string, code im_protocol.ErrorCode, msg string, payloadType im_protocol.ResponsePayload, payload []byte, seq int64) []byte {
+	builder := getBuilder()
+	defer putBuilder(builder)
+
+	buildClientResponse(builder, reqID, code, msg, payloadType, payload, seq)
+	return buildFrame(FrameTypeResponse, builder.FinishedBytes())
+}
+
+// buildClientResponse 在 builder 中构建并 Finish ClientResponse
+func buildClientResponse(builder *flatbuffers.Builder, reqID string, code im_protocol.ErrorCode, msg string, payloadType im_protocol.ResponsePayload, payload []byte, seq int64) {
 	reqIDOffset := builder.CreateString(reqID)
 	msgOffset := builder.CreateString(msg)
 
@@ -138,15 +489,6 @@ func (h *Handler) buildClientResponseFrame(reqID string, code im_protocol.ErrorC
 	if len(payload) > 0 {
 		im_protocol.ClientResponseAddPayload(builder, payloadOffset)
 	}
-	respOffset := im_protocol.ClientResponseEnd(builder)
-	builder.Finish(respOffset)
-
-	respBytes := builder.FinishedBytes()
-
-	// 构建帧：header + body
-	frame := make([]byte, FrameHeaderSize+len(respBytes))
-	binary.BigEndian.PutUint32(frame[:4], uint32(len(respBytes)))
-	frame[4] = FrameTypeResponse
-	copy(frame[FrameHeaderSize:], respBytes)
-	return frame
+	im_protocol.ClientResponseAddSeq(builder, seq)
+	builder.Finish(im_protocol.ClientResponseEnd(builder))
 }
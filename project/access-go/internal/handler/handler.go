@@ -3,7 +3,7 @@ package handler
 import (
 	"context"
 	"encoding/binary"
-	"encoding/json"
+	"fmt"
 	"io"
 	"sync"
 	"time"
@@ -11,12 +11,11 @@ import (
 	"log/slog"
 
 	flatbuffers "github.com/google/flatbuffers/go"
-	"github.com/quic-go/webtransport-go"
 	"sudooom.im.access/internal/connection"
-	"sudooom.im.access/internal/nats"
 	"sudooom.im.access/internal/redis"
 	"sudooom.im.access/internal/workerpool"
 	im_protocol "sudooom.im.access/pkg/flatbuf/im/protocol"
+	"sudooom.im.shared/bus"
 	sharedNats "sudooom.im.shared/nats"
 	"sudooom.im.shared/proto"
 )
@@ -35,26 +34,44 @@ const (
 
 	// Buffer Pool 默认容量（4KB，适合大多数消息）
 	defaultBufferCap = 4096
+
+	// DefaultMaxFrameSize 默认单帧消息体上限（64KB）
+	DefaultMaxFrameSize = 64 * 1024
 )
 
 type Handler struct {
-	connMgr     *connection.Manager
-	natsClient  *nats.Client
-	redisClient *redis.Client
-	nodeID      string
-	logger      *slog.Logger
-	workerPool  *workerpool.Pool
-	bufferPool  *sync.Pool // 消息 buffer 对象池，减少内存分配
+	connMgr           *connection.Manager
+	resumeStore       *connection.ResumeStore
+	rateLimiter       *connection.RateLimiter
+	msgBus            bus.Bus
+	codec             proto.Codec // 发往消息总线的编码，接收时自动识别
+	redisClient       *redis.Client
+	nodeID            string
+	heartbeatInterval time.Duration // 通过 AuthAck 下发给客户端的心跳间隔
+	maxFrameSize      uint32        // 单帧消息体上限，超过时断开连接
+	logger            *slog.Logger
+	workerPool        *workerpool.Pool
+	bufferPool        *sync.Pool // 消息 buffer 对象池，减少内存分配
 }
 
-func NewHandler(connMgr *connection.Manager, natsClient *nats.Client, redisClient *redis.Client, nodeID string, logger *slog.Logger, workerPool *workerpool.Pool) *Handler {
+func NewHandler(connMgr *connection.Manager, resumeStore *connection.ResumeStore, rateLimiter *connection.RateLimiter, msgBus bus.Bus, redisClient *redis.Client, nodeID string, heartbeatInterval time.Duration, maxFrameSize int, logger *slog.Logger, workerPool *workerpool.Pool) *Handler {
+	// 设置默认值
+	if maxFrameSize <= 0 {
+		maxFrameSize = DefaultMaxFrameSize
+	}
+
 	return &Handler{
-		connMgr:     connMgr,
-		natsClient:  natsClient,
-		redisClient: redisClient,
-		nodeID:      nodeID,
-		logger:      logger,
-		workerPool:  workerPool,
+		connMgr:           connMgr,
+		resumeStore:       resumeStore,
+		rateLimiter:       rateLimiter,
+		msgBus:            msgBus,
+		codec:             proto.CodecJSON,
+		redisClient:       redisClient,
+		nodeID:            nodeID,
+		heartbeatInterval: heartbeatInterval,
+		maxFrameSize:      uint32(maxFrameSize),
+		logger:            logger,
+		workerPool:        workerPool,
 		bufferPool: &sync.Pool{
 			New: func() interface{} {
 				return make([]byte, 0, defaultBufferCap)
@@ -63,9 +80,14 @@ func NewHandler(connMgr *connection.Manager, natsClient *nats.Client, redisClien
 	}
 }
 
+// SetCodec 设置上行消息与跨节点踢下线消息的编码（默认 JSON）
+func (h *Handler) SetCodec(codec proto.Codec) {
+	h.codec = codec
+}
+
 // HandleStream 处理客户端流（连接已认证）
-func (h *Handler) HandleStream(ctx context.Context, conn *connection.Connection, stream *webtransport.Stream) {
-	defer func(stream *webtransport.Stream) {
+func (h *Handler) HandleStream(ctx context.Context, conn *connection.Connection, stream connection.Stream) {
+	defer func(stream connection.Stream) {
 		err := stream.Close()
 		if err != nil {
 		}
@@ -84,6 +106,14 @@ func (h *Handler) HandleStream(ctx context.Context, conn *connection.Connection,
 		length := binary.BigEndian.Uint32(header[:4])
 		frameType := header[4]
 
+		// 超长帧无法跳过（跳过同样需要读完消息体），直接断开连接
+		if length > h.maxFrameSize {
+			h.logger.Warn("Frame too large, disconnecting", "conn_id", conn.ID(), "length", length, "max", h.maxFrameSize)
+			h.sendClientResponse(stream, "", im_protocol.ErrorCodePARAM_ERROR, "frame too large", im_protocol.ResponsePayloadNONE, nil)
+			conn.CloseWithError(connection.CloseCodeFrameTooLarge, "frame too large")
+			return
+		}
+
 		body := make([]byte, length)
 		if _, err := io.ReadFull(stream, body); err != nil {
 			h.logger.Error("Failed to read body", "error", err)
@@ -106,7 +136,8 @@ func (h *Handler) HandleStream(ctx context.Context, conn *connection.Connection,
 		copy(buf, body)
 
 		// 异步提交到 Worker Pool，避免阻塞消息读取循环
-		submitted := h.workerPool.Submit(func() {
+		// 按连接 ID 分片提交，保证同一连接的请求按到达顺序处理（如连续发送的聊天消息、摸牌后出牌）
+		submitted := h.workerPool.SubmitOrdered(conn.ID(), func() {
 			defer h.bufferPool.Put(buf[:0]) // 处理完后归还到对象池
 			h.dispatch(ctx, conn, stream, frameType, buf)
 		})
@@ -119,7 +150,7 @@ func (h *Handler) HandleStream(ctx context.Context, conn *connection.Connection,
 }
 
 // dispatch 根据帧类型分发处理
-func (h *Handler) dispatch(ctx context.Context, conn *connection.Connection, stream *webtransport.Stream, frameType byte, body []byte) {
+func (h *Handler) dispatch(ctx context.Context, conn *connection.Connection, stream connection.Stream, frameType byte, body []byte) {
 	switch frameType {
 	case FrameTypeAuth:
 		h.logger.Warn("Unexpected auth request after authentication", "conn_id", conn.ID())
@@ -131,7 +162,12 @@ func (h *Handler) dispatch(ctx context.Context, conn *connection.Connection, str
 }
 
 // handleClientRequest 处理客户端请求
-func (h *Handler) handleClientRequest(ctx context.Context, conn *connection.Connection, stream *webtransport.Stream, body []byte) {
+func (h *Handler) handleClientRequest(ctx context.Context, conn *connection.Connection, stream connection.Stream, body []byte) {
+	if err := VerifyClientRequest(body); err != nil {
+		h.logger.Warn("Invalid client request", "conn_id", conn.ID(), "error", err)
+		h.sendClientResponse(stream, "", im_protocol.ErrorCodePARAM_ERROR, err.Error(), im_protocol.ResponsePayloadNONE, nil)
+		return
+	}
 	clientReq := im_protocol.GetRootAsClientRequest(body, 0)
 
 	reqID := string(clientReq.ReqId())
@@ -139,6 +175,27 @@ func (h *Handler) handleClientRequest(ctx context.Context, conn *connection.Conn
 
 	payload := clientReq.PayloadBytes()
 
+	if err := verifyRequestPayload(payloadType, payload); err != nil {
+		h.logger.Warn("Invalid request payload", "conn_id", conn.ID(), "payloadType", payloadType, "error", err)
+		h.sendClientResponse(stream, reqID, im_protocol.ErrorCodePARAM_ERROR, fmt.Sprintf("invalid %s: %v", payloadType, err), im_protocol.ResponsePayloadNONE, nil)
+		return
+	}
+
+	// 按请求类别限流
+	if class := rateLimitClass(payloadType); class != "" {
+		switch h.rateLimiter.Allow(conn, class, time.Now()) {
+		case connection.RateLimited:
+			h.sendClientResponse(stream, reqID, im_protocol.ErrorCodeRATE_LIMITED, "rate limited", im_protocol.ResponsePayloadNONE, nil)
+			return
+		case connection.RateAbuse:
+			h.logger.Warn("Rate limit repeatedly exceeded, disconnecting",
+				"conn_id", conn.ID(), "user_id", conn.UserID(), "class", class)
+			h.sendClientResponse(stream, reqID, im_protocol.ErrorCodeRATE_LIMITED, "rate limited", im_protocol.ResponsePayloadNONE, nil)
+			conn.CloseWithError(connection.CloseCodeRateLimited, "rate limit exceeded")
+			return
+		}
+	}
+
 	// 根据 Payload 类型分发
 	switch payloadType {
 	case im_protocol.RequestPayloadChatSendReq:
@@ -157,51 +214,37 @@ func (h *Handler) handleClientRequest(ctx context.Context, conn *connection.Conn
 	}
 }
 
-// sendClientResponse 发送响应给客户端
-func (h *Handler) sendClientResponse(stream *webtransport.Stream, reqID string, code im_protocol.ErrorCode, msg string, payloadType im_protocol.ResponsePayload, payload []byte) {
-	builder := flatbuffers.NewBuilder(256 + len(payload))
-
-	reqIDOffset := builder.CreateString(reqID)
-	msgOffset := builder.CreateString(msg)
-
-	var payloadOffset flatbuffers.UOffsetT
-	if len(payload) > 0 {
-		payloadOffset = builder.CreateByteVector(payload)
+// rateLimitClass 请求对应的限流类别，心跳等轻量请求不限流
+func rateLimitClass(payloadType im_protocol.RequestPayload) string {
+	switch payloadType {
+	case im_protocol.RequestPayloadChatSendReq:
+		return "chat"
+	case im_protocol.RequestPayloadRoomReq:
+		return "room"
+	case im_protocol.RequestPayloadGameReq:
+		return "game"
+	default:
+		return ""
 	}
+}
 
-	im_protocol.ClientResponseStart(builder)
-	im_protocol.ClientResponseAddReqId(builder, reqIDOffset)
-	im_protocol.ClientResponseAddTimestamp(builder, time.Now().UnixMilli())
-	im_protocol.ClientResponseAddCode(builder, code)
-	im_protocol.ClientResponseAddMsg(builder, msgOffset)
-	im_protocol.ClientResponseAddPayloadType(builder, payloadType)
-	if len(payload) > 0 {
-		im_protocol.ClientResponseAddPayload(builder, payloadOffset)
-	}
-	respOffset := im_protocol.ClientResponseEnd(builder)
-	builder.Finish(respOffset)
+// sendClientResponse 发送响应给客户端
+func (h *Handler) sendClientResponse(stream connection.Stream, reqID string, code im_protocol.ErrorCode, msg string, payloadType im_protocol.ResponsePayload, payload []byte) {
+	builder := getBuilder()
+	defer putBuilder(builder)
 
-	respBytes := builder.FinishedBytes()
+	buildClientResponse(builder, reqID, code, msg, payloadType, payload, 0)
 
 	// 发送带帧头的响应
-	h.sendFrame(stream, FrameTypeResponse, respBytes)
+	h.sendFrame(stream, FrameTypeResponse, builder)
 }
 
-// sendFrame 发送带帧头的数据
-func (h *Handler) sendFrame(stream *webtransport.Stream, frameType byte, body []byte) {
-	header := make([]byte, FrameHeaderSize)
-	binary.BigEndian.PutUint32(header[:4], uint32(len(body)))
-	header[4] = frameType
-	_, err := stream.Write(header)
-	if err != nil {
+// sendFrame 将已 Finish 的 Builder 内容作为一帧写入流
+// 帧头写在 Builder 缓冲内、与消息体一次写入，保证 WebSocket 等消息型传输上一条消息即一个完整帧
+func (h *Handler) sendFrame(stream connection.Stream, frameType byte, builder *flatbuffers.Builder) {
+	if _, err := stream.Write(frameInPlace(builder, frameType)); err != nil {
 		return
 	}
-	if len(body) > 0 {
-		_, err := stream.Write(body)
-		if err != nil {
-			return
-		}
-	}
 }
 
 // buildUpstreamMessage 构建上行消息（辅助方法，减少重复代码）
@@ -216,9 +259,12 @@ func (h *Handler) buildUpstreamMessage(conn *connection.Connection, payload prot
 
 // publishUpstream 发布上行消息到 Logic（辅助方法）
 func (h *Handler) publishUpstream(msg *proto.UpstreamMessage) error {
-	data, err := json.Marshal(msg)
+	if h.msgBus == nil {
+		return ErrNATSUnavailable
+	}
+	data, err := h.codec.MarshalUpstream(msg)
 	if err != nil {
 		return err
 	}
-	return h.natsClient.Publish(sharedNats.SubjectLogicUpstream, data)
+	return h.msgBus.Publish(sharedNats.SubjectLogicUpstream, data)
 }
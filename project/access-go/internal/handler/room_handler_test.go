@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	im_protocol "sudooom.im.access/pkg/flatbuf/im/protocol"
+	"sudooom.im.shared/proto"
+)
+
+// TestHandleRoomResp 测试房间操作响应回传 reqId、错误码与房间快照
+func TestHandleRoomResp(t *testing.T) {
+	h, connMgr := newTestHandler()
+	stream := addTestConn(connMgr, 1, "WEB")
+	conn := connMgr.GetByUserIDAndPlatform(1, "WEB")
+
+	tests := []struct {
+		name    string
+		resp    proto.RoomResp
+		code    im_protocol.ErrorCode
+		players int
+	}{
+		{"成功", proto.RoomResp{
+			ReqId:  "req-1",
+			Action: "JOIN",
+			RoomId: "r1",
+			RoomInfo: &proto.RoomInfo{
+				RoomId:     "r1",
+				OwnerId:    2,
+				MaxPlayers: 4,
+				Status:     "WAITING",
+				Players:    []proto.RoomPlayer{{UserId: 1}, {UserId: 2}},
+			},
+		}, im_protocol.ErrorCodeSUCCESS, 2},
+		{"房间已满", proto.RoomResp{ReqId: "req-2", Action: "JOIN", Code: "ROOM_FULL", Msg: "房间已满", RoomId: "r1"}, im_protocol.ErrorCodeROOM_FULL, 0},
+		{"未定义错误码", proto.RoomResp{ReqId: "req-3", Action: "READY", Code: "NOPE"}, im_protocol.ErrorCodeUNKNOWN_ERROR, 0},
+	}
+
+	for _, tt := range tests {
+		h.HandleDownstream(mustMarshal(t, &proto.DownstreamMessage{
+			UserId:   1,
+			ConnId:   conn.ID(),
+			Platform: "WEB",
+			Payload:  proto.DownstreamPayload{RoomResp: &tt.resp},
+		}))
+
+		select {
+		case frame := <-stream.frames:
+			resp := im_protocol.GetRootAsClientResponse(frame[5:], 0)
+			if string(resp.ReqId()) != tt.resp.ReqId || resp.Code() != tt.code || string(resp.Msg()) != tt.resp.Msg {
+				t.Fatalf("%s: 响应头不正确: reqId=%s code=%v msg=%s", tt.name, resp.ReqId(), resp.Code(), resp.Msg())
+			}
+			if resp.PayloadType() != im_protocol.ResponsePayloadRoomResp {
+				t.Fatalf("%s: 载荷类型不正确: %v", tt.name, resp.PayloadType())
+			}
+			roomResp := im_protocol.GetRootAsRoomResp(resp.PayloadBytes(), 0)
+			if roomResp.Action() != im_protocol.EnumValuesRoomAction[tt.resp.Action] || string(roomResp.RoomId()) != tt.resp.RoomId {
+				t.Fatalf("%s: 房间响应不正确: action=%v roomId=%s", tt.name, roomResp.Action(), roomResp.RoomId())
+			}
+			players := 0
+			if info := roomResp.RoomInfo(nil); info != nil {
+				players = info.PlayersLength()
+			}
+			if players != tt.players {
+				t.Fatalf("%s: 玩家数 = %d, want %d", tt.name, players, tt.players)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: 未收到房间响应", tt.name)
+		}
+	}
+}
+
+// TestHandleRoomPush 测试房间事件推送，ERROR 事件的错误码与错误信息由 ClientResponse 携带
+func TestHandleRoomPush(t *testing.T) {
+	h, connMgr := newTestHandler()
+	stream := addTestConn(connMgr, 1, "WEB")
+	conn := connMgr.GetByUserIDAndPlatform(1, "WEB")
+
+	tests := []struct {
+		name    string
+		push    proto.RoomPush
+		event   im_protocol.RoomEvent
+		code    im_protocol.ErrorCode
+		players int
+	}{
+		{"玩家准备", proto.RoomPush{
+			Event:  "USER_READY",
+			RoomId: "r1",
+			UserId: 2,
+			RoomInfo: &proto.RoomInfo{
+				RoomId:         "r1",
+				OwnerId:        2,
+				MaxPlayers:     4,
+				CurrentPlayers: 2,
+				Status:         "WAITING",
+				Players:        []proto.RoomPlayer{{UserId: 1}, {UserId: 2}},
+			},
+		}, im_protocol.RoomEventUSER_READY, im_protocol.ErrorCodeSUCCESS, 2},
+		{"房间错误", proto.RoomPush{Event: "ERROR", RoomId: "r1", ErrorCode: "ROOM_FULL", ErrorMsg: "房间已满"}, im_protocol.RoomEventERROR, im_protocol.ErrorCodeROOM_FULL, 0},
+	}
+
+	for _, tt := range tests {
+		h.HandleDownstream(mustMarshal(t, &proto.DownstreamMessage{
+			UserId:   1,
+			ConnId:   conn.ID(),
+			Platform: "WEB",
+			Payload:  proto.DownstreamPayload{RoomPush: &tt.push},
+		}))
+
+		select {
+		case frame := <-stream.frames:
+			resp := im_protocol.GetRootAsClientResponse(frame[5:], 0)
+			if resp.Code() != tt.code || string(resp.Msg()) != tt.push.ErrorMsg {
+				t.Fatalf("%s: 响应头不正确: code=%v msg=%s", tt.name, resp.Code(), resp.Msg())
+			}
+			if resp.PayloadType() != im_protocol.ResponsePayloadRoomPush {
+				t.Fatalf("%s: 载荷类型不正确: %v", tt.name, resp.PayloadType())
+			}
+			roomPush := im_protocol.GetRootAsRoomPush(resp.PayloadBytes(), 0)
+			if roomPush.Event() != tt.event || string(roomPush.RoomId()) != tt.push.RoomId {
+				t.Fatalf("%s: 房间推送不正确: event=%v roomId=%s", tt.name, roomPush.Event(), roomPush.RoomId())
+			}
+			players := 0
+			if info := roomPush.RoomInfo(nil); info != nil {
+				players = info.PlayersLength()
+			}
+			if players != tt.players {
+				t.Fatalf("%s: 玩家数 = %d, want %d", tt.name, players, tt.players)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: 未收到房间推送", tt.name)
+		}
+	}
+
+	// 未定义的事件不推送，避免客户端收到默认值 0
+	h.HandleDownstream(mustMarshal(t, &proto.DownstreamMessage{
+		UserId:   1,
+		ConnId:   conn.ID(),
+		Platform: "WEB",
+		Payload:  proto.DownstreamPayload{RoomPush: &proto.RoomPush{Event: "NOPE", RoomId: "r1"}},
+	}))
+	select {
+	case <-stream.frames:
+		t.Fatal("未定义的房间事件不应推送")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestHandleGamePush 测试游戏推送携带游戏类型、载荷类型与原始载荷
+func TestHandleGamePush(t *testing.T) {
+	h, connMgr := newTestHandler()
+	stream := addTestConn(connMgr, 1, "WEB")
+	conn := connMgr.GetByUserIDAndPlatform(1, "WEB")
+
+	gamePayload := []byte{1, 2, 3, 4}
+	h.HandleDownstream(mustMarshal(t, &proto.DownstreamMessage{
+		UserId:   1,
+		ConnId:   conn.ID(),
+		Platform: "WEB",
+		Payload: proto.DownstreamPayload{GamePush: &proto.GamePush{
+			RoomId:          "r1",
+			GameType:        "HT_MAHJONG",
+			GamePayloadType: "MahjongPush",
+			GamePayload:     gamePayload,
+		}},
+	}))
+
+	select {
+	case frame := <-stream.frames:
+		resp := im_protocol.GetRootAsClientResponse(frame[5:], 0)
+		if resp.Code() != im_protocol.ErrorCodeSUCCESS || resp.PayloadType() != im_protocol.ResponsePayloadGamePush {
+			t.Fatalf("响应头不正确: code=%v payloadType=%v", resp.Code(), resp.PayloadType())
+		}
+		gamePush := im_protocol.GetRootAsGamePush(resp.PayloadBytes(), 0)
+		if string(gamePush.RoomId()) != "r1" || gamePush.GameType() != im_protocol.GameTypeHT_MAHJONG || gamePush.GamePayloadType() != im_protocol.GamePayloadMahjongPush {
+			t.Fatalf("游戏推送不正确: roomId=%s gameType=%v payloadType=%v", gamePush.RoomId(), gamePush.GameType(), gamePush.GamePayloadType())
+		}
+		if string(gamePush.GamePayloadBytes()) != string(gamePayload) {
+			t.Fatalf("游戏载荷 = %v, want %v", gamePush.GamePayloadBytes(), gamePayload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("未收到游戏推送")
+	}
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+	"sudooom.im.access/internal/connection"
+	im_protocol "sudooom.im.access/pkg/flatbuf/im/protocol"
+	sharedModel "sudooom.im.shared/model"
+	sharedNats "sudooom.im.shared/nats"
+	"sudooom.im.shared/proto"
+)
+
+// ErrNATSUnavailable 未配置消息总线（如单元测试环境）
+var ErrNATSUnavailable = errors.New("message bus unavailable")
+
+// SystemNotice 系统推送内容
+type SystemNotice struct {
+	Title     string
+	Content   string
+	Level     string // INFO / WARNING / ERROR，默认 INFO
+	ActionURL string
+}
+
+// PushSystem 向连接推送 SystemPush
+func (h *Handler) PushSystem(conn *connection.Connection, notice SystemNotice) error {
+	builder := getBuilder()
+	defer putBuilder(builder)
+
+	payload := buildSystemPush(builder, notice)
+	return h.pushToClient(newDownstreamTarget(conn), "", im_protocol.ErrorCodeSUCCESS, "", im_protocol.ResponsePayloadSystemPush, payload)
+}
+
+// handleSystemPush 处理 Logic 下发的系统公告
+func (h *Handler) handleSystemPush(target downstreamTarget, push *proto.SystemPush) {
+	notice := SystemNotice{
+		Title:     push.Title,
+		Content:   push.Content,
+		Level:     push.Level,
+		ActionURL: push.ActionUrl,
+	}
+
+	builder := getBuilder()
+	defer putBuilder(builder)
+
+	if err := h.pushToClient(target, "", im_protocol.ErrorCodeSUCCESS, "", im_protocol.ResponsePayloadSystemPush, buildSystemPush(builder, notice)); err != nil {
+		h.logger.Error("Failed to send system push to user", "userId", target.userID, "error", err)
+	}
+}
+
+// Announce 将系统公告请求转发给 Logic（由 Logic 负责跨节点投递与持久化）
+func (h *Handler) Announce(req *proto.SystemAnnouncement, timeout time.Duration) (*proto.SystemAnnouncementResult, error) {
+	if h.msgBus == nil {
+		return nil, ErrNATSUnavailable
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := h.msgBus.Request(sharedNats.SubjectLogicSystemAnnounce, data, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var result proto.SystemAnnouncementResult
+	if err := json.Unmarshal(reply, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// AccessNodes 向 Logic 查询存活的 Access 节点列表
+func (h *Handler) AccessNodes(timeout time.Duration) ([]sharedModel.AccessNode, error) {
+	if h.msgBus == nil {
+		return nil, ErrNATSUnavailable
+	}
+
+	reply, err := h.msgBus.Request(sharedNats.SubjectLogicAccessNodes, nil, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []sharedModel.AccessNode
+	if err := json.Unmarshal(reply, &nodes); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// kickWriteTimeout 踢下线通知的最长写入等待
+const kickWriteTimeout = time.Second
+
+// Kick 踢下线：先发送 KickedPush 告知原因，再以 CloseCodeKicked 关闭连接（客户端据此不自动重连）
+// 连接断开后立即执行下线处理（不保留恢复会话）
+func (h *Handler) Kick(conn *connection.Connection, reason im_protocol.KickReason, msg string) {
+	h.logger.Info("Kicking connection",
+		"conn_id", conn.ID(),
+		"user_id", conn.UserID(),
+		"platform", conn.Platform(),
+		"reason", reason.String(),
+		"msg", msg)
+	builder := getBuilder()
+	defer putBuilder(builder)
+
+	frame := h.buildClientResponseFrame("", im_protocol.ErrorCodeSUCCESS, msg, im_protocol.ResponsePayloadKickedPush, buildKickedPush(builder, reason, msg), 0)
+	conn.CloseWithFrame(frame, connection.CloseCodeKicked, reason.String(), kickWriteTimeout)
+}
+
+// handleKick 处理踢下线下行消息：ConnId 指定单个连接，否则踢掉用户（可按平台过滤）在本节点的所有连接
+func (h *Handler) handleKick(msg *proto.DownstreamMessage) {
+	reason, ok := im_protocol.EnumValuesKickReason[msg.Payload.Kick.Reason]
+	if !ok {
+		h.logger.Warn("Unknown kick reason", "reason", msg.Payload.Kick.Reason, "userId", msg.UserId)
+		reason = im_protocol.KickReasonADMIN
+	}
+
+	if msg.ConnId > 0 {
+		if conn := h.connMgr.Get(msg.ConnId); conn != nil && (msg.UserId == 0 || conn.UserID() == msg.UserId) {
+			h.Kick(conn, reason, msg.Payload.Kick.Msg)
+			return
+		}
+		// 连接已断开、会话仍在恢复宽限期：丢弃会话，不再允许恢复，并执行被推迟的下线处理
+		// 会话按 FlatBuffers 平台名（大写）存储，Web 服务下发的平台为小写
+		platform := strings.ToUpper(msg.Platform)
+		if msg.UserId > 0 && platform != "" && h.resumeStore.Remove(msg.UserId, platform, msg.ConnId) {
+			h.logger.Info("Removed resumable session of kicked connection", "userId", msg.UserId, "platform", platform, "connId", msg.ConnId)
+			h.ReleaseSession(context.Background(), msg.UserId, platform, msg.ConnId)
+		}
+		return
+	}
+
+	for _, conn := range h.connMgr.GetByUserID(msg.UserId) {
+		if msg.Platform == "" || strings.EqualFold(conn.Platform(), msg.Platform) {
+			h.Kick(conn, reason, msg.Payload.Kick.Msg)
+		}
+	}
+}
+
+// kickRemote 通知其他 Access 节点踢掉同用户同平台的旧连接（跨节点单会话）
+func (h *Handler) kickRemote(nodeID string, userID int64, platform string, connID int64, reason im_protocol.KickReason, msg string) error {
+	data, err := h.codec.MarshalDownstream(&proto.DownstreamMessage{
+		UserId:   userID,
+		ConnId:   connID,
+		Platform: platform,
+		Payload: proto.DownstreamPayload{
+			Kick: &proto.Kick{Reason: reason.String(), Msg: msg},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return h.msgBus.Publish(sharedNats.BuildAccessDownstreamSubject(nodeID), data)
+}
+
+// buildKickedPush 在 builder 中构建 FlatBuffers KickedPush，返回的载荷引用 builder 缓冲
+func buildKickedPush(builder *flatbuffers.Builder, reason im_protocol.KickReason, msg string) []byte {
+	msgOffset := builder.CreateString(msg)
+
+	im_protocol.KickedPushStart(builder)
+	im_protocol.KickedPushAddReason(builder, reason)
+	im_protocol.KickedPushAddMsg(builder, msgOffset)
+	builder.Finish(im_protocol.KickedPushEnd(builder))
+
+	return builder.FinishedBytes()
+}
+
+// buildSystemPush 在 builder 中构建 FlatBuffers SystemPush，返回的载荷引用 builder 缓冲
+func buildSystemPush(builder *flatbuffers.Builder, notice SystemNotice) []byte {
+	level, ok := im_protocol.EnumValuesSystemLevel[notice.Level]
+	if !ok {
+		level = im_protocol.SystemLevelINFO
+	}
+
+	titleOffset := builder.CreateString(notice.Title)
+	contentOffset := builder.CreateString(notice.Content)
+	actionURLOffset := builder.CreateString(notice.ActionURL)
+
+	im_protocol.SystemPushStart(builder)
+	im_protocol.SystemPushAddTitle(builder, titleOffset)
+	im_protocol.SystemPushAddContent(builder, contentOffset)
+	im_protocol.SystemPushAddLevel(builder, level)
+	im_protocol.SystemPushAddActionUrl(builder, actionURLOffset)
+	builder.Finish(im_protocol.SystemPushEnd(builder))
+
+	return builder.FinishedBytes()
+}
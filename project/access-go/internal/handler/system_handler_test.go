@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"sudooom.im.access/internal/connection"
+	im_protocol "sudooom.im.access/pkg/flatbuf/im/protocol"
+	"sudooom.im.shared/proto"
+)
+
+type testSession struct{}
+
+func (testSession) AcceptStream(ctx context.Context) (connection.Stream, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+func (testSession) CloseWithError(uint32, string) error { return nil }
+func (testSession) RemoteAddr() net.Addr                { return &net.TCPAddr{IP: net.ParseIP("10.0.0.1")} }
+
+// closeRecorder 记录关闭码的测试会话
+type closeRecorder struct {
+	testSession
+	closed chan uint32
+}
+
+func (s *closeRecorder) CloseWithError(code uint32, _ string) error {
+	s.closed <- code
+	return nil
+}
+
+// frameStream 记录写入帧的测试流
+type frameStream struct {
+	frames chan []byte
+}
+
+func (s *frameStream) Read([]byte) (int, error) { return 0, io.EOF }
+func (s *frameStream) Write(p []byte) (int, error) {
+	s.frames <- append([]byte(nil), p...)
+	return len(p), nil
+}
+func (s *frameStream) Close() error { return nil }
+
+func newTestHandler() (*Handler, *connection.Manager) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	connMgr := connection.NewManager()
+	h := NewHandler(connMgr, connection.NewResumeStore(0, 0, logger), connection.NewRateLimiter(nil, 0, 0), nil, nil, "access-test", 0, 0, logger, nil)
+	return h, connMgr
+}
+
+func addTestConn(connMgr *connection.Manager, userID int64, platform string) *frameStream {
+	stream := &frameStream{frames: make(chan []byte, 8)}
+	c := connection.New(testSession{}, connection.TransportTCP, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	c.SetClientStream(stream)
+	if userID > 0 {
+		c.BindSession(&connection.SessionInfo{UserID: userID, DeviceID: "device", Platform: platform})
+		connMgr.Add(c)
+		connMgr.BindUser(c.ID(), userID, platform)
+	} else {
+		connMgr.Add(c)
+	}
+	return stream
+}
+
+// TestHandleBroadcastSystemPush 测试广播系统公告按平台过滤并跳过未认证连接
+func TestHandleBroadcastSystemPush(t *testing.T) {
+	h, connMgr := newTestHandler()
+	web := addTestConn(connMgr, 1, "WEB")
+	ios := addTestConn(connMgr, 2, "IOS")
+	anonymous := addTestConn(connMgr, 0, "")
+
+	data, _ := json.Marshal(&proto.DownstreamMessage{
+		Platform: "web",
+		Payload: proto.DownstreamPayload{SystemPush: &proto.SystemPush{
+			Title:   "维护通知",
+			Content: "今晚 23:00 维护",
+			Level:   "WARNING",
+		}},
+	})
+	h.HandleBroadcast(data)
+
+	select {
+	case frame := <-web.frames:
+		resp := im_protocol.GetRootAsClientResponse(frame[5:], 0)
+		if resp.PayloadType() != im_protocol.ResponsePayloadSystemPush {
+			t.Fatalf("载荷类型不正确: %v", resp.PayloadType())
+		}
+		push := im_protocol.GetRootAsSystemPush(resp.PayloadBytes(), 0)
+		if string(push.Title()) != "维护通知" || push.Level() != im_protocol.SystemLevelWARNING {
+			t.Fatalf("公告内容不正确: %s %v", push.Title(), push.Level())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("web 连接未收到公告")
+	}
+
+	for name, stream := range map[string]*frameStream{"ios": ios, "未认证": anonymous} {
+		select {
+		case <-stream.frames:
+			t.Fatalf("%s 连接不应收到公告", name)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// blockedStream 写入一直阻塞到 release 关闭，模拟不读取数据的慢客户端
+type blockedStream struct {
+	release chan struct{}
+}
+
+func (s *blockedStream) Read([]byte) (int, error) { return 0, io.EOF }
+func (s *blockedStream) Write(p []byte) (int, error) {
+	<-s.release
+	return len(p), nil
+}
+func (s *blockedStream) Close() error { return nil }
+
+// TestDownstreamSlowConsumerIsolation 测试一个连接写队列满时不拖慢其他连接的下行投递
+func TestDownstreamSlowConsumerIsolation(t *testing.T) {
+	h, connMgr := newTestHandler()
+	fast := addTestConn(connMgr, 1, "WEB")
+
+	// 写队列长度为 1、wait 策略等待 1s 的慢连接
+	wait := connection.SendPolicy{Overflow: connection.OverflowWait, Timeout: time.Second}
+	bp := connection.NewBackpressure(1, map[connection.PayloadClass]connection.SendPolicy{
+		connection.ClassControl: wait,
+		connection.ClassRoom:    wait,
+	})
+	blocked := &blockedStream{release: make(chan struct{})}
+	defer close(blocked.release)
+	slow := connection.New(testSession{}, connection.TransportTCP, bp, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	slow.SetClientStream(blocked)
+	slow.BindSession(&connection.SessionInfo{UserID: 2, DeviceID: "device", Platform: "WEB"})
+	connMgr.Add(slow)
+	connMgr.BindUser(slow.ID(), 2, "WEB")
+	defer slow.Close()
+
+	start := time.Now()
+	const rounds = 4
+	for i := 0; i < rounds; i++ {
+		h.HandleBroadcast(mustMarshal(t, &proto.DownstreamMessage{
+			Payload: proto.DownstreamPayload{SystemPush: &proto.SystemPush{Title: "t", Level: "INFO"}},
+		}))
+		for _, userID := range []int64{2, 1} {
+			h.HandleDownstream(mustMarshal(t, &proto.DownstreamMessage{
+				UserId:  userID,
+				Payload: proto.DownstreamPayload{RoomPush: &proto.RoomPush{Event: "USER_READY", RoomId: "r1", UserId: 2}},
+			}))
+		}
+	}
+
+	for i := 0; i < 2*rounds; i++ {
+		select {
+		case <-fast.frames:
+		case <-time.After(time.Second):
+			t.Fatalf("正常连接只收到 %d 帧", i)
+		}
+	}
+	// 慢连接的写队列在首轮后已满，分发方若等待队列空位会累计阻塞数秒
+	if elapsed := time.Since(start); elapsed >= wait.Timeout {
+		t.Fatalf("慢连接拖慢了下行投递: %v", elapsed)
+	}
+}
+
+// TestAnnounceWithoutNATS 测试未连接 NATS 时公告请求返回错误
+func TestAnnounceWithoutNATS(t *testing.T) {
+	h, _ := newTestHandler()
+	if _, err := h.Announce(&proto.SystemAnnouncement{All: true, Title: "t"}, time.Second); !errors.Is(err, ErrNATSUnavailable) {
+		t.Fatalf("err = %v, want ErrNATSUnavailable", err)
+	}
+}
+
+// TestHandleKick 测试跨节点踢下线：先下发 KICKED 通知再以 Kicked 关闭码关闭，仅踢指定连接
+func TestHandleKick(t *testing.T) {
+	h, connMgr := newTestHandler()
+	addConn := func(platform string) (*connection.Connection, *closeRecorder, *frameStream) {
+		session := &closeRecorder{closed: make(chan uint32, 1)}
+		stream := &frameStream{frames: make(chan []byte, 8)}
+		c := connection.New(session, connection.TransportTCP, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		c.SetClientStream(stream)
+		c.BindSession(&connection.SessionInfo{UserID: 1, DeviceID: "device", Platform: platform})
+		connMgr.Add(c)
+		connMgr.BindUser(c.ID(), 1, platform)
+		return c, session, stream
+	}
+	old, oldSession, oldStream := addConn("WEB")
+	_, otherSession, _ := addConn("IOS")
+
+	h.HandleDownstream(mustMarshal(t, &proto.DownstreamMessage{
+		UserId:   1,
+		ConnId:   old.ID(),
+		Platform: "web",
+		Payload:  proto.DownstreamPayload{Kick: &proto.Kick{Reason: "NEW_LOGIN", Msg: "logged in on another device"}},
+	}))
+
+	select {
+	case frame := <-oldStream.frames:
+		resp := im_protocol.GetRootAsClientResponse(frame[5:], 0)
+		if resp.PayloadType() != im_protocol.ResponsePayloadKickedPush {
+			t.Fatalf("载荷类型不正确: %v", resp.PayloadType())
+		}
+		push := im_protocol.GetRootAsKickedPush(resp.PayloadBytes(), 0)
+		if push.Reason() != im_protocol.KickReasonNEW_LOGIN || string(push.Msg()) != "logged in on another device" {
+			t.Fatalf("踢下线通知不正确: %v %s", push.Reason(), push.Msg())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("未收到 KICKED 通知")
+	}
+
+	select {
+	case code := <-oldSession.closed:
+		if code != connection.CloseCodeKicked {
+			t.Fatalf("关闭码不正确: %d", code)
+		}
+	case <-time.After(2 * kickWriteTimeout):
+		t.Fatalf("连接未关闭")
+	}
+
+	select {
+	case <-otherSession.closed:
+		t.Fatalf("其他连接不应被踢")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	return data
+}
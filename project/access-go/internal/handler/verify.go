@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	im_protocol "sudooom.im.access/pkg/flatbuf/im/protocol"
+)
+
+// FlatBuffers Go 运行时没有校验器，GetRootAs 与字段访问器对越界偏移直接 panic
+// 以下按 schema 校验客户端上行的请求表：表/虚表边界、字符串、向量与嵌套表，校验通过后访问器不会越界
+
+const (
+	maxVerifyDepth  = 16   // 表嵌套深度上限
+	maxVerifyTables = 1024 // 单个 buffer 内表数量上限
+)
+
+var errInvalidBuffer = errors.New("invalid flatbuffer")
+
+type verifier struct {
+	buf    []byte
+	depth  int
+	tables int
+}
+
+// fbTable 已通过边界校验的表
+type fbTable struct {
+	pos      int // 表起始位置
+	vtable   int // 虚表起始位置
+	vtLen    int // 虚表长度
+	tableLen int // 表数据长度
+}
+
+// inRange 检查 [off, off+size) 是否在 buffer 内
+func (v *verifier) inRange(off, size int) bool {
+	return off >= 0 && size >= 0 && off <= len(v.buf) && size <= len(v.buf)-off
+}
+
+func (v *verifier) uint32At(off int) (int, bool) {
+	if !v.inRange(off, 4) {
+		return 0, false
+	}
+	return int(binary.LittleEndian.Uint32(v.buf[off:])), true
+}
+
+// root 校验根表
+func (v *verifier) root() (fbTable, error) {
+	off, ok := v.uint32At(0)
+	if !ok {
+		return fbTable{}, fmt.Errorf("%w: buffer too short", errInvalidBuffer)
+	}
+	return v.table(off)
+}
+
+// table 校验位于 pos 的表及其虚表
+func (v *verifier) table(pos int) (fbTable, error) {
+	v.tables++
+	if v.tables > maxVerifyTables {
+		return fbTable{}, fmt.Errorf("%w: too many tables", errInvalidBuffer)
+	}
+	if !v.inRange(pos, 4) {
+		return fbTable{}, fmt.Errorf("%w: table out of range", errInvalidBuffer)
+	}
+	vtable := pos - int(int32(binary.LittleEndian.Uint32(v.buf[pos:])))
+	if !v.inRange(vtable, 4) {
+		return fbTable{}, fmt.Errorf("%w: vtable out of range", errInvalidBuffer)
+	}
+	vtLen := int(binary.LittleEndian.Uint16(v.buf[vtable:]))
+	tableLen := int(binary.LittleEndian.Uint16(v.buf[vtable+2:]))
+	if vtLen < 4 || vtLen%2 != 0 || !v.inRange(vtable, vtLen) || tableLen < 4 || !v.inRange(pos, tableLen) {
+		return fbTable{}, fmt.Errorf("%w: malformed vtable", errInvalidBuffer)
+	}
+	return fbTable{pos: pos, vtable: vtable, vtLen: vtLen, tableLen: tableLen}, nil
+}
+
+// field 返回字段在表内的偏移，字段缺省时返回 0
+func (v *verifier) field(t fbTable, slot int) int {
+	vo := 4 + 2*slot
+	if vo+2 > t.vtLen {
+		return 0
+	}
+	return int(binary.LittleEndian.Uint16(v.buf[t.vtable+vo:]))
+}
+
+// scalar 校验标量字段
+func (v *verifier) scalar(t fbTable, slot, size int, name string) error {
+	off := v.field(t, slot)
+	if off == 0 {
+		return nil
+	}
+	if off+size > t.tableLen {
+		return fmt.Errorf("%w: field %s out of range", errInvalidBuffer, name)
+	}
+	return nil
+}
+
+// indirect 解引用偏移字段，返回目标位置，字段缺省时返回 -1
+func (v *verifier) indirect(t fbTable, slot int, name string) (int, error) {
+	off := v.field(t, slot)
+	if off == 0 {
+		return -1, nil
+	}
+	if off+4 > t.tableLen {
+		return 0, fmt.Errorf("%w: field %s out of range", errInvalidBuffer, name)
+	}
+	pos := t.pos + off
+	rel, _ := v.uint32At(pos)
+	target := pos + rel
+	if !v.inRange(target, 4) {
+		return 0, fmt.Errorf("%w: field %s out of range", errInvalidBuffer, name)
+	}
+	return target, nil
+}
+
+// vector 校验向量字段（含字符串），返回元素起始位置与元素个数，字段缺省时起始位置为 -1
+func (v *verifier) vector(t fbTable, slot, elemSize int, name string) (int, int, error) {
+	target, err := v.indirect(t, slot, name)
+	if err != nil || target < 0 {
+		return -1, 0, err
+	}
+	n, _ := v.uint32At(target)
+	if n > len(v.buf)/elemSize || !v.inRange(target+4, n*elemSize) {
+		return 0, 0, fmt.Errorf("%w: field %s length out of range", errInvalidBuffer, name)
+	}
+	return target + 4, n, nil
+}
+
+// string 校验字符串字段（FlatBuffers 字符串以 0 结尾）
+func (v *verifier) string(t fbTable, slot int, name string) error {
+	start, n, err := v.vector(t, slot, 1, name)
+	if err != nil || start < 0 {
+		return err
+	}
+	if !v.inRange(start+n, 1) || v.buf[start+n] != 0 {
+		return fmt.Errorf("%w: field %s not terminated", errInvalidBuffer, name)
+	}
+	return nil
+}
+
+// tableVector 校验表向量字段，对每个元素调用 verifyElem
+func (v *verifier) tableVector(t fbTable, slot int, name string, verifyElem func(fbTable) error) error {
+	start, n, err := v.vector(t, slot, 4, name)
+	if err != nil || n == 0 {
+		return err
+	}
+	if v.depth++; v.depth > maxVerifyDepth {
+		return fmt.Errorf("%w: nesting too deep", errInvalidBuffer)
+	}
+	defer func() { v.depth-- }()
+
+	for i := 0; i < n; i++ {
+		pos := start + 4*i
+		rel, _ := v.uint32At(pos)
+		elem, err := v.table(pos + rel)
+		if err != nil {
+			return err
+		}
+		if err := verifyElem(elem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// firstError 返回第一个非 nil 错误
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyRoot 校验 buffer 的根表
+func verifyRoot(buf []byte, verifyFn func(*verifier, fbTable) error) error {
+	v := &verifier{buf: buf}
+	t, err := v.root()
+	if err != nil {
+		return err
+	}
+	return verifyFn(v, t)
+}
+
+// VerifyAuthRequest 校验 AuthRequest
+func VerifyAuthRequest(buf []byte) error {
+	return verifyRoot(buf, func(v *verifier, t fbTable) error {
+		return firstError(
+			v.string(t, 0, "token"),
+			v.string(t, 1, "device_id"),
+			v.scalar(t, 2, 1, "platform"),
+			v.string(t, 3, "app_version"),
+			v.string(t, 4, "resume_token"),
+			v.scalar(t, 5, 8, "last_seq"),
+		)
+	})
+}
+
+// VerifyClientRequest 校验 ClientRequest 外层（payload 按 payload_type 另行校验）
+func VerifyClientRequest(buf []byte) error {
+	return verifyRoot(buf, func(v *verifier, t fbTable) error {
+		_, _, payloadErr := v.vector(t, 3, 1, "payload")
+		return firstError(
+			v.string(t, 0, "req_id"),
+			v.scalar(t, 1, 8, "timestamp"),
+			v.scalar(t, 2, 1, "payload_type"),
+			payloadErr,
+		)
+	})
+}
+
+// VerifyChatSendReq 校验 ChatSendReq
+func VerifyChatSendReq(buf []byte) error {
+	return verifyRoot(buf, func(v *verifier, t fbTable) error {
+		return firstError(
+			v.scalar(t, 0, 1, "chat_type"),
+			v.string(t, 1, "target_id"),
+			v.scalar(t, 2, 1, "msg_type"),
+			v.string(t, 3, "content"),
+			v.tableVector(t, 4, "ext", func(kv fbTable) error {
+				return firstError(v.string(kv, 0, "key"), v.string(kv, 1, "value"))
+			}),
+		)
+	})
+}
+
+// VerifyRoomReq 校验 RoomReq
+func VerifyRoomReq(buf []byte) error {
+	return verifyRoot(buf, func(v *verifier, t fbTable) error {
+		return firstError(
+			v.scalar(t, 0, 1, "action"),
+			v.scalar(t, 1, 1, "game_type"),
+			v.string(t, 2, "room_id"),
+			v.string(t, 3, "room_config"),
+			v.scalar(t, 4, 4, "target_seat_index"),
+		)
+	})
+}
+
+// VerifyGameReq 校验 GameReq（game_payload 由 Logic 按 game_payload_type 解析）
+func VerifyGameReq(buf []byte) error {
+	return verifyRoot(buf, func(v *verifier, t fbTable) error {
+		_, _, payloadErr := v.vector(t, 3, 1, "game_payload")
+		return firstError(
+			v.string(t, 0, "room_id"),
+			v.scalar(t, 1, 1, "game_type"),
+			v.scalar(t, 2, 1, "game_payload_type"),
+			payloadErr,
+		)
+	})
+}
+
+// VerifyConversationReadReq 校验 ConversationReadReq
+func VerifyConversationReadReq(buf []byte) error {
+	return verifyRoot(buf, func(v *verifier, t fbTable) error {
+		return firstError(
+			v.string(t, 0, "peer_id"),
+			v.string(t, 1, "group_id"),
+			v.string(t, 2, "last_read_msg_id"),
+		)
+	})
+}
+
+// verifyRequestPayload 按 payload_type 校验请求载荷，无需解析载荷的请求（如心跳）直接通过
+func verifyRequestPayload(payloadType im_protocol.RequestPayload, payload []byte) error {
+	switch payloadType {
+	case im_protocol.RequestPayloadChatSendReq:
+		return VerifyChatSendReq(payload)
+	case im_protocol.RequestPayloadRoomReq:
+		return VerifyRoomReq(payload)
+	case im_protocol.RequestPayloadGameReq:
+		return VerifyGameReq(payload)
+	case im_protocol.RequestPayloadConversationReadReq:
+		return VerifyConversationReadReq(payload)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,224 @@
+package handler
+
+import (
+	"testing"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+	im_protocol "sudooom.im.access/pkg/flatbuf/im/protocol"
+)
+
+func buildChatSendReq() []byte {
+	b := flatbuffers.NewBuilder(256)
+	key := b.CreateString("k")
+	value := b.CreateString("v")
+	im_protocol.KeyValueStart(b)
+	im_protocol.KeyValueAddKey(b, key)
+	im_protocol.KeyValueAddValue(b, value)
+	kv := im_protocol.KeyValueEnd(b)
+	im_protocol.ChatSendReqStartExtVector(b, 1)
+	b.PrependUOffsetT(kv)
+	ext := b.EndVector(1)
+
+	target := b.CreateString("10002")
+	content := b.CreateString("hello")
+	im_protocol.ChatSendReqStart(b)
+	im_protocol.ChatSendReqAddChatType(b, im_protocol.ChatTypePRIVATE)
+	im_protocol.ChatSendReqAddTargetId(b, target)
+	im_protocol.ChatSendReqAddMsgType(b, im_protocol.MsgTypeTEXT)
+	im_protocol.ChatSendReqAddContent(b, content)
+	im_protocol.ChatSendReqAddExt(b, ext)
+	b.Finish(im_protocol.ChatSendReqEnd(b))
+	return b.FinishedBytes()
+}
+
+func buildRoomReq() []byte {
+	b := flatbuffers.NewBuilder(128)
+	roomID := b.CreateString("room-1")
+	im_protocol.RoomReqStart(b)
+	im_protocol.RoomReqAddAction(b, im_protocol.RoomActionJOIN)
+	im_protocol.RoomReqAddRoomId(b, roomID)
+	im_protocol.RoomReqAddTargetSeatIndex(b, 2)
+	b.Finish(im_protocol.RoomReqEnd(b))
+	return b.FinishedBytes()
+}
+
+func buildGameReq() []byte {
+	b := flatbuffers.NewBuilder(128)
+	payload := b.CreateByteVector([]byte{1, 2, 3})
+	roomID := b.CreateString("room-1")
+	im_protocol.GameReqStart(b)
+	im_protocol.GameReqAddRoomId(b, roomID)
+	im_protocol.GameReqAddGamePayloadType(b, im_protocol.GamePayloadMahjongReq)
+	im_protocol.GameReqAddGamePayload(b, payload)
+	b.Finish(im_protocol.GameReqEnd(b))
+	return b.FinishedBytes()
+}
+
+func buildConversationReadReq() []byte {
+	b := flatbuffers.NewBuilder(128)
+	peerID := b.CreateString("10002")
+	msgID := b.CreateString("123")
+	im_protocol.ConversationReadReqStart(b)
+	im_protocol.ConversationReadReqAddPeerId(b, peerID)
+	im_protocol.ConversationReadReqAddLastReadMsgId(b, msgID)
+	b.Finish(im_protocol.ConversationReadReqEnd(b))
+	return b.FinishedBytes()
+}
+
+func buildClientRequest(payloadType im_protocol.RequestPayload, payload []byte) []byte {
+	b := flatbuffers.NewBuilder(256 + len(payload))
+	payloadOffset := b.CreateByteVector(payload)
+	reqID := b.CreateString("req-1")
+	im_protocol.ClientRequestStart(b)
+	im_protocol.ClientRequestAddReqId(b, reqID)
+	im_protocol.ClientRequestAddTimestamp(b, 1700000000000)
+	im_protocol.ClientRequestAddPayloadType(b, payloadType)
+	im_protocol.ClientRequestAddPayload(b, payloadOffset)
+	b.Finish(im_protocol.ClientRequestEnd(b))
+	return b.FinishedBytes()
+}
+
+func buildAuthRequest() []byte {
+	b := flatbuffers.NewBuilder(256)
+	token := b.CreateString("token")
+	deviceID := b.CreateString("device-1")
+	appVersion := b.CreateString("1.0.0")
+	resumeToken := b.CreateString("resume")
+	im_protocol.AuthRequestStart(b)
+	im_protocol.AuthRequestAddToken(b, token)
+	im_protocol.AuthRequestAddDeviceId(b, deviceID)
+	im_protocol.AuthRequestAddPlatform(b, im_protocol.PlatformWEB)
+	im_protocol.AuthRequestAddAppVersion(b, appVersion)
+	im_protocol.AuthRequestAddResumeToken(b, resumeToken)
+	im_protocol.AuthRequestAddLastSeq(b, 42)
+	b.Finish(im_protocol.AuthRequestEnd(b))
+	return b.FinishedBytes()
+}
+
+// decodeClientRequest 模拟请求处理的解码路径：校验通过后访问所有字段
+func decodeClientRequest(body []byte) error {
+	if err := VerifyClientRequest(body); err != nil {
+		return err
+	}
+	req := im_protocol.GetRootAsClientRequest(body, 0)
+	_ = req.ReqId()
+	_ = req.Timestamp()
+	payload := req.PayloadBytes()
+	if err := verifyRequestPayload(req.PayloadType(), payload); err != nil {
+		return err
+	}
+
+	switch req.PayloadType() {
+	case im_protocol.RequestPayloadChatSendReq:
+		chat := im_protocol.GetRootAsChatSendReq(payload, 0)
+		_, _, _, _ = chat.ChatType(), chat.TargetId(), chat.MsgType(), chat.Content()
+		kv := new(im_protocol.KeyValue)
+		for i := 0; i < chat.ExtLength(); i++ {
+			if chat.Ext(kv, i) {
+				_, _ = kv.Key(), kv.Value()
+			}
+		}
+	case im_protocol.RequestPayloadRoomReq:
+		room := im_protocol.GetRootAsRoomReq(payload, 0)
+		_, _, _, _, _ = room.Action(), room.GameType(), room.RoomId(), room.RoomConfig(), room.TargetSeatIndex()
+	case im_protocol.RequestPayloadGameReq:
+		game := im_protocol.GetRootAsGameReq(payload, 0)
+		_, _, _, _ = game.RoomId(), game.GameType(), game.GamePayloadType(), game.GamePayloadBytes()
+	case im_protocol.RequestPayloadConversationReadReq:
+		read := im_protocol.GetRootAsConversationReadReq(payload, 0)
+		_, _, _ = read.PeerId(), read.GroupId(), read.LastReadMsgId()
+	}
+	return nil
+}
+
+// decodeAuthRequest 模拟认证的解码路径
+func decodeAuthRequest(body []byte) error {
+	if err := VerifyAuthRequest(body); err != nil {
+		return err
+	}
+	req := im_protocol.GetRootAsAuthRequest(body, 0)
+	_, _, _, _ = req.Token(), req.DeviceId(), req.Platform(), req.AppVersion()
+	_, _ = req.ResumeToken(), req.LastSeq()
+	return nil
+}
+
+// TestVerifyRequests 测试合法请求通过校验、截断或篡改的请求被拒绝
+func TestVerifyRequests(t *testing.T) {
+	requests := []struct {
+		name string
+		body []byte
+	}{
+		{"ChatSendReq", buildClientRequest(im_protocol.RequestPayloadChatSendReq, buildChatSendReq())},
+		{"RoomReq", buildClientRequest(im_protocol.RequestPayloadRoomReq, buildRoomReq())},
+		{"GameReq", buildClientRequest(im_protocol.RequestPayloadGameReq, buildGameReq())},
+		{"ConversationReadReq", buildClientRequest(im_protocol.RequestPayloadConversationReadReq, buildConversationReadReq())},
+	}
+
+	for _, tt := range requests {
+		if err := decodeClientRequest(tt.body); err != nil {
+			t.Errorf("%s: 合法请求校验失败: %v", tt.name, err)
+		}
+		// 截断到任意长度都不能通过校验或导致 panic
+		for n := 0; n < len(tt.body)-1; n++ {
+			_ = decodeClientRequest(tt.body[:n])
+		}
+		if err := decodeClientRequest(tt.body[:len(tt.body)/2]); err == nil {
+			t.Errorf("%s: 截断的请求应校验失败", tt.name)
+		}
+	}
+
+	if err := decodeAuthRequest(buildAuthRequest()); err != nil {
+		t.Errorf("AuthRequest: 合法请求校验失败: %v", err)
+	}
+
+	invalid := []struct {
+		name string
+		body []byte
+	}{
+		{"空 buffer", nil},
+		{"根偏移越界", []byte{0xff, 0xff, 0xff, 0x7f}},
+		{"载荷类型不匹配", buildClientRequest(im_protocol.RequestPayloadChatSendReq, []byte{1, 2})},
+	}
+	for _, tt := range invalid {
+		if err := decodeClientRequest(tt.body); err == nil {
+			t.Errorf("%s: 应校验失败", tt.name)
+		}
+	}
+
+	// 字符串长度被篡改为超出 buffer
+	body := buildAuthRequest()
+	tampered := append([]byte(nil), body...)
+	for i := 0; i+4 <= len(tampered); i++ {
+		// token 字符串长度 5 后紧跟 "token"
+		if string(tampered[i+4:min(i+9, len(tampered))]) == "token" {
+			tampered[i+3] = 0x7f
+			break
+		}
+	}
+	if err := decodeAuthRequest(tampered); err == nil {
+		t.Errorf("篡改字符串长度的 AuthRequest 应校验失败")
+	}
+}
+
+// FuzzDecodeClientRequest 对请求解码路径做模糊测试：任意输入都不能导致 panic
+func FuzzDecodeClientRequest(f *testing.F) {
+	f.Add(buildClientRequest(im_protocol.RequestPayloadChatSendReq, buildChatSendReq()))
+	f.Add(buildClientRequest(im_protocol.RequestPayloadRoomReq, buildRoomReq()))
+	f.Add(buildClientRequest(im_protocol.RequestPayloadGameReq, buildGameReq()))
+	f.Add(buildClientRequest(im_protocol.RequestPayloadConversationReadReq, buildConversationReadReq()))
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		_ = decodeClientRequest(body)
+	})
+}
+
+// FuzzDecodeAuthRequest 对认证请求解码路径做模糊测试
+func FuzzDecodeAuthRequest(f *testing.F) {
+	f.Add(buildAuthRequest())
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		_ = decodeAuthRequest(body)
+	})
+}
@@ -5,9 +5,12 @@ import (
 
 	"github.com/nats-io/nats.go"
 	"sudooom.im.access/internal/config"
+	"sudooom.im.shared/bus"
 )
 
+// Client NATS 客户端封装，实现 bus.Bus
 type Client struct {
+	*bus.NATSBus
 	conn   *nats.Conn
 	logger *slog.Logger
 }
@@ -33,8 +36,9 @@ func NewClient(cfg config.NATSConfig) (*Client, error) {
 	}
 
 	return &Client{
-		conn:   conn,
-		logger: slog.Default(),
+		NATSBus: bus.NewNATSBus(conn),
+		conn:    conn,
+		logger:  slog.Default(),
 	}, nil
 }
 
@@ -43,24 +47,6 @@ func (c *Client) Conn() *nats.Conn {
 	return c.conn
 }
 
-func (c *Client) Publish(subject string, data []byte) error {
-	return c.conn.Publish(subject, data)
-}
-
-func (c *Client) Subscribe(subject string, handler func(data []byte)) error {
-	_, err := c.conn.Subscribe(subject, func(msg *nats.Msg) {
-		handler(msg.Data)
-	})
-	return err
-}
-
-func (c *Client) QueueSubscribe(subject, queue string, handler func(data []byte)) error {
-	_, err := c.conn.QueueSubscribe(subject, queue, func(msg *nats.Msg) {
-		handler(msg.Data)
-	})
-	return err
-}
-
 func (c *Client) IsConnected() bool {
 	return c.conn != nil && c.conn.IsConnected()
 }
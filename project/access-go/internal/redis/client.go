@@ -18,10 +18,10 @@ const (
 	locationTTL = 2 * time.Minute
 )
 
-// UserTokenInfo 存储在 Redis 中的用户 Token 信息（仅认证字段）
+// UserTokenInfo 存储在 Redis 中的用户 Token 信息（仅认证字段，字段名与 web-go 登录时写入的一致）
 type UserTokenInfo struct {
-	UserID   int64  `json:"user_id"`
-	DeviceID string `json:"device_id"`
+	UserID   int64  `json:"userId"`
+	DeviceID string `json:"deviceId"`
 	Platform string `json:"platform"`
 }
 
@@ -37,9 +37,10 @@ type UserLocation struct {
 
 // Client Redis 客户端
 type Client struct {
-	client *redis.Client
-	nodeID string
-	logger *slog.Logger
+	client    *redis.Client
+	nodeID    string
+	logger    *slog.Logger
+	refresher *LocationRefresher
 }
 
 // NewClient 创建 Redis 客户端
@@ -51,17 +52,19 @@ func NewClient(cfg config.RedisConfig, nodeID string) *Client {
 		PoolSize: cfg.PoolSize,
 	})
 
+	logger := slog.Default()
 	return &Client{
-		client: client,
-		nodeID: nodeID,
-		logger: slog.Default(),
+		client:    client,
+		nodeID:    nodeID,
+		logger:    logger,
+		refresher: newLocationRefresher(client, locationTTL, cfg.LocationRefreshInterval, cfg.LocationRefreshBatch, logger),
 	}
 }
 
-// RegisterUserLocation 注册用户位置
+// RegisterUserLocation 注册用户位置，返回被覆盖的旧位置（无则为 nil）
 // Key: im:user:location:{userId}:{platform}, Value: JSON{accessNodeId, connId}
-// 一个 platform 只维持一个连接，新连接会覆盖旧连接
-func (c *Client) RegisterUserLocation(ctx context.Context, userId int64, platform string, connId int64) error {
+// 一个 platform 只维持一个连接，新连接会覆盖旧连接；旧位置由 SET GET 原子取回，调用方据此踢掉其他节点上的旧连接
+func (c *Client) RegisterUserLocation(ctx context.Context, userId int64, platform string, connId int64) (*UserLocation, error) {
 	key := sharedRedis.BuildUserLocationKeyWithPlatform(userId, platform)
 
 	// 存储完整的路由信息
@@ -75,36 +78,91 @@ func (c *Client) RegisterUserLocation(ctx context.Context, userId int64, platfor
 
 	data, err := json.Marshal(location)
 	if err != nil {
-		return fmt.Errorf("failed to marshal location: %w", err)
+		return nil, fmt.Errorf("failed to marshal location: %w", err)
 	}
 
-	err = c.client.Set(ctx, key, data, locationTTL).Err()
-
-	if err == nil {
-		c.logger.Debug("Registered user location",
-			"userId", userId,
-			"platform", platform,
-			"connId", connId,
-			"nodeId", c.nodeID)
+	// 同时写入本节点的位置索引，节点宕机后 Logic 据此清理位置
+	var setCmd *redis.StatusCmd
+	_, err = c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		setCmd = pipe.SetArgs(ctx, key, data, redis.SetArgs{TTL: locationTTL, Get: true})
+		pipe.SAdd(ctx, sharedRedis.BuildAccessNodeLocationsKey(c.nodeID), sharedRedis.BuildNodeLocationMember(userId, platform, connId))
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
 	}
+	old := setCmd.Val()
 
-	return err
+	c.logger.Debug("Registered user location",
+		"userId", userId,
+		"platform", platform,
+		"connId", connId,
+		"nodeId", c.nodeID)
+
+	if old == "" {
+		return nil, nil
+	}
+	var previous UserLocation
+	if err := json.Unmarshal([]byte(old), &previous); err != nil {
+		c.logger.Warn("Failed to unmarshal previous location", "userId", userId, "error", err)
+		return nil, nil
+	}
+	return &previous, nil
 }
 
 // UnregisterUserLocation 移除用户位置
-func (c *Client) UnregisterUserLocation(ctx context.Context, userId int64, platform string) error {
+// 仅当位置仍指向本节点的 connId 时删除，避免旧连接下线时删掉新连接（可能在其他节点）的位置
+func (c *Client) UnregisterUserLocation(ctx context.Context, userId int64, platform string, connId int64) error {
 	key := sharedRedis.BuildUserLocationKeyWithPlatform(userId, platform)
 
-	err := c.client.Del(ctx, key).Err()
-
-	if err == nil {
-		// User location unregistered
+	// 位置索引按连接记录，无论位置是否已被覆盖都移除本连接
+	member := sharedRedis.BuildNodeLocationMember(userId, platform, connId)
+	if err := c.client.SRem(ctx, sharedRedis.BuildAccessNodeLocationsKey(c.nodeID), member).Err(); err != nil {
+		return err
 	}
 
+	err := c.client.Watch(ctx, func(tx *redis.Tx) error {
+		data, err := tx.Get(ctx, key).Result()
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var location UserLocation
+		if err := json.Unmarshal([]byte(data), &location); err != nil {
+			return fmt.Errorf("failed to unmarshal location: %w", err)
+		}
+		if location.AccessNodeId != c.nodeID || location.ConnId != connId {
+			return nil
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, key)
+			return nil
+		})
+		return err
+	}, key)
+
+	// 事务期间位置被新连接覆盖，无需删除
+	if errors.Is(err, redis.TxFailedErr) {
+		return nil
+	}
 	return err
 }
 
-// RefreshUserLocation 刷新用户位置 TTL（心跳时调用）
+// TouchUserLocation 标记用户位置待续期（心跳时调用），由 LocationRefresher 按周期批量刷新 TTL
+func (c *Client) TouchUserLocation(userId int64, platform string) {
+	c.refresher.Mark(userId, platform)
+}
+
+// LocationRefresher 返回位置续期器
+func (c *Client) LocationRefresher() *LocationRefresher {
+	return c.refresher
+}
+
+// RefreshUserLocation 立即刷新单个用户位置 TTL
 func (c *Client) RefreshUserLocation(ctx context.Context, userId int64, platform string) error {
 	key := sharedRedis.BuildUserLocationKeyWithPlatform(userId, platform)
 	return c.client.Expire(ctx, key, locationTTL).Err()
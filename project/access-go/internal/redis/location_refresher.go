@@ -0,0 +1,132 @@
+package redis
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	sharedRedis "sudooom.im.shared/redis"
+)
+
+const (
+	defaultLocationRefreshInterval = 10 * time.Second
+	defaultLocationRefreshBatch    = 500
+)
+
+// locationKey 待续期的用户位置
+type locationKey struct {
+	userId   int64
+	platform string
+}
+
+// LocationRefresher 用户位置批量续期
+// 心跳只在本地标记位置为待续期，按固定周期合并去重后以 pipeline 批量 EXPIRE，
+// 避免每次心跳一次 Redis 往返。续期最多延迟一个周期，周期远小于位置 TTL；
+// 节点宕机后不再续期，位置在最后一次续期后的 TTL 内自然过期
+type LocationRefresher struct {
+	client    *redis.Client
+	ttl       time.Duration
+	interval  time.Duration
+	batchSize int
+	logger    *slog.Logger
+
+	mu    sync.Mutex
+	dirty map[locationKey]struct{}
+}
+
+// newLocationRefresher 创建位置续期器，interval 不超过 ttl 的 1/4，保证续期延迟不会导致位置过期
+func newLocationRefresher(client *redis.Client, ttl, interval time.Duration, batchSize int, logger *slog.Logger) *LocationRefresher {
+	// 设置默认值
+	if interval <= 0 {
+		interval = defaultLocationRefreshInterval
+	}
+	if interval > ttl/4 {
+		interval = ttl / 4
+	}
+	if batchSize <= 0 {
+		batchSize = defaultLocationRefreshBatch
+	}
+
+	return &LocationRefresher{
+		client:    client,
+		ttl:       ttl,
+		interval:  interval,
+		batchSize: batchSize,
+		logger:    logger,
+		dirty:     make(map[locationKey]struct{}),
+	}
+}
+
+// Mark 标记用户位置待续期，同一周期内重复标记只续期一次
+func (r *LocationRefresher) Mark(userId int64, platform string) {
+	r.mu.Lock()
+	r.dirty[locationKey{userId: userId, platform: platform}] = struct{}{}
+	r.mu.Unlock()
+}
+
+// Pending 返回待续期的位置数
+func (r *LocationRefresher) Pending() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.dirty)
+}
+
+// Start 按周期批量续期（阻塞，应在 goroutine 中调用）
+func (r *LocationRefresher) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	r.logger.Info("Location refresher started",
+		"interval", r.interval,
+		"batch_size", r.batchSize)
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.Info("Location refresher stopped")
+			return
+		case <-ticker.C:
+			r.Flush(ctx)
+		}
+	}
+}
+
+// Flush 续期所有待续期的位置，返回续期条数
+// 已被删除的位置 EXPIRE 不生效，不会被重新创建；失败的批次留到下个周期重试
+func (r *LocationRefresher) Flush(ctx context.Context) int {
+	r.mu.Lock()
+	if len(r.dirty) == 0 {
+		r.mu.Unlock()
+		return 0
+	}
+	keys := make([]locationKey, 0, len(r.dirty))
+	for k := range r.dirty {
+		keys = append(keys, k)
+	}
+	r.dirty = make(map[locationKey]struct{}, len(keys))
+	r.mu.Unlock()
+
+	refreshed := 0
+	for start := 0; start < len(keys); start += r.batchSize {
+		batch := keys[start:min(start+r.batchSize, len(keys))]
+		_, err := r.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, k := range batch {
+				pipe.Expire(ctx, sharedRedis.BuildUserLocationKeyWithPlatform(k.userId, k.platform), r.ttl)
+			}
+			return nil
+		})
+		if err != nil {
+			r.logger.Error("Failed to refresh user locations", "count", len(batch), "error", err)
+			for _, k := range batch {
+				r.Mark(k.userId, k.platform)
+			}
+			continue
+		}
+		refreshed += len(batch)
+	}
+
+	r.logger.Debug("User locations refreshed", "count", refreshed)
+	return refreshed
+}
@@ -0,0 +1,143 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// countingHook 统计命令数与网络往返次数，不访问真实 Redis
+type countingHook struct {
+	commands   atomic.Int64
+	roundTrips atomic.Int64
+	fail       atomic.Bool
+}
+
+func (h *countingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, errors.New("dial disabled in tests")
+	}
+}
+
+func (h *countingHook) ProcessHook(redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		h.commands.Add(1)
+		h.roundTrips.Add(1)
+		return nil
+	}
+}
+
+func (h *countingHook) ProcessPipelineHook(redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		h.roundTrips.Add(1)
+		if h.fail.Load() {
+			return errors.New("pipeline failed")
+		}
+		h.commands.Add(int64(len(cmds)))
+		return nil
+	}
+}
+
+func newCountingClient() (*redis.Client, *countingHook) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:0"})
+	hook := &countingHook{}
+	client.AddHook(hook)
+	return client, hook
+}
+
+// TestLocationRefresherFlush 测试同周期内去重、按批次 pipeline 续期，失败批次留到下个周期
+func TestLocationRefresherFlush(t *testing.T) {
+	client, hook := newCountingClient()
+	defer client.Close()
+	r := newLocationRefresher(client, locationTTL, time.Second, 2, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	ctx := context.Background()
+
+	r.Mark(1, "WEB")
+	r.Mark(1, "WEB")
+	r.Mark(1, "IOS")
+	r.Mark(2, "WEB")
+	if r.Pending() != 3 {
+		t.Fatalf("重复标记应去重，待续期 %d", r.Pending())
+	}
+
+	if n := r.Flush(ctx); n != 3 {
+		t.Fatalf("应续期 3 条，实际 %d", n)
+	}
+	if hook.commands.Load() != 3 || hook.roundTrips.Load() != 2 {
+		t.Fatalf("应以 2 次往返发送 3 条命令，实际 %d 次往返 %d 条命令", hook.roundTrips.Load(), hook.commands.Load())
+	}
+	if r.Flush(ctx) != 0 || hook.roundTrips.Load() != 2 {
+		t.Fatalf("没有待续期位置时不应访问 Redis")
+	}
+
+	hook.fail.Store(true)
+	r.Mark(3, "WEB")
+	if n := r.Flush(ctx); n != 0 || r.Pending() != 1 {
+		t.Fatalf("失败批次应留到下个周期，续期 %d 待续期 %d", n, r.Pending())
+	}
+}
+
+// TestLocationRefresherInterval 测试续期周期不超过 TTL 的 1/4
+func TestLocationRefresherInterval(t *testing.T) {
+	r := newLocationRefresher(nil, locationTTL, time.Hour, 0, slog.Default())
+	if r.interval != locationTTL/4 || r.batchSize != defaultLocationRefreshBatch {
+		t.Fatalf("interval = %v, batchSize = %d", r.interval, r.batchSize)
+	}
+}
+
+// BenchmarkLocationRefresh 对比逐次 EXPIRE 与批量续期的 Redis 往返次数
+// 模拟 5 万连接、30s 心跳间隔的一个心跳周期，按该周期折算每秒命令数与往返次数
+func BenchmarkLocationRefresh(b *testing.B) {
+	const (
+		connections       = 50000
+		heartbeatInterval = 30 * time.Second
+		refreshInterval   = 10 * time.Second
+	)
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	report := func(b *testing.B, hook *countingHook) {
+		seconds := heartbeatInterval.Seconds() * float64(b.N)
+		b.ReportMetric(float64(hook.commands.Load())/seconds, "redis-cmds/s")
+		b.ReportMetric(float64(hook.roundTrips.Load())/seconds, "redis-roundtrips/s")
+	}
+
+	b.Run("PerHeartbeat", func(b *testing.B) {
+		client, hook := newCountingClient()
+		defer client.Close()
+		c := &Client{client: client, logger: logger}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for conn := int64(0); conn < connections; conn++ {
+				_ = c.RefreshUserLocation(ctx, conn, "WEB")
+			}
+		}
+		report(b, hook)
+	})
+
+	b.Run("Batched", func(b *testing.B) {
+		client, hook := newCountingClient()
+		defer client.Close()
+		r := newLocationRefresher(client, locationTTL, refreshInterval, defaultLocationRefreshBatch, logger)
+		// 心跳在周期内均匀到达，每个续期周期刷新其间标记的位置
+		flushes := int64(heartbeatInterval / refreshInterval)
+		perFlush := connections / flushes
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			for f := int64(0); f < flushes; f++ {
+				for conn := f * perFlush; conn < (f+1)*perFlush; conn++ {
+					r.Mark(conn, "WEB")
+				}
+				r.Flush(ctx)
+			}
+		}
+		report(b, hook)
+	})
+}
@@ -0,0 +1,66 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	sharedModel "sudooom.im.shared/model"
+	sharedRedis "sudooom.im.shared/redis"
+)
+
+// StartNodeHeartbeat 注册本节点并按固定间隔心跳（阻塞，应在 goroutine 中调用）
+// Logic 将超过 AccessNodeTTL 未心跳的节点视为宕机，清理其用户位置；connections 返回当前连接数
+func (c *Client) StartNodeHeartbeat(ctx context.Context, connections func() int) {
+	node := sharedModel.AccessNode{
+		NodeId:    c.nodeID,
+		StartedAt: time.Now().UnixMilli(),
+	}
+	heartbeat := func() {
+		node.LastHeartbeat = time.Now().UnixMilli()
+		node.Connections = connections()
+		if err := c.heartbeatNode(ctx, &node); err != nil && ctx.Err() == nil {
+			c.logger.Error("Failed to send node heartbeat", "nodeId", c.nodeID, "error", err)
+		}
+	}
+
+	heartbeat()
+	c.logger.Info("Access node registered", "nodeId", c.nodeID, "interval", sharedRedis.AccessNodeHeartbeatInterval)
+
+	ticker := time.NewTicker(sharedRedis.AccessNodeHeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			heartbeat()
+		}
+	}
+}
+
+// heartbeatNode 写入节点信息并刷新节点索引中的心跳时间
+func (c *Client) heartbeatNode(ctx context.Context, node *sharedModel.AccessNode) error {
+	data, err := json.Marshal(node)
+	if err != nil {
+		return err
+	}
+	_, err = c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, sharedRedis.BuildAccessNodeKey(node.NodeId), data, sharedRedis.AccessNodeTTL)
+		pipe.ZAdd(ctx, sharedRedis.AccessNodesKey, redis.Z{Score: float64(node.LastHeartbeat), Member: node.NodeId})
+		return nil
+	})
+	return err
+}
+
+// DeregisterNode 正常退出时注销本节点
+// 节点在索引中标记为已过期而非直接移除：Logic 随即按宕机流程清理下线处理未完成的残留位置
+func (c *Client) DeregisterNode(ctx context.Context) error {
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, sharedRedis.AccessNodesKey, redis.Z{Score: 0, Member: c.nodeID})
+		pipe.Del(ctx, sharedRedis.BuildAccessNodeKey(c.nodeID))
+		return nil
+	})
+	return err
+}
@@ -126,39 +126,54 @@ func TestParseAuthResponse(t *testing.T) {
 	// 构建一个模拟的成功响应
 	builder := flatbuffers.NewBuilder(256)
 
-	reqIDOffset := builder.CreateString("")
 	msgOffset := builder.CreateString("success")
-
-	im_protocol.ClientResponseStart(builder)
-	im_protocol.ClientResponseAddReqId(builder, reqIDOffset)
-	im_protocol.ClientResponseAddTimestamp(builder, 1234567890)
-	im_protocol.ClientResponseAddCode(builder, im_protocol.ErrorCodeSUCCESS)
-	im_protocol.ClientResponseAddMsg(builder, msgOffset)
-	im_protocol.ClientResponseAddPayloadType(builder, im_protocol.ResponsePayloadNONE)
-	respOffset := im_protocol.ClientResponseEnd(builder)
-
-	builder.Finish(respOffset)
-	respBytes := builder.FinishedBytes()
+	userIDOffset := builder.CreateString("12345")
+	connIDOffset := builder.CreateString("1")
+	resumeTokenOffset := builder.CreateString("resume-token")
+
+	im_protocol.AuthAckStart(builder)
+	im_protocol.AuthAckAddCode(builder, im_protocol.ErrorCodeSUCCESS)
+	im_protocol.AuthAckAddMsg(builder, msgOffset)
+	im_protocol.AuthAckAddUserId(builder, userIDOffset)
+	im_protocol.AuthAckAddConnId(builder, connIDOffset)
+	im_protocol.AuthAckAddServerTime(builder, 1234567890)
+	im_protocol.AuthAckAddHeartbeatIntervalMs(builder, 30000)
+	im_protocol.AuthAckAddResumeToken(builder, resumeTokenOffset)
+	im_protocol.AuthAckAddResumed(builder, true)
+	im_protocol.AuthAckAddSeq(builder, 42)
+	ackOffset := im_protocol.AuthAckEnd(builder)
+
+	builder.Finish(ackOffset)
+	ackBytes := builder.FinishedBytes()
 
 	// 解析响应
-	response := im_protocol.GetRootAsClientResponse(respBytes, 0)
+	ack := im_protocol.GetRootAsAuthAck(ackBytes, 0)
 
 	// 验证响应
-	if response.Code() != im_protocol.ErrorCodeSUCCESS {
+	if ack.Code() != im_protocol.ErrorCodeSUCCESS {
 		t.Errorf("错误码不匹配，期望: %s, 实际: %s",
-			im_protocol.ErrorCodeSUCCESS.String(), response.Code().String())
+			im_protocol.ErrorCodeSUCCESS.String(), ack.Code().String())
+	}
+
+	if string(ack.Msg()) != "success" {
+		t.Errorf("消息不匹配，期望: success, 实际: %s", string(ack.Msg()))
+	}
+
+	if string(ack.UserId()) != "12345" || string(ack.ConnId()) != "1" {
+		t.Errorf("用户/连接 ID 不匹配: %s/%s", string(ack.UserId()), string(ack.ConnId()))
 	}
 
-	if string(response.Msg()) != "success" {
-		t.Errorf("消息不匹配，期望: success, 实际: %s", string(response.Msg()))
+	if ack.HeartbeatIntervalMs() != 30000 {
+		t.Errorf("心跳间隔不匹配，期望: 30000, 实际: %d", ack.HeartbeatIntervalMs())
 	}
 
-	if response.PayloadType() != im_protocol.ResponsePayloadNONE {
-		t.Errorf("payload 类型不匹配")
+	if string(ack.ResumeToken()) != "resume-token" || !ack.Resumed() || ack.Seq() != 42 {
+		t.Errorf("会话恢复字段不匹配: token=%s resumed=%v seq=%d",
+			string(ack.ResumeToken()), ack.Resumed(), ack.Seq())
 	}
 
 	t.Logf("成功解析认证响应，错误码: %s, 消息: %s",
-		response.Code().String(), string(response.Msg()))
+		ack.Code().String(), string(ack.Msg()))
 }
 
 // mockStream 用于测试的模拟流
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// originChecker 返回 WebTransport/WebSocket 升级请求的 Origin 校验函数
+// 不携带 Origin 的请求（原生客户端）放行；配置 allowed 时只允许列表中的 Origin（"*" 允许全部），
+// 未配置时只允许与请求 Host 同源，防止任意网页借用户浏览器跨站建立连接
+// WebTransport 未配置时不校验，见 webTransportOriginChecker
+func originChecker(allowed []string) func(r *http.Request) bool {
+	origins := make(map[string]struct{}, len(allowed))
+	for _, origin := range allowed {
+		origin = strings.ToLower(strings.TrimRight(strings.TrimSpace(origin), "/"))
+		if origin == "*" {
+			return func(*http.Request) bool { return true }
+		}
+		if origin != "" {
+			origins[origin] = struct{}{}
+		}
+	}
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		if len(origins) == 0 {
+			u, err := url.Parse(origin)
+			return err == nil && strings.EqualFold(u.Host, r.Host)
+		}
+		_, ok := origins[strings.ToLower(origin)]
+		return ok
+	}
+}
+
+// webTransportOriginChecker WebTransport 的 Origin 校验：未配置 allowed 时保持原有行为放行全部，
+// 配置后与 WebSocket 使用同一白名单
+func webTransportOriginChecker(allowed []string) func(r *http.Request) bool {
+	if len(allowed) == 0 {
+		return func(*http.Request) bool { return true }
+	}
+	return originChecker(allowed)
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+// TestOriginChecker 只允许配置的 Origin，未配置时只允许同源，原生客户端不携带 Origin 放行
+func TestOriginChecker(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		want    bool
+	}{
+		{"原生客户端", []string{"https://im.example.com"}, "", true},
+		{"允许的 Origin", []string{"https://im.example.com/"}, "https://IM.example.com", true},
+		{"其他 Origin", []string{"https://im.example.com"}, "https://evil.example.com", false},
+		{"协议不同", []string{"https://im.example.com"}, "http://im.example.com", false},
+		{"允许全部", []string{"*"}, "https://evil.example.com", true},
+		{"未配置同源", nil, "https://access.example.com:8082", true},
+		{"未配置跨域", nil, "https://evil.example.com", false},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "https://access.example.com:8082/ws", nil)
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		if got := originChecker(tt.allowed)(r); got != tt.want {
+			t.Errorf("%s: originChecker(%v)(%q) = %v, want %v", tt.name, tt.allowed, tt.origin, got, tt.want)
+		}
+	}
+}
+
+// TestWebTransportOriginChecker 未配置白名单时 WebTransport 保持放行全部，配置后按白名单校验
+func TestWebTransportOriginChecker(t *testing.T) {
+	r := httptest.NewRequest("GET", "https://access.example.com:8081/webtransport", nil)
+	r.Header.Set("Origin", "https://evil.example.com")
+
+	if !webTransportOriginChecker(nil)(r) {
+		t.Errorf("未配置白名单时应放行跨域 Origin")
+	}
+	if webTransportOriginChecker([]string{"https://im.example.com"})(r) {
+		t.Errorf("配置白名单后应拒绝其他 Origin")
+	}
+}
@@ -4,35 +4,50 @@ import (
 	"context"
 	"crypto/tls"
 	"log/slog"
+	"net"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/quic-go/quic-go"
 	"github.com/quic-go/quic-go/http3"
 	"github.com/quic-go/webtransport-go"
+	"sudooom.im.access/internal/admin"
 	"sudooom.im.access/internal/config"
 	"sudooom.im.access/internal/connection"
 	"sudooom.im.access/internal/handler"
-	"sudooom.im.access/internal/nats"
 	"sudooom.im.access/internal/redis"
 	"sudooom.im.access/internal/workerpool"
+	"sudooom.im.shared/bus"
 	sharedNats "sudooom.im.shared/nats"
+	"sudooom.im.shared/proto"
 )
 
+// quicConnKey 请求上下文中 QUIC 连接的键
+type quicConnKey struct{}
+
 type Server struct {
 	cfg              *config.Config
-	natsClient       *nats.Client
+	msgBus           bus.Bus
+	subscriptions    []bus.Subscription
 	redisClient      *redis.Client
 	logger           *slog.Logger
 	connMgr          *connection.Manager
+	resumeStore      *connection.ResumeStore
+	backpressure     *connection.Backpressure
+	admission        *connection.Admission
+	authTimeout      time.Duration
 	handler          *handler.Handler
 	wtServer         *webtransport.Server
+	wsServer         *http.Server
+	tcpListener      net.Listener
 	heartbeatChecker *connection.HeartbeatChecker
+	adminServer      *admin.Server
 	workerPool       *workerpool.Pool
 	wg               sync.WaitGroup
 }
 
-func New(cfg *config.Config, natsClient *nats.Client, redisClient *redis.Client, logger *slog.Logger) *Server {
+func New(cfg *config.Config, msgBus bus.Bus, redisClient *redis.Client, logger *slog.Logger) *Server {
 	connMgr := connection.NewManager()
 
 	// 设置 Worker Pool 默认值
@@ -48,19 +63,74 @@ func New(cfg *config.Config, natsClient *nats.Client, redisClient *redis.Client,
 	// 创建 Worker Pool
 	workerPool := workerpool.New(workerPoolSize, workerQueueSize, logger)
 
-	handler := handler.NewHandler(connMgr, natsClient, redisClient, cfg.Server.NodeID, logger, workerPool)
+	// 下发给客户端的心跳间隔，默认为超时时间的 1/3
+	heartbeatInterval := cfg.Server.HeartbeatInterval
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = 30 * time.Second
+		if cfg.Server.HeartbeatTimeout > 0 {
+			heartbeatInterval = cfg.Server.HeartbeatTimeout / 3
+		}
+	}
+
+	// 可恢复会话存储
+	resumeStore := connection.NewResumeStore(cfg.Server.ResumeBufferSize, cfg.Server.ResumeGracePeriod, logger)
+
+	// 写队列背压策略
+	backpressure := connection.NewBackpressure(cfg.Backpressure.QueueSize, map[connection.PayloadClass]connection.SendPolicy{
+		connection.ClassControl: sendPolicy(cfg.Backpressure.Control),
+		connection.ClassChat:    sendPolicy(cfg.Backpressure.Chat),
+		connection.ClassRoom:    sendPolicy(cfg.Backpressure.Room),
+		connection.ClassGame:    sendPolicy(cfg.Backpressure.Game),
+	})
+
+	// 首包认证期限
+	authTimeout := cfg.Server.AuthTimeout
+	if authTimeout <= 0 {
+		authTimeout = 10 * time.Second
+	}
+
+	// 请求限流
+	rateLimiter := connection.NewRateLimiter(map[string]connection.RateLimit{
+		"chat": rateLimit(cfg.RateLimit.Chat),
+		"room": rateLimit(cfg.RateLimit.Room),
+		"game": rateLimit(cfg.RateLimit.Game),
+	}, cfg.RateLimit.MaxViolations, cfg.RateLimit.ViolationWindow)
+
+	handler := handler.NewHandler(connMgr, resumeStore, rateLimiter, msgBus, redisClient, cfg.Server.NodeID, heartbeatInterval, cfg.Server.MaxFrameSize, logger, workerPool)
 
 	return &Server{
-		cfg:         cfg,
-		natsClient:  natsClient,
-		redisClient: redisClient,
-		logger:      logger,
-		connMgr:     connMgr,
-		handler:     handler,
-		workerPool:  workerPool,
+		cfg:          cfg,
+		msgBus:       msgBus,
+		redisClient:  redisClient,
+		logger:       logger,
+		connMgr:      connMgr,
+		resumeStore:  resumeStore,
+		backpressure: backpressure,
+		admission:    connection.NewAdmission(cfg.Server.MaxConnections, cfg.Server.MaxConnectionsPerIP),
+		authTimeout:  authTimeout,
+		handler:      handler,
+		workerPool:   workerPool,
+		heartbeatChecker: connection.NewHeartbeatChecker(
+			cfg.Server.HeartbeatTimeout,
+			cfg.Server.HeartbeatCheckInterval,
+			logger,
+		),
 	}
 }
 
+// sendPolicy 将配置转换为连接溢出策略
+func sendPolicy(cfg config.OverflowPolicyConfig) connection.SendPolicy {
+	return connection.SendPolicy{
+		Overflow: connection.OverflowPolicy(cfg.Policy),
+		Timeout:  cfg.Timeout,
+	}
+}
+
+// rateLimit 将配置转换为令牌桶参数
+func rateLimit(cfg config.TokenBucketConfig) connection.RateLimit {
+	return connection.RateLimit{Rate: cfg.Rate, Burst: cfg.Burst}
+}
+
 func (s *Server) Start(ctx context.Context) error {
 	tlsConfig, err := s.loadTLSConfig()
 	if err != nil {
@@ -82,11 +152,12 @@ func (s *Server) Start(ctx context.Context) error {
 			Addr:       s.cfg.Server.Addr,
 			TLSConfig:  tlsConfig,
 			QUICConfig: quicConfig,
+			// 将 QUIC 连接放入请求上下文，会话建立后用于读取 RTT 等连接统计
+			ConnContext: func(ctx context.Context, c *quic.Conn) context.Context {
+				return context.WithValue(ctx, quicConnKey{}, c)
+			},
 		},
-		CheckOrigin: func(r *http.Request) bool {
-			// TODO: 生产环境应该检查 Origin
-			return true
-		},
+		CheckOrigin: webTransportOriginChecker(s.cfg.Server.AllowedOrigins),
 	}
 
 	// 设置 HTTP 路由
@@ -97,52 +168,88 @@ func (s *Server) Start(ctx context.Context) error {
 			s.logger.Error("WebTransport upgrade failed", "error", err)
 			return
 		}
+		quicConn, _ := r.Context().Value(quicConnKey{}).(*quic.Conn)
 		s.wg.Add(1)
-		go s.handleSession(ctx, session)
+		go s.handleSession(ctx, connection.NewWebTransportSession(session, quicConn), connection.TransportWebTransport)
 	})
 
 	s.wtServer.H3.Handler = mux
 
-	// 订阅 NATS 下行消息
-	s.subscribeDownstream()
-
-	//启动心跳检测器（暂时关闭用于调试）
-	s.heartbeatChecker = connection.NewHeartbeatChecker(
-		s.connMgr,
-		s.cfg.Server.HeartbeatTimeout,
-		s.cfg.Server.HeartbeatCheckInterval,
-		s.logger,
-		func(conn *connection.Connection) {
-			// 超时回调：清理用户位置并通知 Logic
-			if conn.UserID() > 0 {
-				err := s.redisClient.UnregisterUserLocation(ctx, conn.UserID(), conn.Platform())
-				if err != nil {
-					s.logger.Error("UnregisterUserLocation", "error", err)
-				}
-				s.handler.SendUserOfflineToLogic(conn)
-			}
-		},
-	)
+	// 订阅下行消息
+	if err := s.subscribeDownstream(); err != nil {
+		return err
+	}
+
+	// 注册本节点并定期心跳，节点宕机后由 Logic 清理其用户位置
+	go s.redisClient.StartNodeHeartbeat(ctx, s.connMgr.Count)
+
+	// 启动用户位置批量续期
+	go s.redisClient.LocationRefresher().Start(ctx)
+
+	// 启动心跳检测器：只关闭超时连接，下线处理由会话清理统一执行
 	go s.heartbeatChecker.Start(ctx)
 
+	// 启动可恢复会话清理：宽限期内未重连的会话执行被推迟的下线处理
+	go s.resumeStore.Start(ctx, func(rs *connection.ResumeSession) {
+		s.handler.ReleaseSession(ctx, rs.UserID(), rs.Platform(), rs.ConnID())
+	})
+
+	// 定期输出写队列背压计数
+	go s.reportBackpressure(ctx)
+
+	// 启动管理接口
+	if s.cfg.Admin.Addr != "" {
+		s.adminServer = admin.New(s.cfg.Admin, s.connMgr, s.handler, s.backpressure, s.logger)
+		if err := s.adminServer.Start(); err != nil {
+			return err
+		}
+	}
+
+	// 启动 WebSocket 降级传输
+	if s.cfg.Server.WSAddr != "" {
+		if err := s.startWebSocket(ctx, tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	// 启动 TCP/TLS 长连接
+	if s.cfg.Server.TCPAddr != "" {
+		if err := s.startTCP(ctx, tlsConfig); err != nil {
+			return err
+		}
+	}
+
 	s.logger.Info("WebTransport server starting", "addr", s.cfg.Server.Addr)
 
 	// 启动服务器
 	return s.wtServer.ListenAndServe()
 }
 
-func (s *Server) handleSession(ctx context.Context, session *webtransport.Session) {
+func (s *Server) handleSession(ctx context.Context, session connection.Session, transport string) {
 	defer s.wg.Done()
 
-	c := connection.NewFromWebTransport(session, s.logger)
+	// 准入控制：超过全局或单 IP 上限时直接拒绝，客户端收到关闭码后退避重试其他节点
+	ip := connection.RemoteIP(session.RemoteAddr())
+	if err := s.admission.Acquire(ip); err != nil {
+		s.logger.Warn("Session rejected", "remote_ip", ip, "transport", transport, "reason", err)
+		if err := session.CloseWithError(connection.CloseCodeOverloaded, err.Error()); err != nil {
+			s.logger.Error("Failed to close session", "error", err)
+		}
+		return
+	}
+	defer s.admission.Release(ip)
+
+	c := connection.New(session, transport, s.backpressure, s.logger)
 	s.connMgr.Add(c)
 	defer func() {
-		// 连接关闭时清理用户位置
-		if c.UserID() > 0 {
-			err := s.redisClient.UnregisterUserLocation(ctx, c.UserID(), c.Platform())
-			if err != nil {
+		// 流结束后关闭会话（WebSocket 不会自行超时关闭）
+		c.Close()
+		s.heartbeatChecker.Remove(c.ID())
+		// 连接关闭时清理用户位置，这是连接下线处理的唯一入口（心跳超时也只关闭连接）
+		// 会话进入恢复宽限期时保留位置，下线处理推迟到会话过期；慢消费者被断开时不保留会话，立即下线
+		if c.UserID() > 0 && !s.holdSession(c) {
+			if err := s.redisClient.UnregisterUserLocation(ctx, c.UserID(), c.Platform(), c.ID()); err != nil {
 				s.logger.Error("Failed to unregister user location", "error", err)
-				return
 			}
 			s.handler.SendUserOfflineToLogic(c)
 		}
@@ -151,24 +258,35 @@ func (s *Server) handleSession(ctx context.Context, session *webtransport.Sessio
 
 	// New session
 
+	// 首包认证期限：超时关闭会话，阻塞中的 AcceptStream / 读取随之返回
+	authTimer := time.AfterFunc(s.authTimeout, func() {
+		s.logger.Warn("Auth timeout, closing session", "conn_id", c.ID(), "remote_ip", ip)
+		c.CloseWithError(connection.CloseCodeAuthTimeout, "auth timeout")
+	})
+
 	// 首个 stream 必须是认证请求
 	firstStream, err := session.AcceptStream(ctx)
 	if err != nil {
 		// Session closed before auth
+		authTimer.Stop()
 		return
 	}
 
 	// 处理首包认证
-	if err := s.handler.HandleFirstStream(ctx, c, firstStream); err != nil {
+	err = s.handler.HandleFirstStream(ctx, c, firstStream)
+	if !authTimer.Stop() {
+		// 认证期间已超时，连接已关闭
+		return
+	}
+	if err != nil {
 		s.logger.Warn("Auth failed, closing session", "conn_id", c.ID(), "error", err)
-		err := session.CloseWithError(4001, "auth failed")
-		if err != nil {
-			s.logger.Error("Failed to close session", "conn_id", c.ID(), "error", err)
-			return
-		}
+		c.CloseWithError(connection.CloseCodeAuthFailed, "auth failed")
 		return
 	}
 
+	// 认证成功后开始心跳检测
+	s.heartbeatChecker.Add(c)
+
 	// 认证成功后，同步处理首个流（阻塞直到流关闭）
 	// 客户端只会使用这一个双向流进行所有通信
 	// Auth successful, process stream
@@ -179,20 +297,64 @@ func (s *Server) handleSession(ctx context.Context, session *webtransport.Sessio
 
 }
 
-func (s *Server) subscribeDownstream() {
+// holdSession 连接断开时尝试让会话进入恢复宽限期，返回 true 表示下线处理被推迟
+// 慢消费者与被踢下线的连接不保留会话，立即下线
+func (s *Server) holdSession(c *connection.Connection) bool {
+	switch c.CloseCode() {
+	case connection.CloseCodeSlowConsumer, connection.CloseCodeKicked:
+		s.resumeStore.Remove(c.UserID(), c.Platform(), c.ID())
+		return false
+	}
+	return s.resumeStore.Detach(c.UserID(), c.Platform(), c.ID())
+}
+
+// reportBackpressure 每分钟输出一次写队列背压计数（仅在出现丢弃、超时或断开时）
+func (s *Server) reportBackpressure(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, st := range s.backpressure.Stats() {
+				if st.Dropped == 0 && st.Timeouts == 0 && st.Disconnects == 0 {
+					continue
+				}
+				s.logger.Warn("Write queue backpressure",
+					"class", st.Class,
+					"enqueued", st.Enqueued,
+					"dropped", st.Dropped,
+					"timeouts", st.Timeouts,
+					"disconnects", st.Disconnects)
+			}
+		}
+	}
+}
+
+// subscribeDownstream 订阅本节点下行消息与广播
+func (s *Server) subscribeDownstream() error {
 	nodeID := s.getNodeID()
 	subject := sharedNats.BuildAccessDownstreamSubject(nodeID)
 
-	s.natsClient.Subscribe(subject, func(data []byte) {
-		s.handler.HandleDownstream(data)
+	sub, err := s.msgBus.Subscribe(subject, func(msg *bus.Msg) {
+		s.handler.HandleDownstream(msg.Data)
 	})
+	if err != nil {
+		return err
+	}
+	s.subscriptions = append(s.subscriptions, sub)
 
 	// 订阅广播
-	s.natsClient.Subscribe(sharedNats.SubjectAccessBroadcast, func(data []byte) {
-		s.handler.HandleDownstream(data)
+	sub, err = s.msgBus.Subscribe(sharedNats.SubjectAccessBroadcast, func(msg *bus.Msg) {
+		s.handler.HandleBroadcast(msg.Data)
 	})
-
-	// Subscribed to downstream
+	if err != nil {
+		return err
+	}
+	s.subscriptions = append(s.subscriptions, sub)
+	return nil
 }
 
 func (s *Server) getNodeID() string {
@@ -223,22 +385,50 @@ func (s *Server) loadTLSConfig() (*tls.Config, error) {
 	return generateSelfSignedTLSConfig()
 }
 
+// SetCodec 设置发往消息总线的编码
+func (s *Server) SetCodec(codec proto.Codec) {
+	s.handler.SetCodec(codec)
+}
+
 // ConnManager 返回连接管理器
 func (s *Server) ConnManager() *connection.Manager {
 	return s.connMgr
 }
 
 func (s *Server) Shutdown() {
-	// 先关闭 WebTransport Server，停止接收新连接
+	// 先关闭 WebTransport / WebSocket / TCP 监听，停止接收新连接
 	if s.wtServer != nil {
 		s.wtServer.Close()
 	}
+	if s.wsServer != nil {
+		s.wsServer.Close()
+	}
+	if s.tcpListener != nil {
+		s.tcpListener.Close()
+	}
+	if s.adminServer != nil {
+		s.adminServer.Close()
+	}
 
-	// 关闭 Worker Pool，等待所有消息处理完成
+	// 停止接收下行消息
+	for _, sub := range s.subscriptions {
+		if err := sub.Unsubscribe(); err != nil {
+			s.logger.Warn("Failed to unsubscribe downstream", "error", err)
+		}
+	}
+
+	// 关闭 Worker Pool，等待执行中的消息处理完成
 	if s.workerPool != nil {
 		s.workerPool.Shutdown()
 	}
 
 	// 等待所有会话处理完成
 	s.wg.Wait()
+
+	// 注销节点
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if err := s.redisClient.DeregisterNode(ctx); err != nil {
+		s.logger.Error("Failed to deregister node", "error", err)
+	}
 }
@@ -27,8 +27,9 @@ import (
 )
 
 const (
-	frameHeaderSize = 5
-	frameTypeAuth   = byte(1)
+	frameHeaderSize  = 5
+	frameTypeAuth    = byte(1)
+	frameTypeAuthAck = byte(3)
 )
 
 // TestWebTransportAuth 测试 WebTransport 认证流程
@@ -116,7 +117,7 @@ func TestWebTransportAuth(t *testing.T) {
 	t.Logf("认证请求已发送")
 
 	// 7. 读取认证响应
-	response, err := readResponse(stream)
+	response, err := readAuthAck(stream)
 	if err != nil {
 		t.Fatalf("读取认证响应失败: %v", err)
 	}
@@ -128,7 +129,14 @@ func TestWebTransportAuth(t *testing.T) {
 			string(response.Msg()))
 	}
 
-	t.Logf("认证成功！错误码: %s", response.Code().String())
+	if len(response.ResumeToken()) == 0 {
+		t.Errorf("AuthAck 缺少恢复令牌")
+	}
+	if response.HeartbeatIntervalMs() <= 0 {
+		t.Errorf("AuthAck 心跳间隔不正确: %d", response.HeartbeatIntervalMs())
+	}
+
+	t.Logf("认证成功！错误码: %s, connId: %s", response.Code().String(), string(response.ConnId()))
 
 	// 9. 验证用户位置已在 Redis 中注册
 	time.Sleep(100 * time.Millisecond) // 等待异步操作完成
@@ -194,7 +202,7 @@ func TestWebTransportAuthFail(t *testing.T) {
 	}
 
 	// 读取响应
-	response, err := readResponse(stream)
+	response, err := readAuthAck(stream)
 	if err != nil {
 		t.Fatalf("读取认证响应失败: %v", err)
 	}
@@ -301,9 +309,10 @@ func setTestUserToken(ctx context.Context, redisClient *redis.Client, userID int
 // cleanupTestData 清理测试数据
 func cleanupTestData(ctx context.Context, t *testing.T, redisClient *redis.Client, userID int64, platform, token string) {
 	// 移除用户位置
-	err := redisClient.UnregisterUserLocation(ctx, userID, platform)
-	if err != nil {
-		t.Logf("清理用户位置失败: %v", err)
+	if location, err := redisClient.GetUserLocation(ctx, userID, platform); err == nil && location != nil {
+		if err := redisClient.UnregisterUserLocation(ctx, userID, platform, location.ConnId); err != nil {
+			t.Logf("清理用户位置失败: %v", err)
+		}
 	}
 
 	// 清理 token 相关数据
@@ -315,7 +324,7 @@ func cleanupTestData(ctx context.Context, t *testing.T, redisClient *redis.Clien
 
 	// 删除 token:info:{token}
 	tokenKey := "token:info:" + token
-	err = underlyingClient.Del(ctx, tokenKey).Err()
+	err := underlyingClient.Del(ctx, tokenKey).Err()
 	if err != nil {
 		t.Logf("清理 token info 失败: %v", err)
 	}
@@ -359,8 +368,8 @@ func sendAuthFrame(stream *webtransport.Stream, authReq []byte) error {
 	return err
 }
 
-// readResponse 读取响应
-func readResponse(stream *webtransport.Stream) (*im_protocol.ClientResponse, error) {
+// readAuthAck 读取认证响应（AuthAck 帧）
+func readAuthAck(stream *webtransport.Stream) (*im_protocol.AuthAck, error) {
 	// 读取帧头
 	header := make([]byte, frameHeaderSize)
 	if _, err := io.ReadFull(stream, header); err != nil {
@@ -368,7 +377,9 @@ func readResponse(stream *webtransport.Stream) (*im_protocol.ClientResponse, err
 	}
 
 	length := binary.BigEndian.Uint32(header[:4])
-	// frameType := header[4] // 响应帧类型
+	if frameType := header[4]; frameType != frameTypeAuthAck {
+		return nil, fmt.Errorf("unexpected frame type: %d", frameType)
+	}
 
 	// 读取消息体
 	body := make([]byte, length)
@@ -376,7 +387,6 @@ func readResponse(stream *webtransport.Stream) (*im_protocol.ClientResponse, err
 		return nil, err
 	}
 
-	// 解析 ClientResponse
-	response := im_protocol.GetRootAsClientResponse(body, 0)
-	return response, nil
+	// 解析 AuthAck
+	return im_protocol.GetRootAsAuthAck(body, 0), nil
 }
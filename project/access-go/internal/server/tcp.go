@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"time"
+
+	"sudooom.im.access/internal/connection"
+)
+
+// Accept 连续失败时的退避时间（与 net/http.Server.Serve 一致），避免文件描述符耗尽等错误下空转
+const (
+	acceptBackoffMin = 5 * time.Millisecond
+	acceptBackoffMax = time.Second
+)
+
+// startTCP 启动 TCP/TLS 长连接监听（原生移动端 SDK，帧协议与 WebTransport 一致）
+func (s *Server) startTCP(ctx context.Context, tlsConfig *tls.Config) error {
+	tcpTLSConfig := tlsConfig.Clone()
+	tcpTLSConfig.NextProtos = nil
+
+	listener, err := tls.Listen("tcp", s.cfg.Server.TCPAddr, tcpTLSConfig)
+	if err != nil {
+		return err
+	}
+	s.tcpListener = listener
+
+	go func() {
+		var backoff time.Duration
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				if errors.Is(err, net.ErrClosed) {
+					return
+				}
+				backoff = nextAcceptBackoff(backoff)
+				s.logger.Error("TCP accept failed", "error", err, "retryIn", backoff)
+				select {
+				case <-time.After(backoff):
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+			backoff = 0
+			s.wg.Add(1)
+			go s.handleSession(ctx, connection.NewTCPSession(conn), connection.TransportTCP)
+		}
+	}()
+
+	s.logger.Info("TCP server starting", "addr", s.cfg.Server.TCPAddr)
+	return nil
+}
+
+// nextAcceptBackoff 返回下一次 Accept 重试前的等待时间：从 acceptBackoffMin 开始翻倍，最大 acceptBackoffMax
+func nextAcceptBackoff(backoff time.Duration) time.Duration {
+	if backoff == 0 {
+		return acceptBackoffMin
+	}
+	return min(backoff*2, acceptBackoffMax)
+}
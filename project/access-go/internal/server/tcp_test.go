@@ -0,0 +1,23 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+// TestNextAcceptBackoff Accept 失败后的退避从 5ms 开始翻倍，最大 1s
+func TestNextAcceptBackoff(t *testing.T) {
+	want := []time.Duration{
+		5 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond,
+		80 * time.Millisecond, 160 * time.Millisecond, 320 * time.Millisecond, 640 * time.Millisecond,
+		time.Second, time.Second,
+	}
+
+	var backoff time.Duration
+	for i, w := range want {
+		backoff = nextAcceptBackoff(backoff)
+		if backoff != w {
+			t.Fatalf("第 %d 次退避 = %v, want %v", i+1, backoff, w)
+		}
+	}
+}
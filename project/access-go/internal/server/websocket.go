@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	"sudooom.im.access/internal/connection"
+)
+
+// defaultWSPath WebSocket 默认路径
+const defaultWSPath = "/ws"
+
+// startWebSocket 启动 WebSocket 降级监听（TLS over TCP，帧协议与 WebTransport 一致）
+func (s *Server) startWebSocket(ctx context.Context, tlsConfig *tls.Config) error {
+	path := s.cfg.Server.WSPath
+	if path == "" {
+		path = defaultWSPath
+	}
+
+	upgrader := websocket.Upgrader{
+		ReadBufferSize:  4096,
+		WriteBufferSize: 4096,
+		CheckOrigin:     originChecker(s.cfg.Server.AllowedOrigins),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		wsConn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			s.logger.Error("WebSocket upgrade failed", "error", err)
+			return
+		}
+		s.wg.Add(1)
+		go s.handleSession(ctx, connection.NewWebSocketSession(wsConn), connection.TransportWebSocket)
+	})
+
+	// HTTP/3 的 ALPN 不适用于 TCP，WebSocket 使用 http/1.1
+	wsTLSConfig := tlsConfig.Clone()
+	wsTLSConfig.NextProtos = []string{"http/1.1"}
+
+	listener, err := tls.Listen("tcp", s.cfg.Server.WSAddr, wsTLSConfig)
+	if err != nil {
+		return err
+	}
+
+	s.wsServer = &http.Server{Handler: mux}
+
+	go func() {
+		if err := s.wsServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("WebSocket server failed", "error", err)
+		}
+	}()
+
+	s.logger.Info("WebSocket server starting", "addr", s.cfg.Server.WSAddr, "path", path)
+	return nil
+}
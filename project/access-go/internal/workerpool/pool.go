@@ -9,10 +9,15 @@ import (
 // Task 定义任务函数类型
 type Task func()
 
+// minShardQueueSize 有序任务分片队列的最小长度
+const minShardQueueSize = 64
+
 // Pool Worker Pool 实现
+// 无序任务进入共享队列，由任意 worker 执行；有序任务按 key 分片，每个分片由固定的 worker 串行执行
 type Pool struct {
 	workers   int
 	taskQueue chan Task
+	shards    []chan Task // 有序任务分片，shards[i] 只由 worker i 消费
 	wg        sync.WaitGroup
 	ctx       context.Context
 	cancel    context.CancelFunc
@@ -25,13 +30,23 @@ type Pool struct {
 func New(workers int, queueSize int, logger *slog.Logger) *Pool {
 	ctx, cancel := context.WithCancel(context.Background())
 
+	// 分片队列总长度与共享队列一致
+	shardQueueSize := queueSize / workers
+	if shardQueueSize < minShardQueueSize {
+		shardQueueSize = minShardQueueSize
+	}
+
 	pool := &Pool{
 		workers:   workers,
 		taskQueue: make(chan Task, queueSize),
+		shards:    make([]chan Task, workers),
 		ctx:       ctx,
 		cancel:    cancel,
 		logger:    logger,
 	}
+	for i := range pool.shards {
+		pool.shards[i] = make(chan Task, shardQueueSize)
+	}
 
 	// 启动 workers
 	for i := 0; i < workers; i++ {
@@ -50,35 +65,38 @@ func New(workers int, queueSize int, logger *slog.Logger) *Pool {
 func (p *Pool) worker(id int) {
 	defer p.wg.Done()
 
+	shard := p.shards[id]
 	for {
+		var task Task
 		select {
 		case <-p.ctx.Done():
 			// Worker pool shutting down
 			return
-		case task, ok := <-p.taskQueue:
-			if !ok {
-				// Task queue closed
-				return
-			}
-
-			// 执行任务，捕获 panic
-			func() {
-				defer func() {
-					if r := recover(); r != nil {
-						p.logger.Error("Task panic recovered",
-							"worker_id", id,
-							"panic", r)
-					}
-				}()
-				task()
-			}()
+		case task = <-shard:
+		case task = <-p.taskQueue:
 		}
+		p.run(id, task)
 	}
 }
 
+// run 执行任务，捕获 panic
+func (p *Pool) run(id int, task Task) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.logger.Error("Task panic recovered",
+				"worker_id", id,
+				"panic", r)
+		}
+	}()
+	task()
+}
+
 // Submit 提交任务到 Worker Pool
 // 如果队列满了，会阻塞直到有空位或 context 被取消
 func (p *Pool) Submit(task Task) bool {
+	if p.ctx.Err() != nil {
+		return false
+	}
 	select {
 	case <-p.ctx.Done():
 		return false
@@ -87,8 +105,26 @@ func (p *Pool) Submit(task Task) bool {
 	}
 }
 
+// SubmitOrdered 按 key 提交有序任务：相同 key 的任务按提交顺序串行执行，不同 key 之间并行
+// 用于保证同一连接的请求按到达顺序处理；分片队列满时阻塞直到有空位或 context 被取消
+func (p *Pool) SubmitOrdered(key int64, task Task) bool {
+	if p.ctx.Err() != nil {
+		return false
+	}
+	shard := p.shards[uint64(key)%uint64(len(p.shards))]
+	select {
+	case <-p.ctx.Done():
+		return false
+	case shard <- task:
+		return true
+	}
+}
+
 // TrySubmit 尝试提交任务，如果队列满了立即返回 false
 func (p *Pool) TrySubmit(task Task) bool {
+	if p.ctx.Err() != nil {
+		return false
+	}
 	select {
 	case <-p.ctx.Done():
 		return false
@@ -100,11 +136,10 @@ func (p *Pool) TrySubmit(task Task) bool {
 	}
 }
 
-// Shutdown 优雅关闭 Worker Pool
-// 等待所有任务完成
+// Shutdown 关闭 Worker Pool：不再接受新任务，等待执行中的任务结束，队列中尚未执行的任务被丢弃
+// 不关闭任务队列，worker 通过 context 退出，避免与并发的 Submit 竞争向已关闭的 channel 发送
 func (p *Pool) Shutdown() {
 	p.cancel()
-	close(p.taskQueue)
 	p.wg.Wait()
 	p.logger.Info("Worker pool shutdown completed")
 }
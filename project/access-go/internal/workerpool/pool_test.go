@@ -0,0 +1,136 @@
+package workerpool
+
+import (
+	"crypto/sha256"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+func newTestPool(workers, queueSize int) *Pool {
+	return New(workers, queueSize, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// TestSubmitOrdered 测试相同 key 的任务按提交顺序执行
+func TestSubmitOrdered(t *testing.T) {
+	const keys, tasksPerKey = 50, 200
+
+	pool := newTestPool(8, 1000)
+	defer pool.Shutdown()
+
+	var mu sync.Mutex
+	got := make(map[int64][]int, keys)
+	var wg sync.WaitGroup
+	wg.Add(keys * tasksPerKey)
+
+	// 每个 key 由独立的 goroutine 提交，模拟多个连接的读循环
+	var submitters sync.WaitGroup
+	for key := int64(1); key <= keys; key++ {
+		submitters.Add(1)
+		go func() {
+			defer submitters.Done()
+			for i := 0; i < tasksPerKey; i++ {
+				pool.SubmitOrdered(key, func() {
+					defer wg.Done()
+					mu.Lock()
+					got[key] = append(got[key], i)
+					mu.Unlock()
+				})
+			}
+		}()
+	}
+	submitters.Wait()
+	wg.Wait()
+
+	for key, seq := range got {
+		for i, v := range seq {
+			if v != i {
+				t.Fatalf("key %d 第 %d 个任务乱序: %v", key, i, seq[:i+1])
+			}
+		}
+	}
+}
+
+// TestSubmitDuringShutdown 测试关闭过程中并发提交不会 panic，关闭后提交返回 false
+func TestSubmitDuringShutdown(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		pool := newTestPool(4, 16)
+
+		// 模拟仍在运行的连接读循环持续提交任务
+		var submitters sync.WaitGroup
+		for key := int64(1); key <= 8; key++ {
+			submitters.Add(1)
+			go func() {
+				defer submitters.Done()
+				for i := 0; ; i++ {
+					var ok bool
+					switch i % 3 {
+					case 0:
+						ok = pool.SubmitOrdered(key, work)
+					case 1:
+						ok = pool.Submit(work)
+					default:
+						ok = pool.TrySubmit(work) || pool.ctx.Err() == nil
+					}
+					if !ok {
+						return
+					}
+				}
+			}()
+		}
+
+		pool.Shutdown()
+		submitters.Wait()
+
+		if pool.Submit(work) || pool.SubmitOrdered(1, work) || pool.TrySubmit(work) {
+			t.Fatalf("关闭后提交应返回 false")
+		}
+	}
+}
+
+// work 模拟一次请求处理（解析 + 序列化）的 CPU 开销
+func work() {
+	var buf [256]byte
+	_ = sha256.Sum256(buf[:])
+}
+
+// benchmarkPool 以 conns 个并发连接提交任务
+func benchmarkPool(b *testing.B, conns int, submit func(p *Pool, connID int64, task Task)) {
+	pool := newTestPool(64, 10000)
+	defer pool.Shutdown()
+
+	var wg sync.WaitGroup
+	b.ResetTimer()
+	var connID int64
+	var idMu sync.Mutex
+	b.SetParallelism(conns)
+	b.RunParallel(func(pb *testing.PB) {
+		idMu.Lock()
+		connID++
+		id := connID
+		idMu.Unlock()
+		for pb.Next() {
+			wg.Add(1)
+			submit(pool, id, func() {
+				work()
+				wg.Done()
+			})
+		}
+	})
+	wg.Wait()
+}
+
+// BenchmarkSubmit 原有共享队列（不保证单连接顺序）
+func BenchmarkSubmit(b *testing.B) {
+	benchmarkPool(b, 16, func(p *Pool, _ int64, task Task) {
+		p.Submit(task)
+	})
+}
+
+// BenchmarkSubmitOrdered 按连接分片的有序队列
+func BenchmarkSubmitOrdered(b *testing.B) {
+	benchmarkPool(b, 16, func(p *Pool, connID int64, task Task) {
+		p.SubmitOrdered(connID, task)
+	})
+}
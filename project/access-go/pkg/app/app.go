@@ -0,0 +1,84 @@
+// Package app 组装并运行 Access 服务，供 cmd/access 与单进程模式（cmd/standalone）复用
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"sudooom.im.access/internal/config"
+	"sudooom.im.access/internal/nats"
+	imRedis "sudooom.im.access/internal/redis"
+	"sudooom.im.access/internal/server"
+	"sudooom.im.shared/bus"
+	"sudooom.im.shared/proto"
+)
+
+// Options 运行参数
+type Options struct {
+	ConfigPath string
+	Bus        bus.Bus // 消息总线，为空时按配置连接 NATS
+	Logger     *slog.Logger
+}
+
+// Run 启动 Access 服务并阻塞到 ctx 取消，随后优雅关闭
+func Run(ctx context.Context, opts Options) error {
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	// 加载配置
+	cfg, err := config.Load(opts.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	codec, err := proto.ParseCodec(cfg.NATS.Codec)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	// 未指定总线时连接 NATS
+	msgBus := opts.Bus
+	if msgBus == nil {
+		natsClient, err := nats.NewClient(cfg.NATS)
+		if err != nil {
+			return fmt.Errorf("connect NATS: %w", err)
+		}
+		defer natsClient.Close()
+		logger.Info("Connected to NATS", "url", cfg.NATS.URL)
+		msgBus = natsClient
+	}
+
+	// 初始化 Redis 客户端
+	redisClient := imRedis.NewClient(cfg.Redis, cfg.Server.NodeID)
+	defer func() {
+		if err := redisClient.Close(); err != nil {
+			logger.Error("Failed to close Redis client", "error", err)
+		}
+	}()
+	logger.Info("Connected to Redis", "addr", cfg.Redis.Addr)
+
+	// 创建并启动服务器
+	srv := server.New(cfg, msgBus, redisClient, logger)
+	srv.SetCodec(codec)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Start(ctx)
+	}()
+
+	logger.Info("Access server started",
+		"addr", cfg.Server.Addr,
+		"node_id", cfg.Server.NodeID)
+
+	select {
+	case <-ctx.Done():
+		logger.Info("Shutting down server...")
+		srv.Shutdown()
+		logger.Info("Server stopped")
+		return nil
+	case err := <-errCh:
+		srv.Shutdown()
+		return fmt.Errorf("server failed: %w", err)
+	}
+}
@@ -77,8 +77,28 @@ func (rcv *AuthRequest) AppVersion() []byte {
 	return nil
 }
 
+func (rcv *AuthRequest) ResumeToken() []byte {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(12))
+	if o != 0 {
+		return rcv._tab.ByteVector(o + rcv._tab.Pos)
+	}
+	return nil
+}
+
+func (rcv *AuthRequest) LastSeq() int64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(14))
+	if o != 0 {
+		return rcv._tab.GetInt64(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *AuthRequest) MutateLastSeq(n int64) bool {
+	return rcv._tab.MutateInt64Slot(14, n)
+}
+
 func AuthRequestStart(builder *flatbuffers.Builder) {
-	builder.StartObject(4)
+	builder.StartObject(6)
 }
 func AuthRequestAddToken(builder *flatbuffers.Builder, token flatbuffers.UOffsetT) {
 	builder.PrependUOffsetTSlot(0, flatbuffers.UOffsetT(token), 0)
@@ -92,6 +112,12 @@ func AuthRequestAddPlatform(builder *flatbuffers.Builder, platform Platform) {
 func AuthRequestAddAppVersion(builder *flatbuffers.Builder, appVersion flatbuffers.UOffsetT) {
 	builder.PrependUOffsetTSlot(3, flatbuffers.UOffsetT(appVersion), 0)
 }
+func AuthRequestAddResumeToken(builder *flatbuffers.Builder, resumeToken flatbuffers.UOffsetT) {
+	builder.PrependUOffsetTSlot(4, flatbuffers.UOffsetT(resumeToken), 0)
+}
+func AuthRequestAddLastSeq(builder *flatbuffers.Builder, lastSeq int64) {
+	builder.PrependInt64Slot(5, lastSeq, 0)
+}
 func AuthRequestEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 	return builder.EndObject()
 }
@@ -127,8 +127,20 @@ func (rcv *ClientResponse) MutatePayload(j int, n byte) bool {
 	return false
 }
 
+func (rcv *ClientResponse) Seq() int64 {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(16))
+	if o != 0 {
+		return rcv._tab.GetInt64(o + rcv._tab.Pos)
+	}
+	return 0
+}
+
+func (rcv *ClientResponse) MutateSeq(n int64) bool {
+	return rcv._tab.MutateInt64Slot(16, n)
+}
+
 func ClientResponseStart(builder *flatbuffers.Builder) {
-	builder.StartObject(6)
+	builder.StartObject(7)
 }
 func ClientResponseAddReqId(builder *flatbuffers.Builder, reqId flatbuffers.UOffsetT) {
 	builder.PrependUOffsetTSlot(0, flatbuffers.UOffsetT(reqId), 0)
@@ -151,6 +163,9 @@ func ClientResponseAddPayload(builder *flatbuffers.Builder, payload flatbuffers.
 func ClientResponseStartPayloadVector(builder *flatbuffers.Builder, numElems int) flatbuffers.UOffsetT {
 	return builder.StartVector(1, numElems, 1)
 }
+func ClientResponseAddSeq(builder *flatbuffers.Builder, seq int64) {
+	builder.PrependInt64Slot(6, seq, 0)
+}
 func ClientResponseEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 	return builder.EndObject()
 }
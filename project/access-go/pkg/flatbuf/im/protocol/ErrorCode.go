@@ -7,33 +7,78 @@ import "strconv"
 type ErrorCode int16
 
 const (
-	ErrorCodeSUCCESS        ErrorCode = 0
-	ErrorCodeUNKNOWN_ERROR  ErrorCode = 1
-	ErrorCodeAUTH_FAILED    ErrorCode = 1001
-	ErrorCodePARAM_ERROR    ErrorCode = 1002
-	ErrorCodeROOM_NOT_FOUND ErrorCode = 2001
-	ErrorCodeROOM_FULL      ErrorCode = 2002
-	ErrorCodeNOT_IN_ROOM    ErrorCode = 2003
+	ErrorCodeSUCCESS               ErrorCode = 0
+	ErrorCodeUNKNOWN_ERROR         ErrorCode = 1
+	ErrorCodeAUTH_FAILED           ErrorCode = 1001
+	ErrorCodePARAM_ERROR           ErrorCode = 1002
+	ErrorCodeRATE_LIMITED          ErrorCode = 1003
+	ErrorCodeSERVICE_UNAVAILABLE   ErrorCode = 1004
+	ErrorCodeROOM_NOT_FOUND        ErrorCode = 2001
+	ErrorCodeROOM_FULL             ErrorCode = 2002
+	ErrorCodeNOT_IN_ROOM           ErrorCode = 2003
+	ErrorCodeROOM_BUSY             ErrorCode = 2004
+	ErrorCodeINVALID_PASSWORD      ErrorCode = 2005
+	ErrorCodeGAME_STARTED          ErrorCode = 2006
+	ErrorCodeALREADY_IN_ROOM       ErrorCode = 2007
+	ErrorCodeNOT_ROOM_HOST         ErrorCode = 2008
+	ErrorCodeSEAT_OCCUPIED         ErrorCode = 2009
+	ErrorCodeINVALID_SEAT          ErrorCode = 2010
+	ErrorCodePLAYER_READY          ErrorCode = 2011
+	ErrorCodeNOT_ALL_READY         ErrorCode = 2012
+	ErrorCodeNOT_ENOUGH_PLAYERS    ErrorCode = 2013
+	ErrorCodeNO_AVAILABLE_SEAT     ErrorCode = 2014
+	ErrorCodeUNSUPPORTED_GAME_TYPE ErrorCode = 2015
+	ErrorCodeNOT_GROUP_MEMBER      ErrorCode = 3001
 )
 
 var EnumNamesErrorCode = map[ErrorCode]string{
-	ErrorCodeSUCCESS:        "SUCCESS",
-	ErrorCodeUNKNOWN_ERROR:  "UNKNOWN_ERROR",
-	ErrorCodeAUTH_FAILED:    "AUTH_FAILED",
-	ErrorCodePARAM_ERROR:    "PARAM_ERROR",
-	ErrorCodeROOM_NOT_FOUND: "ROOM_NOT_FOUND",
-	ErrorCodeROOM_FULL:      "ROOM_FULL",
-	ErrorCodeNOT_IN_ROOM:    "NOT_IN_ROOM",
+	ErrorCodeSUCCESS:               "SUCCESS",
+	ErrorCodeUNKNOWN_ERROR:         "UNKNOWN_ERROR",
+	ErrorCodeAUTH_FAILED:           "AUTH_FAILED",
+	ErrorCodePARAM_ERROR:           "PARAM_ERROR",
+	ErrorCodeRATE_LIMITED:          "RATE_LIMITED",
+	ErrorCodeSERVICE_UNAVAILABLE:   "SERVICE_UNAVAILABLE",
+	ErrorCodeROOM_NOT_FOUND:        "ROOM_NOT_FOUND",
+	ErrorCodeROOM_FULL:             "ROOM_FULL",
+	ErrorCodeNOT_IN_ROOM:           "NOT_IN_ROOM",
+	ErrorCodeROOM_BUSY:             "ROOM_BUSY",
+	ErrorCodeINVALID_PASSWORD:      "INVALID_PASSWORD",
+	ErrorCodeGAME_STARTED:          "GAME_STARTED",
+	ErrorCodeALREADY_IN_ROOM:       "ALREADY_IN_ROOM",
+	ErrorCodeNOT_ROOM_HOST:         "NOT_ROOM_HOST",
+	ErrorCodeSEAT_OCCUPIED:         "SEAT_OCCUPIED",
+	ErrorCodeINVALID_SEAT:          "INVALID_SEAT",
+	ErrorCodePLAYER_READY:          "PLAYER_READY",
+	ErrorCodeNOT_ALL_READY:         "NOT_ALL_READY",
+	ErrorCodeNOT_ENOUGH_PLAYERS:    "NOT_ENOUGH_PLAYERS",
+	ErrorCodeNO_AVAILABLE_SEAT:     "NO_AVAILABLE_SEAT",
+	ErrorCodeUNSUPPORTED_GAME_TYPE: "UNSUPPORTED_GAME_TYPE",
+	ErrorCodeNOT_GROUP_MEMBER:      "NOT_GROUP_MEMBER",
 }
 
 var EnumValuesErrorCode = map[string]ErrorCode{
-	"SUCCESS":        ErrorCodeSUCCESS,
-	"UNKNOWN_ERROR":  ErrorCodeUNKNOWN_ERROR,
-	"AUTH_FAILED":    ErrorCodeAUTH_FAILED,
-	"PARAM_ERROR":    ErrorCodePARAM_ERROR,
-	"ROOM_NOT_FOUND": ErrorCodeROOM_NOT_FOUND,
-	"ROOM_FULL":      ErrorCodeROOM_FULL,
-	"NOT_IN_ROOM":    ErrorCodeNOT_IN_ROOM,
+	"SUCCESS":               ErrorCodeSUCCESS,
+	"UNKNOWN_ERROR":         ErrorCodeUNKNOWN_ERROR,
+	"AUTH_FAILED":           ErrorCodeAUTH_FAILED,
+	"PARAM_ERROR":           ErrorCodePARAM_ERROR,
+	"RATE_LIMITED":          ErrorCodeRATE_LIMITED,
+	"SERVICE_UNAVAILABLE":   ErrorCodeSERVICE_UNAVAILABLE,
+	"ROOM_NOT_FOUND":        ErrorCodeROOM_NOT_FOUND,
+	"ROOM_FULL":             ErrorCodeROOM_FULL,
+	"NOT_IN_ROOM":           ErrorCodeNOT_IN_ROOM,
+	"ROOM_BUSY":             ErrorCodeROOM_BUSY,
+	"INVALID_PASSWORD":      ErrorCodeINVALID_PASSWORD,
+	"GAME_STARTED":          ErrorCodeGAME_STARTED,
+	"ALREADY_IN_ROOM":       ErrorCodeALREADY_IN_ROOM,
+	"NOT_ROOM_HOST":         ErrorCodeNOT_ROOM_HOST,
+	"SEAT_OCCUPIED":         ErrorCodeSEAT_OCCUPIED,
+	"INVALID_SEAT":          ErrorCodeINVALID_SEAT,
+	"PLAYER_READY":          ErrorCodePLAYER_READY,
+	"NOT_ALL_READY":         ErrorCodeNOT_ALL_READY,
+	"NOT_ENOUGH_PLAYERS":    ErrorCodeNOT_ENOUGH_PLAYERS,
+	"NO_AVAILABLE_SEAT":     ErrorCodeNO_AVAILABLE_SEAT,
+	"UNSUPPORTED_GAME_TYPE": ErrorCodeUNSUPPORTED_GAME_TYPE,
+	"NOT_GROUP_MEMBER":      ErrorCodeNOT_GROUP_MEMBER,
 }
 
 func (v ErrorCode) String() string {
@@ -7,18 +7,21 @@ import "strconv"
 type GamePayload int8
 
 const (
-	GamePayloadNONE       GamePayload = 0
-	GamePayloadMahjongReq GamePayload = 1
+	GamePayloadNONE        GamePayload = 0
+	GamePayloadMahjongReq  GamePayload = 1
+	GamePayloadMahjongPush GamePayload = 2
 )
 
 var EnumNamesGamePayload = map[GamePayload]string{
-	GamePayloadNONE:       "NONE",
-	GamePayloadMahjongReq: "MahjongReq",
+	GamePayloadNONE:        "NONE",
+	GamePayloadMahjongReq:  "MahjongReq",
+	GamePayloadMahjongPush: "MahjongPush",
 }
 
 var EnumValuesGamePayload = map[string]GamePayload{
-	"NONE":       GamePayloadNONE,
-	"MahjongReq": GamePayloadMahjongReq,
+	"NONE":        GamePayloadNONE,
+	"MahjongReq":  GamePayloadMahjongReq,
+	"MahjongPush": GamePayloadMahjongPush,
 }
 
 func (v GamePayload) String() string {
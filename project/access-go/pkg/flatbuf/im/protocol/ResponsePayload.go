@@ -15,6 +15,7 @@ const (
 	ResponsePayloadGamePush      ResponsePayload = 11
 	ResponsePayloadRoomPush      ResponsePayload = 12
 	ResponsePayloadSystemPush    ResponsePayload = 13
+	ResponsePayloadKickedPush    ResponsePayload = 14
 )
 
 var EnumNamesResponsePayload = map[ResponsePayload]string{
@@ -26,6 +27,7 @@ var EnumNamesResponsePayload = map[ResponsePayload]string{
 	ResponsePayloadGamePush:      "GamePush",
 	ResponsePayloadRoomPush:      "RoomPush",
 	ResponsePayloadSystemPush:    "SystemPush",
+	ResponsePayloadKickedPush:    "KickedPush",
 }
 
 var EnumValuesResponsePayload = map[string]ResponsePayload{
@@ -37,6 +39,7 @@ var EnumValuesResponsePayload = map[string]ResponsePayload{
 	"GamePush":      ResponsePayloadGamePush,
 	"RoomPush":      ResponsePayloadRoomPush,
 	"SystemPush":    ResponsePayloadSystemPush,
+	"KickedPush":    ResponsePayloadKickedPush,
 }
 
 func (v ResponsePayload) String() string {
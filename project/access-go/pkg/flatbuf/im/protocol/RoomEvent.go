@@ -13,6 +13,8 @@ const (
 	RoomEventGAME_START     RoomEvent = 3
 	RoomEventGAME_OVER      RoomEvent = 4
 	RoomEventROOM_DISMISSED RoomEvent = 5
+	RoomEventSEAT_CHANGED   RoomEvent = 6
+	RoomEventERROR          RoomEvent = 7
 )
 
 var EnumNamesRoomEvent = map[RoomEvent]string{
@@ -22,6 +24,8 @@ var EnumNamesRoomEvent = map[RoomEvent]string{
 	RoomEventGAME_START:     "GAME_START",
 	RoomEventGAME_OVER:      "GAME_OVER",
 	RoomEventROOM_DISMISSED: "ROOM_DISMISSED",
+	RoomEventSEAT_CHANGED:   "SEAT_CHANGED",
+	RoomEventERROR:          "ERROR",
 }
 
 var EnumValuesRoomEvent = map[string]RoomEvent{
@@ -31,6 +35,8 @@ var EnumValuesRoomEvent = map[string]RoomEvent{
 	"GAME_START":     RoomEventGAME_START,
 	"GAME_OVER":      RoomEventGAME_OVER,
 	"ROOM_DISMISSED": RoomEventROOM_DISMISSED,
+	"SEAT_CHANGED":   RoomEventSEAT_CHANGED,
+	"ERROR":          RoomEventERROR,
 }
 
 func (v RoomEvent) String() string {
@@ -62,8 +62,20 @@ func (rcv *RoomResp) RoomInfo(obj *RoomInfo) *RoomInfo {
 	return nil
 }
 
+func (rcv *RoomResp) Action() RoomAction {
+	o := flatbuffers.UOffsetT(rcv._tab.Offset(8))
+	if o != 0 {
+		return RoomAction(rcv._tab.GetInt8(o + rcv._tab.Pos))
+	}
+	return 0
+}
+
+func (rcv *RoomResp) MutateAction(n RoomAction) bool {
+	return rcv._tab.MutateInt8Slot(8, int8(n))
+}
+
 func RoomRespStart(builder *flatbuffers.Builder) {
-	builder.StartObject(2)
+	builder.StartObject(3)
 }
 func RoomRespAddRoomId(builder *flatbuffers.Builder, roomId flatbuffers.UOffsetT) {
 	builder.PrependUOffsetTSlot(0, flatbuffers.UOffsetT(roomId), 0)
@@ -71,6 +83,9 @@ func RoomRespAddRoomId(builder *flatbuffers.Builder, roomId flatbuffers.UOffsetT
 func RoomRespAddRoomInfo(builder *flatbuffers.Builder, roomInfo flatbuffers.UOffsetT) {
 	builder.PrependUOffsetTSlot(1, flatbuffers.UOffsetT(roomInfo), 0)
 }
+func RoomRespAddAction(builder *flatbuffers.Builder, action RoomAction) {
+	builder.PrependInt8Slot(2, int8(action), 0)
+}
 func RoomRespEnd(builder *flatbuffers.Builder) flatbuffers.UOffsetT {
 	return builder.EndObject()
 }
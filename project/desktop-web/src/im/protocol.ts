@@ -2,6 +2,7 @@
 
 /* eslint-disable @typescript-eslint/no-unused-vars, @typescript-eslint/no-explicit-any, @typescript-eslint/no-non-null-assertion */
 
+export { AuthAck } from './protocol/auth-ack.js';
 export { AuthRequest } from './protocol/auth-request.js';
 export { ChatPush } from './protocol/chat-push.js';
 export { ChatSendAck } from './protocol/chat-send-ack.js';
@@ -18,6 +19,8 @@ export { GameType } from './protocol/game-type.js';
 export { HeartbeatReq } from './protocol/heartbeat-req.js';
 export { HeartbeatResp } from './protocol/heartbeat-resp.js';
 export { KeyValue } from './protocol/key-value.js';
+export { KickReason } from './protocol/kick-reason.js';
+export { KickedPush } from './protocol/kicked-push.js';
 export { MahjongAction } from './protocol/mahjong-action.js';
 export { MahjongColor } from './protocol/mahjong-color.js';
 export { MahjongPush } from './protocol/mahjong-push.js';
@@ -51,8 +51,20 @@ appVersion(optionalEncoding?:any):string|Uint8Array|null {
   return offset ? this.bb!.__string(this.bb_pos + offset, optionalEncoding) : null;
 }
 
+resumeToken():string|null
+resumeToken(optionalEncoding:flatbuffers.Encoding):string|Uint8Array|null
+resumeToken(optionalEncoding?:any):string|Uint8Array|null {
+  const offset = this.bb!.__offset(this.bb_pos, 12);
+  return offset ? this.bb!.__string(this.bb_pos + offset, optionalEncoding) : null;
+}
+
+lastSeq():bigint {
+  const offset = this.bb!.__offset(this.bb_pos, 14);
+  return offset ? this.bb!.readInt64(this.bb_pos + offset) : BigInt('0');
+}
+
 static startAuthRequest(builder:flatbuffers.Builder) {
-  builder.startObject(4);
+  builder.startObject(6);
 }
 
 static addToken(builder:flatbuffers.Builder, tokenOffset:flatbuffers.Offset) {
@@ -71,17 +83,27 @@ static addAppVersion(builder:flatbuffers.Builder, appVersionOffset:flatbuffers.O
   builder.addFieldOffset(3, appVersionOffset, 0);
 }
 
+static addResumeToken(builder:flatbuffers.Builder, resumeTokenOffset:flatbuffers.Offset) {
+  builder.addFieldOffset(4, resumeTokenOffset, 0);
+}
+
+static addLastSeq(builder:flatbuffers.Builder, lastSeq:bigint) {
+  builder.addFieldInt64(5, lastSeq, BigInt('0'));
+}
+
 static endAuthRequest(builder:flatbuffers.Builder):flatbuffers.Offset {
   const offset = builder.endObject();
   return offset;
 }
 
-static createAuthRequest(builder:flatbuffers.Builder, tokenOffset:flatbuffers.Offset, deviceIdOffset:flatbuffers.Offset, platform:Platform, appVersionOffset:flatbuffers.Offset):flatbuffers.Offset {
+static createAuthRequest(builder:flatbuffers.Builder, tokenOffset:flatbuffers.Offset, deviceIdOffset:flatbuffers.Offset, platform:Platform, appVersionOffset:flatbuffers.Offset, resumeTokenOffset:flatbuffers.Offset, lastSeq:bigint):flatbuffers.Offset {
   AuthRequest.startAuthRequest(builder);
   AuthRequest.addToken(builder, tokenOffset);
   AuthRequest.addDeviceId(builder, deviceIdOffset);
   AuthRequest.addPlatform(builder, platform);
   AuthRequest.addAppVersion(builder, appVersionOffset);
+  AuthRequest.addResumeToken(builder, resumeTokenOffset);
+  AuthRequest.addLastSeq(builder, lastSeq);
   return AuthRequest.endAuthRequest(builder);
 }
 }
@@ -70,8 +70,13 @@ payloadArray():Uint8Array|null {
   return offset ? new Uint8Array(this.bb!.bytes().buffer, this.bb!.bytes().byteOffset + this.bb!.__vector(this.bb_pos + offset), this.bb!.__vector_len(this.bb_pos + offset)) : null;
 }
 
+seq():bigint {
+  const offset = this.bb!.__offset(this.bb_pos, 16);
+  return offset ? this.bb!.readInt64(this.bb_pos + offset) : BigInt('0');
+}
+
 static startClientResponse(builder:flatbuffers.Builder) {
-  builder.startObject(6);
+  builder.startObject(7);
 }
 
 static addReqId(builder:flatbuffers.Builder, reqIdOffset:flatbuffers.Offset) {
@@ -110,12 +115,16 @@ static startPayloadVector(builder:flatbuffers.Builder, numElems:number) {
   builder.startVector(1, numElems, 1);
 }
 
+static addSeq(builder:flatbuffers.Builder, seq:bigint) {
+  builder.addFieldInt64(6, seq, BigInt('0'));
+}
+
 static endClientResponse(builder:flatbuffers.Builder):flatbuffers.Offset {
   const offset = builder.endObject();
   return offset;
 }
 
-static createClientResponse(builder:flatbuffers.Builder, reqIdOffset:flatbuffers.Offset, timestamp:bigint, code:ErrorCode, msgOffset:flatbuffers.Offset, payloadType:ResponsePayload, payloadOffset:flatbuffers.Offset):flatbuffers.Offset {
+static createClientResponse(builder:flatbuffers.Builder, reqIdOffset:flatbuffers.Offset, timestamp:bigint, code:ErrorCode, msgOffset:flatbuffers.Offset, payloadType:ResponsePayload, payloadOffset:flatbuffers.Offset, seq:bigint):flatbuffers.Offset {
   ClientResponse.startClientResponse(builder);
   ClientResponse.addReqId(builder, reqIdOffset);
   ClientResponse.addTimestamp(builder, timestamp);
@@ -123,6 +132,7 @@ static createClientResponse(builder:flatbuffers.Builder, reqIdOffset:flatbuffers
   ClientResponse.addMsg(builder, msgOffset);
   ClientResponse.addPayloadType(builder, payloadType);
   ClientResponse.addPayload(builder, payloadOffset);
+  ClientResponse.addSeq(builder, seq);
   return ClientResponse.endClientResponse(builder);
 }
 }
@@ -7,7 +7,22 @@ export enum ErrorCode {
   UNKNOWN_ERROR = 1,
   AUTH_FAILED = 1001,
   PARAM_ERROR = 1002,
+  RATE_LIMITED = 1003,
+  SERVICE_UNAVAILABLE = 1004,
   ROOM_NOT_FOUND = 2001,
   ROOM_FULL = 2002,
-  NOT_IN_ROOM = 2003
+  NOT_IN_ROOM = 2003,
+  ROOM_BUSY = 2004,
+  INVALID_PASSWORD = 2005,
+  GAME_STARTED = 2006,
+  ALREADY_IN_ROOM = 2007,
+  NOT_ROOM_HOST = 2008,
+  SEAT_OCCUPIED = 2009,
+  INVALID_SEAT = 2010,
+  PLAYER_READY = 2011,
+  NOT_ALL_READY = 2012,
+  NOT_ENOUGH_PLAYERS = 2013,
+  NO_AVAILABLE_SEAT = 2014,
+  UNSUPPORTED_GAME_TYPE = 2015,
+  NOT_GROUP_MEMBER = 3001
 }
@@ -4,5 +4,6 @@
 
 export enum GamePayload {
   NONE = 0,
-  MahjongReq = 1
+  MahjongReq = 1,
+  MahjongPush = 2
 }
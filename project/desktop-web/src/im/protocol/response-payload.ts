@@ -10,5 +10,6 @@ export enum ResponsePayload {
   ChatPush = 10,
   GamePush = 11,
   RoomPush = 12,
-  SystemPush = 13
+  SystemPush = 13,
+  KickedPush = 14
 }
@@ -8,5 +8,7 @@ export enum RoomEvent {
   USER_READY = 2,
   GAME_START = 3,
   GAME_OVER = 4,
-  ROOM_DISMISSED = 5
+  ROOM_DISMISSED = 5,
+  SEAT_CHANGED = 6,
+  ERROR = 7
 }
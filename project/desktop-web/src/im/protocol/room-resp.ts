@@ -4,6 +4,7 @@
 
 import * as flatbuffers from 'flatbuffers';
 
+import { RoomAction } from '../../im/protocol/room-action.js';
 import { RoomInfo } from '../../im/protocol/room-info.js';
 
 
@@ -37,8 +38,13 @@ roomInfo(obj?:RoomInfo):RoomInfo|null {
   return offset ? (obj || new RoomInfo()).__init(this.bb!.__indirect(this.bb_pos + offset), this.bb!) : null;
 }
 
+action():RoomAction {
+  const offset = this.bb!.__offset(this.bb_pos, 8);
+  return offset ? this.bb!.readInt8(this.bb_pos + offset) : RoomAction.CREATE;
+}
+
 static startRoomResp(builder:flatbuffers.Builder) {
-  builder.startObject(2);
+  builder.startObject(3);
 }
 
 static addRoomId(builder:flatbuffers.Builder, roomIdOffset:flatbuffers.Offset) {
@@ -49,6 +55,10 @@ static addRoomInfo(builder:flatbuffers.Builder, roomInfoOffset:flatbuffers.Offse
   builder.addFieldOffset(1, roomInfoOffset, 0);
 }
 
+static addAction(builder:flatbuffers.Builder, action:RoomAction) {
+  builder.addFieldInt8(2, action, RoomAction.CREATE);
+}
+
 static endRoomResp(builder:flatbuffers.Builder):flatbuffers.Offset {
   const offset = builder.endObject();
   return offset;
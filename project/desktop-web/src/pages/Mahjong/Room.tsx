@@ -2,7 +2,7 @@ import { useState, useEffect } from 'react';
 import { useNavigate, useParams } from 'react-router-dom';
 import { message } from 'antd';
 import { EyeOutlined, PlusOutlined, SendOutlined } from '@ant-design/icons';
-import { mahjongRoomService } from '@/services/mahjongRoomService';
+import { mahjongRoomService, RoomError } from '@/services/mahjongRoomService';
 import { useIMStore } from '@/stores/imStore';
 import { RoomInfo, RoomPlayer as FBRoomPlayer } from '@/im/protocol';
 import styles from './Room.module.css';
@@ -99,7 +99,7 @@ function MahjongRoom() {
             await mahjongRoomService.takeSeat(roomId!, seatIndex);
             message.success(`正在占据 ${POSITION_LABELS[position]} 座位...`);
         } catch (error) {
-            message.error('占座失败');
+            message.error(error instanceof RoomError ? error.message : '占座失败');
         }
     };
 
@@ -108,7 +108,7 @@ function MahjongRoom() {
             await mahjongRoomService.toggleReady(roomId!);
             message.info(isReady ? '正在取消准备...' : '正在准备...');
         } catch (error) {
-            message.error('操作失败');
+            message.error(error instanceof RoomError ? error.message : '操作失败');
         }
     };
 
@@ -118,7 +118,7 @@ function MahjongRoom() {
             message.info('正在开始游戏...');
             navigate(`/mahjong/game/${roomId}`);
         } catch (error) {
-            message.error('开始游戏失败');
+            message.error(error instanceof RoomError ? error.message : '开始游戏失败');
         }
     };
 
@@ -2,7 +2,7 @@ import { useState, useEffect } from 'react';
 import { useNavigate } from 'react-router-dom';
 import { Switch, message } from 'antd';
 import { ArrowLeftOutlined, PlusOutlined, LoginOutlined } from '@ant-design/icons';
-import { mahjongRoomService } from '@/services/mahjongRoomService';
+import { mahjongRoomService, RoomError } from '@/services/mahjongRoomService';
 import { useIMStore } from '@/stores/imStore';
 import styles from './Mahjong.module.css';
 
@@ -72,7 +72,7 @@ function Mahjong() {
 
         } catch (error) {
             console.error('[Mahjong] Join room error:', error);
-            message.error('加入房间失败');
+            message.error(error instanceof RoomError ? error.message : '加入房间失败');
         }
     };
 
@@ -100,7 +100,7 @@ function Mahjong() {
             // 成功后会通过 onRoomUpdate 监听器跳转
         } catch (error) {
             console.error('[Mahjong] Create room error:', error);
-            message.error('创建房间失败');
+            message.error(error instanceof RoomError ? error.message : '创建房间失败');
         }
     };
 
@@ -7,7 +7,9 @@ import {
     RoomEvent,
     RoomReq,
     RoomPush,
+    RoomResp,
     RoomInfo,
+    ErrorCode,
     GameType,
     ClientRequest,
     RequestPayload
@@ -15,12 +17,40 @@ import {
 
 type RoomUpdateCallback = (roomInfo: RoomInfo) => void;
 
+/** 房间请求超时时间（毫秒） */
+const ROOM_REQUEST_TIMEOUT = 10000;
+
+/**
+ * 房间操作失败（code 为服务端返回的 ErrorCode）
+ */
+export class RoomError extends Error {
+    constructor(public readonly code: ErrorCode, message: string) {
+        super(message);
+        this.name = 'RoomError';
+    }
+}
+
+/**
+ * 房间操作结果
+ */
+export interface RoomResult {
+    roomId: string;
+    roomInfo: RoomInfo | null;
+}
+
+interface PendingRoomRequest {
+    resolve: (result: RoomResult) => void;
+    reject: (error: Error) => void;
+    timer: ReturnType<typeof setTimeout>;
+}
+
 /**
  * 麻将房间消息服务
  * 处理房间相关的所有消息发送和接收
  */
 class MahjongRoomService {
     private roomUpdateCallbacks: RoomUpdateCallback[] = [];
+    private pendingRequests: Map<string, PendingRoomRequest> = new Map();
 
     constructor() {
         this.initMessageListeners();
@@ -31,7 +61,7 @@ class MahjongRoomService {
      */
     private initMessageListeners(): void {
         // 监听房间推送消息
-        messageDispatcher.register(ResponsePayload.RoomPush, (payload, reqId) => {
+        messageDispatcher.register(ResponsePayload.RoomPush, (payload) => {
             if (!payload) return;
 
             try {
@@ -50,6 +80,47 @@ class MahjongRoomService {
                 console.error('[MahjongRoomService] Failed to parse room push:', error);
             }
         });
+
+        // 监听房间操作响应（通过 reqId 关联请求）
+        messageDispatcher.register(ResponsePayload.RoomResp, (payload, reqId, code, msg) => {
+            if (!reqId) return;
+            const pending = this.pendingRequests.get(reqId);
+            if (!pending) return;
+
+            this.pendingRequests.delete(reqId);
+            clearTimeout(pending.timer);
+
+            if (code !== ErrorCode.SUCCESS) {
+                pending.reject(new RoomError(code, msg || ErrorCode[code] || '房间操作失败'));
+                return;
+            }
+
+            let roomId = '';
+            let roomInfo: RoomInfo | null = null;
+            if (payload) {
+                try {
+                    const roomResp = RoomResp.getRootAsRoomResp(new flatbuffers.ByteBuffer(payload));
+                    roomId = roomResp.roomId() || '';
+                    roomInfo = roomResp.roomInfo();
+                } catch (error) {
+                    console.error('[MahjongRoomService] Failed to parse room response:', error);
+                }
+            }
+            pending.resolve({ roomId, roomInfo });
+        });
+    }
+
+    /**
+     * 等待 reqId 对应的房间响应
+     */
+    private waitForResponse(reqId: string): Promise<RoomResult> {
+        return new Promise((resolve, reject) => {
+            const timer = setTimeout(() => {
+                this.pendingRequests.delete(reqId);
+                reject(new Error('房间请求超时'));
+            }, ROOM_REQUEST_TIMEOUT);
+            this.pendingRequests.set(reqId, { resolve, reject, timer });
+        });
     }
 
     /**
@@ -66,14 +137,15 @@ class MahjongRoomService {
     }
 
     /**
-     * 发送房间请求
+     * 发送房间请求，等待服务端以相同 reqId 回复的 RoomResp
+     * 失败时抛出 RoomError
      */
     private async sendRoomRequest(
         action: RoomAction,
         roomId: string,
         targetSeatIndex: number = -1,
         roomConfig: string = ''
-    ): Promise<void> {
+    ): Promise<RoomResult> {
         const builder = new flatbuffers.Builder(256);
 
         // 构建房间ID和配置
@@ -122,23 +194,36 @@ class MahjongRoomService {
         view.setUint8(4, FrameType.Request);
         frame.set(frameBody, 5);
 
-        await transportManager.send(frame);
+        const response = this.waitForResponse(reqId);
+        try {
+            await transportManager.send(frame);
+        } catch (error) {
+            const pending = this.pendingRequests.get(reqId);
+            if (pending) {
+                clearTimeout(pending.timer);
+                this.pendingRequests.delete(reqId);
+            }
+            throw error;
+        }
 
         console.log('[MahjongRoomService] Sent room request:', RoomAction[action], {
+            reqId,
             roomId,
             targetSeatIndex,
             roomConfig,
         });
+
+        return response;
     }
 
     /**
      * 创建房间
      * @param roomConfig 房间配置 JSON 字符串
-     * @returns Promise<string> 返回创建的房间 ID
+     * @returns 创建的房间 ID 与房间快照
      */
-    async createRoom(roomConfig: string): Promise<void> {
-        // 使用临时的房间 ID，实际的房间 ID 会在响应中返回
-        await this.sendRoomRequest(RoomAction.CREATE, '', -1, roomConfig);
+    async createRoom(roomConfig: string): Promise<RoomResult> {
+        // 房间 ID 由服务端生成，在响应中返回
+        return this.sendRoomRequest(RoomAction.CREATE, '', -1, roomConfig);
     }
 
     /**
@@ -147,17 +232,17 @@ class MahjongRoomService {
      * @param password 房间密码（可选）
      * @param seatIndex 座位索引（可选，-1 表示自动分配）
      */
-    async joinRoom(roomId: string, password?: string, seatIndex: number = -1): Promise<void> {
+    async joinRoom(roomId: string, password?: string, seatIndex: number = -1): Promise<RoomResult> {
         // 如果有密码，可以放在 roomConfig 中
         const config = password ? JSON.stringify({ password }) : '';
-        await this.sendRoomRequest(RoomAction.JOIN, roomId, seatIndex, config);
+        return this.sendRoomRequest(RoomAction.JOIN, roomId, seatIndex, config);
     }
 
     /**
      * 切换准备状态
      */
-    async toggleReady(roomId: string): Promise<void> {
-        await this.sendRoomRequest(RoomAction.READY, roomId);
+    async toggleReady(roomId: string): Promise<RoomResult> {
+        return this.sendRoomRequest(RoomAction.READY, roomId);
     }
 
     /**
@@ -165,24 +250,24 @@ class MahjongRoomService {
      * @param roomId 房间ID
      * @param seatIndex 座位索引 (0=东, 1=南, 2=西, 3=北)
      */
-    async takeSeat(roomId: string, seatIndex: number): Promise<void> {
+    async takeSeat(roomId: string, seatIndex: number): Promise<RoomResult> {
         // 使用 CHANGE_SEAT 动作
-        await this.sendRoomRequest(RoomAction.CHANGE_SEAT, roomId, seatIndex);
+        return this.sendRoomRequest(RoomAction.CHANGE_SEAT, roomId, seatIndex);
     }
 
     /**
      * 离开座位
      */
-    async leaveSeat(roomId: string): Promise<void> {
-        await this.sendRoomRequest(RoomAction.LEAVE, roomId);
+    async leaveSeat(roomId: string): Promise<RoomResult> {
+        return this.sendRoomRequest(RoomAction.LEAVE, roomId);
     }
 
     /**
      * 开始游戏（房主）
      * 注意：需要后端支持 START_GAME 动作
      */
-    async startGame(roomId: string): Promise<void> {
-        await this.sendRoomRequest(RoomAction.START_GAME, roomId);
+    async startGame(roomId: string): Promise<RoomResult> {
+        return this.sendRoomRequest(RoomAction.START_GAME, roomId);
     }
 
     /**
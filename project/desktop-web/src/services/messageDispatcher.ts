@@ -2,7 +2,7 @@ import { transportManager } from '@/services/transport/WebTransportManager';
 import { IMProtocol, FrameType } from '@/services/protocol/IMProtocol';
 import { ResponsePayload } from '@/im/protocol';
 
-type MessageHandler = (payload: Uint8Array | null, reqId: string | null) => void;
+type MessageHandler = (payload: Uint8Array | null, reqId: string | null, code: number, msg: string | null) => void;
 
 /**
  * 消息分发器
@@ -59,7 +59,7 @@ class MessageDispatcher {
         const handlers = this.handlers.get(resp.payloadType) || [];
         for (const handler of handlers) {
             try {
-                handler(resp.payload, resp.reqId);
+                handler(resp.payload, resp.reqId, resp.code, resp.msg);
             } catch (e) {
                 console.error('[MessageDispatcher] Handler error:', e);
             }
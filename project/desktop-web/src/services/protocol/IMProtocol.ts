@@ -1,5 +1,6 @@
 import * as flatbuffers from 'flatbuffers';
 import {
+    AuthAck,
     AuthRequest,
     ClientRequest,
     ClientResponse,
@@ -84,8 +85,9 @@ export class IMProtocol {
 
     /**
      * 创建认证请求帧
+     * 断线重连时携带上次 AuthAck 的 resumeToken 与已收到的最大下行序号，服务端会补发缺失的推送
      */
-    static createAuthRequest(token: string, deviceId: string, appVersion: string): Uint8Array {
+    static createAuthRequest(token: string, deviceId: string, appVersion: string, resumeToken: string = '', lastSeq: bigint = BigInt(0)): Uint8Array {
         console.log('[IMProtocol] Creating AuthRequest:', {
             token: token.substring(0, 20) + '...',
             deviceId,
@@ -98,13 +100,16 @@ export class IMProtocol {
         const tokenOffset = builder.createString(token);
         const deviceIdOffset = builder.createString(deviceId);
         const appVersionOffset = builder.createString(appVersion);
+        const resumeTokenOffset = builder.createString(resumeToken);
 
         const authReqOffset = AuthRequest.createAuthRequest(
             builder,
             tokenOffset,
             deviceIdOffset,
             Platform.WEB,
-            appVersionOffset
+            appVersionOffset,
+            resumeTokenOffset,
+            lastSeq
         );
         builder.finish(authReqOffset);
 
@@ -257,6 +262,7 @@ export class IMProtocol {
         msg: string | null;
         payloadType: ResponsePayload;
         payload: Uint8Array | null;
+        seq: bigint;
     } {
         const bb = new flatbuffers.ByteBuffer(body);
         const resp = ClientResponse.getRootAsClientResponse(bb);
@@ -267,6 +273,37 @@ export class IMProtocol {
             msg: resp.msg(),
             payloadType: resp.payloadType(),
             payload: resp.payloadArray(),
+            seq: resp.seq(),
+        };
+    }
+
+    /**
+     * 解析认证响应（AuthAck 帧）
+     */
+    static parseAuthAck(body: Uint8Array): {
+        code: number;
+        msg: string | null;
+        userId: string | null;
+        connId: string | null;
+        serverTime: bigint;
+        heartbeatIntervalMs: number;
+        resumeToken: string | null;
+        resumed: boolean;
+        seq: bigint;
+    } {
+        const bb = new flatbuffers.ByteBuffer(body);
+        const ack = AuthAck.getRootAsAuthAck(bb);
+
+        return {
+            code: ack.code(),
+            msg: ack.msg(),
+            userId: ack.userId(),
+            connId: ack.connId(),
+            serverTime: ack.serverTime(),
+            heartbeatIntervalMs: ack.heartbeatIntervalMs(),
+            resumeToken: ack.resumeToken(),
+            resumed: ack.resumed(),
+            seq: ack.seq(),
         };
     }
 }
@@ -1,6 +1,24 @@
 import { IMProtocol, FrameType } from '../protocol/IMProtocol.js';
+import { ErrorCode, ResponsePayload } from '@/im/protocol';
 import { getUTC8TimeString } from '@/utils/time';
 
+/**
+ * 服务端应用关闭码（与 access-go connection 包一致）
+ */
+const CloseCode = {
+    AuthFailed: 4001,
+    SlowConsumer: 4002,
+    Overloaded: 4003, // 节点连接数或单 IP 连接数超限，需退避后重试
+    AuthTimeout: 4004,
+    RateLimited: 4005,
+    FrameTooLarge: 4006,
+    Kicked: 4007, // 被管理员或其他设备踢下线，不自动重连
+    HeartbeatTimeout: 4008, // 心跳超时，可携带恢复令牌重连
+} as const;
+
+// 节点过载时的最小重连退避
+const OVERLOADED_MIN_DELAY = 5000;
+
 type ConnectionStatus = 'disconnected' | 'connecting' | 'connected' | 'reconnecting';
 type MessageHandler = (frameType: FrameType, body: Uint8Array) => void;
 type StatusHandler = (status: ConnectionStatus) => void;
@@ -32,6 +50,16 @@ class WebTransportManager {
     private authResolve: ((value: boolean) => void) | null = null;
     private authReject: ((reason?: any) => void) | null = null;
 
+    // 会话恢复：AuthAck 下发的恢复令牌与已收到的最大下行序号，重连时带回服务端补发缺失推送
+    private resumeToken: string = '';
+    private lastSeq: bigint = BigInt(0);
+    private heartbeatIntervalMs: number = 30000;
+
+    // 最近一次会话关闭时服务端下发的关闭码
+    private lastCloseCode: number = 0;
+    // 收到 KICKED 通知：关闭码可能随连接中断丢失，以通知为准
+    private kicked: boolean = false;
+
     /**
      * 连接到 WebTransport 服务器并发送认证请求
      * @param url WebTransport URL，格式: https://host:port
@@ -64,6 +92,11 @@ class WebTransportManager {
             });
 
             this.transport = transport;
+            this.lastCloseCode = 0;
+            this.kicked = false;
+            transport.closed
+                .then((info) => { this.lastCloseCode = info.closeCode ?? 0; })
+                .catch(() => { });
 
             // 等待连接就绪
             await transport.ready;
@@ -86,7 +119,9 @@ class WebTransportManager {
             const authFrame = IMProtocol.createAuthRequest(
                 authData.token,
                 authData.deviceId,
-                authData.appVersion
+                authData.appVersion,
+                this.resumeToken,
+                this.lastSeq
             );
 
             await this.send(authFrame);
@@ -270,17 +305,22 @@ class WebTransportManager {
                 // 提取帧体
                 const bodyData = buffer.subarray(5, totalFrameLength);
 
+                // 认证响应与下行序号跟踪
+                if (frameType === FrameType.AuthAck) {
+                    this.handleAuthAck(bodyData);
+                } else if (frameType === FrameType.Response) {
+                    const { seq, payloadType } = IMProtocol.parseClientResponse(bodyData);
+                    if (seq > this.lastSeq) {
+                        this.lastSeq = seq;
+                    }
+                    if (payloadType === ResponsePayload.KickedPush) {
+                        this.kicked = true;
+                    }
+                }
+
                 // 分发消息
                 this.messageHandlers.forEach((handler) => handler(frameType, bodyData));
 
-                // 特殊处理：如果是Response帧，检查是否是认证响应
-                if (frameType === 2 && this.authResolve) {  // FrameType.Response = 2
-                    console.log('[WebTransport] Received Response frame, resolving auth');
-                    this.authResolve(true);
-                    this.authResolve = null;
-                    this.authReject = null;
-                }
-
                 // 移除已处理的帧，保留剩余数据
                 buffer = buffer.subarray(totalFrameLength);
             }
@@ -291,10 +331,20 @@ class WebTransportManager {
     }
 
     private handleDisconnect(): void {
+        if (this.kicked || this.lastCloseCode === CloseCode.Kicked) {
+            console.warn('[WebTransport] Kicked by server, not reconnecting');
+            this.resumeToken = '';
+            this.setStatus('disconnected');
+            return;
+        }
         if (this.reconnectAttempts < this.maxReconnectAttempts && this.authData) {
             this.setStatus('reconnecting');
             this.reconnectAttempts++;
-            const delay = this.reconnectDelay * Math.pow(2, this.reconnectAttempts - 1);
+            let delay = this.reconnectDelay * Math.pow(2, this.reconnectAttempts - 1);
+            if (this.lastCloseCode === CloseCode.Overloaded) {
+                // 节点过载：加大退避并加入随机抖动，避免大量客户端同时重连
+                delay = Math.max(delay, OVERLOADED_MIN_DELAY) + Math.random() * OVERLOADED_MIN_DELAY;
+            }
             setTimeout(() => {
                 if (this.authData) {
                     this.connect(this.url, this.authData).catch(() => { });
@@ -305,6 +355,36 @@ class WebTransportManager {
         }
     }
 
+    /**
+     * 处理认证响应：保存恢复令牌，按服务端协商的间隔调整心跳
+     */
+    private handleAuthAck(body: Uint8Array): void {
+        const ack = IMProtocol.parseAuthAck(body);
+        if (ack.code !== ErrorCode.SUCCESS) {
+            console.error('[WebTransport] Authentication failed:', ack.msg);
+            this.resumeToken = '';
+            this.lastSeq = BigInt(0);
+            this.authReject?.(new Error(ack.msg || 'auth failed'));
+        } else {
+            console.log('[WebTransport] Authenticated', { connId: ack.connId, resumed: ack.resumed, seq: ack.seq });
+            this.resumeToken = ack.resumeToken || '';
+            // 新会话从服务端当前序号开始计数；恢复成功时补发帧紧随 AuthAck 到达
+            if (!ack.resumed) {
+                this.lastSeq = ack.seq;
+            }
+            if (ack.heartbeatIntervalMs > 0 && ack.heartbeatIntervalMs !== this.heartbeatIntervalMs) {
+                this.heartbeatIntervalMs = ack.heartbeatIntervalMs;
+                if (this.heartbeatInterval) {
+                    this.stopHeartbeat();
+                    this.startHeartbeat();
+                }
+            }
+            this.authResolve?.(true);
+        }
+        this.authResolve = null;
+        this.authReject = null;
+    }
+
     private startHeartbeat(): void {
         this.heartbeatInterval = window.setInterval(async () => {
             if (this.status !== 'connected') return;
@@ -316,7 +396,7 @@ class WebTransportManager {
             } catch (error) {
                 console.error('[WebTransport] Heartbeat failed:', error);
             }
-        }, 30000);
+        }, this.heartbeatIntervalMs);
     }
 
     private stopHeartbeat(): void {
@@ -2,11 +2,14 @@ import { create } from 'zustand';
 import * as flatbuffers from 'flatbuffers';
 import { transportManager } from '@/services/transport/WebTransportManager';
 import { IMProtocol, FrameType } from '@/services/protocol/IMProtocol';
-import { ChatType, MsgType, ResponsePayload, ChatPush } from '@/im/protocol';
+import { ChatType, MsgType, ResponsePayload, ChatPush, SystemPush, SystemLevel, KickedPush, KickReason, ErrorCode } from '@/im/protocol';
 import { useChatStore } from './chatStore';
 import { latencyAnalyzer } from '@/services/WebTransportLatencyAnalyzer';
 import { getUTC8TimeString } from '@/utils/time';
 
+// 成功 ACK 后等待补发失败 ACK 的时间，需大于服务端批量写入的刷新间隔
+const SAVE_FAILURE_ACK_WINDOW_MS = 60_000;
+
 interface Message {
     id: string;
     conversationId: string;
@@ -18,6 +21,20 @@ interface Message {
     latency?: number; // 消息延迟（毫秒）
 }
 
+interface SystemNotice {
+    title: string;
+    content: string;
+    level: 'INFO' | 'WARNING' | 'ERROR';
+    actionUrl: string;
+    receivedAt: number;
+}
+
+interface KickNotice {
+    reason: 'NEW_LOGIN' | 'LOGOUT' | 'BANNED' | 'ADMIN';
+    msg: string;
+    receivedAt: number;
+}
+
 interface MessageState {
     messages: Map<string, Message[]>;
     addMessage: (convId: string, msg: Message) => void;
@@ -25,12 +42,20 @@ interface MessageState {
     updateMessageStatus: (msgId: string, status: Message['status']) => void;
     initListener: () => void;
     handleChatPush: (payload: Uint8Array) => void;
+    systemNotices: SystemNotice[]; // 系统公告（最新在前）
+    handleSystemPush: (payload: Uint8Array) => void;
+    kickNotice: KickNotice | null; // 被踢下线通知（收到后不再自动重连）
+    handleKickedPush: (payload: Uint8Array) => void;
     sendTimestamps: Map<string, string>; // reqId -> 发送时间字符串，用于计算延迟
+    pendingAcks: Map<string, string>; // reqId -> 本地消息 ID，收到 ChatSendAck 后更新发送状态
 }
 
 export const useMessageStore = create<MessageState>((set, get) => ({
     messages: new Map(),
     sendTimestamps: new Map(),
+    pendingAcks: new Map(),
+    systemNotices: [],
+    kickNotice: null,
 
     addMessage: (convId: string, msg: Message) => {
         set((state) => {
@@ -73,6 +98,7 @@ export const useMessageStore = create<MessageState>((set, get) => ({
 
             // 保存发送时间戳（用于本地延迟计算）
             get().sendTimestamps.set(reqId, getUTC8TimeString());
+            get().pendingAcks.set(reqId, msgId);
 
             await transportManager.send(frame);
 
@@ -110,9 +136,13 @@ export const useMessageStore = create<MessageState>((set, get) => ({
             const senderId = chatPush.senderId() || '';
             const content = chatPush.content() || '';
             const sendTime = chatPush.sendTime();
+            const isGroup = chatPush.chatType() === ChatType.GROUP;
+            const senderInfo = chatPush.senderInfo();
+            const senderName = senderInfo?.nickname() || senderId;
+            const senderAvatar = senderInfo?.avatar() || `https://api.dicebear.com/7.x/avataaars/svg?seed=${senderId}`;
 
-            // 会话 ID 使用发送者 ID（私聊场景）
-            const conversationId = senderId;
+            // 会话 ID：群聊使用群 ID，私聊使用发送者 ID
+            const conversationId = isGroup ? (chatPush.targetId() || '') : senderId;
 
             // 创建消息对象
             const msg: Message = {
@@ -146,8 +176,8 @@ export const useMessageStore = create<MessageState>((set, get) => ({
                 // 新会话，创建并添加
                 chatStore.updateConversation({
                     id: conversationId,
-                    name: senderId,  // 暂时使用 senderId 作为名称，后续可从用户信息获取
-                    avatar: `https://api.dicebear.com/7.x/avataaars/svg?seed=${senderId}`,
+                    name: isGroup ? conversationId : senderName,
+                    avatar: isGroup ? `https://api.dicebear.com/7.x/identicon/svg?seed=${conversationId}` : senderAvatar,
                     lastMessage: content,
                     unreadCount: 1,
                     updatedAt: Number(sendTime),
@@ -158,6 +188,38 @@ export const useMessageStore = create<MessageState>((set, get) => ({
         }
     },
 
+    handleSystemPush: (payload: Uint8Array) => {
+        try {
+            const push = SystemPush.getRootAsSystemPush(new flatbuffers.ByteBuffer(payload));
+            const notice: SystemNotice = {
+                title: push.title() || '',
+                content: push.content() || '',
+                level: (SystemLevel[push.level()] ?? 'INFO') as SystemNotice['level'],
+                actionUrl: push.actionUrl() || '',
+                receivedAt: Date.now(),
+            };
+            console.log('[MessageStore] 📢 系统公告:', notice.level, notice.title);
+            set((state) => ({ systemNotices: [notice, ...state.systemNotices].slice(0, 50) }));
+        } catch (e) {
+            console.error('[MessageStore] Failed to parse SystemPush:', e);
+        }
+    },
+
+    handleKickedPush: (payload: Uint8Array) => {
+        try {
+            const push = KickedPush.getRootAsKickedPush(new flatbuffers.ByteBuffer(payload));
+            const notice: KickNotice = {
+                reason: (KickReason[push.reason()] ?? 'ADMIN') as KickNotice['reason'],
+                msg: push.msg() || '',
+                receivedAt: Date.now(),
+            };
+            console.warn('[MessageStore] 🚫 被踢下线:', notice.reason, notice.msg);
+            set({ kickNotice: notice });
+        } catch (e) {
+            console.error('[MessageStore] Failed to parse KickedPush:', e);
+        }
+    },
+
     initListener: () => {
         console.log('[MessageStore] initListener called, registering message handler');
         transportManager.onMessage((frameType: FrameType, body: Uint8Array) => {
@@ -175,6 +237,21 @@ export const useMessageStore = create<MessageState>((set, get) => ({
                                 // 删除本地时间戳映射
                                 get().sendTimestamps.delete(resp.reqId);
                             }
+
+                            // 服务端拒绝（非群成员、服务繁忙等）时标记发送失败
+                            // 批量写入数据库失败时服务端会在成功 ACK 之后补发失败 ACK，成功后保留映射一段时间
+                            const reqId = resp.reqId;
+                            const msgId = get().pendingAcks.get(reqId);
+                            if (msgId) {
+                                if (resp.code === ErrorCode.SUCCESS) {
+                                    setTimeout(() => get().pendingAcks.delete(reqId), SAVE_FAILURE_ACK_WINDOW_MS);
+                                    get().updateMessageStatus(msgId, 'sent');
+                                } else {
+                                    get().pendingAcks.delete(reqId);
+                                    console.warn(`[MessageStore] 消息发送失败 reqId=${resp.reqId}, code=${ErrorCode[resp.code]}, msg=${resp.msg}`);
+                                    get().updateMessageStatus(msgId, 'failed');
+                                }
+                            }
                         }
                         break;
                     case ResponsePayload.ChatPush:
@@ -184,6 +261,16 @@ export const useMessageStore = create<MessageState>((set, get) => ({
                             console.warn('[MessageStore] ChatPush has no payload!');
                         }
                         break;
+                    case ResponsePayload.SystemPush:
+                        if (resp.payload) {
+                            get().handleSystemPush(resp.payload);
+                        }
+                        break;
+                    case ResponsePayload.KickedPush:
+                        if (resp.payload) {
+                            get().handleKickedPush(resp.payload);
+                        }
+                        break;
                     default:
                         console.log('[MessageStore] Unknown response payload type:', resp.payloadType);
                 }
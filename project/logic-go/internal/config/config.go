@@ -15,19 +15,36 @@ type Config struct {
 	Redis    RedisConfig    `mapstructure:"redis"`
 	Batch    BatchConfig    `mapstructure:"batch"`
 	Room     RoomConfig     `mapstructure:"room"`
+
+	Announcement AnnouncementConfig `mapstructure:"announcement"`
+	AccessNode   AccessNodeConfig   `mapstructure:"access_node"`
 }
 
 type AppConfig struct {
 	Name     string `mapstructure:"name"`
+	NodeID   string `mapstructure:"node_id"` // 节点 ID，多节点部署时必须唯一且重启后保持不变，为空时使用主机名
 	LogLevel string `mapstructure:"log_level"`
 }
 
 type NATSConfig struct {
-	URL           string        `mapstructure:"url"`
-	MaxReconnects int           `mapstructure:"max_reconnects"`
-	ReconnectWait time.Duration `mapstructure:"reconnect_wait"`
-	WorkerCount   int           `mapstructure:"worker_count"` // Worker Pool 并发数
-	BufferSize    int           `mapstructure:"buffer_size"`  // 消息缓冲区大小
+	URL           string          `mapstructure:"url"`
+	MaxReconnects int             `mapstructure:"max_reconnects"`
+	ReconnectWait time.Duration   `mapstructure:"reconnect_wait"`
+	WorkerCount   int             `mapstructure:"worker_count"` // Worker Pool 并发数
+	BufferSize    int             `mapstructure:"buffer_size"`  // 消息缓冲区大小
+	Codec         string          `mapstructure:"codec"`        // 下行消息编码：json（默认）/ binary，接收方自动识别
+	JetStream     JetStreamConfig `mapstructure:"jetstream"`    // 上行消息持久化消费
+}
+
+// JetStreamConfig 上行消息 JetStream 持久化配置，未启用时使用 core NATS 队列订阅
+type JetStreamConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	Replicas   int           `mapstructure:"replicas"`    // 流副本数
+	MaxAge     time.Duration `mapstructure:"max_age"`     // 未消费消息保留时长
+	AckWait    time.Duration `mapstructure:"ack_wait"`    // 未确认消息的重新投递等待时间
+	MaxDeliver int           `mapstructure:"max_deliver"` // 最大投递次数，超过后进入死信
+	NakDelay   time.Duration `mapstructure:"nak_delay"`   // 处理失败后的重新投递延迟
+	DLQMaxAge  time.Duration `mapstructure:"dlq_max_age"` // 死信保留时长
 }
 
 type DatabaseConfig struct {
@@ -50,8 +67,9 @@ type RedisConfig struct {
 }
 
 type BatchConfig struct {
-	Size          int           `mapstructure:"size"`           // 批量大小阈值
-	FlushInterval time.Duration `mapstructure:"flush_interval"` // 强制刷新间隔
+	Size           int           `mapstructure:"size"`            // 批量大小阈值
+	FlushInterval  time.Duration `mapstructure:"flush_interval"`  // 强制刷新间隔
+	EnqueueTimeout time.Duration `mapstructure:"enqueue_timeout"` // 队列满时入队的最长等待时间
 }
 
 type RoomConfig struct {
@@ -60,6 +78,14 @@ type RoomConfig struct {
 	EvictTimeout       time.Duration `mapstructure:"evict_timeout"`        // 房间无响应超时时间
 }
 
+type AnnouncementConfig struct {
+	MaxTTL time.Duration `mapstructure:"max_ttl"` // 持久化公告的最长有效期
+}
+
+type AccessNodeConfig struct {
+	CheckInterval time.Duration `mapstructure:"check_interval"` // Access 节点存活检测间隔
+}
+
 // Load 从指定路径加载配置
 func Load(configPath string) (*Config, error) {
 	viper.SetConfigFile(configPath)
@@ -83,6 +109,7 @@ func Load(configPath string) (*Config, error) {
 // applyEnv 从环境变量覆盖配置
 func (c *Config) applyEnv() {
 	// App
+	c.App.NodeID = sharedConfig.GetEnv("LOGIC_NODE_ID", c.App.NodeID)
 	c.App.LogLevel = sharedConfig.GetEnv("LOG_LEVEL", c.App.LogLevel)
 
 	// NATS
@@ -91,6 +118,10 @@ func (c *Config) applyEnv() {
 	c.NATS.ReconnectWait = sharedConfig.GetEnvDuration("NATS_RECONNECT_WAIT", c.NATS.ReconnectWait)
 	c.NATS.WorkerCount = sharedConfig.GetEnvInt("NATS_WORKER_COUNT", c.NATS.WorkerCount)
 	c.NATS.BufferSize = sharedConfig.GetEnvInt("NATS_BUFFER_SIZE", c.NATS.BufferSize)
+	c.NATS.Codec = sharedConfig.GetEnv("NATS_CODEC", c.NATS.Codec)
+	c.NATS.JetStream.Enabled = sharedConfig.GetEnvBool("NATS_JETSTREAM_ENABLED", c.NATS.JetStream.Enabled)
+	c.NATS.JetStream.Replicas = sharedConfig.GetEnvInt("NATS_JETSTREAM_REPLICAS", c.NATS.JetStream.Replicas)
+	c.NATS.JetStream.MaxDeliver = sharedConfig.GetEnvInt("NATS_JETSTREAM_MAX_DELIVER", c.NATS.JetStream.MaxDeliver)
 
 	// Database
 	c.Database.Host = sharedConfig.GetEnv("POSTGRES_HOST", c.Database.Host)
@@ -111,9 +142,16 @@ func (c *Config) applyEnv() {
 	// Batch
 	c.Batch.Size = sharedConfig.GetEnvInt("BATCH_SIZE", c.Batch.Size)
 	c.Batch.FlushInterval = sharedConfig.GetEnvDuration("BATCH_FLUSH_INTERVAL", c.Batch.FlushInterval)
+	c.Batch.EnqueueTimeout = sharedConfig.GetEnvDuration("BATCH_ENQUEUE_TIMEOUT", c.Batch.EnqueueTimeout)
 
 	// Room
 	c.Room.MaxRooms = sharedConfig.GetEnvInt("ROOM_MAX_ROOMS", c.Room.MaxRooms)
 	c.Room.EvictCheckInterval = sharedConfig.GetEnvDuration("ROOM_EVICT_CHECK_INTERVAL", c.Room.EvictCheckInterval)
 	c.Room.EvictTimeout = sharedConfig.GetEnvDuration("ROOM_EVICT_TIMEOUT", c.Room.EvictTimeout)
+
+	// Announcement
+	c.Announcement.MaxTTL = sharedConfig.GetEnvDuration("ANNOUNCEMENT_MAX_TTL", c.Announcement.MaxTTL)
+
+	// Access Node
+	c.AccessNode.CheckInterval = sharedConfig.GetEnvDuration("ACCESS_NODE_CHECK_INTERVAL", c.AccessNode.CheckInterval)
 }
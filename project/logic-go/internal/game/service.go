@@ -2,7 +2,6 @@ package game
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log/slog"
 
@@ -34,15 +33,10 @@ func NewGameService(
 	}
 }
 
-// BroadcastGameEvent 广播游戏事件给房间所有玩家（所有人收到相同消息）
-// 适用场景：游戏开始、游戏结束、公共信息等
-func (s *GameService) BroadcastGameEvent(ctx context.Context, roomId string, event string, data interface{}) error {
-	eventData, err := json.Marshal(data)
-	if err != nil {
-		s.logger.Error("Failed to marshal game event data", "error", err, "event", event)
-		return err
-	}
-
+// BroadcastGameEvent 广播游戏推送给房间所有玩家（所有人收到相同消息）
+// 适用场景：出牌结果、轮次变化、结算等公共信息
+// gamePayload 为 FlatBuffers 编码的游戏推送数据（如 MahjongPush），payloadType 为对应的 GamePayload 枚举名
+func (s *GameService) BroadcastGameEvent(ctx context.Context, roomId string, gameType string, payloadType string, gamePayload []byte) error {
 	// 获取房间所有用户ID
 	roomUsersKey := sharedRedis.BuildRoomUsersKey(roomId)
 	userIdStrs, err := s.redisClient.SMembers(ctx, roomUsersKey).Result()
@@ -62,35 +56,25 @@ func (s *GameService) BroadcastGameEvent(ctx context.Context, roomId string, eve
 		userIds = append(userIds, userId)
 	}
 
-	// 广播事件
-	if err := s.routerService.SendRoomPushToUsers(ctx, userIds, event, roomId, eventData); err != nil {
-		s.logger.Warn("Failed to broadcast game event", "error", err, "event", event, "roomId", roomId)
+	// 广播推送
+	if err := s.routerService.SendGamePushToUsers(ctx, userIds, roomId, gameType, payloadType, gamePayload); err != nil {
+		s.logger.Warn("Failed to broadcast game event", "error", err, "payloadType", payloadType, "roomId", roomId)
 		return err
 	}
 
 	return nil
 }
 
-// SendPersonalizedGameEvents 给每个玩家发送个性化的游戏事件
+// SendPersonalizedGameEvents 给每个玩家发送个性化的游戏推送
 // 适用场景：发牌（每个玩家手牌不同）、摸牌（只有摸牌者知道牌面）等
-// userDataMap: key 为 userId，value 为该玩家应该收到的数据
-func (s *GameService) SendPersonalizedGameEvents(ctx context.Context, roomId string, event string, userDataMap map[int64]interface{}) error {
+// userPayloads: key 为 userId，value 为该玩家应该收到的 FlatBuffers 游戏推送数据
+func (s *GameService) SendPersonalizedGameEvents(ctx context.Context, roomId string, gameType string, payloadType string, userPayloads map[int64][]byte) error {
 	// 遍历每个玩家，发送个性化消息
-	for userId, data := range userDataMap {
-		eventData, err := json.Marshal(data)
-		if err != nil {
-			s.logger.Error("Failed to marshal personalized event data",
-				"error", err,
-				"event", event,
-				"userId", userId)
-			continue // 不阻塞其他玩家的消息
-		}
-
-		// 给单个玩家发送消息
-		if err := s.routerService.SendRoomPushToUsers(ctx, []int64{userId}, event, roomId, eventData); err != nil {
-			s.logger.Warn("Failed to send personalized event",
+	for userId, gamePayload := range userPayloads {
+		if err := s.routerService.SendGamePushToUsers(ctx, []int64{userId}, roomId, gameType, payloadType, gamePayload); err != nil {
+			s.logger.Warn("Failed to send personalized game event",
 				"error", err,
-				"event", event,
+				"payloadType", payloadType,
 				"userId", userId,
 				"roomId", roomId)
 			// 不阻塞其他玩家
@@ -117,28 +101,14 @@ func (s *GameService) StartGame(ctx context.Context, room *model.Room) error {
 	}
 }
 
-// startMahjongGame 广播麻将游戏开始事件
-// 注意：实际的游戏初始化应由 MahjongService.StartGame() 完成
-// 调用顺序：
-//  1. 外部调用 MahjongService.StartGame() 初始化游戏引擎
-//  2. 然后调用此方法广播游戏开始事件
+// startMahjongGame 麻将游戏开始
+// 注意：
+//  1. 房间的 GAME_START 推送（携带 RoomInfo）已由 RoomService.StartGame 广播，这里不再重复推送
+//  2. 实际的游戏初始化应由 MahjongService.StartGame() 完成，发牌等数据通过 SendPersonalizedGameEvents 下发
 func (s *GameService) startMahjongGame(ctx context.Context, room *model.Room) error {
-	s.logger.Info("Broadcasting mahjong game start event",
+	s.logger.Info("Mahjong game started",
 		"roomId", room.RoomID,
-		"gameType", room.GameType)
-
-	// 广播游戏开始事件
-	gameStartData := map[string]interface{}{
-		"room_id":   room.RoomID,
-		"game_type": room.GameType,
-		"status":    "playing",
-		"players":   room.Players,
-	}
-
-	if err := s.BroadcastGameEvent(ctx, room.RoomID, "GAME_STARTED", gameStartData); err != nil {
-		return fmt.Errorf("failed to broadcast game start event: %w", err)
-	}
-
-	s.logger.Info("Game start event broadcasted", "roomId", room.RoomID)
+		"gameType", room.GameType,
+		"playerCount", len(room.Players))
 	return nil
 }
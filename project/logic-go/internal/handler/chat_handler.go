@@ -2,12 +2,23 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 
 	"sudooom.im.logic/internal/service"
 	"sudooom.im.shared/proto"
 )
 
+var (
+	// errNoChatTarget 消息既没有接收者也没有群组
+	errNoChatTarget = errors.New("chat message has no target")
+	// errNotGroupMember 发送者不是群成员
+	errNotGroupMember = errors.New("sender is not a group member")
+	// errMessageNotSaved 消息写入数据库失败（已回复成功 ACK 之后）
+	errMessageNotSaved = errors.New("chat message not saved")
+)
+
 // ChatHandler 聊天消息处理器
 type ChatHandler struct {
 	messageBatcher      *service.MessageBatcher
@@ -15,6 +26,7 @@ type ChatHandler struct {
 	groupService        *service.GroupService
 	routerService       *service.RouterService
 	conversationService *service.ConversationService
+	userService         *service.UserService
 	logger              *slog.Logger
 }
 
@@ -25,6 +37,7 @@ func NewChatHandler(
 	groupService *service.GroupService,
 	routerService *service.RouterService,
 	conversationService *service.ConversationService,
+	userService *service.UserService,
 ) *ChatHandler {
 	return &ChatHandler{
 		messageBatcher:      messageBatcher,
@@ -32,17 +45,49 @@ func NewChatHandler(
 		groupService:        groupService,
 		routerService:       routerService,
 		conversationService: conversationService,
+		userService:         userService,
 		logger:              slog.Default(),
 	}
 }
 
-// Handle 处理聊天消息
-func (h *ChatHandler) Handle(ctx context.Context, msg *proto.UserMessage, accessNodeId string, connId int64, platform string) {
-	// 1. 异步批量消息存储（立即返回 serverMsgId）
-	serverMsgId, err := h.messageBatcher.SaveMessage(msg)
+// Handle 处理聊天消息，消息写入数据库后以写入结果调用 saved；被拒绝的消息已回复失败 ACK，以 nil 调用
+func (h *ChatHandler) Handle(ctx context.Context, msg *proto.UserMessage, accessNodeId string, connId int64, platform string, saved func(error)) error {
+	// 1. 校验接收目标，群聊校验发送者是否为群成员（成员列表复用于路由）
+	var members []int64
+	switch {
+	case msg.ToUserId > 0:
+	case msg.ToGroupId > 0:
+		var err error
+		members, err = h.groupService.GetGroupMembers(ctx, msg.ToGroupId)
+		if err != nil {
+			h.logger.Error("Failed to get group members", "groupId", msg.ToGroupId, "error", err)
+			h.rejectMessage(msg, accessNodeId, connId, err)
+			saved(nil)
+			return nil
+		}
+		if !containsUser(members, msg.FromUserId) {
+			h.rejectMessage(msg, accessNodeId, connId, errNotGroupMember)
+			saved(nil)
+			return nil
+		}
+	default:
+		h.rejectMessage(msg, accessNodeId, connId, errNoChatTarget)
+		saved(nil)
+		return nil
+	}
+
+	// 2. 异步批量消息存储（立即返回 serverMsgId），写入失败时向发送连接补发失败 ACK，客户端据此重发
+	serverMsgId, err := h.messageBatcher.SaveMessage(msg, func(err error) {
+		if err != nil {
+			h.rejectMessage(msg, accessNodeId, connId, fmt.Errorf("%w: %w", errMessageNotSaved, err))
+		}
+		saved(err)
+	})
 	if err != nil {
 		h.logger.Error("Failed to queue message for saving", "error", err)
-		return
+		h.rejectMessage(msg, accessNodeId, connId, err)
+		saved(nil)
+		return nil
 	}
 
 	// 直接回 ACK 给发送者（使用 connId 避免查询 Redis）
@@ -50,10 +95,13 @@ func (h *ChatHandler) Handle(ctx context.Context, msg *proto.UserMessage, access
 		h.logger.Error("Failed to send ack", "error", err)
 	}
 
-	// 3. 路由消息给接收者
+	// 3. 获取发送者资料，随推送下发，客户端无需再查询
+	sender := h.userService.GetUserInfo(ctx, msg.FromUserId)
+
+	// 4. 路由消息给接收者
 	if msg.ToUserId > 0 {
 		// 单聊消息
-		if err := h.routerService.RouteMessage(ctx, msg.ToUserId, msg, serverMsgId); err != nil {
+		if err := h.routerService.RouteMessage(ctx, msg.ToUserId, msg, serverMsgId, sender); err != nil {
 			h.logger.Error("Failed to route message to user", "toUserId", msg.ToUserId, "error", err)
 		}
 
@@ -68,15 +116,9 @@ func (h *ChatHandler) Handle(ctx context.Context, msg *proto.UserMessage, access
 		}()
 
 	} else if msg.ToGroupId > 0 {
-		// 群聊消息
-		members, err := h.groupService.GetGroupMembers(ctx, msg.ToGroupId)
-		if err != nil {
-			h.logger.Error("Failed to get group members", "groupId", msg.ToGroupId, "error", err)
-			return
-		}
-		// 过滤发送者
+		// 群聊消息，过滤发送者
 		filteredMembers := filterOut(members, msg.FromUserId)
-		if err := h.routerService.RouteToMultiple(ctx, filteredMembers, msg, serverMsgId); err != nil {
+		if err := h.routerService.RouteToMultiple(ctx, filteredMembers, msg, serverMsgId, sender); err != nil {
 			h.logger.Error("Failed to route message to group", "groupId", msg.ToGroupId, "error", err)
 		}
 
@@ -86,12 +128,49 @@ func (h *ChatHandler) Handle(ctx context.Context, msg *proto.UserMessage, access
 		}()
 	}
 
-	// 4. 异步多端同步：同步消息给发送者的其他设备（非关键路径）
+	// 5. 异步多端同步：同步消息给发送者的其他设备（非关键路径）
 	go func() {
-		if err := h.routerService.SyncToSenderOtherDevices(context.Background(), platform, msg.FromUserId, msg, serverMsgId); err != nil {
+		if err := h.routerService.SyncToSenderOtherDevices(context.Background(), platform, msg.FromUserId, msg, serverMsgId, sender); err != nil {
 			h.logger.Error("Failed to sync to sender other devices", "error", err)
 		}
 	}()
+	return nil
+}
+
+// rejectMessage 向发送者回复失败的 ACK，消息不会存储与投递
+func (h *ChatHandler) rejectMessage(msg *proto.UserMessage, accessNodeId string, connId int64, reason error) {
+	code, text := chatErrorCode(reason)
+	h.logger.Warn("Chat message rejected",
+		"fromUserId", msg.FromUserId, "clientMsgId", msg.ClientMsgId, "code", code, "reason", reason)
+	if err := h.routerService.SendAckErrorToUserDirect(accessNodeId, connId, msg.FromUserId, msg.ClientMsgId, code, text); err != nil {
+		h.logger.Error("Failed to send ack", "error", err)
+	}
+}
+
+// chatErrorCode 将聊天消息错误映射为 FlatBuffers ErrorCode 枚举名和错误信息
+func chatErrorCode(err error) (string, string) {
+	switch {
+	case errors.Is(err, errNoChatTarget):
+		return "PARAM_ERROR", "消息缺少接收者"
+	case errors.Is(err, errNotGroupMember):
+		return "NOT_GROUP_MEMBER", "您不是该群成员"
+	case errors.Is(err, service.ErrMessageQueueFull):
+		return "SERVICE_UNAVAILABLE", "服务繁忙，请稍后重试"
+	case errors.Is(err, errMessageNotSaved):
+		return "SERVICE_UNAVAILABLE", "消息保存失败，请重新发送"
+	default:
+		return "UNKNOWN_ERROR", "消息发送失败"
+	}
+}
+
+// containsUser 判断用户是否在列表中
+func containsUser(members []int64, userId int64) bool {
+	for _, m := range members {
+		if m == userId {
+			return true
+		}
+	}
+	return false
 }
 
 // filterOut 过滤掉指定用户
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"fmt"
+	"testing"
+
+	"sudooom.im.logic/internal/service"
+)
+
+func TestChatErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code string
+	}{
+		{"缺少接收者", errNoChatTarget, "PARAM_ERROR"},
+		{"非群成员", errNotGroupMember, "NOT_GROUP_MEMBER"},
+		{"队列满", fmt.Errorf("save: %w", service.ErrMessageQueueFull), "SERVICE_UNAVAILABLE"},
+		{"写入失败", fmt.Errorf("%w: %w", errMessageNotSaved, fmt.Errorf("db down")), "SERVICE_UNAVAILABLE"},
+		{"其他错误", fmt.Errorf("boom"), "UNKNOWN_ERROR"},
+	}
+
+	for _, tt := range tests {
+		code, msg := chatErrorCode(tt.err)
+		if code != tt.code || msg == "" {
+			t.Errorf("%s: chatErrorCode = %q, %q, want %q", tt.name, code, msg, tt.code)
+		}
+	}
+}
+
+func TestContainsUser(t *testing.T) {
+	members := []int64{1, 2, 3}
+	if !containsUser(members, 2) || containsUser(members, 4) || containsUser(nil, 1) {
+		t.Fatalf("containsUser 结果不正确")
+	}
+}
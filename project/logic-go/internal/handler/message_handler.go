@@ -17,6 +17,8 @@ type MessageHandler struct {
 	roomHandler *RoomHandler
 	gameHandler *GameHandler
 	userHandler *UserHandler
+
+	systemHandler *SystemHandler
 }
 
 // NewMessageHandler 创建消息处理器
@@ -26,44 +28,53 @@ func NewMessageHandler(
 	groupService *service.GroupService,
 	routerService *service.RouterService,
 	conversationService *service.ConversationService,
+	userService *service.UserService,
 	redisClient *redis.Client,
 	roomService *room.RoomService,
 	gameService *game.GameService,
+	announcementService *service.AnnouncementService,
 ) *MessageHandler {
 	return &MessageHandler{
-		chatHandler: NewChatHandler(messageBatcher, messageService, groupService, routerService, conversationService),
+		chatHandler: NewChatHandler(messageBatcher, messageService, groupService, routerService, conversationService, userService),
 		roomHandler: NewRoomHandler(redisClient, roomService, gameService, routerService),
 		gameHandler: NewGameHandler(gameService),
-		userHandler: NewUserHandler(conversationService, routerService),
+		userHandler: NewUserHandler(conversationService, routerService, announcementService),
+
+		systemHandler: NewSystemHandler(announcementService),
 	}
 }
 
-// HandleUserMessage 处理用户消息
-func (h *MessageHandler) HandleUserMessage(ctx context.Context, msg *proto.UserMessage, accessNodeId string, connId int64, platform string) {
-	h.chatHandler.Handle(ctx, msg, accessNodeId, connId, platform)
+// HandleUserMessage 处理用户消息，消息写入数据库后调用 saved
+func (h *MessageHandler) HandleUserMessage(ctx context.Context, msg *proto.UserMessage, accessNodeId string, connId int64, platform string, saved func(error)) error {
+	return h.chatHandler.Handle(ctx, msg, accessNodeId, connId, platform, saved)
 }
 
 // HandleConversationRead 处理会话已读
-func (h *MessageHandler) HandleConversationRead(ctx context.Context, event *proto.ConversationRead) {
-	h.userHandler.HandleConversationRead(ctx, event)
+func (h *MessageHandler) HandleConversationRead(ctx context.Context, event *proto.ConversationRead) error {
+	return h.userHandler.HandleConversationRead(ctx, event)
 }
 
 // HandleUserOnline 处理用户上线
-func (h *MessageHandler) HandleUserOnline(ctx context.Context, event *proto.UserOnline, accessNodeId string) {
-	h.userHandler.HandleUserOnline(ctx, event, accessNodeId)
+func (h *MessageHandler) HandleUserOnline(ctx context.Context, event *proto.UserOnline, accessNodeId string) error {
+	return h.userHandler.HandleUserOnline(ctx, event, accessNodeId)
 }
 
 // HandleUserOffline 处理用户下线
-func (h *MessageHandler) HandleUserOffline(ctx context.Context, event *proto.UserOffline, accessNodeId string) {
-	h.userHandler.HandleUserOffline(ctx, event, accessNodeId)
+func (h *MessageHandler) HandleUserOffline(ctx context.Context, event *proto.UserOffline, accessNodeId string) error {
+	return h.userHandler.HandleUserOffline(ctx, event, accessNodeId)
 }
 
 // HandleRoomRequest 处理房间请求
-func (h *MessageHandler) HandleRoomRequest(ctx context.Context, req *proto.RoomRequest, accessNodeId string, connId int64, platform string) {
-	_ = h.roomHandler.Handle(ctx, req, accessNodeId, connId, platform)
+func (h *MessageHandler) HandleRoomRequest(ctx context.Context, req *proto.RoomRequest, accessNodeId string, connId int64, platform string) error {
+	return h.roomHandler.Handle(ctx, req, accessNodeId, connId, platform)
 }
 
 // HandleGameRequest 处理游戏请求
-func (h *MessageHandler) HandleGameRequest(ctx context.Context, req *proto.GameRequest, accessNodeId string, connId int64, platform string) {
-	_ = h.gameHandler.Handle(ctx, req, accessNodeId, connId, platform)
+func (h *MessageHandler) HandleGameRequest(ctx context.Context, req *proto.GameRequest, accessNodeId string, connId int64, platform string) error {
+	return h.gameHandler.Handle(ctx, req, accessNodeId, connId, platform)
+}
+
+// HandleSystemAnnouncement 处理系统公告请求
+func (h *MessageHandler) HandleSystemAnnouncement(ctx context.Context, req *proto.SystemAnnouncement) (*proto.SystemAnnouncementResult, error) {
+	return h.systemHandler.HandleAnnouncement(ctx, req)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 
 	"github.com/redis/go-redis/v9"
@@ -14,11 +15,17 @@ import (
 	"sudooom.im.shared/proto"
 )
 
-// RoomActionHandler 房间操作处理器接口
+// RoomActionHandler 房间操作处理器接口，返回操作后的房间快照
 type RoomActionHandler interface {
-	Handle(ctx context.Context, req *proto.RoomRequest, accessNodeId string, connId int64, platform string) error
+	Handle(ctx context.Context, req *proto.RoomRequest) (*sharedModel.Room, error)
 }
 
+// errUnknownRoomAction 未知的房间操作
+var errUnknownRoomAction = errors.New("unknown room action")
+
+// errInvalidRoomConfig 房间配置不是合法的 JSON
+var errInvalidRoomConfig = errors.New("invalid room config")
+
 // RoomHandler 房间请求处理器
 type RoomHandler struct {
 	actionHandlers map[string]RoomActionHandler
@@ -49,49 +56,116 @@ func NewRoomHandler(redisClient *redis.Client, roomService *room.RoomService, ga
 // registerActionHandlers 注册各种房间操作处理器
 func (h *RoomHandler) registerActionHandlers() {
 	h.actionHandlers["CREATE"] = &CreateRoomHandler{
-		roomService:   h.roomService,
-		routerService: h.routerService,
-		logger:        h.logger,
+		roomService: h.roomService,
+		logger:      h.logger,
 	}
 	h.actionHandlers["JOIN"] = &JoinRoomHandler{
-		roomService:   h.roomService,
-		routerService: h.routerService,
-		logger:        h.logger,
+		roomService: h.roomService,
+		logger:      h.logger,
 	}
 	h.actionHandlers["LEAVE"] = &LeaveRoomHandler{
-		roomService:   h.roomService,
-		routerService: h.routerService,
-		logger:        h.logger,
+		roomService: h.roomService,
+		logger:      h.logger,
 	}
 	h.actionHandlers["READY"] = &ReadyRoomHandler{
-		roomService:   h.roomService,
-		routerService: h.routerService,
-		logger:        h.logger,
+		roomService: h.roomService,
+		logger:      h.logger,
 	}
 	h.actionHandlers["CHANGE_SEAT"] = &ChangeSeatHandler{
-		roomService:   h.roomService,
-		routerService: h.routerService,
-		logger:        h.logger,
+		roomService: h.roomService,
+		logger:      h.logger,
 	}
 	h.actionHandlers["START_GAME"] = &StartGameHandler{
-		roomService:   h.roomService,
-		gameService:   h.gameService,
-		routerService: h.routerService,
-		logger:        h.logger,
+		roomService: h.roomService,
+		gameService: h.gameService,
+		logger:      h.logger,
 	}
 }
 
-// Handle 处理房间请求
+// Handle 处理房间请求，无论成功失败都以 RoomResp 回复发起请求的连接
+// 房间操作的错误已随 RoomResp 回复，仅 RoomResp 发送失败时返回错误
 func (h *RoomHandler) Handle(ctx context.Context, req *proto.RoomRequest, accessNodeId string, connId int64, platform string) error {
-	handler, ok := h.actionHandlers[req.Action]
-	if !ok {
-		// 记录警告日志
+	var (
+		snapshot *sharedModel.Room
+		err      error
+	)
+	if handler, ok := h.actionHandlers[req.Action]; ok {
+		snapshot, err = handler.Handle(ctx, req)
+	} else {
 		h.logger.Warn("Unknown room action", "action", req.Action, "userId", req.UserId, "reqId", req.ReqId)
-		// 后续可以通过 RouterService 发送 RoomPush 错误事件给客户端
-		return nil
+		err = errUnknownRoomAction
 	}
 
-	return handler.Handle(ctx, req, accessNodeId, connId, platform)
+	senderLoc := sharedModel.UserLocation{
+		AccessNodeId: accessNodeId,
+		ConnId:       connId,
+		Platform:     platform,
+		UserId:       req.UserId,
+	}
+	if sendErr := h.routerService.SendRoomResp(senderLoc, buildRoomResp(req, snapshot, err)); sendErr != nil {
+		return fmt.Errorf("send room response to user %d: %w", req.UserId, sendErr)
+	}
+	return nil
+}
+
+// buildRoomResp 构建房间操作响应：成功携带房间快照，失败携带错误码
+func buildRoomResp(req *proto.RoomRequest, snapshot *sharedModel.Room, err error) *proto.RoomResp {
+	resp := &proto.RoomResp{
+		ReqId:  req.ReqId,
+		Action: req.Action,
+		RoomId: req.RoomId,
+	}
+	if err != nil {
+		resp.Code, resp.Msg = roomErrorCode(err)
+		return resp
+	}
+	if snapshot != nil {
+		resp.RoomId = snapshot.RoomID
+		resp.RoomInfo = room.ToRoomInfo(snapshot)
+	}
+	return resp
+}
+
+// roomErrorCode 将房间操作错误映射为 FlatBuffers ErrorCode 枚举名和错误信息
+func roomErrorCode(err error) (string, string) {
+	switch {
+	case errors.Is(err, errUnknownRoomAction):
+		return "PARAM_ERROR", "未知的房间操作"
+	case errors.Is(err, errInvalidRoomConfig):
+		return "PARAM_ERROR", "房间配置格式错误"
+	case errors.Is(err, room.ErrRoomNotFound):
+		return "ROOM_NOT_FOUND", "房间已解散"
+	case errors.Is(err, room.ErrRoomFull):
+		return "ROOM_FULL", "房间已满"
+	case errors.Is(err, room.ErrNotInRoom):
+		return "NOT_IN_ROOM", "您不在房间中"
+	case errors.Is(err, room.ErrRoomBusy), errors.Is(err, room.ErrLockFailed):
+		return "ROOM_BUSY", "房间正在处理其他操作"
+	case errors.Is(err, room.ErrInvalidPassword):
+		return "INVALID_PASSWORD", "房间密码错误"
+	case errors.Is(err, room.ErrGameStarted):
+		return "GAME_STARTED", "游戏已开始"
+	case errors.Is(err, room.ErrAlreadyInRoom):
+		return "ALREADY_IN_ROOM", "您已在房间中"
+	case errors.Is(err, room.ErrNotRoomHost):
+		return "NOT_ROOM_HOST", "只有房主可以操作"
+	case errors.Is(err, room.ErrSeatOccupied):
+		return "SEAT_OCCUPIED", "座位已被占用"
+	case errors.Is(err, room.ErrInvalidSeat):
+		return "INVALID_SEAT", "座位无效"
+	case errors.Is(err, room.ErrPlayerReady):
+		return "PLAYER_READY", "已准备，请先取消准备"
+	case errors.Is(err, room.ErrNotAllReady):
+		return "NOT_ALL_READY", "还有玩家未准备"
+	case errors.Is(err, room.ErrNotEnoughPlayers):
+		return "NOT_ENOUGH_PLAYERS", "玩家人数不足"
+	case errors.Is(err, room.ErrNoAvailableSeat):
+		return "NO_AVAILABLE_SEAT", "没有空闲座位"
+	case errors.Is(err, room.ErrUnsupportedGameType):
+		return "UNSUPPORTED_GAME_TYPE", "不支持的游戏类型"
+	default:
+		return "UNKNOWN_ERROR", "房间操作失败"
+	}
 }
 
 // ============================================================================
@@ -100,26 +174,23 @@ func (h *RoomHandler) Handle(ctx context.Context, req *proto.RoomRequest, access
 
 // CreateRoomHandler 创建房间
 type CreateRoomHandler struct {
-	roomService   *room.RoomService
-	routerService *service.RouterService
-	logger        *slog.Logger
+	roomService *room.RoomService
+	logger      *slog.Logger
 }
 
-func (h *CreateRoomHandler) Handle(ctx context.Context, req *proto.RoomRequest, accessNodeId string, connId int64, platform string) error {
+func (h *CreateRoomHandler) Handle(ctx context.Context, req *proto.RoomRequest) (*sharedModel.Room, error) {
 	h.logger.Info("Create room",
 		"userId", req.UserId,
 		"reqId", req.ReqId,
 		"gameType", req.GameType,
-		"roomConfig", req.RoomConfig,
-		"accessNodeId", accessNodeId)
+		"roomConfig", req.RoomConfig)
 
 	// 解析房间配置
 	var config map[string]string
 	if req.RoomConfig != "" {
 		if err := json.Unmarshal([]byte(req.RoomConfig), &config); err != nil {
 			h.logger.Error("Failed to parse room config", "error", err, "userId", req.UserId)
-			h.sendErrorResponse(ctx, accessNodeId, connId, platform, req.UserId, "", err)
-			return nil // 不阻塞流程
+			return nil, errInvalidRoomConfig
 		}
 	}
 	if config == nil {
@@ -157,275 +228,152 @@ func (h *CreateRoomHandler) Handle(ctx context.Context, req *proto.RoomRequest,
 	})
 	if err != nil {
 		h.logger.Error("Failed to create room", "error", err, "userId", req.UserId)
-		h.sendErrorResponse(ctx, accessNodeId, connId, platform, req.UserId, "", err)
-		return nil // 不阻塞流程
+		return nil, err
 	}
 
 	h.logger.Info("Room created successfully",
 		"roomId", roomCreate.RoomID,
 		"roomName", roomCreate.RoomName,
 		"userId", req.UserId)
-	return nil
-}
-
-// sendErrorResponse 发送错误响应
-func (h *CreateRoomHandler) sendErrorResponse(ctx context.Context, accessNodeId string, connId int64, platform string, userId int64, roomId string, err error) {
-	// 将 error 映射到错误码和消息
-	errorCode, errorMsg := h.mapErrorToCodeAndMsg(err)
-
-	// 构建错误响应的 RoomInfo（使用 JSON）
-	errorInfo := map[string]interface{}{
-		"errorCode": errorCode,
-		"errorMsg":  errorMsg,
-		"roomId":    roomId,
-	}
-	roomInfoBytes, marshalErr := json.Marshal(errorInfo)
-	if marshalErr != nil {
-		h.logger.Error("Failed to marshal error info", "error", marshalErr)
-		return
-	}
-
-	// 通过 RouterService 发送错误响应
-	senderLoc := sharedModel.UserLocation{
-		AccessNodeId: accessNodeId,
-		ConnId:       connId,
-		Platform:     platform,
-		UserId:       userId,
-	}
-
-	if sendErr := h.routerService.SendRoomPushToSelf(senderLoc, "ERROR", roomId, roomInfoBytes); sendErr != nil {
-		h.logger.Warn("Failed to send error response", "userId", userId, "roomId", roomId, "error", sendErr)
-	}
-}
-
-// mapErrorToCodeAndMsg 将 error 映射到错误码和错误消息
-func (h *CreateRoomHandler) mapErrorToCodeAndMsg(err error) (string, string) {
-	// 创建房间的特定错误
-	if err != nil {
-		errMsg := err.Error()
-		// 根据错误信息判断错误类型
-		if errors.Is(err, room.ErrRoomBusy) {
-			return "ROOM_BUSY", "房间正在处理其他操作"
-		}
-		// JSON 解析错误
-		if json.Unmarshal([]byte(""), &map[string]string{}) != nil {
-			return "INVALID_CONFIG", "房间配置格式错误"
-		}
-		// 其他通用错误
-		return "CREATE_FAILED", "创建房间失败: " + errMsg
-	}
-	return "CREATE_FAILED", "创建房间失败"
+	return roomCreate, nil
 }
 
 // JoinRoomHandler 加入房间
 type JoinRoomHandler struct {
-	roomService   *room.RoomService
-	routerService *service.RouterService
-	logger        *slog.Logger
+	roomService *room.RoomService
+	logger      *slog.Logger
 }
 
-func (h *JoinRoomHandler) Handle(ctx context.Context, req *proto.RoomRequest, accessNodeId string, connId int64, platform string) error {
+func (h *JoinRoomHandler) Handle(ctx context.Context, req *proto.RoomRequest) (*sharedModel.Room, error) {
 	h.logger.Info("Join room",
 		"userId", req.UserId,
 		"reqId", req.ReqId,
-		"roomId", req.RoomId,
-		"accessNodeId", accessNodeId)
+		"roomId", req.RoomId)
 
 	// 调用 Service 层处理业务逻辑
-	_, err := h.roomService.JoinRoom(ctx, room.JoinRoomParams{
+	snapshot, err := h.roomService.JoinRoom(ctx, room.JoinRoomParams{
 		UserId:   req.UserId,
 		RoomId:   req.RoomId,
 		Password: req.RoomConfig, // RoomConfig 用于传递密码
 	})
-
 	if err != nil {
 		h.logger.Warn("Failed to join room", "error", err, "userId", req.UserId, "roomId", req.RoomId)
-		h.sendErrorResponse(ctx, accessNodeId, connId, platform, req.UserId, req.RoomId, err)
-		return nil // 不阻塞消息处理
-	}
-
-	return nil
-}
-
-// sendErrorResponse 发送错误响应
-func (h *JoinRoomHandler) sendErrorResponse(ctx context.Context, accessNodeId string, connId int64, platform string, userId int64, roomId string, err error) {
-	// 将 error 映射到错误码和消息
-	errorCode, errorMsg := h.mapErrorToCodeAndMsg(err)
-
-	// 构建错误响应的 RoomInfo（使用 JSON）
-	errorInfo := map[string]interface{}{
-		"errorCode": errorCode,
-		"errorMsg":  errorMsg,
-		"roomId":    roomId,
-	}
-	roomInfoBytes, marshalErr := json.Marshal(errorInfo)
-	if marshalErr != nil {
-		h.logger.Error("Failed to marshal error info", "error", marshalErr)
-		return
-	}
-
-	// 通过 RouterService 发送错误响应
-	senderLoc := sharedModel.UserLocation{
-		AccessNodeId: accessNodeId,
-		ConnId:       connId,
-		Platform:     platform,
-		UserId:       userId,
-	}
-
-	if sendErr := h.routerService.SendRoomPushToSelf(senderLoc, "ERROR", roomId, roomInfoBytes); sendErr != nil {
-		h.logger.Warn("Failed to send error response", "userId", userId, "roomId", roomId, "error", sendErr)
+		return nil, err
 	}
-}
 
-// mapErrorToCodeAndMsg 将 error 映射到错误码和错误消息
-func (h *JoinRoomHandler) mapErrorToCodeAndMsg(err error) (string, string) {
-	switch {
-	case errors.Is(err, room.ErrRoomNotFound):
-		return "ROOM_NOT_FOUND", "房间已解散"
-	case errors.Is(err, room.ErrRoomFull):
-		return "ROOM_FULL", "房间已满"
-	case errors.Is(err, room.ErrRoomBusy):
-		return "ROOM_BUSY", "房间正在处理其他操作"
-	case errors.Is(err, room.ErrInvalidPassword):
-		return "INVALID_PASSWORD", "房间密码错误"
-	case errors.Is(err, room.ErrGameStarted):
-		return "GAME_STARTED", "游戏已开始"
-	case errors.Is(err, room.ErrAlreadyInRoom):
-		return "ALREADY_IN_ROOM", "您已在房间中"
-	case errors.Is(err, room.ErrLockFailed):
-		return "LOCK_FAILED", "无法获取房间锁"
-	default:
-		return "JOIN_FAILED", "加入房间失败"
-	}
+	return snapshot, nil
 }
 
 // LeaveRoomHandler 离开房间
 type LeaveRoomHandler struct {
-	roomService   *room.RoomService
-	routerService *service.RouterService
-	logger        *slog.Logger
+	roomService *room.RoomService
+	logger      *slog.Logger
 }
 
-func (h *LeaveRoomHandler) Handle(ctx context.Context, req *proto.RoomRequest, accessNodeId string, connId int64, platform string) error {
+func (h *LeaveRoomHandler) Handle(ctx context.Context, req *proto.RoomRequest) (*sharedModel.Room, error) {
 	h.logger.Info("Leave room",
 		"userId", req.UserId,
 		"reqId", req.ReqId,
-		"roomId", req.RoomId,
-		"accessNodeId", accessNodeId)
+		"roomId", req.RoomId)
 
 	// 调用 Service 层处理离开房间逻辑
-	_, err := h.roomService.LeaveRoom(ctx, room.LeaveRoomParams{
+	snapshot, err := h.roomService.LeaveRoom(ctx, room.LeaveRoomParams{
 		UserId: req.UserId,
 		RoomId: req.RoomId,
 	})
-
 	if err != nil {
 		h.logger.Warn("Failed to leave room", "error", err, "userId", req.UserId, "roomId", req.RoomId)
-		// 不阻塞消息处理，只记录日志
-		return nil
+		return nil, err
 	}
 
 	h.logger.Info("User left room successfully", "userId", req.UserId, "roomId", req.RoomId)
-	return nil
+	return snapshot, nil
 }
 
 // ReadyRoomHandler 准备/取消准备
 type ReadyRoomHandler struct {
-	roomService   *room.RoomService
-	routerService *service.RouterService
-	logger        *slog.Logger
+	roomService *room.RoomService
+	logger      *slog.Logger
 }
 
-func (h *ReadyRoomHandler) Handle(ctx context.Context, req *proto.RoomRequest, accessNodeId string, connId int64, platform string) error {
+func (h *ReadyRoomHandler) Handle(ctx context.Context, req *proto.RoomRequest) (*sharedModel.Room, error) {
 	h.logger.Info("Toggle ready in room",
 		"userId", req.UserId,
 		"reqId", req.ReqId,
-		"roomId", req.RoomId,
-		"accessNodeId", accessNodeId)
+		"roomId", req.RoomId)
 
 	// 调用 Service 层处理准备状态切换
-	_, err := h.roomService.ReadyRoom(ctx, room.ReadyRoomParams{
+	snapshot, err := h.roomService.ReadyRoom(ctx, room.ReadyRoomParams{
 		UserId: req.UserId,
 		RoomId: req.RoomId,
 	})
-
 	if err != nil {
 		h.logger.Warn("Failed to toggle ready", "error", err, "userId", req.UserId, "roomId", req.RoomId)
-		// 不阻塞消息处理，只记录日志
-		return nil
+		return nil, err
 	}
 
 	h.logger.Info("Ready state toggled successfully", "userId", req.UserId, "roomId", req.RoomId)
-	return nil
+	return snapshot, nil
 }
 
 // ChangeSeatHandler 换座位
 type ChangeSeatHandler struct {
-	roomService   *room.RoomService
-	routerService *service.RouterService
-	logger        *slog.Logger
+	roomService *room.RoomService
+	logger      *slog.Logger
 }
 
-func (h *ChangeSeatHandler) Handle(ctx context.Context, req *proto.RoomRequest, accessNodeId string, connId int64, platform string) error {
+func (h *ChangeSeatHandler) Handle(ctx context.Context, req *proto.RoomRequest) (*sharedModel.Room, error) {
 	h.logger.Info("Change seat",
 		"userId", req.UserId,
 		"reqId", req.ReqId,
 		"roomId", req.RoomId,
-		"targetSeatIndex", req.SeatIndex,
-		"accessNodeId", accessNodeId)
+		"targetSeatIndex", req.SeatIndex)
 
 	// 调用 Service 层处理换座位
-	_, err := h.roomService.ChangeSeat(ctx, room.ChangeSeatParams{
+	snapshot, err := h.roomService.ChangeSeat(ctx, room.ChangeSeatParams{
 		UserId:     req.UserId,
 		RoomId:     req.RoomId,
 		TargetSeat: req.SeatIndex,
 	})
-
 	if err != nil {
 		h.logger.Warn("Failed to change seat", "error", err, "userId", req.UserId, "roomId", req.RoomId)
-		// 不阻塞消息处理，只记录日志
-		return nil
+		return nil, err
 	}
 
 	h.logger.Info("Seat changed successfully", "userId", req.UserId, "roomId", req.RoomId, "newSeat", req.SeatIndex)
-	return nil
+	return snapshot, nil
 }
 
 // StartGameHandler 开始游戏
 type StartGameHandler struct {
-	roomService   *room.RoomService
-	gameService   *game.GameService
-	routerService *service.RouterService
-	logger        *slog.Logger
+	roomService *room.RoomService
+	gameService *game.GameService
+	logger      *slog.Logger
 }
 
-func (h *StartGameHandler) Handle(ctx context.Context, req *proto.RoomRequest, accessNodeId string, connId int64, platform string) error {
+func (h *StartGameHandler) Handle(ctx context.Context, req *proto.RoomRequest) (*sharedModel.Room, error) {
 	h.logger.Info("Start game",
 		"userId", req.UserId,
 		"reqId", req.ReqId,
 		"roomId", req.RoomId,
-		"gameType", req.GameType,
-		"accessNodeId", accessNodeId)
+		"gameType", req.GameType)
 
 	// 调用 Service 层进行验证并更新房间状态
-	room, err := h.roomService.StartGame(ctx, room.StartGameParams{
+	snapshot, err := h.roomService.StartGame(ctx, room.StartGameParams{
 		UserId: req.UserId,
 		RoomId: req.RoomId,
 	})
-
 	if err != nil {
 		h.logger.Warn("Failed to start game", "error", err, "userId", req.UserId, "roomId", req.RoomId)
-		// 不阻塞消息处理，只记录日志
-		return nil
+		return nil, err
 	}
 
 	// 调用 GameService 启动游戏（初始化游戏状态并广播）
-	if err := h.gameService.StartGame(ctx, room); err != nil {
+	if err := h.gameService.StartGame(ctx, snapshot); err != nil {
 		h.logger.Warn("Failed to initialize game", "error", err, "roomId", req.RoomId)
-		// 不阻塞消息处理，只记录日志
-		return nil
+		return nil, err
 	}
 
-	h.logger.Info("Game started successfully", "userId", req.UserId, "roomId", req.RoomId, "gameType", room.GameType)
-	return nil
+	h.logger.Info("Game started successfully", "userId", req.UserId, "roomId", req.RoomId, "gameType", snapshot.GameType)
+	return snapshot, nil
 }
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"fmt"
+	"testing"
+
+	"sudooom.im.logic/internal/room"
+	sharedModel "sudooom.im.shared/model"
+	"sudooom.im.shared/proto"
+)
+
+func TestBuildRoomResp(t *testing.T) {
+	req := &proto.RoomRequest{UserId: 1, ReqId: "req-1", Action: "JOIN", RoomId: "r1"}
+
+	tests := []struct {
+		name string
+		err  error
+		code string
+	}{
+		{"成功", nil, ""},
+		{"房间不存在", room.ErrRoomNotFound, "ROOM_NOT_FOUND"},
+		{"包装的错误", fmt.Errorf("join: %w", room.ErrRoomFull), "ROOM_FULL"},
+		{"锁冲突", room.ErrLockFailed, "ROOM_BUSY"},
+		{"未知操作", errUnknownRoomAction, "PARAM_ERROR"},
+		{"其他错误", fmt.Errorf("boom"), "UNKNOWN_ERROR"},
+	}
+
+	snapshot := &sharedModel.Room{RoomID: "r1", MaxPlayers: 4, Players: []sharedModel.RoomPlayer{{UserID: 1}}}
+	for _, tt := range tests {
+		var s *sharedModel.Room
+		if tt.err == nil {
+			s = snapshot
+		}
+		resp := buildRoomResp(req, s, tt.err)
+		if resp.ReqId != req.ReqId || resp.Action != req.Action || resp.RoomId != "r1" {
+			t.Errorf("%s: 响应未回传请求信息: %+v", tt.name, resp)
+		}
+		if resp.Code != tt.code {
+			t.Errorf("%s: code = %q, want %q", tt.name, resp.Code, tt.code)
+		}
+		if (tt.err != nil) != (resp.Msg != "") {
+			t.Errorf("%s: msg = %q", tt.name, resp.Msg)
+		}
+		if (tt.err == nil) != (resp.RoomInfo != nil) {
+			t.Errorf("%s: 仅成功时携带房间快照", tt.name)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+
+	"sudooom.im.logic/internal/service"
+	"sudooom.im.shared/proto"
+)
+
+// SystemHandler 系统公告处理器
+type SystemHandler struct {
+	announcementService *service.AnnouncementService
+	logger              *slog.Logger
+}
+
+// NewSystemHandler 创建系统公告处理器
+func NewSystemHandler(announcementService *service.AnnouncementService) *SystemHandler {
+	return &SystemHandler{
+		announcementService: announcementService,
+		logger:              slog.Default(),
+	}
+}
+
+// HandleAnnouncement 发布系统公告
+func (h *SystemHandler) HandleAnnouncement(ctx context.Context, req *proto.SystemAnnouncement) (*proto.SystemAnnouncementResult, error) {
+	result, err := h.announcementService.Publish(ctx, req)
+	if err != nil {
+		h.logger.Warn("Failed to publish system announcement", "all", req.All, "users", len(req.UserIds), "error", err)
+		return nil, err
+	}
+	return result, nil
+}
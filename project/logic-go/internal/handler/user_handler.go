@@ -2,9 +2,11 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 
 	"sudooom.im.logic/internal/service"
+	sharedModel "sudooom.im.shared/model"
 	"sudooom.im.shared/proto"
 )
 
@@ -12,47 +14,71 @@ import (
 type UserHandler struct {
 	conversationService *service.ConversationService
 	routerService       *service.RouterService
+	announcementService *service.AnnouncementService
 	logger              *slog.Logger
 }
 
 // NewUserHandler 创建用户事件处理器
-func NewUserHandler(conversationService *service.ConversationService, routerService *service.RouterService) *UserHandler {
+func NewUserHandler(conversationService *service.ConversationService, routerService *service.RouterService, announcementService *service.AnnouncementService) *UserHandler {
 	return &UserHandler{
 		conversationService: conversationService,
 		routerService:       routerService,
+		announcementService: announcementService,
 		logger:              slog.Default(),
 	}
 }
 
 // HandleUserOnline 处理用户上线
-func (h *UserHandler) HandleUserOnline(ctx context.Context, event *proto.UserOnline, accessNodeId string) {
+func (h *UserHandler) HandleUserOnline(ctx context.Context, event *proto.UserOnline, accessNodeId string) error {
 	// location 由 access-go 管理，这里只记录日志
 	h.logger.Info("User online",
 		"userId", event.UserId,
 		"platform", event.Platform,
 		"deviceId", event.DeviceId,
 		"accessNodeId", accessNodeId)
+
+	// 补发有效期内错过的系统公告
+	loc := sharedModel.UserLocation{
+		AccessNodeId: accessNodeId,
+		ConnId:       event.ConnId,
+		UserId:       event.UserId,
+		Platform:     event.Platform,
+	}
+	delivered, err := h.announcementService.DeliverPending(ctx, loc)
+	if err != nil {
+		return fmt.Errorf("deliver pending announcements to user %d: %w", event.UserId, err)
+	}
+	if delivered > 0 {
+		h.logger.Info("Delivered pending announcements", "userId", event.UserId, "platform", event.Platform, "count", delivered)
+	}
+	return nil
 }
 
 // HandleUserOffline 处理用户下线
-func (h *UserHandler) HandleUserOffline(ctx context.Context, event *proto.UserOffline, accessNodeId string) {
+func (h *UserHandler) HandleUserOffline(ctx context.Context, event *proto.UserOffline, accessNodeId string) error {
 	// 清除位置缓存
 	h.routerService.InvalidateUserCache(event.UserId)
 
+	// 在线期间的公告已实时推送，推进投递游标
+	if err := h.announcementService.MarkOffline(ctx, event.UserId, event.Platform); err != nil {
+		h.logger.Warn("Failed to advance announcement cursor", "userId", event.UserId, "error", err)
+	}
+
 	h.logger.Info("User offline",
 		"userId", event.UserId,
 		"accessNodeId", accessNodeId)
+	return nil
 }
 
 // HandleConversationRead 处理会话已读
-func (h *UserHandler) HandleConversationRead(ctx context.Context, event *proto.ConversationRead) {
+func (h *UserHandler) HandleConversationRead(ctx context.Context, event *proto.ConversationRead) error {
 	if err := h.conversationService.MarkRead(ctx, event.UserId, event.PeerID, event.GroupID, event.LastReadMsgID); err != nil {
-		h.logger.Error("Failed to mark conversation read", "userId", event.UserId, "error", err)
-		return
+		return fmt.Errorf("mark conversation read for user %d: %w", event.UserId, err)
 	}
 	h.logger.Debug("Conversation marked read",
 		"userId", event.UserId,
 		"peerId", event.PeerID,
 		"groupId", event.GroupID,
 		"lastReadMsgId", event.LastReadMsgID)
+	return nil
 }
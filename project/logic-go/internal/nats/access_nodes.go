@@ -0,0 +1,68 @@
+package nats
+
+import (
+	"encoding/json"
+	"log/slog"
+
+	"sudooom.im.shared/bus"
+	sharedModel "sudooom.im.shared/model"
+	sharedNats "sudooom.im.shared/nats"
+)
+
+// AccessNodeLister 存活 Access 节点查询接口
+type AccessNodeLister interface {
+	LiveNodes() []sharedModel.AccessNode
+}
+
+// AccessNodesResponder 存活 Access 节点查询订阅器（request/reply，队列组内任一 Logic 节点应答）
+// 例：nats req im.logic.access.nodes ""
+type AccessNodesResponder struct {
+	msgBus       bus.Bus
+	lister       AccessNodeLister
+	logger       *slog.Logger
+	subscription bus.Subscription
+}
+
+// NewAccessNodesResponder 创建存活节点查询订阅器
+func NewAccessNodesResponder(msgBus bus.Bus, lister AccessNodeLister) *AccessNodesResponder {
+	return &AccessNodesResponder{
+		msgBus: msgBus,
+		lister: lister,
+		logger: slog.Default(),
+	}
+}
+
+// Start 启动订阅
+func (r *AccessNodesResponder) Start() error {
+	sub, err := r.msgBus.QueueSubscribe(sharedNats.SubjectLogicAccessNodes, sharedNats.QueueGroupLogic, r.handleRequest)
+	if err != nil {
+		return err
+	}
+
+	r.subscription = sub
+	r.logger.Info("Access nodes responder started", "subject", sharedNats.SubjectLogicAccessNodes)
+	return nil
+}
+
+// handleRequest 回复存活节点列表
+func (r *AccessNodesResponder) handleRequest(msg *bus.Msg) {
+	if msg.Reply == "" {
+		return
+	}
+	data, err := json.Marshal(r.lister.LiveNodes())
+	if err != nil {
+		r.logger.Error("Failed to marshal access nodes", "error", err)
+		return
+	}
+	if err := msg.Respond(data); err != nil {
+		r.logger.Warn("Failed to respond access nodes request", "error", err)
+	}
+}
+
+// Stop 停止订阅
+func (r *AccessNodesResponder) Stop() error {
+	if r.subscription != nil {
+		return r.subscription.Unsubscribe()
+	}
+	return nil
+}
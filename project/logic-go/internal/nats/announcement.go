@@ -0,0 +1,81 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"sudooom.im.shared/bus"
+	sharedNats "sudooom.im.shared/nats"
+	"sudooom.im.shared/proto"
+)
+
+// AnnouncementHandler 系统公告处理器接口
+type AnnouncementHandler interface {
+	HandleSystemAnnouncement(ctx context.Context, req *proto.SystemAnnouncement) (*proto.SystemAnnouncementResult, error)
+}
+
+// AnnouncementSubscriber 系统公告请求订阅器（request/reply，队列组内任一 Logic 节点处理）
+// 例：nats req im.logic.system.announce '{"All":true,"Title":"维护通知","Content":"...","TTLSeconds":3600}'
+type AnnouncementSubscriber struct {
+	msgBus       bus.Bus
+	handler      AnnouncementHandler
+	logger       *slog.Logger
+	subscription bus.Subscription
+}
+
+// NewAnnouncementSubscriber 创建系统公告请求订阅器
+func NewAnnouncementSubscriber(msgBus bus.Bus, handler AnnouncementHandler) *AnnouncementSubscriber {
+	return &AnnouncementSubscriber{
+		msgBus:  msgBus,
+		handler: handler,
+		logger:  slog.Default(),
+	}
+}
+
+// Start 启动订阅
+func (s *AnnouncementSubscriber) Start(ctx context.Context) error {
+	sub, err := s.msgBus.QueueSubscribe(sharedNats.SubjectLogicSystemAnnounce, sharedNats.QueueGroupLogic, func(msg *bus.Msg) {
+		s.handleRequest(ctx, msg)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.subscription = sub
+	s.logger.Info("Announcement subscriber started", "subject", sharedNats.SubjectLogicSystemAnnounce)
+	return nil
+}
+
+// handleRequest 处理公告请求并回复结果
+func (s *AnnouncementSubscriber) handleRequest(ctx context.Context, msg *bus.Msg) {
+	var req proto.SystemAnnouncement
+	result := &proto.SystemAnnouncementResult{}
+	if err := json.Unmarshal(msg.Data, &req); err != nil {
+		result.Error = "invalid request: " + err.Error()
+	} else if res, err := s.handler.HandleSystemAnnouncement(ctx, &req); err != nil {
+		result.Error = err.Error()
+	} else {
+		result = res
+	}
+
+	if msg.Reply == "" {
+		return
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		s.logger.Error("Failed to marshal announcement result", "error", err)
+		return
+	}
+	if err := msg.Respond(data); err != nil {
+		s.logger.Warn("Failed to respond announcement request", "error", err)
+	}
+}
+
+// Stop 停止订阅
+func (s *AnnouncementSubscriber) Stop() error {
+	if s.subscription != nil {
+		return s.subscription.Unsubscribe()
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"sudooom.im.shared/bus"
+	sharedModel "sudooom.im.shared/model"
+	sharedNats "sudooom.im.shared/nats"
+	"sudooom.im.shared/proto"
+)
+
+// onlineRecorder 仅记录上线事件的 MessageHandler
+type onlineRecorder struct {
+	MessageHandler
+	online chan string
+}
+
+func (r *onlineRecorder) HandleUserOnline(_ context.Context, event *proto.UserOnline, accessNodeId string) error {
+	r.online <- accessNodeId
+	return nil
+}
+
+type staticLister []sharedModel.AccessNode
+
+func (l staticLister) LiveNodes() []sharedModel.AccessNode { return l }
+
+// TestSubscribersOnMemoryBus 上行订阅与 request/reply 应答在进程内总线上与 NATS 行为一致
+func TestSubscribersOnMemoryBus(t *testing.T) {
+	msgBus := bus.NewMemoryBus(0)
+	defer msgBus.Close()
+
+	handler := &onlineRecorder{online: make(chan string, 1)}
+	subscriber := NewMessageSubscriber(msgBus, handler, SubscriberConfig{WorkerCount: 1, BufferSize: 8})
+	if err := subscriber.Start(context.Background()); err != nil {
+		t.Fatalf("Start subscriber failed: %v", err)
+	}
+	defer subscriber.Stop()
+
+	responder := NewAccessNodesResponder(msgBus, staticLister{{NodeId: "access-1"}})
+	if err := responder.Start(); err != nil {
+		t.Fatalf("Start responder failed: %v", err)
+	}
+	defer responder.Stop()
+
+	// 滚动升级期间 JSON 与二进制信封的上行消息都能处理
+	for _, codec := range []proto.Codec{proto.CodecJSON, proto.CodecBinary} {
+		data, _ := codec.MarshalUpstream(&proto.UpstreamMessage{
+			AccessNodeId: "access-1",
+			Payload:      proto.UpstreamPayload{UserOnline: &proto.UserOnline{UserId: 1}},
+		})
+		if err := msgBus.Publish(sharedNats.SubjectLogicUpstream, data); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+		select {
+		case node := <-handler.online:
+			if node != "access-1" {
+				t.Fatalf("%s: accessNodeId = %q, want access-1", codec, node)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: 未收到上行消息", codec)
+		}
+	}
+
+	reply, err := msgBus.Request(sharedNats.SubjectLogicAccessNodes, nil, time.Second)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	var nodes []sharedModel.AccessNode
+	if err := json.Unmarshal(reply, &nodes); err != nil || len(nodes) != 1 || nodes[0].NodeId != "access-1" {
+		t.Fatalf("nodes = %+v, err = %v", nodes, err)
+	}
+}
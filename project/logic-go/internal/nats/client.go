@@ -6,10 +6,12 @@ import (
 
 	"github.com/nats-io/nats.go"
 	"sudooom.im.logic/internal/config"
+	"sudooom.im.shared/bus"
 )
 
-// Client NATS 客户端封装
+// Client NATS 客户端封装，实现 bus.Bus
 type Client struct {
+	*bus.NATSBus
 	conn   *nats.Conn
 	logger *slog.Logger
 }
@@ -37,8 +39,9 @@ func NewClient(cfg config.NATSConfig) (*Client, error) {
 	}
 
 	return &Client{
-		conn:   conn,
-		logger: slog.Default(),
+		NATSBus: bus.NewNATSBus(conn),
+		conn:    conn,
+		logger:  slog.Default(),
 	}, nil
 }
 
@@ -0,0 +1,201 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"sudooom.im.logic/internal/config"
+	sharedNats "sudooom.im.shared/nats"
+)
+
+// JetStream 默认配置
+const (
+	defaultJetStreamMaxAge     = 24 * time.Hour
+	defaultJetStreamAckWait    = 30 * time.Second
+	defaultJetStreamMaxDeliver = 5
+	defaultJetStreamNakDelay   = time.Second
+	defaultJetStreamDLQMaxAge  = 7 * 24 * time.Hour
+
+	// jetStreamSetupTimeout 创建流与消费者的超时时间
+	jetStreamSetupTimeout = 10 * time.Second
+)
+
+// SetJetStream 启用 JetStream 持久化消费，需在 Start 之前调用
+// 上行消息写入工作队列流，所有 Logic 节点共享同一持久消费者，处理完成后显式确认
+func (s *MessageSubscriber) SetJetStream(js jetstream.JetStream, cfg config.JetStreamConfig) {
+	if cfg.Replicas <= 0 {
+		cfg.Replicas = 1
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = defaultJetStreamMaxAge
+	}
+	if cfg.AckWait <= 0 {
+		cfg.AckWait = defaultJetStreamAckWait
+	}
+	if cfg.MaxDeliver <= 0 {
+		cfg.MaxDeliver = defaultJetStreamMaxDeliver
+	}
+	if cfg.NakDelay <= 0 {
+		cfg.NakDelay = defaultJetStreamNakDelay
+	}
+	if cfg.DLQMaxAge <= 0 {
+		cfg.DLQMaxAge = defaultJetStreamDLQMaxAge
+	}
+	s.js = js
+	s.jsConfig = cfg
+}
+
+// startJetStream 创建上行流、死信流与持久消费者，并开始拉取消息
+func (s *MessageSubscriber) startJetStream(ctx context.Context) error {
+	setupCtx, cancel := context.WithTimeout(ctx, jetStreamSetupTimeout)
+	defer cancel()
+
+	cfg := s.jsConfig
+	if _, err := s.js.CreateOrUpdateStream(setupCtx, jetstream.StreamConfig{
+		Name:      sharedNats.StreamLogicUpstream,
+		Subjects:  []string{sharedNats.SubjectLogicUpstream},
+		Retention: jetstream.WorkQueuePolicy,
+		Storage:   jetstream.FileStorage,
+		Replicas:  cfg.Replicas,
+		MaxAge:    cfg.MaxAge,
+	}); err != nil {
+		return fmt.Errorf("create stream %s: %w", sharedNats.StreamLogicUpstream, err)
+	}
+	if _, err := s.js.CreateOrUpdateStream(setupCtx, jetstream.StreamConfig{
+		Name:      sharedNats.StreamLogicUpstreamDLQ,
+		Subjects:  []string{sharedNats.SubjectLogicUpstreamDLQ},
+		Retention: jetstream.LimitsPolicy,
+		Storage:   jetstream.FileStorage,
+		Replicas:  cfg.Replicas,
+		MaxAge:    cfg.DLQMaxAge,
+	}); err != nil {
+		return fmt.Errorf("create stream %s: %w", sharedNats.StreamLogicUpstreamDLQ, err)
+	}
+
+	consumer, err := s.js.CreateOrUpdateConsumer(setupCtx, sharedNats.StreamLogicUpstream, jetstream.ConsumerConfig{
+		Durable:       sharedNats.QueueGroupLogic,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       cfg.AckWait,
+		MaxDeliver:    cfg.MaxDeliver,
+		MaxAckPending: s.config.BufferSize + s.config.WorkerCount,
+	})
+	if err != nil {
+		return fmt.Errorf("create consumer %s: %w", sharedNats.QueueGroupLogic, err)
+	}
+
+	// 回调阻塞直到消息进入缓冲区，缓冲区满时停止拉取而不是丢弃
+	consumeCtx, err := consumer.Consume(func(msg jetstream.Msg) {
+		select {
+		case s.msgChan <- upstreamDelivery{jsMsg: msg}:
+		case <-ctx.Done():
+		}
+	}, jetstream.PullMaxMessages(s.config.WorkerCount))
+	if err != nil {
+		return fmt.Errorf("consume %s: %w", sharedNats.StreamLogicUpstream, err)
+	}
+	s.consumeCtx = consumeCtx
+
+	s.logger.Info("Upstream JetStream consumer started",
+		"stream", sharedNats.StreamLogicUpstream,
+		"consumer", sharedNats.QueueGroupLogic,
+		"workerCount", s.config.WorkerCount,
+		"bufferSize", s.config.BufferSize,
+		"maxDeliver", cfg.MaxDeliver,
+	)
+	return nil
+}
+
+// handleJetStreamMessage 处理 JetStream 消息，处理完成后确认（聊天消息在写入数据库后确认）
+func (s *MessageSubscriber) handleJetStreamMessage(ctx context.Context, msg jetstream.Msg) {
+	s.safeHandle(ctx, msg.Data(), false, func(err error) {
+		s.settleJetStreamMessage(ctx, msg, err)
+	})
+}
+
+// settleJetStreamMessage 根据处理结果确认消息
+// 处理失败时延迟重新投递；无法解析、写入数据库失败或已达最大投递次数的消息转入死信
+func (s *MessageSubscriber) settleJetStreamMessage(ctx context.Context, msg jetstream.Msg, err error) {
+	if err == nil {
+		if err := msg.Ack(); err != nil {
+			s.logger.Warn("Failed to ack upstream message", "error", err)
+		}
+		return
+	}
+
+	var deliveries uint64
+	var streamSeq uint64
+	if meta, metaErr := msg.Metadata(); metaErr == nil {
+		deliveries = meta.NumDelivered
+		streamSeq = meta.Sequence.Stream
+	}
+
+	retryable := !errors.Is(err, errMalformedUpstream) && !errors.Is(err, errMessageNotSaved)
+	if retryable && deliveries < uint64(s.jsConfig.MaxDeliver) {
+		s.logger.Warn("Upstream message failed, scheduling redelivery",
+			"streamSeq", streamSeq, "deliveries", deliveries, "error", err)
+		if err := msg.NakWithDelay(s.jsConfig.NakDelay); err != nil {
+			s.logger.Warn("Failed to nak upstream message", "error", err)
+		}
+		return
+	}
+
+	if dlqErr := s.deadLetter(ctx, msg, err, deliveries, streamSeq); dlqErr != nil {
+		// 死信写入失败时保留原消息，稍后重新投递
+		s.logger.Error("Failed to dead-letter upstream message", "streamSeq", streamSeq, "error", dlqErr)
+		if err := msg.NakWithDelay(s.jsConfig.NakDelay); err != nil {
+			s.logger.Warn("Failed to nak upstream message", "error", err)
+		}
+		return
+	}
+	s.logger.Error("Upstream message moved to dead-letter subject",
+		"subject", sharedNats.SubjectLogicUpstreamDLQ, "streamSeq", streamSeq, "deliveries", deliveries, "error", err)
+	if err := msg.Term(); err != nil {
+		s.logger.Warn("Failed to terminate upstream message", "error", err)
+	}
+}
+
+// safeHandle 处理上行消息，panic 视为处理失败；done 只调用一次
+func (s *MessageSubscriber) safeHandle(ctx context.Context, data []byte, forwarded bool, done func(error)) {
+	var once sync.Once
+	finish := func(err error) {
+		once.Do(func() { done(err) })
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			finish(fmt.Errorf("handler panic: %v", r))
+		}
+	}()
+	s.handleUpstreamMessage(ctx, data, forwarded, finish)
+}
+
+// deadLetter 将原消息连同失败原因写入死信 Subject，等待流确认后返回
+func (s *MessageSubscriber) deadLetter(ctx context.Context, msg jetstream.Msg, reason error, deliveries, streamSeq uint64) error {
+	dlq := nats.NewMsg(sharedNats.SubjectLogicUpstreamDLQ)
+	dlq.Data = msg.Data()
+	dlq.Header.Set(sharedNats.HeaderDLQReason, reason.Error())
+	dlq.Header.Set(sharedNats.HeaderDLQDeliveries, strconv.FormatUint(deliveries, 10))
+	dlq.Header.Set(sharedNats.HeaderDLQStreamSeq, strconv.FormatUint(streamSeq, 10))
+
+	publishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jetStreamSetupTimeout)
+	defer cancel()
+	_, err := s.js.PublishMsg(publishCtx, dlq)
+	return err
+}
+
+// nakPending 停止后对缓冲区中未处理的 JetStream 消息立即 nak
+func (s *MessageSubscriber) nakPending() {
+	if s.msgChan == nil {
+		return
+	}
+	for delivery := range s.msgChan {
+		if delivery.jsMsg != nil {
+			_ = delivery.jsMsg.Nak()
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"sudooom.im.logic/internal/config"
+	"sudooom.im.shared/bus"
+	sharedNats "sudooom.im.shared/nats"
+	"sudooom.im.shared/proto"
+)
+
+// runJetStreamServer 启动开启 JetStream 的内嵌 nats-server
+func runJetStreamServer(t *testing.T) (*nats.Conn, jetstream.JetStream) {
+	t.Helper()
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatalf("create nats-server failed: %v", err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatalf("nats-server not ready")
+	}
+	t.Cleanup(srv.Shutdown)
+
+	nc, err := nats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	t.Cleanup(nc.Close)
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Fatalf("create JetStream failed: %v", err)
+	}
+	return nc, js
+}
+
+func publishOnline(t *testing.T, nc *nats.Conn, userID int64) {
+	t.Helper()
+	data, _ := proto.CodecJSON.MarshalUpstream(&proto.UpstreamMessage{
+		AccessNodeId: "access-1",
+		Payload:      proto.UpstreamPayload{UserOnline: &proto.UserOnline{UserId: userID}},
+	})
+	if err := nc.Publish(sharedNats.SubjectLogicUpstream, data); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+}
+
+// waitStreamMsgs 等待流中消息数达到 want
+func waitStreamMsgs(t *testing.T, js jetstream.JetStream, stream string, want uint64) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		s, err := js.Stream(context.Background(), stream)
+		if err != nil {
+			t.Fatalf("get stream %s failed: %v", stream, err)
+		}
+		info, err := s.Info(context.Background())
+		if err == nil && info.State.Msgs == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("stream %s msgs = %d, want %d", stream, info.State.Msgs, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func newJetStreamSubscriber(js jetstream.JetStream, handler MessageHandler, cfg config.JetStreamConfig) *MessageSubscriber {
+	subscriber := NewMessageSubscriber(bus.NewMemoryBus(0), handler, SubscriberConfig{WorkerCount: 2, BufferSize: 8})
+	subscriber.SetJetStream(js, cfg)
+	return subscriber
+}
+
+// TestJetStreamDurableUpstream Logic 停止期间发布的上行消息在重启后送达，确认后从工作队列移除
+func TestJetStreamDurableUpstream(t *testing.T) {
+	nc, js := runJetStreamServer(t)
+	handler := &onlineRecorder{online: make(chan string, 8)}
+
+	first := newJetStreamSubscriber(js, handler, config.JetStreamConfig{})
+	if err := first.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	first.Stop()
+
+	// 无 Logic 在线时由 Access 以 core NATS 发布
+	for i := int64(1); i <= 3; i++ {
+		publishOnline(t, nc, i)
+	}
+	waitStreamMsgs(t, js, sharedNats.StreamLogicUpstream, 3)
+
+	second := newJetStreamSubscriber(js, handler, config.JetStreamConfig{})
+	if err := second.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer second.Stop()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-handler.online:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("只收到 %d 条上行消息", i)
+		}
+	}
+	waitStreamMsgs(t, js, sharedNats.StreamLogicUpstream, 0)
+}
+
+// failingHandler 对指定用户的上线事件始终处理失败
+type failingHandler struct {
+	MessageHandler
+	failUserID int64
+	attempts   atomic.Int32
+}
+
+func (h *failingHandler) HandleUserOnline(_ context.Context, event *proto.UserOnline, _ string) error {
+	if event.UserId == h.failUserID {
+		h.attempts.Add(1)
+		return errors.New("handler failure")
+	}
+	return nil
+}
+
+// TestJetStreamDeadLetter 处理失败的消息重新投递至 MaxDeliver 次后进入死信，无法解析的消息直接进入死信
+func TestJetStreamDeadLetter(t *testing.T) {
+	nc, js := runJetStreamServer(t)
+	handler := &failingHandler{failUserID: 1}
+
+	subscriber := newJetStreamSubscriber(js, handler, config.JetStreamConfig{MaxDeliver: 3, NakDelay: 10 * time.Millisecond})
+	if err := subscriber.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer subscriber.Stop()
+
+	publishOnline(t, nc, 1)
+	if err := nc.Publish(sharedNats.SubjectLogicUpstream, []byte("not an envelope")); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	publishOnline(t, nc, 2)
+
+	waitStreamMsgs(t, js, sharedNats.StreamLogicUpstreamDLQ, 2)
+	waitStreamMsgs(t, js, sharedNats.StreamLogicUpstream, 0)
+	if got := handler.attempts.Load(); got != 3 {
+		t.Fatalf("attempts = %d, want 3", got)
+	}
+
+	dlq, err := js.Stream(context.Background(), sharedNats.StreamLogicUpstreamDLQ)
+	if err != nil {
+		t.Fatalf("get DLQ stream failed: %v", err)
+	}
+	deliveries := map[string]string{}
+	for seq := uint64(1); seq <= 2; seq++ {
+		msg, err := dlq.GetMsg(context.Background(), seq)
+		if err != nil {
+			t.Fatalf("GetMsg(%d) failed: %v", seq, err)
+		}
+		if msg.Header.Get(sharedNats.HeaderDLQReason) == "" || msg.Header.Get(sharedNats.HeaderDLQStreamSeq) == "" {
+			t.Fatalf("死信缺少消息头: %v", msg.Header)
+		}
+		deliveries[string(msg.Data)] = msg.Header.Get(sharedNats.HeaderDLQDeliveries)
+	}
+	if got := deliveries["not an envelope"]; got != "1" {
+		t.Fatalf("无法解析的消息 deliveries = %q, want 1", got)
+	}
+	if len(deliveries) != 2 {
+		t.Fatalf("死信内容不正确: %v", deliveries)
+	}
+	for data, got := range deliveries {
+		if data != "not an envelope" && got != "3" {
+			t.Fatalf("处理失败的消息 deliveries = %q, want 3", got)
+		}
+	}
+}
+
+// savingHandler 将聊天消息的写入回调交给测试控制
+type savingHandler struct {
+	MessageHandler
+	saved chan func(error)
+}
+
+func (h *savingHandler) HandleUserMessage(_ context.Context, _ *proto.UserMessage, _ string, _ int64, _ string, saved func(error)) error {
+	h.saved <- saved
+	return nil
+}
+
+// TestJetStreamChatAckAfterSave 聊天消息写入数据库后才确认，写入失败的消息直接进入死信而不重新投递
+func TestJetStreamChatAckAfterSave(t *testing.T) {
+	nc, js := runJetStreamServer(t)
+	handler := &savingHandler{saved: make(chan func(error), 4)}
+
+	subscriber := newJetStreamSubscriber(js, handler, config.JetStreamConfig{NakDelay: 10 * time.Millisecond})
+	if err := subscriber.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer subscriber.Stop()
+
+	for _, clientMsgId := range []string{"c1", "c2"} {
+		data, _ := proto.CodecJSON.MarshalUpstream(&proto.UpstreamMessage{
+			AccessNodeId: "access-1",
+			Payload:      proto.UpstreamPayload{UserMessage: &proto.UserMessage{ClientMsgId: clientMsgId, FromUserId: 1, ToUserId: 2}},
+		})
+		if err := nc.Publish(sharedNats.SubjectLogicUpstream, data); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+
+	var callbacks []func(error)
+	for len(callbacks) < 2 {
+		select {
+		case saved := <-handler.saved:
+			callbacks = append(callbacks, saved)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("只收到 %d 条聊天消息", len(callbacks))
+		}
+	}
+
+	// 写入完成前消息保持未确认
+	time.Sleep(50 * time.Millisecond)
+	waitStreamMsgs(t, js, sharedNats.StreamLogicUpstream, 2)
+
+	callbacks[0](nil)
+	waitStreamMsgs(t, js, sharedNats.StreamLogicUpstream, 1)
+
+	callbacks[1](errors.New("db down"))
+	waitStreamMsgs(t, js, sharedNats.StreamLogicUpstreamDLQ, 1)
+	waitStreamMsgs(t, js, sharedNats.StreamLogicUpstream, 0)
+	select {
+	case <-handler.saved:
+		t.Fatalf("写入失败的消息不应重新投递")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -1,38 +1,44 @@
 package nats
 
 import (
-	"encoding/json"
 	"log/slog"
 
-	"github.com/nats-io/nats.go"
+	"sudooom.im.shared/bus"
 	sharedNats "sudooom.im.shared/nats"
 	"sudooom.im.shared/proto"
 )
 
 // MessagePublisher 消息发布器
 type MessagePublisher struct {
-	nc     *nats.Conn
+	msgBus bus.Bus
+	codec  proto.Codec
 	logger *slog.Logger
 }
 
 // NewMessagePublisher 创建消息发布器
-func NewMessagePublisher(nc *nats.Conn) *MessagePublisher {
+func NewMessagePublisher(msgBus bus.Bus) *MessagePublisher {
 	return &MessagePublisher{
-		nc:     nc,
+		msgBus: msgBus,
+		codec:  proto.CodecJSON,
 		logger: slog.Default(),
 	}
 }
 
+// SetCodec 设置下行消息编码（默认 JSON）
+func (p *MessagePublisher) SetCodec(codec proto.Codec) {
+	p.codec = codec
+}
+
 // PublishToAccess 推送消息到指定 Access 节点
 func (p *MessagePublisher) PublishToAccess(accessNodeId string, message *proto.DownstreamMessage) error {
 	subject := sharedNats.BuildAccessDownstreamSubject(accessNodeId)
-	data, err := json.Marshal(message)
+	data, err := p.codec.MarshalDownstream(message)
 	if err != nil {
 		p.logger.Error("Failed to marshal message", "error", err)
 		return err
 	}
 
-	if err := p.nc.Publish(subject, data); err != nil {
+	if err := p.msgBus.Publish(subject, data); err != nil {
 		p.logger.Error("Failed to publish to access", "accessNodeId", accessNodeId, "error", err)
 		return err
 	}
@@ -43,13 +49,13 @@ func (p *MessagePublisher) PublishToAccess(accessNodeId string, message *proto.D
 
 // Broadcast 广播消息到所有 Access 节点
 func (p *MessagePublisher) Broadcast(message *proto.DownstreamMessage) error {
-	data, err := json.Marshal(message)
+	data, err := p.codec.MarshalDownstream(message)
 	if err != nil {
 		p.logger.Error("Failed to marshal broadcast message", "error", err)
 		return err
 	}
 
-	if err := p.nc.Publish(sharedNats.SubjectAccessBroadcast, data); err != nil {
+	if err := p.msgBus.Publish(sharedNats.SubjectAccessBroadcast, data); err != nil {
 		p.logger.Error("Failed to broadcast message", "error", err)
 		return err
 	}
@@ -0,0 +1,131 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"sudooom.im.shared/bus"
+	sharedNats "sudooom.im.shared/nats"
+	"sudooom.im.shared/proto"
+)
+
+// staticRouter 房间归属固定的 RoomRouter
+type staticRouter struct {
+	nodeID string
+	owners map[string]string
+}
+
+func (r staticRouter) NodeID() string { return r.nodeID }
+
+func (r staticRouter) ResolveOwner(_ context.Context, roomId string) (string, error) {
+	if owner, ok := r.owners[roomId]; ok {
+		return owner, nil
+	}
+	return "", nil
+}
+
+// roomRecorder 记录处理房间与游戏请求的节点
+type roomRecorder struct {
+	MessageHandler
+	nodeID string
+	rooms  chan [2]string // {nodeID, roomId}
+}
+
+func (r *roomRecorder) HandleRoomRequest(_ context.Context, req *proto.RoomRequest, _ string, _ int64, _ string) error {
+	r.rooms <- [2]string{r.nodeID, req.RoomId}
+	return nil
+}
+
+func (r *roomRecorder) HandleGameRequest(_ context.Context, req *proto.GameRequest, _ string, _ int64, _ string) error {
+	r.rooms <- [2]string{r.nodeID, req.RoomId}
+	return nil
+}
+
+// TestRoomOwnershipRouting 队列组轮询到任一节点的房间与游戏请求都由房间归属节点处理
+func TestRoomOwnershipRouting(t *testing.T) {
+	msgBus := bus.NewMemoryBus(0)
+	defer msgBus.Close()
+
+	owners := map[string]string{"r1": "logic-b", "r2": "logic-a"}
+	rooms := make(chan [2]string, 16)
+	for _, nodeID := range []string{"logic-a", "logic-b"} {
+		subscriber := NewMessageSubscriber(msgBus, &roomRecorder{nodeID: nodeID, rooms: rooms}, SubscriberConfig{WorkerCount: 1, BufferSize: 8})
+		subscriber.SetRoomRouter(staticRouter{nodeID: nodeID, owners: owners})
+		if err := subscriber.Start(context.Background()); err != nil {
+			t.Fatalf("Start subscriber failed: %v", err)
+		}
+		defer subscriber.Stop()
+	}
+
+	// 每种请求各发送两次，队列组轮询保证两个节点都收到过
+	payloads := []proto.UpstreamPayload{
+		{RoomRequest: &proto.RoomRequest{UserId: 1, Action: "JOIN", RoomId: "r1"}},
+		{RoomRequest: &proto.RoomRequest{UserId: 1, Action: "JOIN", RoomId: "r1"}},
+		{GameRequest: &proto.GameRequest{UserId: 1, RoomId: "r2", GameType: "HT_MAHJONG"}},
+		{GameRequest: &proto.GameRequest{UserId: 1, RoomId: "r2", GameType: "HT_MAHJONG"}},
+		{RoomRequest: &proto.RoomRequest{UserId: 1, Action: "JOIN", RoomId: "r3"}},
+	}
+	for _, payload := range payloads {
+		data, _ := proto.CodecBinary.MarshalUpstream(&proto.UpstreamMessage{AccessNodeId: "access-1", Payload: payload})
+		if err := msgBus.Publish(sharedNats.SubjectLogicUpstream, data); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+
+	for i := range payloads {
+		select {
+		case got := <-rooms:
+			// 不存在的房间由收到请求的节点处理，不转发
+			if want, ok := owners[got[1]]; ok && got[0] != want {
+				t.Fatalf("房间 %s 的请求由 %s 处理，want %s", got[1], got[0], want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("只处理了 %d 条请求", i)
+		}
+	}
+}
+
+// failingRoomHandler 处理房间请求始终失败
+type failingRoomHandler struct {
+	MessageHandler
+}
+
+func (failingRoomHandler) HandleRoomRequest(context.Context, *proto.RoomRequest, string, int64, string) error {
+	return errors.New("room handler failure")
+}
+
+// TestRoomForwardFailure 归属节点不可达或处理失败时转发返回错误，JetStream 模式下据此重新投递
+func TestRoomForwardFailure(t *testing.T) {
+	msgBus := bus.NewMemoryBus(0)
+	defer msgBus.Close()
+
+	owners := map[string]string{"r1": "logic-b"}
+	a := NewMessageSubscriber(msgBus, &roomRecorder{nodeID: "logic-a", rooms: make(chan [2]string, 1)}, SubscriberConfig{WorkerCount: 1, BufferSize: 1})
+	a.SetRoomRouter(staticRouter{nodeID: "logic-a", owners: owners})
+
+	data, _ := proto.CodecBinary.MarshalUpstream(&proto.UpstreamMessage{
+		AccessNodeId: "access-1",
+		Payload:      proto.UpstreamPayload{RoomRequest: &proto.RoomRequest{UserId: 1, Action: "JOIN", RoomId: "r1"}},
+	})
+
+	var err error
+	a.handleUpstreamMessage(context.Background(), data, false, func(e error) { err = e })
+	if !errors.Is(err, bus.ErrNoResponders) {
+		t.Fatalf("归属节点不可达时 err = %v, want ErrNoResponders", err)
+	}
+
+	b := NewMessageSubscriber(msgBus, failingRoomHandler{}, SubscriberConfig{WorkerCount: 1, BufferSize: 1})
+	b.SetRoomRouter(staticRouter{nodeID: "logic-b", owners: owners})
+	if err := b.Start(context.Background()); err != nil {
+		t.Fatalf("Start subscriber failed: %v", err)
+	}
+	defer b.Stop()
+
+	a.handleUpstreamMessage(context.Background(), data, false, func(e error) { err = e })
+	if err == nil || !strings.Contains(err.Error(), "room handler failure") {
+		t.Fatalf("归属节点处理失败时 err = %v, want 携带失败原因", err)
+	}
+}
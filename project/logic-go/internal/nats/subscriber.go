@@ -2,23 +2,36 @@ package nats
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 
-	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"sudooom.im.logic/internal/config"
+	"sudooom.im.shared/bus"
 	sharedNats "sudooom.im.shared/nats"
 	"sudooom.im.shared/proto"
 )
 
-// MessageHandler 消息处理器接口
+// MessageHandler 消息处理器接口，返回错误表示处理失败，JetStream 模式下据此重新投递
 type MessageHandler interface {
-	HandleUserMessage(ctx context.Context, msg *proto.UserMessage, accessNodeId string, connId int64, platform string)
-	HandleUserOnline(ctx context.Context, event *proto.UserOnline, accessNodeId string)
-	HandleUserOffline(ctx context.Context, event *proto.UserOffline, accessNodeId string)
-	HandleConversationRead(ctx context.Context, event *proto.ConversationRead)
-	HandleRoomRequest(ctx context.Context, req *proto.RoomRequest, accessNodeId string, connId int64, platform string)
-	HandleGameRequest(ctx context.Context, req *proto.GameRequest, accessNodeId string, connId int64, platform string)
+	// HandleUserMessage 处理聊天消息，返回 nil 时消息写入数据库后调用 saved（恰好一次），被拒绝的消息以 nil 调用
+	// JetStream 模式下消息在 saved 回调时才确认
+	HandleUserMessage(ctx context.Context, msg *proto.UserMessage, accessNodeId string, connId int64, platform string, saved func(error)) error
+	HandleUserOnline(ctx context.Context, event *proto.UserOnline, accessNodeId string) error
+	HandleUserOffline(ctx context.Context, event *proto.UserOffline, accessNodeId string) error
+	HandleConversationRead(ctx context.Context, event *proto.ConversationRead) error
+	HandleRoomRequest(ctx context.Context, req *proto.RoomRequest, accessNodeId string, connId int64, platform string) error
+	HandleGameRequest(ctx context.Context, req *proto.GameRequest, accessNodeId string, connId int64, platform string) error
+}
+
+// RoomRouter 房间归属路由，房间与游戏请求只由房间归属节点处理
+type RoomRouter interface {
+	NodeID() string
+	// ResolveOwner 返回房间归属节点 ID，房间不存在时返回空字符串
+	ResolveOwner(ctx context.Context, roomId string) (string, error)
 }
 
 // SubscriberConfig Worker Pool 配置
@@ -27,20 +40,47 @@ type SubscriberConfig struct {
 	BufferSize  int // 消息缓冲区大小
 }
 
+// forwardTimeout 转发房间请求后等待归属节点处理完成的超时时间
+const forwardTimeout = 5 * time.Second
+
+var (
+	// errMalformedUpstream 上行消息无法解析，重新投递也无法处理
+	errMalformedUpstream = errors.New("malformed upstream message")
+	// errMessageNotSaved 聊天消息写入数据库失败；消息已推送给接收者，重新投递会重复推送
+	errMessageNotSaved = errors.New("chat message not saved")
+)
+
+// upstreamDelivery 待处理的上行消息
+type upstreamDelivery struct {
+	data      []byte
+	jsMsg     jetstream.Msg // JetStream 模式下用于确认，core NATS 模式为 nil
+	forwarded *bus.Msg      // 其他节点转发的房间请求，处理完成后回复转发节点
+}
+
 // MessageSubscriber 消息订阅器
 type MessageSubscriber struct {
-	nc           *nats.Conn
+	msgBus       bus.Bus
 	handler      MessageHandler
 	logger       *slog.Logger
-	subscription *nats.Subscription
+	subscription bus.Subscription
 	config       SubscriberConfig
-	msgChan      chan *nats.Msg
+	msgChan      chan upstreamDelivery
 	wg           sync.WaitGroup
 	cancelFunc   context.CancelFunc
+
+	// 房间归属路由（SetRoomRouter 设置后启用）
+	roomRouter  RoomRouter
+	nodeSub     bus.Subscription
+	forwardChan chan upstreamDelivery
+
+	// JetStream 模式（SetJetStream 设置后启用）
+	js         jetstream.JetStream
+	jsConfig   config.JetStreamConfig
+	consumeCtx jetstream.ConsumeContext
 }
 
 // NewMessageSubscriber 创建消息订阅器
-func NewMessageSubscriber(nc *nats.Conn, handler MessageHandler, config SubscriberConfig) *MessageSubscriber {
+func NewMessageSubscriber(msgBus bus.Bus, handler MessageHandler, config SubscriberConfig) *MessageSubscriber {
 	// 设置默认值
 	if config.WorkerCount <= 0 {
 		config.WorkerCount = 100
@@ -50,17 +90,23 @@ func NewMessageSubscriber(nc *nats.Conn, handler MessageHandler, config Subscrib
 	}
 
 	return &MessageSubscriber{
-		nc:      nc,
+		msgBus:  msgBus,
 		handler: handler,
 		logger:  slog.Default(),
 		config:  config,
 	}
 }
 
+// SetRoomRouter 启用房间归属路由，需在 Start 之前调用
+// 队列组中收到的房间与游戏请求转发给归属节点，并订阅本节点的定向 Subject 接收其他节点转发的请求
+func (s *MessageSubscriber) SetRoomRouter(router RoomRouter) {
+	s.roomRouter = router
+}
+
 // Start 启动订阅
 func (s *MessageSubscriber) Start(ctx context.Context) error {
 	// 创建带缓冲的消息通道
-	s.msgChan = make(chan *nats.Msg, s.config.BufferSize)
+	s.msgChan = make(chan upstreamDelivery, s.config.BufferSize)
 
 	// 创建可取消的上下文
 	workerCtx, cancel := context.WithCancel(ctx)
@@ -72,10 +118,25 @@ func (s *MessageSubscriber) Start(ctx context.Context) error {
 		go s.worker(workerCtx)
 	}
 
+	if s.roomRouter != nil {
+		if err := s.startNodeSubscription(workerCtx); err != nil {
+			cancel()
+			return err
+		}
+	}
+
+	if s.js != nil {
+		if err := s.startJetStream(workerCtx); err != nil {
+			cancel()
+			return fmt.Errorf("start jetstream consumer: %w", err)
+		}
+		return nil
+	}
+
 	// 订阅上行消息 - 使用队列组实现负载均衡
-	sub, err := s.nc.QueueSubscribe(sharedNats.SubjectLogicUpstream, sharedNats.QueueGroupLogic, func(msg *nats.Msg) {
+	sub, err := s.msgBus.QueueSubscribe(sharedNats.SubjectLogicUpstream, sharedNats.QueueGroupLogic, func(msg *bus.Msg) {
 		select {
-		case s.msgChan <- msg:
+		case s.msgChan <- upstreamDelivery{data: msg.Data}:
 			// 消息入队成功
 		default:
 			// 缓冲区满，记录警告
@@ -88,7 +149,7 @@ func (s *MessageSubscriber) Start(ctx context.Context) error {
 	}
 
 	s.subscription = sub
-	s.logger.Info("NATS subscriber started",
+	s.logger.Info("Upstream subscriber started",
 		"subject", sharedNats.SubjectLogicUpstream,
 		"workerCount", s.config.WorkerCount,
 		"bufferSize", s.config.BufferSize,
@@ -96,6 +157,55 @@ func (s *MessageSubscriber) Start(ctx context.Context) error {
 	return nil
 }
 
+// startNodeSubscription 订阅本节点的定向 Subject，接收其他节点转发的房间请求
+// 转发的请求由独立的 worker 处理，避免两个节点的 worker 都在等待对方处理转发请求而互相阻塞
+func (s *MessageSubscriber) startNodeSubscription(ctx context.Context) error {
+	s.forwardChan = make(chan upstreamDelivery, s.config.BufferSize)
+	for i := 0; i < s.config.WorkerCount; i++ {
+		s.wg.Add(1)
+		go s.forwardWorker(ctx)
+	}
+
+	subject := sharedNats.BuildLogicNodeSubject(s.roomRouter.NodeID())
+	sub, err := s.msgBus.Subscribe(subject, func(msg *bus.Msg) {
+		// 缓冲区满时阻塞回调而不是丢弃，转发节点等待超时后重新投递
+		select {
+		case s.forwardChan <- upstreamDelivery{data: msg.Data, forwarded: msg}:
+		case <-ctx.Done():
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("subscribe %s: %w", subject, err)
+	}
+	s.nodeSub = sub
+	s.logger.Info("Room ownership routing enabled", "subject", subject)
+	return nil
+}
+
+// forwardWorker 处理其他节点转发的房间请求，处理完成后回复转发节点：成功为空，失败为错误信息
+func (s *MessageSubscriber) forwardWorker(ctx context.Context) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case delivery := <-s.forwardChan:
+			msg := delivery.forwarded
+			s.safeHandle(ctx, delivery.data, true, func(err error) {
+				var reply []byte
+				if err != nil {
+					s.logger.Error("Failed to handle forwarded room request", "error", err)
+					reply = []byte(err.Error())
+				}
+				if err := msg.Respond(reply); err != nil {
+					s.logger.Warn("Failed to reply forwarded room request", "error", err)
+				}
+			})
+		}
+	}
+}
+
 // worker 工作协程
 func (s *MessageSubscriber) worker(ctx context.Context) {
 	defer s.wg.Done()
@@ -104,41 +214,111 @@ func (s *MessageSubscriber) worker(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			return
-		case msg, ok := <-s.msgChan:
+		case delivery, ok := <-s.msgChan:
 			if !ok {
 				return
 			}
-			s.handleUpstreamMessage(ctx, msg.Data)
+			if delivery.jsMsg != nil {
+				s.handleJetStreamMessage(ctx, delivery.jsMsg)
+				continue
+			}
+			s.handleUpstreamMessage(ctx, delivery.data, false, s.logUpstreamError)
 		}
 	}
 }
 
-// handleUpstreamMessage 处理上行消息
-func (s *MessageSubscriber) handleUpstreamMessage(ctx context.Context, data []byte) {
+// handleUpstreamMessage 处理上行消息，处理完成后调用 done：消息无法解析时为 errMalformedUpstream，
+// 聊天消息在写入数据库后才调用，写入失败时为 errMessageNotSaved
+// forwarded 为 false 时，归属其他节点的房间请求转发给归属节点
+func (s *MessageSubscriber) handleUpstreamMessage(ctx context.Context, data []byte, forwarded bool, done func(error)) {
 	var message proto.UpstreamMessage
 	s.logger.Info("Received message", "subject", sharedNats.SubjectLogicUpstream)
-	if err := json.Unmarshal(data, &message); err != nil {
-		s.logger.Error("Failed to unmarshal message", "error", err)
+	if err := proto.UnmarshalUpstream(data, &message); err != nil {
+		done(fmt.Errorf("%w: %w", errMalformedUpstream, err))
 		return
 	}
 
+	if !forwarded {
+		if sent, err := s.forwardToOwner(ctx, &message, data); sent || err != nil {
+			done(err)
+			return
+		}
+	}
+
 	accessNodeId := message.AccessNodeId
 	platform := message.Platform
 
+	var err error
 	switch {
 	case message.Payload.UserMessage != nil:
-		s.handler.HandleUserMessage(ctx, message.Payload.UserMessage, accessNodeId, message.ConnId, platform)
+		err = s.handler.HandleUserMessage(ctx, message.Payload.UserMessage, accessNodeId, message.ConnId, platform, func(err error) {
+			if err != nil {
+				err = fmt.Errorf("%w: %w", errMessageNotSaved, err)
+			}
+			done(err)
+		})
+		if err == nil {
+			return
+		}
 	case message.Payload.UserOnline != nil:
-		s.handler.HandleUserOnline(ctx, message.Payload.UserOnline, accessNodeId)
+		err = s.handler.HandleUserOnline(ctx, message.Payload.UserOnline, accessNodeId)
 	case message.Payload.UserOffline != nil:
-		s.handler.HandleUserOffline(ctx, message.Payload.UserOffline, accessNodeId)
+		err = s.handler.HandleUserOffline(ctx, message.Payload.UserOffline, accessNodeId)
 	case message.Payload.ConversationRead != nil:
-		s.handler.HandleConversationRead(ctx, message.Payload.ConversationRead)
+		err = s.handler.HandleConversationRead(ctx, message.Payload.ConversationRead)
+	case message.Payload.RoomRequest != nil:
+		err = s.handler.HandleRoomRequest(ctx, message.Payload.RoomRequest, accessNodeId, message.ConnId, platform)
+	case message.Payload.GameRequest != nil:
+		err = s.handler.HandleGameRequest(ctx, message.Payload.GameRequest, accessNodeId, message.ConnId, platform)
+	}
+	done(err)
+}
+
+// logUpstreamError core NATS 模式无法重新投递，处理失败只记录日志
+func (s *MessageSubscriber) logUpstreamError(err error) {
+	if err != nil {
+		s.logger.Error("Failed to handle upstream message", "error", err)
+	}
+}
+
+// forwardToOwner 将归属其他节点的房间与游戏请求原样转发给归属节点并等待处理完成，返回是否已转发
+// 归属节点未在 forwardTimeout 内处理完成或处理失败时返回错误，JetStream 模式下据此重新投递
+// 查询归属失败时在本节点处理，与未启用归属路由时行为一致
+func (s *MessageSubscriber) forwardToOwner(ctx context.Context, message *proto.UpstreamMessage, data []byte) (bool, error) {
+	if s.roomRouter == nil {
+		return false, nil
+	}
+	var roomId string
+	switch {
 	case message.Payload.RoomRequest != nil:
-		s.handler.HandleRoomRequest(ctx, message.Payload.RoomRequest, accessNodeId, message.ConnId, platform)
+		roomId = message.Payload.RoomRequest.RoomId
 	case message.Payload.GameRequest != nil:
-		s.handler.HandleGameRequest(ctx, message.Payload.GameRequest, accessNodeId, message.ConnId, platform)
+		roomId = message.Payload.GameRequest.RoomId
+	}
+	if roomId == "" {
+		// 创建房间等不指定房间的请求由本节点处理，新房间归属本节点
+		return false, nil
+	}
+
+	owner, err := s.roomRouter.ResolveOwner(ctx, roomId)
+	if err != nil {
+		s.logger.Warn("Failed to resolve room owner, handling locally", "roomId", roomId, "error", err)
+		return false, nil
 	}
+	if owner == "" || owner == s.roomRouter.NodeID() {
+		// 房间不存在时由本节点处理，本地处理器回复 ROOM_NOT_FOUND
+		return false, nil
+	}
+
+	reply, err := s.msgBus.Request(sharedNats.BuildLogicNodeSubject(owner), data, forwardTimeout)
+	if err != nil {
+		return true, fmt.Errorf("forward room request to %s: %w", owner, err)
+	}
+	if len(reply) > 0 {
+		return true, fmt.Errorf("owner %s failed to handle room request: %s", owner, reply)
+	}
+	s.logger.Debug("Forwarded room request to owner", "roomId", roomId, "owner", owner)
+	return true, nil
 }
 
 // Stop 停止订阅
@@ -154,6 +334,15 @@ func (s *MessageSubscriber) Stop() error {
 			s.logger.Error("Failed to unsubscribe", "error", err)
 		}
 	}
+	if s.nodeSub != nil {
+		if err := s.nodeSub.Unsubscribe(); err != nil {
+			s.logger.Error("Failed to unsubscribe node subject", "error", err)
+		}
+	}
+	if s.consumeCtx != nil {
+		s.consumeCtx.Stop()
+		<-s.consumeCtx.Closed()
+	}
 
 	// 关闭消息通道
 	if s.msgChan != nil {
@@ -163,7 +352,10 @@ func (s *MessageSubscriber) Stop() error {
 	// 等待所有 worker 完成
 	s.wg.Wait()
 
-	s.logger.Info("NATS subscriber stopped")
+	// 未处理的 JetStream 消息立即重新投递给其他节点，无需等待 AckWait
+	s.nakPending()
+
+	s.logger.Info("Upstream subscriber stopped")
 	return nil
 }
 
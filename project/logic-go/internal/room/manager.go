@@ -28,6 +28,10 @@ type RoomManager struct {
 	// 用于发送清理通知
 	roomService interface{} // 使用 interface{} 避免循环依赖，实际类型为 *RoomService
 
+	// 房间移除后释放归属、删除持久化状态，为空时跳过
+	ownership *Ownership
+	store     *RoomStore
+
 	logger *slog.Logger
 }
 
@@ -51,6 +55,16 @@ func (m *RoomManager) SetRoomService(rs interface{}) {
 	m.roomService = rs
 }
 
+// SetOwnership 设置房间归属服务，移除房间时释放归属
+func (m *RoomManager) SetOwnership(ownership *Ownership) {
+	m.ownership = ownership
+}
+
+// SetStore 设置房间状态存储，移除房间时删除持久化状态
+func (m *RoomManager) SetStore(store *RoomStore) {
+	m.store = store
+}
+
 // GetOrCreate 获取或创建房间
 func (m *RoomManager) GetOrCreate(roomId string, creatorID int64, config *model.RoomConfig, gameType string) *Room {
 	if val, ok := m.rooms.Load(roomId); ok {
@@ -62,6 +76,12 @@ func (m *RoomManager) GetOrCreate(roomId string, creatorID int64, config *model.
 	return actual.(*Room)
 }
 
+// Restore 放入由快照恢复的房间，房间已存在时返回现有实例
+func (m *RoomManager) Restore(snapshot *model.Room) *Room {
+	actual, _ := m.rooms.LoadOrStore(snapshot.RoomID, RestoreRoom(snapshot))
+	return actual.(*Room)
+}
+
 // Get 获取房间
 func (m *RoomManager) Get(roomId string) (*Room, bool) {
 	val, ok := m.rooms.Load(roomId)
@@ -72,8 +92,29 @@ func (m *RoomManager) Get(roomId string) (*Room, bool) {
 }
 
 // Remove 移除房间
+// 持有 persistMu 标记房间已移除后再删除持久化状态，避免进行中的写入在删除后复活房间
 func (m *RoomManager) Remove(roomId string) {
-	m.rooms.Delete(roomId)
+	if value, ok := m.rooms.LoadAndDelete(roomId); ok {
+		r := value.(*Room)
+		r.persistMu.Lock()
+		defer r.persistMu.Unlock()
+		r.removed = true
+	}
+
+	// 房间已被其他节点接管时保留持久化状态，由新归属节点负责
+	owned := true
+	if m.ownership != nil {
+		released, err := m.ownership.Release(context.Background(), roomId)
+		if err != nil {
+			m.logger.Warn("Failed to release room ownership", "roomId", roomId, "error", err)
+		}
+		owned = released
+	}
+	if owned && m.store != nil {
+		if err := m.store.Delete(context.Background(), roomId); err != nil {
+			m.logger.Warn("Failed to delete room state", "roomId", roomId, "error", err)
+		}
+	}
 	m.logger.Info("Removed room", "roomId", roomId)
 }
 
@@ -118,13 +159,10 @@ func (m *RoomManager) evictInactive() {
 			if m.roomService != nil {
 				// 使用类型断言
 				if rs, ok := m.roomService.(interface {
-					BroadcastToRoom(ctx context.Context, roomId string, event string, data interface{}) error
+					BroadcastToRoom(ctx context.Context, roomId string, event string, actorId int64) error
 				}); ok {
 					ctx := context.Background()
-					if err := rs.BroadcastToRoom(ctx, roomId, "room.evicted", map[string]interface{}{
-						"roomId": roomId,
-						"reason": "房间超时未活跃，已被自动清理",
-					}); err != nil {
+					if err := rs.BroadcastToRoom(ctx, roomId, "ROOM_DISMISSED", 0); err != nil {
 						m.logger.Warn("Failed to send eviction notification", "roomId", roomId, "error", err)
 					}
 				}
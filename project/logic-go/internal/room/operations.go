@@ -53,14 +53,20 @@ type StartGameParams struct {
 	RoomId string
 }
 
+// maxRoomIdAttempts 生成房间 ID 的最大尝试次数
+const maxRoomIdAttempts = 3
+
 // ============================================================================
 // 房间操作方法
 // ============================================================================
 
 // CreateRoom 创建房间
 func (s *RoomService) CreateRoom(ctx context.Context, params CreateRoomParams) (*model.Room, error) {
-	// 生成房间 ID
-	roomId := s.sfNode.Generate().String()
+	// 生成房间 ID，多节点部署时登记归属
+	roomId, err := s.newRoomId(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	// 构建房间配置
 	config := &model.RoomConfig{
@@ -96,16 +102,41 @@ func (s *RoomService) CreateRoom(ctx context.Context, params CreateRoomParams) (
 	}
 
 	s.logger.Info("Room created", "roomId", roomId, "creator", params.UserId)
+	s.persist(ctx, room)
+
+	// 推送房间快照给房主
+	if err := s.BroadcastToRoom(ctx, roomId, "USER_JOINED", params.UserId); err != nil {
+		return nil, err
+	}
 
 	return room.CopyRoomInfo(), nil
 }
 
+// newRoomId 生成房间 ID 并登记为本节点所有，ID 已被其他节点登记时重新生成
+func (s *RoomService) newRoomId(ctx context.Context) (string, error) {
+	for i := 0; i < maxRoomIdAttempts; i++ {
+		roomId := s.sfNode.Generate().String()
+		if s.ownership == nil {
+			return roomId, nil
+		}
+		claimed, err := s.ownership.Claim(ctx, roomId)
+		if err != nil {
+			s.logger.Error("Failed to claim room ownership", "error", err, "roomId", roomId)
+			return "", ErrLockFailed
+		}
+		if claimed {
+			return roomId, nil
+		}
+	}
+	return "", ErrLockFailed
+}
+
 // JoinRoom 加入房间
 func (s *RoomService) JoinRoom(ctx context.Context, params JoinRoomParams) (*model.Room, error) {
 	// 获取房间
-	room, ok := s.roomManager.Get(params.RoomId)
-	if !ok {
-		return nil, ErrRoomNotFound
+	room, err := s.getRoom(ctx, params.RoomId)
+	if err != nil {
+		return nil, err
 	}
 
 	// 获取策略（直接访问 roomInfo，避免不必要的拷贝）
@@ -131,23 +162,23 @@ func (s *RoomService) JoinRoom(ctx context.Context, params JoinRoomParams) (*mod
 	}
 
 	s.logger.Info("User joined room", "userId", params.UserId, "roomId", params.RoomId, "seatIndex", seatIndex)
+	s.persist(ctx, room)
 
 	// 广播房间更新
-	snapshot := room.CopyRoomInfo()
-	err = s.BroadcastToRoom(ctx, params.RoomId, "ROOM_UPDATED", snapshot)
+	err = s.BroadcastToRoom(ctx, params.RoomId, "USER_JOINED", params.UserId)
 	if err != nil {
 		return nil, err
 	}
 
-	return snapshot, nil
+	return room.CopyRoomInfo(), nil
 }
 
 // LeaveRoom 离开房间
 func (s *RoomService) LeaveRoom(ctx context.Context, params LeaveRoomParams) (*model.Room, error) {
 	// 获取房间
-	room, ok := s.roomManager.Get(params.RoomId)
-	if !ok {
-		return nil, ErrRoomNotFound
+	room, err := s.getRoom(ctx, params.RoomId)
+	if err != nil {
+		return nil, err
 	}
 
 	// 离开房间
@@ -157,23 +188,23 @@ func (s *RoomService) LeaveRoom(ctx context.Context, params LeaveRoomParams) (*m
 	}
 
 	s.logger.Info("User left room", "userId", params.UserId, "roomId", params.RoomId)
+	s.persist(ctx, room)
 
 	// 广播房间更新
-	snapshot := room.CopyRoomInfo()
-	err := s.BroadcastToRoom(ctx, params.RoomId, "ROOM_UPDATED", snapshot)
+	err = s.BroadcastToRoom(ctx, params.RoomId, "USER_LEFT", params.UserId)
 	if err != nil {
 		return nil, err
 	}
 
-	return snapshot, nil
+	return room.CopyRoomInfo(), nil
 }
 
 // ReadyRoom 准备/取消准备
 func (s *RoomService) ReadyRoom(ctx context.Context, params ReadyRoomParams) (*model.Room, error) {
 	// 获取房间
-	room, ok := s.roomManager.Get(params.RoomId)
-	if !ok {
-		return nil, ErrRoomNotFound
+	room, err := s.getRoom(ctx, params.RoomId)
+	if err != nil {
+		return nil, err
 	}
 
 	// 切换准备状态
@@ -183,23 +214,23 @@ func (s *RoomService) ReadyRoom(ctx context.Context, params ReadyRoomParams) (*m
 	}
 
 	s.logger.Info("User ready status changed", "userId", params.UserId, "roomId", params.RoomId)
+	s.persist(ctx, room)
 
 	// 广播房间更新
-	snapshot := room.CopyRoomInfo()
-	err := s.BroadcastToRoom(ctx, params.RoomId, "ROOM_UPDATED", snapshot)
+	err = s.BroadcastToRoom(ctx, params.RoomId, "USER_READY", params.UserId)
 	if err != nil {
 		return nil, err
 	}
 
-	return snapshot, nil
+	return room.CopyRoomInfo(), nil
 }
 
 // ChangeSeat 换座位
 func (s *RoomService) ChangeSeat(ctx context.Context, params ChangeSeatParams) (*model.Room, error) {
 	// 获取房间
-	room, ok := s.roomManager.Get(params.RoomId)
-	if !ok {
-		return nil, ErrRoomNotFound
+	room, err := s.getRoom(ctx, params.RoomId)
+	if err != nil {
+		return nil, err
 	}
 
 	// 换座位
@@ -209,23 +240,23 @@ func (s *RoomService) ChangeSeat(ctx context.Context, params ChangeSeatParams) (
 	}
 
 	s.logger.Info("User changed seat", "userId", params.UserId, "roomId", params.RoomId, "targetSeat", params.TargetSeat)
+	s.persist(ctx, room)
 
 	// 广播房间更新
-	snapshot := room.CopyRoomInfo()
-	err := s.BroadcastToRoom(ctx, params.RoomId, "ROOM_UPDATED", snapshot)
+	err = s.BroadcastToRoom(ctx, params.RoomId, "SEAT_CHANGED", params.UserId)
 	if err != nil {
 		return nil, err
 	}
 
-	return snapshot, nil
+	return room.CopyRoomInfo(), nil
 }
 
 // StartGame 开始游戏
 func (s *RoomService) StartGame(ctx context.Context, params StartGameParams) (*model.Room, error) {
 	// 获取房间
-	r, ok := s.roomManager.Get(params.RoomId)
-	if !ok {
-		return nil, ErrRoomNotFound
+	r, err := s.getRoom(ctx, params.RoomId)
+	if err != nil {
+		return nil, err
 	}
 
 	// 获取策略（直接访问 roomInfo）
@@ -241,15 +272,15 @@ func (s *RoomService) StartGame(ctx context.Context, params StartGameParams) (*m
 	}
 
 	s.logger.Info("Game started successfully", "userId", params.UserId, "roomId", params.RoomId)
+	s.persist(ctx, r)
 
 	// 广播游戏开始
-	snapshot := r.CopyRoomInfo()
-	err = s.BroadcastToRoom(ctx, params.RoomId, "GAME_STARTED", snapshot)
+	err = s.BroadcastToRoom(ctx, params.RoomId, "GAME_START", params.UserId)
 	if err != nil {
 		return nil, err
 	}
 
-	return snapshot, nil
+	return r.CopyRoomInfo(), nil
 }
 
 // getGameTypeStrategy 根据游戏类型获取对应的策略
@@ -0,0 +1,155 @@
+package room
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	sharedRedis "sudooom.im.shared/redis"
+)
+
+// resolveOwnerScript 返回房间归属节点；归属节点已宕机或房间无归属时，仅当房间状态仍在 Redis 中才由本节点接管，
+// 房间状态已不存在时清理失效的归属并返回 nil，避免为不存在的房间写入永久归属
+// KEYS[1]: room_owner:{roomId}  KEYS[2]: 本节点房间索引  KEYS[3]: room:{roomId}
+// ARGV[1]: 本节点 ID  ARGV[2]: roomId  ARGV[3]: Logic 节点 Key 前缀  ARGV[4]: 节点房间索引 Key 后缀
+var resolveOwnerScript = redis.NewScript(`
+local owner = redis.call('GET', KEYS[1])
+if owner and (owner == ARGV[1] or redis.call('EXISTS', ARGV[3] .. owner) == 1) then
+	return owner
+end
+if owner then
+	redis.call('SREM', ARGV[3] .. owner .. ARGV[4], ARGV[2])
+end
+if redis.call('EXISTS', KEYS[3]) == 0 then
+	if owner then
+		redis.call('DEL', KEYS[1])
+	end
+	return false
+end
+redis.call('SET', KEYS[1], ARGV[1])
+redis.call('SADD', KEYS[2], ARGV[2])
+return ARGV[1]
+`)
+
+// claimOwnerScript 为新建房间登记归属，已有归属时返回 0
+// KEYS[1]: room_owner:{roomId}  KEYS[2]: 本节点房间索引  ARGV[1]: 本节点 ID  ARGV[2]: roomId
+var claimOwnerScript = redis.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'NX') then
+	redis.call('SADD', KEYS[2], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// releaseOwnerScript 仅当房间仍归属本节点时删除归属，并从本节点房间索引移除
+// KEYS[1]: room_owner:{roomId}  KEYS[2]: 本节点房间索引  ARGV[1]: 本节点 ID  ARGV[2]: roomId
+var releaseOwnerScript = redis.NewScript(`
+redis.call('SREM', KEYS[2], ARGV[2])
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// Ownership 房间归属：房间状态只存在于创建它的 Logic 节点内存中，
+// 归属记录在 Redis，其他节点收到该房间的请求时转发给归属节点处理
+type Ownership struct {
+	redisClient *redis.Client
+	nodeID      string
+	startedAt   string // 节点启动时间（毫秒），写入存活 Key
+	logger      *slog.Logger
+}
+
+// NewOwnership 创建房间归属服务，nodeID 为本 Logic 节点 ID
+func NewOwnership(redisClient *redis.Client, nodeID string) *Ownership {
+	return &Ownership{
+		redisClient: redisClient,
+		nodeID:      nodeID,
+		startedAt:   strconv.FormatInt(time.Now().UnixMilli(), 10),
+		logger:      slog.Default().With("component", "RoomOwnership"),
+	}
+}
+
+// NodeID 返回本节点 ID
+func (o *Ownership) NodeID() string {
+	return o.nodeID
+}
+
+// ResolveOwner 返回房间归属节点 ID，房间不存在时返回空字符串
+// 归属节点超过 LogicNodeTTL 未心跳或房间无归属时，若房间状态仍在 Redis 中则由本节点接管并返回本节点 ID
+func (o *Ownership) ResolveOwner(ctx context.Context, roomId string) (string, error) {
+	owner, err := resolveOwnerScript.Run(ctx, o.redisClient,
+		[]string{sharedRedis.BuildRoomOwnerKey(roomId), sharedRedis.BuildLogicNodeRoomsKey(o.nodeID), sharedRedis.BuildRoomKey(roomId)},
+		o.nodeID, roomId, sharedRedis.LogicNodeKeyPrefix, sharedRedis.LogicNodeRoomsKeySuffix,
+	).Text()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return owner, nil
+}
+
+// Claim 为新建房间登记归属，房间已有归属（ID 冲突）时返回 false
+func (o *Ownership) Claim(ctx context.Context, roomId string) (bool, error) {
+	return claimOwnerScript.Run(ctx, o.redisClient,
+		[]string{sharedRedis.BuildRoomOwnerKey(roomId), sharedRedis.BuildLogicNodeRoomsKey(o.nodeID)},
+		o.nodeID, roomId,
+	).Bool()
+}
+
+// Release 房间销毁后释放归属，返回房间此前是否归属本节点；已被其他节点接管的房间不受影响
+func (o *Ownership) Release(ctx context.Context, roomId string) (bool, error) {
+	return releaseOwnerScript.Run(ctx, o.redisClient,
+		[]string{sharedRedis.BuildRoomOwnerKey(roomId), sharedRedis.BuildLogicNodeRoomsKey(o.nodeID)},
+		o.nodeID, roomId,
+	).Bool()
+}
+
+// OwnedRooms 返回归属本节点的房间 ID，用于重启后恢复房间
+func (o *Ownership) OwnedRooms(ctx context.Context) ([]string, error) {
+	return o.redisClient.SMembers(ctx, sharedRedis.BuildLogicNodeRoomsKey(o.nodeID)).Result()
+}
+
+// Register 写入本节点存活 Key，应在恢复房间与开始消费前调用，避免其他节点接管本节点的房间
+func (o *Ownership) Register(ctx context.Context) error {
+	if err := o.heartbeat(ctx); err != nil {
+		return err
+	}
+	o.logger.Info("Logic node registered", "nodeId", o.nodeID, "interval", sharedRedis.LogicNodeHeartbeatInterval)
+	return nil
+}
+
+// Start 按固定间隔心跳（阻塞，应在 goroutine 中调用）
+// 退出时删除存活 Key，其他节点随即接管本节点的房间
+func (o *Ownership) Start(ctx context.Context) {
+	key := sharedRedis.BuildLogicNodeKey(o.nodeID)
+	heartbeat := func() {
+		if err := o.heartbeat(ctx); err != nil && ctx.Err() == nil {
+			o.logger.Error("Failed to send logic node heartbeat", "nodeId", o.nodeID, "error", err)
+		}
+	}
+
+	ticker := time.NewTicker(sharedRedis.LogicNodeHeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := o.redisClient.Del(context.WithoutCancel(ctx), key).Err(); err != nil {
+				o.logger.Error("Failed to deregister logic node", "nodeId", o.nodeID, "error", err)
+			}
+			return
+		case <-ticker.C:
+			heartbeat()
+		}
+	}
+}
+
+// heartbeat 写入本节点存活 Key 并刷新 TTL
+func (o *Ownership) heartbeat(ctx context.Context) error {
+	return o.redisClient.Set(ctx, sharedRedis.BuildLogicNodeKey(o.nodeID), o.startedAt, sharedRedis.LogicNodeTTL).Err()
+}
@@ -0,0 +1,96 @@
+package room
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	sharedRedis "sudooom.im.shared/redis"
+)
+
+// 注意：该测试需要一个运行中的 Redis 实例，无法连接时跳过
+func getTestRedisClient(t *testing.T) *redis.Client {
+	client := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+		DB:   15, // 使用测试专用数据库
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("跳过测试：无法连接 Redis: %v", err)
+	}
+
+	client.FlushDB(ctx)
+	return client
+}
+
+// TestOwnership 新建房间归属创建节点，归属节点存活时其他节点解析到归属节点，宕机后由解析节点接管，
+// 不存在的房间不登记归属
+func TestOwnership(t *testing.T) {
+	client := getTestRedisClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	a := NewOwnership(client, "logic-a")
+	b := NewOwnership(client, "logic-b")
+	client.Set(ctx, sharedRedis.BuildLogicNodeKey("logic-a"), "1", time.Minute)
+
+	if ok, err := a.Claim(ctx, "r1"); err != nil || !ok {
+		t.Fatalf("Claim = %v, %v, want true", ok, err)
+	}
+	if ok, _ := b.Claim(ctx, "r1"); ok {
+		t.Fatalf("已有归属的房间不应被重复登记")
+	}
+	if owner, err := b.ResolveOwner(ctx, "r1"); err != nil || owner != "logic-a" {
+		t.Fatalf("ResolveOwner = %q, %v, want logic-a", owner, err)
+	}
+
+	// 归属节点宕机后由解析节点接管，原节点释放不影响新归属
+	client.Set(ctx, sharedRedis.BuildRoomKey("r1"), "{}", time.Minute)
+	client.Del(ctx, sharedRedis.BuildLogicNodeKey("logic-a"))
+	if owner, _ := b.ResolveOwner(ctx, "r1"); owner != "logic-b" {
+		t.Fatalf("宕机节点的房间应由 logic-b 接管，got %q", owner)
+	}
+	if released, err := a.Release(ctx, "r1"); err != nil || released {
+		t.Fatalf("Release = %v, %v, 已被接管的房间不应由原节点释放", released, err)
+	}
+	if owner, _ := client.Get(ctx, sharedRedis.BuildRoomOwnerKey("r1")).Result(); owner != "logic-b" {
+		t.Fatalf("原节点释放后归属 = %q, want logic-b", owner)
+	}
+	if rooms, _ := a.OwnedRooms(ctx); len(rooms) != 0 {
+		t.Fatalf("logic-a 房间索引 = %v, want 空", rooms)
+	}
+	if rooms, _ := b.OwnedRooms(ctx); len(rooms) != 1 || rooms[0] != "r1" {
+		t.Fatalf("logic-b 房间索引 = %v, want [r1]", rooms)
+	}
+
+	// 不存在的房间不登记归属
+	if owner, err := a.ResolveOwner(ctx, "r2"); err != nil || owner != "" {
+		t.Fatalf("ResolveOwner(不存在的房间) = %q, %v, want 空", owner, err)
+	}
+	if n, _ := client.Exists(ctx, sharedRedis.BuildRoomOwnerKey("r2")).Result(); n != 0 {
+		t.Fatalf("不存在的房间不应写入归属")
+	}
+
+	// 房间状态仍在但无归属时由解析节点接管
+	client.Set(ctx, sharedRedis.BuildRoomKey("r3"), "{}", time.Minute)
+	if owner, _ := a.ResolveOwner(ctx, "r3"); owner != "logic-a" {
+		t.Fatalf("无归属房间应由 logic-a 接管，got %q", owner)
+	}
+
+	// 归属节点宕机且房间状态已不存在时清理失效归属
+	client.Del(ctx, sharedRedis.BuildRoomKey("r1"))
+	client.Del(ctx, sharedRedis.BuildLogicNodeKey("logic-b"))
+	if owner, err := a.ResolveOwner(ctx, "r1"); err != nil || owner != "" {
+		t.Fatalf("ResolveOwner(已失效房间) = %q, %v, want 空", owner, err)
+	}
+	if n, _ := client.Exists(ctx, sharedRedis.BuildRoomOwnerKey("r1")).Result(); n != 0 {
+		t.Fatalf("失效房间的归属应被删除")
+	}
+	if rooms, _ := b.OwnedRooms(ctx); len(rooms) != 0 {
+		t.Fatalf("logic-b 房间索引 = %v, want 空", rooms)
+	}
+}
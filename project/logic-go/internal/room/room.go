@@ -20,6 +20,9 @@ type Room struct {
 	mu         sync.RWMutex // 读写锁，保护房间状态
 	roomInfo   *model.Room  // 房间数据
 	lastActive time.Time    // 最后活跃时间（用于淘汰策略）
+
+	persistMu sync.Mutex // 串行写入 Redis，保证后写入的总是更新的快照
+	removed   bool       // 房间已移除，不再写入 Redis（受 persistMu 保护）
 }
 
 // NewRoom 创建房间实例
@@ -44,6 +47,17 @@ func NewRoom(roomID string, creatorID int64, config *model.RoomConfig, gameType
 	}
 }
 
+// RestoreRoom 由持久化的房间快照恢复房间实例
+func RestoreRoom(snapshot *model.Room) *Room {
+	if snapshot.Players == nil {
+		snapshot.Players = make([]model.RoomPlayer, 0)
+	}
+	return &Room{
+		roomInfo:   snapshot,
+		lastActive: time.Now(),
+	}
+}
+
 // CopyRoomInfo 复制房间信息（需要在持有锁的情况下调用）
 // 返回深拷贝的房间数据，用于返回给外部
 func (r *Room) CopyRoomInfo() *model.Room {
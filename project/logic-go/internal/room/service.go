@@ -3,23 +3,27 @@ package room
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 
 	"github.com/redis/go-redis/v9"
 	"sudooom.im.logic/internal/service"
 	"sudooom.im.shared/model"
+	"sudooom.im.shared/proto"
 	sharedRedis "sudooom.im.shared/redis"
 	"sudooom.im.shared/snowflake"
 )
 
 // RoomService 房间服务
-// 简化为使用 RoomManager，不再直接操作 Redis
+// 房间状态以 RoomManager 内存为准，每次变更后写入 Redis（RoomStore），节点重启或接管房间时据此恢复
 type RoomService struct {
 	roomManager   *RoomManager
-	redisClient   *redis.Client // 仅用于获取用户信息
+	redisClient   *redis.Client
+	store         *RoomStore
 	sfNode        *snowflake.Node
 	routerService *service.RouterService
+	ownership     *Ownership // 多 Logic 节点部署时登记房间归属，为空时不登记
 	logger        *slog.Logger
 }
 
@@ -33,12 +37,84 @@ func NewRoomService(
 	return &RoomService{
 		roomManager:   roomManager,
 		redisClient:   redisClient,
+		store:         NewRoomStore(redisClient),
 		sfNode:        sfNode,
 		routerService: routerService,
 		logger:        slog.Default(),
 	}
 }
 
+// SetOwnership 设置房间归属服务，新建房间登记为本节点所有
+func (s *RoomService) SetOwnership(ownership *Ownership) {
+	s.ownership = ownership
+}
+
+// Store 返回房间状态存储
+func (s *RoomService) Store() *RoomStore {
+	return s.store
+}
+
+// Restore 启动时由 Redis 恢复归属本节点的房间，返回恢复的房间数
+// 状态已过期的房间释放归属
+func (s *RoomService) Restore(ctx context.Context) (int, error) {
+	if s.ownership == nil {
+		return 0, nil
+	}
+	roomIds, err := s.ownership.OwnedRooms(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	for _, roomId := range roomIds {
+		snapshot, err := s.store.Load(ctx, roomId)
+		if errors.Is(err, ErrRoomNotFound) {
+			if _, err := s.ownership.Release(ctx, roomId); err != nil {
+				s.logger.Warn("Failed to release room ownership", "roomId", roomId, "error", err)
+			}
+			continue
+		}
+		if err != nil {
+			s.logger.Warn("Failed to restore room", "roomId", roomId, "error", err)
+			continue
+		}
+		s.roomManager.Restore(snapshot)
+		restored++
+	}
+	return restored, nil
+}
+
+// getRoom 获取房间，本节点内存中没有时由 Redis 恢复（接管宕机节点的房间）
+func (s *RoomService) getRoom(ctx context.Context, roomId string) (*Room, error) {
+	if r, ok := s.roomManager.Get(roomId); ok {
+		return r, nil
+	}
+	snapshot, err := s.store.Load(ctx, roomId)
+	if err != nil {
+		if !errors.Is(err, ErrRoomNotFound) {
+			s.logger.Error("Failed to load room state", "roomId", roomId, "error", err)
+		}
+		return nil, ErrRoomNotFound
+	}
+	s.logger.Info("Room restored from Redis", "roomId", roomId, "players", len(snapshot.Players))
+	return s.roomManager.Restore(snapshot), nil
+}
+
+// persist 将房间当前状态写入 Redis，失败时仅记录日志（内存状态为准，下次变更时重新写入完整快照）
+// 已移除的房间不再写入
+func (s *RoomService) persist(ctx context.Context, r *Room) {
+	r.persistMu.Lock()
+	defer r.persistMu.Unlock()
+	if r.removed {
+		return
+	}
+
+	snapshot := r.CopyRoomInfo()
+	if err := s.store.Save(ctx, snapshot); err != nil {
+		s.logger.Error("Failed to persist room state", "roomId", snapshot.RoomID, "error", err)
+	}
+}
+
 // getUserInfo 从 Redis 获取用户基本信息
 func (s *RoomService) getUserInfo(ctx context.Context, userId int64) *model.User {
 	userInfoKey := sharedRedis.BuildUserInfoKey(userId)
@@ -67,8 +143,9 @@ func (s *RoomService) getUserInfo(ctx context.Context, userId int64) *model.User
 	return &user
 }
 
-// BroadcastToRoom 广播消息给房间所有用户
-func (s *RoomService) BroadcastToRoom(ctx context.Context, roomId string, event string, data interface{}) error {
+// BroadcastToRoom 广播房间事件给房间所有用户，携带当前房间快照
+// actorId 为触发事件的用户 ID（系统事件传 0）
+func (s *RoomService) BroadcastToRoom(ctx context.Context, roomId string, event string, actorId int64) error {
 	// 获取房间快照
 	r, ok := s.roomManager.Get(roomId)
 	if !ok {
@@ -83,15 +160,8 @@ func (s *RoomService) BroadcastToRoom(ctx context.Context, roomId string, event
 		userIds = append(userIds, player.UserID)
 	}
 
-	// 序列化数据
-	eventData, err := json.Marshal(data)
-	if err != nil {
-		s.logger.Error("Failed to marshal event data", "error", err, "event", event)
-		return err
-	}
-
 	// 通过 RouterService 广播
-	if err := s.routerService.SendRoomPushToUsers(ctx, userIds, event, roomId, eventData); err != nil {
+	if err := s.routerService.SendRoomPushToUsers(ctx, userIds, event, roomId, actorId, ToRoomInfo(snapshot)); err != nil {
 		s.logger.Warn("Failed to broadcast to room", "error", err, "roomId", roomId, "event", event)
 		return err
 	}
@@ -99,6 +169,45 @@ func (s *RoomService) BroadcastToRoom(ctx context.Context, roomId string, event
 	return nil
 }
 
+// ToRoomInfo 将房间模型转换为下行协议的房间快照（与 FlatBuffers RoomInfo 对应）
+func ToRoomInfo(r *model.Room) *proto.RoomInfo {
+	players := make([]proto.RoomPlayer, 0, len(r.Players))
+	for _, p := range r.Players {
+		player := proto.RoomPlayer{
+			UserId:    p.UserID,
+			IsReady:   p.IsReady,
+			SeatIndex: p.SeatIndex,
+			IsOnline:  true, // 房间内玩家视为在线（断线状态暂未追踪）
+		}
+		if p.UserInfo != nil {
+			player.Nickname = p.UserInfo.Nickname
+			player.Avatar = p.UserInfo.Avatar
+		}
+		players = append(players, player)
+	}
+
+	return &proto.RoomInfo{
+		RoomId:         r.RoomID,
+		OwnerId:        r.CreatorID,
+		MaxPlayers:     int32(r.MaxPlayers),
+		CurrentPlayers: int32(len(r.Players)),
+		Status:         toRoomStatus(r.Status),
+		Players:        players,
+	}
+}
+
+// toRoomStatus 将房间状态映射为 FlatBuffers RoomStatus 枚举名
+func toRoomStatus(status string) string {
+	switch status {
+	case "playing":
+		return "PLAYING"
+	case "finished":
+		return "SETTLING"
+	default:
+		return "WAITING"
+	}
+}
+
 // GetRoom 获取房间信息
 func (s *RoomService) GetRoom(ctx context.Context, roomId string) (*model.Room, error) {
 	r, ok := s.roomManager.Get(roomId)
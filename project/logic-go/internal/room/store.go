@@ -0,0 +1,116 @@
+package room
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+	"sudooom.im.shared/model"
+	sharedRedis "sudooom.im.shared/redis"
+)
+
+// releaseUserRoomScript 仅当用户仍映射到该房间时删除映射，避免误删用户在其他房间的映射
+var releaseUserRoomScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// RoomStore 房间状态的 Redis 持久化
+// 归属节点在每次房间变更后写入完整快照，节点重启或接管房间时据此恢复：
+//   - room:{roomId}        房间快照 JSON
+//   - room_users:{roomId}  房间成员 Set，游戏广播据此推送
+//   - user_room:{userId}   用户所在房间
+type RoomStore struct {
+	redisClient *redis.Client
+}
+
+// NewRoomStore 创建房间状态存储
+func NewRoomStore(redisClient *redis.Client) *RoomStore {
+	return &RoomStore{redisClient: redisClient}
+}
+
+// Save 写入房间快照、成员与用户房间映射，已离开的成员删除映射
+// 同一房间的 Save 需串行调用（见 Room.persistMu），否则旧快照可能覆盖新快照
+func (s *RoomStore) Save(ctx context.Context, snapshot *model.Room) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	usersKey := sharedRedis.BuildRoomUsersKey(snapshot.RoomID)
+	previous, err := s.redisClient.SMembers(ctx, usersKey).Result()
+	if err != nil {
+		return err
+	}
+
+	current := make(map[string]struct{}, len(snapshot.Players))
+	members := make([]interface{}, 0, len(snapshot.Players))
+	for _, p := range snapshot.Players {
+		userId := strconv.FormatInt(p.UserID, 10)
+		current[userId] = struct{}{}
+		members = append(members, userId)
+	}
+
+	_, err = s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, sharedRedis.BuildRoomKey(snapshot.RoomID), data, sharedRedis.RoomTTL)
+		pipe.Del(ctx, usersKey)
+		if len(members) > 0 {
+			pipe.SAdd(ctx, usersKey, members...)
+			pipe.Expire(ctx, usersKey, sharedRedis.RoomTTL)
+		}
+		for _, p := range snapshot.Players {
+			pipe.Set(ctx, sharedRedis.BuildUserRoomKey(p.UserID), snapshot.RoomID, sharedRedis.RoomTTL)
+		}
+		for _, userId := range previous {
+			if _, ok := current[userId]; ok {
+				continue
+			}
+			if id, err := strconv.ParseInt(userId, 10, 64); err == nil {
+				releaseUserRoomScript.Eval(ctx, pipe, []string{sharedRedis.BuildUserRoomKey(id)}, snapshot.RoomID)
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+// Load 读取房间快照，不存在时返回 ErrRoomNotFound
+func (s *RoomStore) Load(ctx context.Context, roomId string) (*model.Room, error) {
+	data, err := s.redisClient.Get(ctx, sharedRedis.BuildRoomKey(roomId)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrRoomNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot model.Room
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// Delete 删除房间快照、成员与成员的房间映射
+func (s *RoomStore) Delete(ctx context.Context, roomId string) error {
+	usersKey := sharedRedis.BuildRoomUsersKey(roomId)
+	members, err := s.redisClient.SMembers(ctx, usersKey).Result()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, sharedRedis.BuildRoomKey(roomId), usersKey)
+		for _, userId := range members {
+			if id, err := strconv.ParseInt(userId, 10, 64); err == nil {
+				releaseUserRoomScript.Eval(ctx, pipe, []string{sharedRedis.BuildUserRoomKey(id)}, roomId)
+			}
+		}
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,142 @@
+package room
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"sudooom.im.shared/model"
+	sharedRedis "sudooom.im.shared/redis"
+)
+
+// TestRoomStore 写入房间快照、成员与用户房间映射，成员离开后删除其映射
+func TestRoomStore(t *testing.T) {
+	client := getTestRedisClient(t)
+	defer client.Close()
+	ctx := context.Background()
+	store := NewRoomStore(client)
+
+	r := NewRoom("r1", 1, &model.RoomConfig{RoomName: "test", MaxPlayers: 4}, "HT_MAHJONG")
+	_ = r.Join(1, 0, &model.User{UserID: 1, Nickname: "a"})
+	_ = r.Join(2, 1, nil)
+	if err := store.Save(ctx, r.CopyRoomInfo()); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if users, _ := client.SMembers(ctx, sharedRedis.BuildRoomUsersKey("r1")).Result(); len(users) != 2 {
+		t.Fatalf("room_users = %v, want 2 个成员", users)
+	}
+
+	// 用户 2 离开后改入其他房间，旧房间的写入不应覆盖新映射
+	_ = r.Leave(1)
+	client.Set(ctx, sharedRedis.BuildUserRoomKey(2), "r2", 0)
+	_ = r.Leave(2)
+	if err := store.Save(ctx, r.CopyRoomInfo()); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if n, _ := client.Exists(ctx, sharedRedis.BuildUserRoomKey(1)).Result(); n != 0 {
+		t.Fatalf("离开房间的用户映射应删除")
+	}
+	if roomId, _ := client.Get(ctx, sharedRedis.BuildUserRoomKey(2)).Result(); roomId != "r2" {
+		t.Fatalf("user_room:2 = %q, want r2", roomId)
+	}
+
+	_ = r.Join(3, 2, nil)
+	if err := store.Save(ctx, r.CopyRoomInfo()); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	snapshot, err := store.Load(ctx, "r1")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if snapshot.RoomName != "test" || len(snapshot.Players) != 1 || snapshot.Players[0].UserID != 3 {
+		t.Fatalf("恢复的快照不正确: %+v", snapshot)
+	}
+
+	if err := store.Delete(ctx, "r1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Load(ctx, "r1"); !errors.Is(err, ErrRoomNotFound) {
+		t.Fatalf("删除后 Load err = %v, want ErrRoomNotFound", err)
+	}
+	if n, _ := client.Exists(ctx, sharedRedis.BuildRoomUsersKey("r1"), sharedRedis.BuildUserRoomKey(3)).Result(); n != 0 {
+		t.Fatalf("删除后仍残留 %d 个 Key", n)
+	}
+}
+
+// TestRoomServiceRestore 重启后恢复归属本节点的房间，接管的房间在首次访问时由 Redis 恢复
+func TestRoomServiceRestore(t *testing.T) {
+	client := getTestRedisClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	newService := func(nodeID string) (*RoomService, *RoomManager) {
+		manager := NewRoomManager(100, time.Hour, time.Hour)
+		service := NewRoomService(manager, client, nil, nil)
+		ownership := NewOwnership(client, nodeID)
+		manager.SetOwnership(ownership)
+		manager.SetStore(service.Store())
+		service.SetOwnership(ownership)
+		if err := ownership.Register(ctx); err != nil {
+			t.Fatalf("Register failed: %v", err)
+		}
+		return service, manager
+	}
+
+	// logic-a 持有两个房间，其中 r2 的状态已过期
+	for _, roomId := range []string{"r1", "r2"} {
+		if ok, _ := NewOwnership(client, "logic-a").Claim(ctx, roomId); !ok {
+			t.Fatalf("Claim %s failed", roomId)
+		}
+	}
+	r := NewRoom("r1", 1, &model.RoomConfig{MaxPlayers: 4}, "HT_MAHJONG")
+	_ = r.Join(1, 0, nil)
+	_ = NewRoomStore(client).Save(ctx, r.CopyRoomInfo())
+
+	serviceA, managerA := newService("logic-a")
+	if n, err := serviceA.Restore(ctx); err != nil || n != 1 {
+		t.Fatalf("Restore = %d, %v, want 1", n, err)
+	}
+	if snapshot, err := serviceA.GetRoom(ctx, "r1"); err != nil || len(snapshot.Players) != 1 {
+		t.Fatalf("恢复的房间不正确: %+v, %v", snapshot, err)
+	}
+	if rooms, _ := client.SMembers(ctx, sharedRedis.BuildLogicNodeRoomsKey("logic-a")).Result(); len(rooms) != 1 {
+		t.Fatalf("状态过期的房间应释放归属: %v", rooms)
+	}
+
+	// logic-a 宕机，logic-b 接管后首次访问由 Redis 恢复
+	client.Del(ctx, sharedRedis.BuildLogicNodeKey("logic-a"))
+	serviceB, _ := newService("logic-b")
+	if owner, _ := serviceB.ownership.ResolveOwner(ctx, "r1"); owner != "logic-b" {
+		t.Fatalf("r1 应由 logic-b 接管，got %q", owner)
+	}
+	if room, err := serviceB.getRoom(ctx, "r1"); err != nil || len(room.CopyRoomInfo().Players) != 1 {
+		t.Fatalf("接管的房间未恢复: %v", err)
+	}
+
+	// logic-a 淘汰残留的房间副本时不删除 logic-b 使用的状态
+	managerA.Remove("r1")
+	if _, err := serviceB.Store().Load(ctx, "r1"); err != nil {
+		t.Fatalf("原节点淘汰后状态被删除: %v", err)
+	}
+}
+
+// TestRoomRemovePersist 房间移除后进行中的写入不会复活已删除的房间状态
+func TestRoomRemovePersist(t *testing.T) {
+	client := getTestRedisClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	manager := NewRoomManager(100, time.Hour, time.Hour)
+	service := NewRoomService(manager, client, nil, nil)
+	manager.SetStore(service.Store())
+
+	r := manager.GetOrCreate("r1", 1, &model.RoomConfig{MaxPlayers: 4}, "HT_MAHJONG")
+	service.persist(ctx, r)
+	manager.Remove("r1")
+	service.persist(ctx, r)
+
+	if _, err := service.Store().Load(ctx, "r1"); !errors.Is(err, ErrRoomNotFound) {
+		t.Fatalf("移除后 Load err = %v, want ErrRoomNotFound", err)
+	}
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	sharedModel "sudooom.im.shared/model"
+	"sudooom.im.shared/proto"
+	sharedRedis "sudooom.im.shared/redis"
+	"sudooom.im.shared/snowflake"
+)
+
+// DefaultAnnouncementMaxTTL 持久化公告的默认最长有效期
+const DefaultAnnouncementMaxTTL = 7 * 24 * time.Hour
+
+var (
+	ErrAnnouncementNoTarget = errors.New("announcement requires UserIds or All")
+	ErrAnnouncementEmpty    = errors.New("announcement requires Title or Content")
+	ErrAnnouncementLevel    = errors.New("announcement level must be INFO, WARNING or ERROR")
+)
+
+// announcementLevels 与 FlatBuffers SystemLevel 枚举名一致
+var announcementLevels = map[string]bool{"INFO": true, "WARNING": true, "ERROR": true}
+
+// announcementRecord 持久化的公告
+type announcementRecord struct {
+	Id        string           `json:"Id"`
+	Platform  string           `json:"Platform,omitempty"`
+	CreatedAt int64            `json:"CreatedAt"` // 毫秒
+	ExpireAt  int64            `json:"ExpireAt"`  // 毫秒
+	Push      proto.SystemPush `json:"Push"`
+}
+
+// AnnouncementService 系统公告服务
+// 在线设备立即推送；设置了有效期的公告持久化到 Redis，有效期内上线的设备按投递游标补发
+type AnnouncementService struct {
+	redisClient   *redis.Client
+	routerService *RouterService
+	sfNode        *snowflake.Node
+	maxTTL        time.Duration
+	logger        *slog.Logger
+}
+
+// NewAnnouncementService 创建系统公告服务
+func NewAnnouncementService(redisClient *redis.Client, routerService *RouterService, sfNode *snowflake.Node, maxTTL time.Duration) *AnnouncementService {
+	if maxTTL <= 0 {
+		maxTTL = DefaultAnnouncementMaxTTL
+	}
+	return &AnnouncementService{
+		redisClient:   redisClient,
+		routerService: routerService,
+		sfNode:        sfNode,
+		maxTTL:        maxTTL,
+		logger:        slog.Default(),
+	}
+}
+
+// Publish 发布系统公告
+func (s *AnnouncementService) Publish(ctx context.Context, req *proto.SystemAnnouncement) (*proto.SystemAnnouncementResult, error) {
+	push, err := buildAnnouncementPush(req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &proto.SystemAnnouncementResult{}
+
+	// 先持久化再推送：推送过程中上线的设备最多收到两次，而不会漏收
+	if req.TTLSeconds > 0 {
+		now := time.Now()
+		ttl := min(time.Duration(req.TTLSeconds)*time.Second, s.maxTTL)
+		record := &announcementRecord{
+			Id:        s.sfNode.Generate().String(),
+			Platform:  req.Platform,
+			CreatedAt: now.UnixMilli(),
+			ExpireAt:  now.Add(ttl).UnixMilli(),
+			Push:      *push,
+		}
+		if err := s.save(ctx, record, req.UserIds, ttl); err != nil {
+			return nil, err
+		}
+		result.AnnouncementId = record.Id
+	}
+
+	if req.All {
+		if err := s.routerService.BroadcastSystemPush(req.Platform, push); err != nil {
+			return nil, err
+		}
+		result.Delivered = -1
+	} else {
+		result.Delivered = s.routerService.SendSystemPushToUsers(ctx, req.UserIds, req.Platform, push)
+	}
+
+	s.logger.Info("System announcement published",
+		"announcementId", result.AnnouncementId,
+		"all", req.All,
+		"users", len(req.UserIds),
+		"platform", req.Platform,
+		"level", push.Level,
+		"delivered", result.Delivered)
+	return result, nil
+}
+
+// buildAnnouncementPush 校验请求并构建推送内容
+func buildAnnouncementPush(req *proto.SystemAnnouncement) (*proto.SystemPush, error) {
+	if !req.All && len(req.UserIds) == 0 {
+		return nil, ErrAnnouncementNoTarget
+	}
+	if req.Title == "" && req.Content == "" {
+		return nil, ErrAnnouncementEmpty
+	}
+
+	level := strings.ToUpper(req.Level)
+	if level == "" {
+		level = "INFO"
+	}
+	if !announcementLevels[level] {
+		return nil, ErrAnnouncementLevel
+	}
+
+	return &proto.SystemPush{
+		Title:     req.Title,
+		Content:   req.Content,
+		Level:     level,
+		ActionUrl: req.ActionUrl,
+	}, nil
+}
+
+// save 持久化公告：内容按有效期过期，索引按过期时间排序便于查询与清理
+func (s *AnnouncementService) save(ctx context.Context, record *announcementRecord, userIds []int64, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	now := strconv.FormatInt(record.CreatedAt, 10)
+	member := redis.Z{Score: float64(record.ExpireAt), Member: record.Id}
+
+	pipe := s.redisClient.Pipeline()
+	pipe.Set(ctx, sharedRedis.BuildSystemAnnouncementKey(record.Id), data, ttl)
+	if len(userIds) == 0 {
+		pipe.ZAdd(ctx, sharedRedis.SystemAnnouncementsKey, member)
+		pipe.ZRemRangeByScore(ctx, sharedRedis.SystemAnnouncementsKey, "-inf", now)
+	} else {
+		for _, userId := range userIds {
+			key := sharedRedis.BuildUserAnnouncementsKey(userId)
+			pipe.ZAdd(ctx, key, member)
+			pipe.ZRemRangeByScore(ctx, key, "-inf", now)
+			pipe.Expire(ctx, key, s.maxTTL)
+		}
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// DeliverPending 设备上线时补发游标之后创建、仍在有效期内的公告，返回补发条数
+func (s *AnnouncementService) DeliverPending(ctx context.Context, loc sharedModel.UserLocation) (int, error) {
+	now := time.Now().UnixMilli()
+	cursorKey := sharedRedis.BuildAnnouncementCursorKey(loc.UserId, loc.Platform)
+
+	active := &redis.ZRangeBy{Min: strconv.FormatInt(now, 10), Max: "+inf"}
+	pipe := s.redisClient.Pipeline()
+	cursorCmd := pipe.Get(ctx, cursorKey)
+	globalCmd := pipe.ZRangeByScore(ctx, sharedRedis.SystemAnnouncementsKey, active)
+	userCmd := pipe.ZRangeByScore(ctx, sharedRedis.BuildUserAnnouncementsKey(loc.UserId), active)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return 0, err
+	}
+
+	// 游标不存在（新设备或离线超过最长有效期）时补发全部有效公告
+	cursor, _ := cursorCmd.Int64()
+	ids := append(globalCmd.Val(), userCmd.Val()...)
+
+	records, err := s.load(ctx, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, record := range records {
+		if record.CreatedAt <= cursor || record.ExpireAt <= now {
+			continue
+		}
+		if record.Platform != "" && !strings.EqualFold(record.Platform, loc.Platform) {
+			continue
+		}
+		push := record.Push
+		if err := s.routerService.SendSystemPushToLocation(loc, &push); err != nil {
+			s.logger.Warn("Failed to deliver pending announcement", "announcementId", record.Id, "userId", loc.UserId, "error", err)
+			continue
+		}
+		delivered++
+	}
+
+	if err := s.advanceCursor(ctx, loc.UserId, loc.Platform, now); err != nil {
+		return delivered, err
+	}
+	return delivered, nil
+}
+
+// MarkOffline 设备下线时推进游标：在线期间的公告已实时推送，下次上线无需补发
+func (s *AnnouncementService) MarkOffline(ctx context.Context, userId int64, platform string) error {
+	if platform == "" {
+		return nil
+	}
+	return s.advanceCursor(ctx, userId, platform, time.Now().UnixMilli())
+}
+
+// advanceCursor 游标与索引同样最多保留 maxTTL，过期后视为新设备
+func (s *AnnouncementService) advanceCursor(ctx context.Context, userId int64, platform string, at int64) error {
+	return s.redisClient.Set(ctx, sharedRedis.BuildAnnouncementCursorKey(userId, platform), at, s.maxTTL).Err()
+}
+
+// load 批量读取公告内容，已过期的跳过，按创建时间排序
+func (s *AnnouncementService) load(ctx context.Context, ids []string) ([]*announcementRecord, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = sharedRedis.BuildSystemAnnouncementKey(id)
+	}
+	values, err := s.redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*announcementRecord, 0, len(values))
+	for _, v := range values {
+		data, ok := v.(string)
+		if !ok {
+			continue
+		}
+		var record announcementRecord
+		if err := json.Unmarshal([]byte(data), &record); err != nil {
+			s.logger.Warn("Failed to unmarshal announcement", "error", err)
+			continue
+		}
+		records = append(records, &record)
+	}
+
+	sort.Slice(records, func(i, j int) bool { return records[i].CreatedAt < records[j].CreatedAt })
+	return records, nil
+}
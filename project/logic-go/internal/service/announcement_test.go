@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"sudooom.im.logic/internal/nats"
+	sharedModel "sudooom.im.shared/model"
+	"sudooom.im.shared/proto"
+	sharedRedis "sudooom.im.shared/redis"
+	"sudooom.im.shared/snowflake"
+)
+
+func TestBuildAnnouncementPush(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     proto.SystemAnnouncement
+		level   string
+		wantErr error
+	}{
+		{"默认级别", proto.SystemAnnouncement{All: true, Title: "t"}, "INFO", nil},
+		{"级别大小写", proto.SystemAnnouncement{UserIds: []int64{1}, Content: "c", Level: "warning"}, "WARNING", nil},
+		{"缺少目标", proto.SystemAnnouncement{Title: "t"}, "", ErrAnnouncementNoTarget},
+		{"缺少内容", proto.SystemAnnouncement{All: true}, "", ErrAnnouncementEmpty},
+		{"非法级别", proto.SystemAnnouncement{All: true, Title: "t", Level: "FATAL"}, "", ErrAnnouncementLevel},
+	}
+
+	for _, tt := range tests {
+		push, err := buildAnnouncementPush(&tt.req)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && push.Level != tt.level {
+			t.Errorf("%s: level = %s, want %s", tt.name, push.Level, tt.level)
+		}
+	}
+}
+
+func TestAnnouncementService_DeliverPending(t *testing.T) {
+	client := getTestRedisClient(t)
+	defer client.Close()
+
+	// 无 NATS 连接时分发失败只记录日志，不影响补发计数
+	routerService := NewRouterService(NewLocationService(client), NewDispatcherService(nats.NewMessagePublisher(nil)))
+	sfNode, err := snowflake.NewNode(1)
+	if err != nil {
+		t.Fatalf("NewNode failed: %v", err)
+	}
+	svc := NewAnnouncementService(client, routerService, sfNode, time.Hour)
+	ctx := context.Background()
+
+	publish := func(req proto.SystemAnnouncement) {
+		t.Helper()
+		if _, err := svc.Publish(ctx, &req); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+
+	web := sharedModel.UserLocation{AccessNodeId: "access-1", ConnId: 1, UserId: 1001, Platform: "WEB"}
+	ios := sharedModel.UserLocation{AccessNodeId: "access-1", ConnId: 2, UserId: 1001, Platform: "IOS"}
+
+	publish(proto.SystemAnnouncement{All: true, Title: "全员", TTLSeconds: 60})
+	publish(proto.SystemAnnouncement{All: true, Platform: "ios", Title: "仅 iOS", TTLSeconds: 60})
+	publish(proto.SystemAnnouncement{UserIds: []int64{1001}, Title: "指定用户", TTLSeconds: 60})
+	publish(proto.SystemAnnouncement{UserIds: []int64{2002}, Title: "其他用户", TTLSeconds: 60})
+	publish(proto.SystemAnnouncement{All: true, Title: "不持久化"})
+
+	if n, err := svc.DeliverPending(ctx, web); err != nil || n != 2 {
+		t.Fatalf("web 首次上线应补发 2 条，实际 %d, err %v", n, err)
+	}
+	if n, err := svc.DeliverPending(ctx, ios); err != nil || n != 3 {
+		t.Fatalf("ios 首次上线应补发 3 条，实际 %d, err %v", n, err)
+	}
+
+	// 再次上线不重复补发
+	if n, _ := svc.DeliverPending(ctx, web); n != 0 {
+		t.Fatalf("重复上线不应补发，实际 %d", n)
+	}
+
+	// 下线后发布的公告在下次上线时补发
+	if err := svc.MarkOffline(ctx, web.UserId, web.Platform); err != nil {
+		t.Fatalf("MarkOffline failed: %v", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	publish(proto.SystemAnnouncement{All: true, Title: "离线期间", TTLSeconds: 60})
+	if n, _ := svc.DeliverPending(ctx, web); n != 1 {
+		t.Fatalf("应补发离线期间的 1 条公告，实际 %d", n)
+	}
+
+	if ttl := client.TTL(ctx, sharedRedis.BuildAnnouncementCursorKey(web.UserId, web.Platform)).Val(); ttl <= 0 || ttl > time.Hour {
+		t.Fatalf("游标 TTL 应不超过 maxTTL，实际 %v", ttl)
+	}
+}
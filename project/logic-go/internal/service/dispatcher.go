@@ -48,3 +48,8 @@ func (s *DispatcherService) Dispatch(userId int64, locations []sharedModel.UserL
 	}
 	return nil
 }
+
+// Broadcast 广播 payload 到所有 Access 节点，platform 非空时仅投递该平台的连接
+func (s *DispatcherService) Broadcast(platform string, payload proto.DownstreamPayload) error {
+	return s.publisher.Broadcast(s.buildDownstreamMessage(0, 0, platform, payload))
+}
@@ -22,6 +22,7 @@ type cachedUserLocation struct {
 // LocationService 用户位置管理服务
 type LocationService struct {
 	redisClient   *redis.Client
+	nodeService   *NodeService
 	logger        *slog.Logger
 	locationCache sync.Map // map[int64]*cachedUserLocation
 }
@@ -35,13 +36,18 @@ func NewLocationService(redisClient *redis.Client) *LocationService {
 
 }
 
+// SetNodeService 设置节点存活检测，路由时忽略指向宕机节点的位置
+func (s *LocationService) SetNodeService(nodeService *NodeService) {
+	s.nodeService = nodeService
+}
+
 // GetUserLocations 获取用户所有平台的位置（带缓存）
 func (s *LocationService) GetUserLocations(ctx context.Context, userId int64) ([]sharedModel.UserLocation, error) {
 	// 1. 尝试从缓存读取
 	if cached, ok := s.locationCache.Load(userId); ok {
 		entry := cached.(*cachedUserLocation)
 		// 缓存命中，直接返回
-		return entry.Locations, nil
+		return s.filterLive(entry.Locations), nil
 	}
 
 	// 2. 缓存未命中，查询 Redis
@@ -57,7 +63,7 @@ func (s *LocationService) GetUserLocations(ctx context.Context, userId int64) ([
 		})
 	}
 
-	return locations, nil
+	return s.filterLive(locations), nil
 }
 
 // GetUserLocationsByPlatforms 获取用户在指定平台的位置
@@ -65,7 +71,31 @@ func (s *LocationService) GetUserLocationsByPlatforms(ctx context.Context, userI
 	if len(platforms) == 0 {
 		return nil, nil
 	}
-	return s.getUserLocationsFromRedis(ctx, userId, platforms)
+	locations, err := s.getUserLocationsFromRedis(ctx, userId, platforms)
+	if err != nil {
+		return nil, err
+	}
+	return s.filterLive(locations), nil
+}
+
+// filterLive 过滤指向宕机节点的位置（不修改缓存中的切片）
+func (s *LocationService) filterLive(locations []sharedModel.UserLocation) []sharedModel.UserLocation {
+	if s.nodeService == nil {
+		return locations
+	}
+	for i, loc := range locations {
+		if s.nodeService.IsLive(loc.AccessNodeId) {
+			continue
+		}
+		live := append(make([]sharedModel.UserLocation, 0, len(locations)-1), locations[:i]...)
+		for _, rest := range locations[i+1:] {
+			if s.nodeService.IsLive(rest.AccessNodeId) {
+				live = append(live, rest)
+			}
+		}
+		return live
+	}
+	return locations
 }
 
 // getUserLocationsFromRedis 从 Redis 获取用户位置（私有方法）
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"sync"
 	"time"
@@ -15,15 +16,20 @@ import (
 
 // MessageBatcherConfig 批量写入配置
 type MessageBatcherConfig struct {
-	BatchSize     int           // 批量大小阈值
-	FlushInterval time.Duration // 强制刷新间隔
+	BatchSize      int           // 批量大小阈值
+	FlushInterval  time.Duration // 强制刷新间隔
+	EnqueueTimeout time.Duration // 队列满时入队的最长等待时间
 }
 
+// ErrMessageQueueFull 写入队列持续满载，消息未被接收
+var ErrMessageQueueFull = errors.New("message batch queue full")
+
 // MessageToSave 待保存的消息
 type MessageToSave struct {
 	ServerMsgId int64
 	Msg         *proto.UserMessage
-	ResultChan  chan error // 用于通知保存结果
+	ResultChan  chan error      // 用于通知保存结果
+	OnSaved     func(err error) // 写入完成后回调（可为 nil），在写入协程中调用，不应阻塞
 }
 
 // MessageBatcher 消息批量写入器
@@ -46,6 +52,9 @@ func NewMessageBatcher(db *pgxpool.Pool, sf *snowflake.Node, config MessageBatch
 	if config.FlushInterval <= 0 {
 		config.FlushInterval = 10 * time.Second
 	}
+	if config.EnqueueTimeout <= 0 {
+		config.EnqueueTimeout = time.Second
+	}
 
 	return &MessageBatcher{
 		db:       db,
@@ -74,14 +83,16 @@ func (b *MessageBatcher) Stop() {
 	b.logger.Info("MessageBatcher stopped")
 }
 
-// SaveMessage 异步保存消息（立即返回 serverMsgId）
-func (b *MessageBatcher) SaveMessage(msg *proto.UserMessage) (int64, error) {
+// SaveMessage 异步保存消息（立即返回 serverMsgId），写入完成后以写入结果调用 onSaved
+// 队列满时最多等待 EnqueueTimeout，超时返回 ErrMessageQueueFull，此时不会调用 onSaved
+func (b *MessageBatcher) SaveMessage(msg *proto.UserMessage, onSaved func(err error)) (int64, error) {
 	serverMsgId := b.sf.Generate().Int64()
 
 	msgToSave := &MessageToSave{
 		ServerMsgId: serverMsgId,
 		Msg:         msg,
 		ResultChan:  make(chan error, 1),
+		OnSaved:     onSaved,
 	}
 
 	select {
@@ -89,10 +100,17 @@ func (b *MessageBatcher) SaveMessage(msg *proto.UserMessage) (int64, error) {
 		// 入队成功，立即返回（不等待数据库写入）
 		return serverMsgId, nil
 	default:
-		// 队列满，记录警告，同步等待
+		// 队列满，记录警告，限时等待
 		b.logger.Warn("Message batch queue full, waiting...")
-		b.msgChan <- msgToSave
+	}
+
+	timer := time.NewTimer(b.config.EnqueueTimeout)
+	defer timer.Stop()
+	select {
+	case b.msgChan <- msgToSave:
 		return serverMsgId, nil
+	case <-timer.C:
+		return 0, ErrMessageQueueFull
 	}
 }
 
@@ -124,9 +142,9 @@ func (b *MessageBatcher) worker(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
-			// 上下文取消，刷入剩余消息
+			// 上下文取消，刷入剩余消息（已取消的上下文无法写入）
 			if len(batch) > 0 {
-				b.flush(ctx, batch)
+				b.flush(context.WithoutCancel(ctx), batch)
 			}
 			return
 		case <-b.stopChan:
@@ -207,6 +225,9 @@ func (b *MessageBatcher) flush(ctx context.Context, batch []*MessageToSave) {
 			default:
 			}
 		}
+		if batch[i].OnSaved != nil {
+			batch[i].OnSaved(err)
+		}
 	}
 
 	elapsed := time.Since(startTime)
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	sharedModel "sudooom.im.shared/model"
+	"sudooom.im.shared/proto"
+	sharedRedis "sudooom.im.shared/redis"
+)
+
+const (
+	defaultNodeCheckInterval = 5 * time.Second
+	nodePurgeBatch           = 500
+)
+
+// deleteNodeLocationScript 仅当位置仍指向宕机节点的该连接时删除，避免误删用户在其他节点的新位置
+var deleteNodeLocationScript = redis.NewScript(`
+local data = redis.call('GET', KEYS[1])
+if not data then
+	return 0
+end
+local loc = cjson.decode(data)
+if loc.accessNodeId == ARGV[1] and tostring(loc.connId) == ARGV[2] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// OfflineHandler 宕机节点上的连接下线处理
+type OfflineHandler func(ctx context.Context, event *proto.UserOffline, accessNodeId string) error
+
+// NodeService Access 节点存活检测
+// Access 节点定期心跳写入节点索引，超过 AccessNodeTTL 未心跳的节点视为宕机：
+// 路由时忽略指向宕机节点的位置，并由一个 Logic 节点清理其位置、为其上的连接补发下线事件
+type NodeService struct {
+	redisClient   *redis.Client
+	checkInterval time.Duration
+	logger        *slog.Logger
+
+	mu     sync.RWMutex
+	live   map[string]sharedModel.AccessNode
+	loaded bool
+}
+
+// NewNodeService 创建节点存活检测服务
+func NewNodeService(redisClient *redis.Client, checkInterval time.Duration) *NodeService {
+	if checkInterval <= 0 {
+		checkInterval = defaultNodeCheckInterval
+	}
+	return &NodeService{
+		redisClient:   redisClient,
+		checkInterval: checkInterval,
+		logger:        slog.Default(),
+		live:          make(map[string]sharedModel.AccessNode),
+	}
+}
+
+// Start 定期检测节点存活并清理宕机节点（阻塞，应在 goroutine 中调用）
+func (s *NodeService) Start(ctx context.Context, onOffline OfflineHandler) {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	s.logger.Info("Access node checker started", "interval", s.checkInterval, "ttl", sharedRedis.AccessNodeTTL)
+	for {
+		if err := s.Check(ctx, onOffline); err != nil && ctx.Err() == nil {
+			s.logger.Error("Failed to check access nodes", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check 刷新存活节点列表并清理宕机节点
+func (s *NodeService) Check(ctx context.Context, onOffline OfflineHandler) error {
+	deadline := strconv.FormatInt(time.Now().Add(-sharedRedis.AccessNodeTTL).UnixMilli(), 10)
+
+	pipe := s.redisClient.Pipeline()
+	liveCmd := pipe.ZRangeByScore(ctx, sharedRedis.AccessNodesKey, &redis.ZRangeBy{Min: deadline, Max: "+inf"})
+	deadCmd := pipe.ZRangeByScore(ctx, sharedRedis.AccessNodesKey, &redis.ZRangeBy{Min: "-inf", Max: "(" + deadline})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	live, err := s.loadNodes(ctx, liveCmd.Val())
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.live = live
+	s.loaded = true
+	s.mu.Unlock()
+
+	for _, nodeId := range deadCmd.Val() {
+		if err := s.purgeNode(ctx, nodeId, onOffline); err != nil {
+			s.logger.Error("Failed to purge dead access node", "nodeId", nodeId, "error", err)
+		}
+	}
+	return nil
+}
+
+// loadNodes 读取节点信息，信息已过期的节点只保留 ID
+func (s *NodeService) loadNodes(ctx context.Context, nodeIds []string) (map[string]sharedModel.AccessNode, error) {
+	nodes := make(map[string]sharedModel.AccessNode, len(nodeIds))
+	if len(nodeIds) == 0 {
+		return nodes, nil
+	}
+
+	keys := make([]string, len(nodeIds))
+	for i, nodeId := range nodeIds {
+		keys[i] = sharedRedis.BuildAccessNodeKey(nodeId)
+	}
+	values, err := s.redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, nodeId := range nodeIds {
+		node := sharedModel.AccessNode{NodeId: nodeId}
+		if data, ok := values[i].(string); ok {
+			if err := json.Unmarshal([]byte(data), &node); err != nil {
+				s.logger.Warn("Failed to unmarshal access node", "nodeId", nodeId, "error", err)
+			}
+		}
+		nodes[nodeId] = node
+	}
+	return nodes, nil
+}
+
+// purgeNode 清理宕机节点：从节点索引移除成功的 Logic 节点负责清理，其余节点跳过
+// 清理中途失败时剩余位置按位置 TTL 自然过期
+func (s *NodeService) purgeNode(ctx context.Context, nodeId string, onOffline OfflineHandler) error {
+	removed, err := s.redisClient.ZRem(ctx, sharedRedis.AccessNodesKey, nodeId).Result()
+	if err != nil || removed == 0 {
+		return err
+	}
+
+	locationsKey := sharedRedis.BuildAccessNodeLocationsKey(nodeId)
+	purged, offline := 0, 0
+	var cursor uint64
+	for {
+		members, next, err := s.redisClient.SScan(ctx, locationsKey, cursor, "", nodePurgeBatch).Result()
+		if err != nil {
+			return err
+		}
+		for _, member := range members {
+			userId, platform, connId, err := sharedRedis.ParseNodeLocationMember(member)
+			if err != nil {
+				s.logger.Warn("Invalid node location member", "nodeId", nodeId, "member", member, "error", err)
+				continue
+			}
+			deleted, err := deleteNodeLocationScript.Run(ctx, s.redisClient,
+				[]string{sharedRedis.BuildUserLocationKeyWithPlatform(userId, platform)},
+				nodeId, strconv.FormatInt(connId, 10)).Int()
+			if err != nil {
+				s.logger.Warn("Failed to delete dead node location", "nodeId", nodeId, "userId", userId, "error", err)
+			}
+			purged += deleted
+
+			// 节点宕机时其上的连接都未完成下线处理，位置是否已过期或被覆盖都补发下线事件
+			if onOffline != nil {
+				if err := onOffline(ctx, &proto.UserOffline{UserId: userId, ConnId: connId, Platform: strings.ToUpper(platform)}, nodeId); err != nil {
+					s.logger.Warn("Failed to handle offline of dead node connection", "nodeId", nodeId, "userId", userId, "error", err)
+				}
+			}
+			offline++
+		}
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+
+	if err := s.redisClient.Del(ctx, locationsKey, sharedRedis.BuildAccessNodeKey(nodeId)).Err(); err != nil {
+		return err
+	}
+
+	s.logger.Warn("Dead access node purged",
+		"nodeId", nodeId,
+		"locationsPurged", purged,
+		"usersOffline", offline)
+	return nil
+}
+
+// IsLive 节点是否存活，尚未完成首次检测时视为存活
+func (s *NodeService) IsLive(nodeId string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.loaded {
+		return true
+	}
+	_, ok := s.live[nodeId]
+	return ok
+}
+
+// LiveNodes 返回存活的 Access 节点（按节点 ID 排序）
+func (s *NodeService) LiveNodes() []sharedModel.AccessNode {
+	s.mu.RLock()
+	nodes := make([]sharedModel.AccessNode, 0, len(s.live))
+	for _, node := range s.live {
+		nodes = append(nodes, node)
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].NodeId < nodes[j].NodeId })
+	return nodes
+}
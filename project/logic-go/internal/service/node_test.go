@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	sharedModel "sudooom.im.shared/model"
+	"sudooom.im.shared/proto"
+	sharedRedis "sudooom.im.shared/redis"
+)
+
+func TestLocationService_FilterLive(t *testing.T) {
+	nodeService := NewNodeService(nil, 0)
+	locationService := NewLocationService(nil)
+	locationService.SetNodeService(nodeService)
+
+	locations := []sharedModel.UserLocation{
+		{AccessNodeId: "access-1", Platform: "WEB"},
+		{AccessNodeId: "access-2", Platform: "IOS"},
+	}
+
+	// 首次检测前不过滤
+	if got := locationService.filterLive(locations); len(got) != 2 {
+		t.Fatalf("首次检测前不应过滤，实际 %d 条", len(got))
+	}
+
+	nodeService.live = map[string]sharedModel.AccessNode{"access-2": {NodeId: "access-2"}}
+	nodeService.loaded = true
+	got := locationService.filterLive(locations)
+	if len(got) != 1 || got[0].AccessNodeId != "access-2" {
+		t.Fatalf("应只保留存活节点的位置: %+v", got)
+	}
+	if locations[0].AccessNodeId != "access-1" {
+		t.Fatalf("不应修改原切片: %+v", locations)
+	}
+}
+
+func TestNodeService_Check(t *testing.T) {
+	client := getTestRedisClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	now := time.Now()
+	client.ZAdd(ctx, sharedRedis.AccessNodesKey,
+		redis.Z{Score: float64(now.UnixMilli()), Member: "access-live"},
+		redis.Z{Score: float64(now.Add(-time.Minute).UnixMilli()), Member: "access-dead"})
+	liveInfo, _ := json.Marshal(sharedModel.AccessNode{NodeId: "access-live", Connections: 3})
+	client.Set(ctx, sharedRedis.BuildAccessNodeKey("access-live"), liveInfo, time.Minute)
+
+	setLocation := func(userId int64, platform, nodeId string, connId int64) {
+		data, _ := json.Marshal(sharedModel.UserLocation{UserId: userId, AccessNodeId: nodeId, ConnId: connId, Platform: platform})
+		client.Set(ctx, sharedRedis.BuildUserLocationKeyWithPlatform(userId, platform), data, time.Minute)
+		client.SAdd(ctx, sharedRedis.BuildAccessNodeLocationsKey(nodeId), sharedRedis.BuildNodeLocationMember(userId, platform, connId))
+	}
+	setLocation(1, "WEB", "access-dead", 10)
+	setLocation(2, "WEB", "access-dead", 20)
+	// 用户 2 已在存活节点重新登录，宕机节点的索引仍残留旧连接
+	setLocation(2, "WEB", "access-live", 21)
+
+	var offline []*proto.UserOffline
+	nodeService := NewNodeService(client, time.Second)
+	onOffline := func(_ context.Context, event *proto.UserOffline, accessNodeId string) error {
+		if accessNodeId != "access-dead" {
+			t.Errorf("下线事件节点不正确: %s", accessNodeId)
+		}
+		offline = append(offline, event)
+		return nil
+	}
+	if err := nodeService.Check(ctx, onOffline); err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	if len(offline) != 2 {
+		t.Fatalf("宕机节点上的 2 个连接都应补发下线事件，实际 %d", len(offline))
+	}
+	if n := client.Exists(ctx, sharedRedis.BuildUserLocationKeyWithPlatform(1, "web")).Val(); n != 0 {
+		t.Fatalf("宕机节点的位置应被删除")
+	}
+	if n := client.Exists(ctx, sharedRedis.BuildUserLocationKeyWithPlatform(2, "web")).Val(); n != 1 {
+		t.Fatalf("已迁移到存活节点的位置不应被删除")
+	}
+	if n := client.Exists(ctx, sharedRedis.BuildAccessNodeLocationsKey("access-dead")).Val(); n != 0 {
+		t.Fatalf("宕机节点的位置索引应被删除")
+	}
+
+	nodes := nodeService.LiveNodes()
+	if len(nodes) != 1 || nodes[0].NodeId != "access-live" || nodes[0].Connections != 3 {
+		t.Fatalf("存活节点列表不正确: %+v", nodes)
+	}
+	if nodeService.IsLive("access-dead") || !nodeService.IsLive("access-live") {
+		t.Fatalf("节点存活状态不正确")
+	}
+
+	// 已清理的节点不会重复触发下线
+	offline = nil
+	if err := nodeService.Check(ctx, onOffline); err != nil || len(offline) != 0 {
+		t.Fatalf("不应重复清理: %d, err %v", len(offline), err)
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
@@ -123,8 +124,51 @@ func (s *RouterService) SendAckToUserDirect(accessNodeId string, connId int64, u
 	return s.dispatcherService.Dispatch(userId, locations, payload)
 }
 
+// SendAckErrorToUserDirect 直接回复发送失败的 ACK，code 为 FlatBuffers ErrorCode 枚举名
+func (s *RouterService) SendAckErrorToUserDirect(accessNodeId string, connId int64, userId int64, clientMsgId string, code, msg string) error {
+	locations := []sharedModel.UserLocation{{
+		AccessNodeId: accessNodeId,
+		ConnId:       connId,
+		UserId:       userId,
+	}}
+	payload := proto.DownstreamPayload{
+		MessageAck: &proto.MessageAck{
+			ClientMsgId: clientMsgId,
+			ToUserId:    userId,
+			Timestamp:   time.Now().UnixMilli(),
+			Code:        code,
+			Msg:         msg,
+		},
+	}
+	return s.dispatcherService.Dispatch(userId, locations, payload)
+}
+
+// buildPushMessage 由用户消息构建聊天推送，携带会话类型、扩展属性与发送者资料
+func buildPushMessage(msg *proto.UserMessage, serverMsgId int64, sender *proto.UserInfo) *proto.PushMessage {
+	chatType := msg.ChatType
+	if chatType == "" {
+		// 兼容未携带 ChatType 的旧版 Access
+		chatType = "PRIVATE"
+		if msg.ToGroupId > 0 {
+			chatType = "GROUP"
+		}
+	}
+	return &proto.PushMessage{
+		ServerMsgId: serverMsgId,
+		FromUserId:  msg.FromUserId,
+		SenderInfo:  sender,
+		ToUserId:    msg.ToUserId,
+		ToGroupId:   msg.ToGroupId,
+		ChatType:    chatType,
+		MsgType:     msg.MsgType,
+		Content:     msg.Content,
+		Ext:         msg.Ext,
+		Timestamp:   time.Now().UnixMilli(),
+	}
+}
+
 // SyncToSenderOtherDevices 同步消息给发送者的其他设备（多端同步）
-func (s *RouterService) SyncToSenderOtherDevices(ctx context.Context, excludePlatform string, userId int64, msg *proto.UserMessage, serverMsgId int64) error {
+func (s *RouterService) SyncToSenderOtherDevices(ctx context.Context, excludePlatform string, userId int64, msg *proto.UserMessage, serverMsgId int64, sender *proto.UserInfo) error {
 	// 1. 查询用户所有设备位置
 	locations, err := s.locationService.GetUserLocations(ctx, userId)
 	if err != nil {
@@ -134,21 +178,13 @@ func (s *RouterService) SyncToSenderOtherDevices(ctx context.Context, excludePla
 	// 2. 过滤排除平台并分发到其他设备
 	otherLocations := s.filterOtherPlatformLocations(locations, excludePlatform)
 	payload := proto.DownstreamPayload{
-		PushMessage: &proto.PushMessage{
-			ServerMsgId: serverMsgId,
-			FromUserId:  msg.FromUserId,
-			ToUserId:    msg.ToUserId,
-			ToGroupId:   msg.ToGroupId,
-			MsgType:     msg.MsgType,
-			Content:     msg.Content,
-			Timestamp:   time.Now().UnixMilli(),
-		},
+		PushMessage: buildPushMessage(msg, serverMsgId, sender),
 	}
 	return s.dispatcherService.Dispatch(userId, otherLocations, payload)
 }
 
 // RouteMessage 路由消息到用户
-func (s *RouterService) RouteMessage(ctx context.Context, userId int64, msg *proto.UserMessage, serverMsgId int64) error {
+func (s *RouterService) RouteMessage(ctx context.Context, userId int64, msg *proto.UserMessage, serverMsgId int64, sender *proto.UserInfo) error {
 	// 1. 查询用户位置
 	locations, err := s.locationService.GetUserLocations(ctx, userId)
 	if err != nil {
@@ -162,35 +198,19 @@ func (s *RouterService) RouteMessage(ctx context.Context, userId int64, msg *pro
 
 	// 2. 分发消息
 	payload := proto.DownstreamPayload{
-		PushMessage: &proto.PushMessage{
-			ServerMsgId: serverMsgId,
-			FromUserId:  msg.FromUserId,
-			ToUserId:    msg.ToUserId,
-			ToGroupId:   msg.ToGroupId,
-			MsgType:     msg.MsgType,
-			Content:     msg.Content,
-			Timestamp:   time.Now().UnixMilli(),
-		},
+		PushMessage: buildPushMessage(msg, serverMsgId, sender),
 	}
 	return s.dispatcherService.Dispatch(userId, locations, payload)
 }
 
 // RouteToMultiple 批量路由消息（群消息）- 并行处理
-func (s *RouterService) RouteToMultiple(ctx context.Context, userIds []int64, msg *proto.UserMessage, serverMsgId int64) error {
+func (s *RouterService) RouteToMultiple(ctx context.Context, userIds []int64, msg *proto.UserMessage, serverMsgId int64, sender *proto.UserInfo) error {
 	// 1. 并发获取所有用户位置
 	allUserLocations := s.fetchMultipleUserLocations(ctx, userIds)
 
 	// 2. 分发消息
 	payload := proto.DownstreamPayload{
-		PushMessage: &proto.PushMessage{
-			ServerMsgId: serverMsgId,
-			FromUserId:  msg.FromUserId,
-			ToUserId:    msg.ToUserId,
-			ToGroupId:   msg.ToGroupId,
-			MsgType:     msg.MsgType,
-			Content:     msg.Content,
-			Timestamp:   time.Now().UnixMilli(),
-		},
+		PushMessage: buildPushMessage(msg, serverMsgId, sender),
 	}
 	for _, ul := range allUserLocations {
 		if err := s.dispatcherService.Dispatch(ul.userId, ul.locations, payload); err != nil {
@@ -202,7 +222,7 @@ func (s *RouterService) RouteToMultiple(ctx context.Context, userIds []int64, ms
 }
 
 // SendRoomPushToSelf 发送房间推送给自己（快速响应+多端同步）
-func (s *RouterService) SendRoomPushToSelf(senderLoc sharedModel.UserLocation, event string, roomId string, roomInfo []byte) error {
+func (s *RouterService) SendRoomPushToSelf(senderLoc sharedModel.UserLocation, event string, roomId string, roomInfo *proto.RoomInfo) error {
 	payload := proto.DownstreamPayload{
 		RoomPush: &proto.RoomPush{
 			Event:    event,
@@ -212,6 +232,17 @@ func (s *RouterService) SendRoomPushToSelf(senderLoc sharedModel.UserLocation, e
 			ToUserId: senderLoc.UserId,
 		},
 	}
+	return s.dispatchRoomPushToSelf(senderLoc, payload)
+}
+
+// SendRoomResp 回复房间操作响应给发起请求的连接（其他设备通过房间推送同步）
+func (s *RouterService) SendRoomResp(senderLoc sharedModel.UserLocation, resp *proto.RoomResp) error {
+	payload := proto.DownstreamPayload{RoomResp: resp}
+	return s.dispatcherService.Dispatch(senderLoc.UserId, []sharedModel.UserLocation{senderLoc}, payload)
+}
+
+// dispatchRoomPushToSelf 房间推送的自身分发（快速响应+多端同步）
+func (s *RouterService) dispatchRoomPushToSelf(senderLoc sharedModel.UserLocation, payload proto.DownstreamPayload) error {
 	return s.dispatchToSelfAndOtherDevices(
 		senderLoc,
 		func() error {
@@ -224,7 +255,8 @@ func (s *RouterService) SendRoomPushToSelf(senderLoc sharedModel.UserLocation, e
 }
 
 // SendRoomPushToUsers 发送房间推送给多个用户（全量推送）
-func (s *RouterService) SendRoomPushToUsers(ctx context.Context, userIds []int64, event string, roomId string, roomInfo []byte) error {
+// actorId 为触发事件的用户 ID
+func (s *RouterService) SendRoomPushToUsers(ctx context.Context, userIds []int64, event string, roomId string, actorId int64, roomInfo *proto.RoomInfo) error {
 	// 1. 并发获取所有用户位置
 	allUserLocations := s.fetchMultipleUserLocations(ctx, userIds)
 
@@ -234,7 +266,7 @@ func (s *RouterService) SendRoomPushToUsers(ctx context.Context, userIds []int64
 			RoomPush: &proto.RoomPush{
 				Event:    event,
 				RoomId:   roomId,
-				UserId:   ul.userId,
+				UserId:   actorId,
 				RoomInfo: roomInfo,
 				ToUserId: ul.userId,
 			},
@@ -248,13 +280,14 @@ func (s *RouterService) SendRoomPushToUsers(ctx context.Context, userIds []int64
 }
 
 // SendGamePushToSelf 发送游戏推送给自己（快速响应+多端同步）
-func (s *RouterService) SendGamePushToSelf(senderLoc sharedModel.UserLocation, roomId string, gameType string, gamePayload []byte) error {
+func (s *RouterService) SendGamePushToSelf(senderLoc sharedModel.UserLocation, roomId string, gameType string, gamePayloadType string, gamePayload []byte) error {
 	payload := proto.DownstreamPayload{
 		GamePush: &proto.GamePush{
-			RoomId:      roomId,
-			GameType:    gameType,
-			GamePayload: gamePayload,
-			ToUserId:    senderLoc.UserId,
+			RoomId:          roomId,
+			GameType:        gameType,
+			GamePayloadType: gamePayloadType,
+			GamePayload:     gamePayload,
+			ToUserId:        senderLoc.UserId,
 		},
 	}
 	return s.dispatchToSelfAndOtherDevices(
@@ -269,7 +302,7 @@ func (s *RouterService) SendGamePushToSelf(senderLoc sharedModel.UserLocation, r
 }
 
 // SendGamePushToUsers 发送游戏推送给多个用户（全量推送）
-func (s *RouterService) SendGamePushToUsers(ctx context.Context, userIds []int64, roomId string, gameType string, gamePayload []byte) error {
+func (s *RouterService) SendGamePushToUsers(ctx context.Context, userIds []int64, roomId string, gameType string, gamePayloadType string, gamePayload []byte) error {
 	// 1. 并发获取所有用户位置
 	allUserLocations := s.fetchMultipleUserLocations(ctx, userIds)
 
@@ -277,10 +310,11 @@ func (s *RouterService) SendGamePushToUsers(ctx context.Context, userIds []int64
 	for _, ul := range allUserLocations {
 		payload := proto.DownstreamPayload{
 			GamePush: &proto.GamePush{
-				RoomId:      roomId,
-				GameType:    gameType,
-				GamePayload: gamePayload,
-				ToUserId:    ul.userId,
+				RoomId:          roomId,
+				GameType:        gameType,
+				GamePayloadType: gamePayloadType,
+				GamePayload:     gamePayload,
+				ToUserId:        ul.userId,
 			},
 		}
 		if err := s.dispatcherService.Dispatch(ul.userId, ul.locations, payload); err != nil {
@@ -291,6 +325,54 @@ func (s *RouterService) SendGamePushToUsers(ctx context.Context, userIds []int64
 	return nil
 }
 
+// SendSystemPushToUsers 发送系统公告给多个用户，platform 非空时只推送该平台，返回投递的设备数
+func (s *RouterService) SendSystemPushToUsers(ctx context.Context, userIds []int64, platform string, push *proto.SystemPush) int {
+	// 1. 并发获取所有用户位置
+	allUserLocations := s.fetchMultipleUserLocations(ctx, userIds)
+
+	// 2. 分发到匹配平台的设备
+	payload := proto.DownstreamPayload{SystemPush: push}
+	delivered := 0
+	for _, ul := range allUserLocations {
+		locations := ul.locations
+		if platform != "" {
+			locations = filterPlatformLocations(locations, platform)
+		}
+		if len(locations) == 0 {
+			continue
+		}
+		if err := s.dispatcherService.Dispatch(ul.userId, locations, payload); err != nil {
+			s.logger.Warn("Failed to dispatch system push to user", "userId", ul.userId, "error", err)
+			continue
+		}
+		delivered += len(locations)
+	}
+
+	return delivered
+}
+
+// SendSystemPushToLocation 发送系统公告到指定设备（上线补发）
+func (s *RouterService) SendSystemPushToLocation(loc sharedModel.UserLocation, push *proto.SystemPush) error {
+	payload := proto.DownstreamPayload{SystemPush: push}
+	return s.dispatcherService.Dispatch(loc.UserId, []sharedModel.UserLocation{loc}, payload)
+}
+
+// BroadcastSystemPush 广播系统公告到所有 Access 节点，platform 非空时只推送该平台
+func (s *RouterService) BroadcastSystemPush(platform string, push *proto.SystemPush) error {
+	return s.dispatcherService.Broadcast(platform, proto.DownstreamPayload{SystemPush: push})
+}
+
+// filterPlatformLocations 只保留指定平台的设备位置
+func filterPlatformLocations(locations []sharedModel.UserLocation, platform string) []sharedModel.UserLocation {
+	matched := make([]sharedModel.UserLocation, 0, len(locations))
+	for _, loc := range locations {
+		if strings.EqualFold(loc.Platform, platform) {
+			matched = append(matched, loc)
+		}
+	}
+	return matched
+}
+
 // InvalidateUserCache 代理到 LocationService
 func (s *RouterService) InvalidateUserCache(userId int64) {
 	s.locationService.InvalidateCache(userId)
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/redis/go-redis/v9"
+	"sudooom.im.shared/proto"
+	sharedRedis "sudooom.im.shared/redis"
+)
+
+// cachedUserInfo user:info:{userId} 中与展示相关的字段（由 web-go 登录时写入）
+type cachedUserInfo struct {
+	Nickname string `json:"nickname"`
+	Avatar   string `json:"avatar"`
+}
+
+// UserService 用户资料服务
+type UserService struct {
+	redisClient *redis.Client
+	logger      *slog.Logger
+}
+
+// NewUserService 创建用户资料服务
+func NewUserService(redisClient *redis.Client) *UserService {
+	return &UserService{
+		redisClient: redisClient,
+		logger:      slog.Default(),
+	}
+}
+
+// GetUserInfo 从 user:info 缓存获取用户资料
+// 缓存缺失或解析失败时只返回用户 ID，不阻断消息推送
+func (s *UserService) GetUserInfo(ctx context.Context, userId int64) *proto.UserInfo {
+	info := &proto.UserInfo{UserId: userId}
+
+	data, err := s.redisClient.Get(ctx, sharedRedis.BuildUserInfoKey(userId)).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			s.logger.Warn("Failed to get user info", "userId", userId, "error", err)
+		}
+		return info
+	}
+
+	var cached cachedUserInfo
+	if err := json.Unmarshal(data, &cached); err != nil {
+		s.logger.Warn("Failed to unmarshal user info", "userId", userId, "error", err)
+		return info
+	}
+
+	info.Nickname = cached.Nickname
+	info.Avatar = cached.Avatar
+	return info
+}
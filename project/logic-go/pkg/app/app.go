@@ -0,0 +1,266 @@
+// Package app 组装并运行 Logic 服务，供 cmd/logic 与单进程模式（cmd/standalone）复用
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/redis/go-redis/v9"
+
+	"sudooom.im.logic/internal/config"
+	"sudooom.im.logic/internal/game"
+	"sudooom.im.logic/internal/handler"
+	imNats "sudooom.im.logic/internal/nats"
+	imRoom "sudooom.im.logic/internal/room"
+	"sudooom.im.logic/internal/service"
+	"sudooom.im.shared/bus"
+	"sudooom.im.shared/proto"
+	"sudooom.im.shared/snowflake"
+)
+
+// Options 运行参数
+type Options struct {
+	ConfigPath string
+	Bus        bus.Bus // 消息总线，为空时按配置连接 NATS
+	Logger     *slog.Logger
+}
+
+// Run 启动 Logic 服务并阻塞到 ctx 取消，随后优雅关闭
+func Run(ctx context.Context, opts Options) error {
+	logger := opts.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	// 加载配置
+	cfg, err := config.Load(opts.ConfigPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	codec, err := proto.ParseCodec(cfg.NATS.Codec)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	nodeID := cfg.App.NodeID
+	if nodeID == "" {
+		if nodeID, err = os.Hostname(); err != nil {
+			return fmt.Errorf("load config: app.node_id is empty and hostname is unavailable: %w", err)
+		}
+	}
+
+	// 派生可取消的上下文，退出时停止后台任务
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// 未指定总线时连接 NATS
+	msgBus := opts.Bus
+	var js jetstream.JetStream
+	if msgBus == nil {
+		natsClient, err := imNats.NewClient(cfg.NATS)
+		if err != nil {
+			return fmt.Errorf("connect NATS: %w", err)
+		}
+		defer natsClient.Close()
+		logger.Info("Connected to NATS", "url", cfg.NATS.URL)
+		msgBus = natsClient
+
+		if cfg.NATS.JetStream.Enabled {
+			if js, err = jetstream.New(natsClient.Conn()); err != nil {
+				return fmt.Errorf("create JetStream context: %w", err)
+			}
+		}
+	} else if cfg.NATS.JetStream.Enabled {
+		return fmt.Errorf("load config: nats.jetstream requires a NATS connection, not a custom bus")
+	}
+
+	// 连接 Redis
+	redisClient := connectRedis(cfg.Redis)
+	defer func() {
+		if err := redisClient.Close(); err != nil {
+			logger.Error("Failed to close Redis client", "error", err)
+		}
+	}()
+	logger.Info("Connected to Redis", "host", cfg.Redis.Host)
+
+	// 连接数据库
+	db, err := connectDatabase(ctx, cfg.Database)
+	if err != nil {
+		return fmt.Errorf("connect database: %w", err)
+	}
+	defer db.Close()
+	logger.Info("Connected to PostgreSQL", "host", cfg.Database.Host)
+
+	// 初始化 雪花ID生成器
+	sfNode, err := snowflake.NewNode(1)
+	if err != nil {
+		return fmt.Errorf("create snowflake node: %w", err)
+	}
+
+	// 初始化服务
+	publisher := imNats.NewMessagePublisher(msgBus)
+	publisher.SetCodec(codec)
+
+	// 创建 LocationService 和 DispatcherService
+	// 路由时忽略指向宕机 Access 节点的位置
+	nodeService := service.NewNodeService(redisClient, cfg.AccessNode.CheckInterval)
+	locationService := service.NewLocationService(redisClient)
+	locationService.SetNodeService(nodeService)
+	dispatcherService := service.NewDispatcherService(publisher)
+
+	// 创建 RouterService（编排层）
+	routerService := service.NewRouterService(locationService, dispatcherService)
+
+	groupService := service.NewGroupService(db)
+	messageService := service.NewMessageService(db)
+
+	// 创建消息批量写入器
+	messageBatcher := service.NewMessageBatcher(db, sfNode, service.MessageBatcherConfig{
+		BatchSize:      cfg.Batch.Size,
+		FlushInterval:  cfg.Batch.FlushInterval,
+		EnqueueTimeout: cfg.Batch.EnqueueTimeout,
+	})
+	messageBatcher.Start(ctx)
+
+	// 创建会话服务
+	conversationService := service.NewConversationService(redisClient)
+
+	// 创建用户资料服务
+	userService := service.NewUserService(redisClient)
+
+	// 创建房间管理器
+	roomManager := imRoom.NewRoomManager(
+		cfg.Room.MaxRooms,
+		cfg.Room.EvictTimeout,
+		cfg.Room.EvictCheckInterval,
+	)
+
+	// 创建房间服务
+	roomService := imRoom.NewRoomService(roomManager, redisClient, sfNode, routerService)
+
+	// 设置 RoomManager 的 RoomService 引用（用于发送清理通知）
+	roomManager.SetRoomService(roomService)
+
+	// 房间归属：新建房间归属本节点，其他节点收到的房间请求转发到本节点
+	ownership := imRoom.NewOwnership(redisClient, nodeID)
+	roomManager.SetOwnership(ownership)
+	roomManager.SetStore(roomService.Store())
+	roomService.SetOwnership(ownership)
+	if err := ownership.Register(ctx); err != nil {
+		return fmt.Errorf("register logic node: %w", err)
+	}
+	go ownership.Start(ctx)
+
+	// 由 Redis 恢复本节点重启前的房间
+	restored, err := roomService.Restore(ctx)
+	if err != nil {
+		return fmt.Errorf("restore rooms: %w", err)
+	}
+	logger.Info("Rooms restored from Redis", "count", restored)
+
+	// 创建游戏管理器
+	gameManager := game.NewGameManager(5000, 30*time.Minute)
+
+	// 创建游戏服务
+	gameService := game.NewGameService(gameManager, redisClient, routerService)
+
+	// 创建系统公告服务
+	announcementService := service.NewAnnouncementService(redisClient, routerService, sfNode, cfg.Announcement.MaxTTL)
+
+	// 创建消息处理器
+	msgHandler := handler.NewMessageHandler(
+		messageBatcher,
+		messageService,
+		groupService,
+		routerService,
+		conversationService,
+		userService,
+		redisClient,
+		roomService,
+		gameService,
+		announcementService,
+	)
+
+	// 启动订阅者
+	subscriber := imNats.NewMessageSubscriber(msgBus, msgHandler, imNats.SubscriberConfig{
+		WorkerCount: cfg.NATS.WorkerCount,
+		BufferSize:  cfg.NATS.BufferSize,
+	})
+	subscriber.SetRoomRouter(ownership)
+	if js != nil {
+		subscriber.SetJetStream(js, cfg.NATS.JetStream)
+	}
+	if err := subscriber.Start(ctx); err != nil {
+		return fmt.Errorf("start subscriber: %w", err)
+	}
+
+	// 启动系统公告请求订阅
+	announcementSubscriber := imNats.NewAnnouncementSubscriber(msgBus, msgHandler)
+	if err := announcementSubscriber.Start(ctx); err != nil {
+		return fmt.Errorf("start announcement subscriber: %w", err)
+	}
+
+	// 启动 Access 节点存活检测：清理宕机节点的用户位置并补发下线事件
+	go nodeService.Start(ctx, msgHandler.HandleUserOffline)
+
+	// 启动存活节点查询订阅
+	accessNodesResponder := imNats.NewAccessNodesResponder(msgBus, nodeService)
+	if err := accessNodesResponder.Start(); err != nil {
+		return fmt.Errorf("start access nodes responder: %w", err)
+	}
+
+	logger.Info("Logic service started", "name", cfg.App.Name, "nodeId", nodeID)
+
+	<-ctx.Done()
+
+	logger.Info("Shutting down...")
+	if err := accessNodesResponder.Stop(); err != nil {
+		logger.Error("Failed to stop access nodes responder", "error", err)
+	}
+	if err := announcementSubscriber.Stop(); err != nil {
+		logger.Error("Failed to stop announcement subscriber", "error", err)
+	}
+	if err := subscriber.Stop(); err != nil {
+		logger.Error("Failed to stop subscriber", "error", err)
+	}
+	messageBatcher.Stop()
+	logger.Info("Logic service stopped")
+	return nil
+}
+
+// connectRedis 连接 Redis
+func connectRedis(cfg config.RedisConfig) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Password: cfg.Password,
+		DB:       cfg.DB,
+		PoolSize: cfg.PoolSize,
+	})
+}
+
+// connectDatabase 连接 PostgreSQL
+func connectDatabase(ctx context.Context, cfg config.DatabaseConfig) (*pgxpool.Pool, error) {
+	dsn := fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=disable",
+		cfg.User,
+		cfg.Password,
+		cfg.Host,
+		cfg.Port,
+		cfg.Name,
+	)
+
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	poolConfig.MaxConns = int32(cfg.MaxOpenConns)
+	poolConfig.MinConns = int32(cfg.MaxIdleConns)
+	poolConfig.MaxConnLifetime = cfg.ConnMaxLifetime
+	poolConfig.MaxConnIdleTime = 10 * time.Minute
+
+	return pgxpool.NewWithConfig(ctx, poolConfig)
+}
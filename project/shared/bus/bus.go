@@ -0,0 +1,56 @@
+// Package bus 定义 Access 与 Logic 之间的消息总线抽象
+// 提供 NATS 实现（跨进程部署）与进程内实现（单进程模式、测试）
+package bus
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrTimeout 请求在超时时间内未收到回复
+	ErrTimeout = errors.New("bus: request timeout")
+	// ErrNoResponders 请求的 Subject 没有任何订阅者
+	ErrNoResponders = errors.New("bus: no responders")
+	// ErrNoReply 消息不携带回复地址（非 request/reply 消息）
+	ErrNoReply = errors.New("bus: message has no reply subject")
+	// ErrInvalidSubject Subject 为空或格式不合法
+	ErrInvalidSubject = errors.New("bus: invalid subject")
+	// ErrClosed 总线已关闭
+	ErrClosed = errors.New("bus: closed")
+)
+
+// Msg 总线消息
+type Msg struct {
+	Subject string
+	Reply   string // 回复地址，仅 request/reply 消息非空
+	Data    []byte
+
+	respond func(data []byte) error
+}
+
+// Respond 回复 request/reply 消息
+func (m *Msg) Respond(data []byte) error {
+	if m.Reply == "" || m.respond == nil {
+		return ErrNoReply
+	}
+	return m.respond(data)
+}
+
+// Handler 消息处理函数
+// 同一订阅的消息按发布顺序串行回调，耗时处理应自行转交 worker
+type Handler func(msg *Msg)
+
+// Subscription 订阅句柄
+type Subscription interface {
+	Unsubscribe() error
+}
+
+// Bus 消息总线：发布/订阅、队列组订阅（组内单播，负载均衡）与请求/回复
+// Subject 语法与 NATS 一致：以 "." 分隔，"*" 匹配单个 token，">" 匹配其后全部 token
+type Bus interface {
+	Publish(subject string, data []byte) error
+	Subscribe(subject string, handler Handler) (Subscription, error)
+	QueueSubscribe(subject, queue string, handler Handler) (Subscription, error)
+	Request(subject string, data []byte, timeout time.Duration) ([]byte, error)
+}
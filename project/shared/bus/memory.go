@@ -0,0 +1,299 @@
+package bus
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultPendingLimit 进程内订阅的默认待处理消息上限，超出后丢弃（对应 NATS 慢消费者）
+	DefaultPendingLimit = 65536
+
+	// inboxPrefix 请求回复地址前缀
+	inboxPrefix = "_INBOX."
+)
+
+// MemoryBus 进程内消息总线，语义与 NATS core 对齐：
+//   - 每个订阅独立协程按发布顺序串行回调，发布不阻塞
+//   - 同一队列组内每条消息轮询投递给一个订阅者
+//   - 待处理消息超过上限时丢弃新消息
+type MemoryBus struct {
+	pendingLimit int
+	logger       *slog.Logger
+
+	mu       sync.RWMutex
+	subs     map[*memorySub]struct{}
+	inboxes  map[string]chan []byte
+	queueSeq map[string]*atomic.Uint64 // 队列组轮询计数，key: subject + " " + queue
+	closed   bool
+
+	inboxSeq atomic.Uint64
+}
+
+// NewMemoryBus 创建进程内消息总线，pendingLimit <= 0 时使用 DefaultPendingLimit
+func NewMemoryBus(pendingLimit int) *MemoryBus {
+	if pendingLimit <= 0 {
+		pendingLimit = DefaultPendingLimit
+	}
+	return &MemoryBus{
+		pendingLimit: pendingLimit,
+		logger:       slog.Default(),
+		subs:         make(map[*memorySub]struct{}),
+		inboxes:      make(map[string]chan []byte),
+		queueSeq:     make(map[string]*atomic.Uint64),
+	}
+}
+
+// memorySub 进程内订阅
+type memorySub struct {
+	bus     *MemoryBus
+	subject string
+	queue   string
+	handler Handler
+	ch      chan *Msg
+	done    chan struct{}
+	once    sync.Once
+}
+
+// Publish 发布消息
+func (b *MemoryBus) Publish(subject string, data []byte) error {
+	_, err := b.publish(subject, "", data)
+	return err
+}
+
+// Subscribe 订阅 Subject
+func (b *MemoryBus) Subscribe(subject string, handler Handler) (Subscription, error) {
+	return b.subscribe(subject, "", handler)
+}
+
+// QueueSubscribe 以队列组方式订阅 Subject，组内每条消息只投递给一个订阅者
+func (b *MemoryBus) QueueSubscribe(subject, queue string, handler Handler) (Subscription, error) {
+	if queue == "" {
+		return nil, ErrInvalidSubject
+	}
+	return b.subscribe(subject, queue, handler)
+}
+
+// Request 发送请求并等待回复
+func (b *MemoryBus) Request(subject string, data []byte, timeout time.Duration) ([]byte, error) {
+	inbox := inboxPrefix + strconv.FormatUint(b.inboxSeq.Add(1), 10)
+	replyCh := make(chan []byte, 1)
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil, ErrClosed
+	}
+	b.inboxes[inbox] = replyCh
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		delete(b.inboxes, inbox)
+		b.mu.Unlock()
+	}()
+
+	delivered, err := b.publish(subject, inbox, data)
+	if err != nil {
+		return nil, err
+	}
+	if delivered == 0 {
+		return nil, ErrNoResponders
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case reply := <-replyCh:
+		return reply, nil
+	case <-timer.C:
+		return nil, ErrTimeout
+	}
+}
+
+// Close 关闭总线并取消所有订阅，未处理的消息被丢弃
+func (b *MemoryBus) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	subs := b.subs
+	b.subs = make(map[*memorySub]struct{})
+	b.mu.Unlock()
+
+	for s := range subs {
+		s.stop()
+	}
+}
+
+func (b *MemoryBus) subscribe(subject, queue string, handler Handler) (Subscription, error) {
+	if !validSubject(subject, true) {
+		return nil, ErrInvalidSubject
+	}
+
+	s := &memorySub{
+		bus:     b,
+		subject: subject,
+		queue:   queue,
+		handler: handler,
+		ch:      make(chan *Msg, b.pendingLimit),
+		done:    make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil, ErrClosed
+	}
+	b.subs[s] = struct{}{}
+	if queue != "" {
+		key := subject + " " + queue
+		if _, ok := b.queueSeq[key]; !ok {
+			b.queueSeq[key] = new(atomic.Uint64)
+		}
+	}
+	b.mu.Unlock()
+
+	go s.run()
+	return s, nil
+}
+
+// publish 投递消息，返回投递到的订阅数（回复地址计为一次）
+func (b *MemoryBus) publish(subject, reply string, data []byte) (int, error) {
+	if !validSubject(subject, false) {
+		return 0, ErrInvalidSubject
+	}
+
+	// 发布方可能复用缓冲区，复制一次后由所有订阅者只读共享
+	data = append([]byte(nil), data...)
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return 0, ErrClosed
+	}
+
+	if replyCh, ok := b.inboxes[subject]; ok {
+		select {
+		case replyCh <- data:
+		default:
+			// 已收到过回复（队列组外多个应答者），忽略后续回复
+		}
+		return 1, nil
+	}
+
+	delivered := 0
+	var groups map[string][]*memorySub
+	for s := range b.subs {
+		if !subjectMatches(s.subject, subject) {
+			continue
+		}
+		if s.queue == "" {
+			s.deliver(b.newMsg(subject, reply, data))
+			delivered++
+			continue
+		}
+		if groups == nil {
+			groups = make(map[string][]*memorySub)
+		}
+		key := s.subject + " " + s.queue
+		groups[key] = append(groups[key], s)
+	}
+
+	for key, members := range groups {
+		// 组内成员顺序随 map 遍历变化，结合轮询计数在成员间分摊负载
+		idx := b.queueSeq[key].Add(1) % uint64(len(members))
+		members[idx].deliver(b.newMsg(subject, reply, data))
+		delivered++
+	}
+	return delivered, nil
+}
+
+func (b *MemoryBus) newMsg(subject, reply string, data []byte) *Msg {
+	msg := &Msg{Subject: subject, Reply: reply, Data: data}
+	if reply != "" {
+		msg.respond = func(data []byte) error {
+			return b.Publish(reply, data)
+		}
+	}
+	return msg
+}
+
+// Unsubscribe 取消订阅，未处理的消息被丢弃
+func (s *memorySub) Unsubscribe() error {
+	s.bus.mu.Lock()
+	delete(s.bus.subs, s)
+	s.bus.mu.Unlock()
+	s.stop()
+	return nil
+}
+
+func (s *memorySub) stop() {
+	s.once.Do(func() { close(s.done) })
+}
+
+func (s *memorySub) deliver(msg *Msg) {
+	select {
+	case s.ch <- msg:
+	default:
+		s.bus.logger.Warn("Memory bus subscriber pending limit reached, dropping message",
+			"subject", msg.Subject, "queue", s.queue, "pendingLimit", cap(s.ch))
+	}
+}
+
+func (s *memorySub) run() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case msg := <-s.ch:
+			// 优先响应取消订阅，避免退订后继续回调
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+			s.handler(msg)
+		}
+	}
+}
+
+// validSubject 校验 Subject 格式，wildcard 表示是否允许通配符（仅订阅允许）
+func validSubject(subject string, wildcard bool) bool {
+	if subject == "" {
+		return false
+	}
+	tokens := strings.Split(subject, ".")
+	for i, token := range tokens {
+		switch {
+		case token == "":
+			return false
+		case token == "*" || token == ">":
+			if !wildcard || (token == ">" && i != len(tokens)-1) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// subjectMatches 判断订阅 Subject（可含通配符）是否匹配发布 Subject
+func subjectMatches(pattern, subject string) bool {
+	pt := strings.Split(pattern, ".")
+	st := strings.Split(subject, ".")
+	for i, p := range pt {
+		if p == ">" {
+			return len(st) > i
+		}
+		if i >= len(st) || (p != "*" && p != st[i]) {
+			return false
+		}
+	}
+	return len(pt) == len(st)
+}
@@ -0,0 +1,228 @@
+package bus
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryBusPublishSubscribe(t *testing.T) {
+	b := NewMemoryBus(0)
+	defer b.Close()
+
+	got := make(chan string, 10)
+	for _, subject := range []string{"im.access.a.downstream", "im.access.*.downstream", "im.>"} {
+		subject := subject
+		if _, err := b.Subscribe(subject, func(msg *Msg) { got <- subject + "|" + string(msg.Data) }); err != nil {
+			t.Fatalf("Subscribe(%q) failed: %v", subject, err)
+		}
+	}
+
+	buf := []byte("hello")
+	if err := b.Publish("im.access.a.downstream", buf); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	// 发布后复用缓冲区不应影响已投递的消息
+	copy(buf, "xxxxx")
+
+	seen := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		select {
+		case s := <-got:
+			seen[s] = true
+		case <-time.After(time.Second):
+			t.Fatalf("只收到 %d 条消息: %v", i, seen)
+		}
+	}
+	for _, want := range []string{"im.access.a.downstream|hello", "im.access.*.downstream|hello", "im.>|hello"} {
+		if !seen[want] {
+			t.Fatalf("未收到 %q: %v", want, seen)
+		}
+	}
+
+	if err := b.Publish("im.access.b.upstream", nil); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	select {
+	case s := <-got:
+		if s != "im.>|" {
+			t.Fatalf("不应匹配的订阅收到消息: %q", s)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("通配符 > 订阅未收到消息")
+	}
+}
+
+func TestMemoryBusOrderingAndUnsubscribe(t *testing.T) {
+	b := NewMemoryBus(0)
+	defer b.Close()
+
+	var mu sync.Mutex
+	var seq []byte
+	done := make(chan struct{})
+	sub, err := b.Subscribe("order", func(msg *Msg) {
+		mu.Lock()
+		seq = append(seq, msg.Data[0])
+		n := len(seq)
+		mu.Unlock()
+		if n == 100 {
+			close(done)
+		}
+	})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		b.Publish("order", []byte{byte(i)})
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("消息未全部送达")
+	}
+	for i, v := range seq {
+		if int(v) != i {
+			t.Fatalf("第 %d 条消息乱序: %d", i, v)
+		}
+	}
+
+	if err := sub.Unsubscribe(); err != nil {
+		t.Fatalf("Unsubscribe failed: %v", err)
+	}
+	b.Publish("order", []byte{0})
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seq) != 100 {
+		t.Fatalf("退订后仍收到消息: %d", len(seq))
+	}
+}
+
+func TestMemoryBusQueueGroup(t *testing.T) {
+	b := NewMemoryBus(0)
+	defer b.Close()
+
+	const members, messages = 3, 300
+	var total atomic.Int64
+	counts := make([]atomic.Int64, members)
+	var plain atomic.Int64
+	for i := 0; i < members; i++ {
+		i := i
+		if _, err := b.QueueSubscribe("im.logic.upstream", "logic-group", func(*Msg) {
+			counts[i].Add(1)
+			total.Add(1)
+		}); err != nil {
+			t.Fatalf("QueueSubscribe failed: %v", err)
+		}
+	}
+	// 普通订阅不受队列组影响，收到全部消息
+	b.Subscribe("im.logic.upstream", func(*Msg) { plain.Add(1) })
+
+	for i := 0; i < messages; i++ {
+		b.Publish("im.logic.upstream", nil)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for (total.Load() < messages || plain.Load() < messages) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if total.Load() != messages || plain.Load() != messages {
+		t.Fatalf("队列组收到 %d 条、普通订阅收到 %d 条，want 各 %d", total.Load(), plain.Load(), messages)
+	}
+	for i := range counts {
+		if counts[i].Load() == 0 {
+			t.Fatalf("队列组成员 %d 未分到消息", i)
+		}
+	}
+}
+
+func TestMemoryBusRequest(t *testing.T) {
+	b := NewMemoryBus(0)
+	defer b.Close()
+
+	if _, err := b.Request("im.logic.access.nodes", nil, time.Second); !errors.Is(err, ErrNoResponders) {
+		t.Fatalf("err = %v, want ErrNoResponders", err)
+	}
+
+	b.QueueSubscribe("echo", "g", func(msg *Msg) {
+		if err := msg.Respond(append([]byte("re:"), msg.Data...)); err != nil {
+			t.Errorf("Respond failed: %v", err)
+		}
+	})
+	reply, err := b.Request("echo", []byte("ping"), time.Second)
+	if err != nil || string(reply) != "re:ping" {
+		t.Fatalf("Request = %q, %v", reply, err)
+	}
+
+	b.Subscribe("silent", func(msg *Msg) {})
+	if _, err := b.Request("silent", nil, 20*time.Millisecond); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want ErrTimeout", err)
+	}
+
+	noReply := make(chan error, 1)
+	b.Subscribe("plain", func(msg *Msg) { noReply <- msg.Respond(nil) })
+	b.Publish("plain", nil)
+	if err := <-noReply; !errors.Is(err, ErrNoReply) {
+		t.Fatalf("err = %v, want ErrNoReply", err)
+	}
+}
+
+func TestMemoryBusPendingLimitAndClose(t *testing.T) {
+	b := NewMemoryBus(2)
+
+	release := make(chan struct{})
+	var handled atomic.Int64
+	b.Subscribe("slow", func(*Msg) {
+		<-release
+		handled.Add(1)
+	})
+	for i := 0; i < 10; i++ {
+		if err := b.Publish("slow", nil); err != nil {
+			t.Fatalf("慢消费者不应阻塞或报错: %v", err)
+		}
+	}
+	close(release)
+	time.Sleep(20 * time.Millisecond)
+	// 回调中 1 条 + 队列中 2 条，其余丢弃
+	if n := handled.Load(); n > 3 {
+		t.Fatalf("超出待处理上限的消息未丢弃: handled=%d", n)
+	}
+
+	b.Close()
+	if err := b.Publish("slow", nil); !errors.Is(err, ErrClosed) {
+		t.Fatalf("err = %v, want ErrClosed", err)
+	}
+	if _, err := b.Subscribe("slow", func(*Msg) {}); !errors.Is(err, ErrClosed) {
+		t.Fatalf("err = %v, want ErrClosed", err)
+	}
+}
+
+func TestSubjectMatches(t *testing.T) {
+	tests := []struct {
+		pattern, subject string
+		want             bool
+	}{
+		{"im.logic.upstream", "im.logic.upstream", true},
+		{"im.logic.upstream", "im.logic.upstream.x", false},
+		{"im.*.upstream", "im.logic.upstream", true},
+		{"im.*", "im.logic.upstream", false},
+		{"im.>", "im.logic.upstream", true},
+		{"im.>", "im", false},
+	}
+	for _, tt := range tests {
+		if got := subjectMatches(tt.pattern, tt.subject); got != tt.want {
+			t.Errorf("subjectMatches(%q, %q) = %v, want %v", tt.pattern, tt.subject, got, tt.want)
+		}
+	}
+
+	for _, subject := range []string{"", "a..b", "a.>.b"} {
+		if validSubject(subject, true) {
+			t.Errorf("validSubject(%q) = true, want false", subject)
+		}
+	}
+	if validSubject("a.*", false) {
+		t.Errorf("发布 Subject 不允许通配符")
+	}
+}
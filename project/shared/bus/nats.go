@@ -0,0 +1,68 @@
+package bus
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSBus 基于 NATS 连接的消息总线
+type NATSBus struct {
+	conn *nats.Conn
+}
+
+// NewNATSBus 使用已建立的 NATS 连接创建消息总线（连接的生命周期由调用方管理）
+func NewNATSBus(conn *nats.Conn) *NATSBus {
+	return &NATSBus{conn: conn}
+}
+
+// Publish 发布消息
+func (b *NATSBus) Publish(subject string, data []byte) error {
+	return b.conn.Publish(subject, data)
+}
+
+// Subscribe 订阅 Subject
+func (b *NATSBus) Subscribe(subject string, handler Handler) (Subscription, error) {
+	sub, err := b.conn.Subscribe(subject, natsHandler(handler))
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// QueueSubscribe 以队列组方式订阅 Subject，组内每条消息只投递给一个订阅者
+func (b *NATSBus) QueueSubscribe(subject, queue string, handler Handler) (Subscription, error) {
+	sub, err := b.conn.QueueSubscribe(subject, queue, natsHandler(handler))
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// Request 发送请求并等待回复
+func (b *NATSBus) Request(subject string, data []byte, timeout time.Duration) ([]byte, error) {
+	msg, err := b.conn.Request(subject, data, timeout)
+	switch {
+	case errors.Is(err, nats.ErrTimeout):
+		return nil, fmt.Errorf("%w: %w", ErrTimeout, err)
+	case errors.Is(err, nats.ErrNoResponders):
+		return nil, fmt.Errorf("%w: %w", ErrNoResponders, err)
+	case err != nil:
+		return nil, err
+	}
+	return msg.Data, nil
+}
+
+// natsHandler 将总线处理函数适配为 NATS 回调
+func natsHandler(handler Handler) nats.MsgHandler {
+	return func(m *nats.Msg) {
+		handler(&Msg{
+			Subject: m.Subject,
+			Reply:   m.Reply,
+			Data:    m.Data,
+			respond: m.Respond,
+		})
+	}
+}
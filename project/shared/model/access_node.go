@@ -0,0 +1,9 @@
+package model
+
+// AccessNode Access 节点注册信息（节点心跳写入 Redis）
+type AccessNode struct {
+	NodeId        string `json:"nodeId"`
+	StartedAt     int64  `json:"startedAt"`     // 启动时间（毫秒）
+	LastHeartbeat int64  `json:"lastHeartbeat"` // 最近一次心跳时间（毫秒）
+	Connections   int    `json:"connections"`   // 心跳时的连接数
+}
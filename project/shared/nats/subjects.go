@@ -10,13 +10,47 @@ const (
 	SubjectAccessDownstreamPrefix = "im.access."
 	SubjectAccessDownstreamSuffix = ".downstream"
 
+	// SubjectLogicNodePrefix Logic -> Logic 转发给房间归属节点的上行消息前缀
+	// 完整格式: im.logic.node.{node_id}.upstream
+	SubjectLogicNodePrefix = "im.logic.node."
+	SubjectLogicNodeSuffix = ".upstream"
+
 	// SubjectAccessBroadcast Logic -> All Access 广播消息
 	SubjectAccessBroadcast = "im.access.broadcast"
 
-	// QueueGroupLogic Logic 服务队列组名称
+	// SubjectLogicSystemAnnounce 运维 -> Logic 系统公告请求（request/reply）
+	SubjectLogicSystemAnnounce = "im.logic.system.announce"
+
+	// SubjectLogicAccessNodes 运维 -> Logic 查询存活 Access 节点列表（request/reply）
+	SubjectLogicAccessNodes = "im.logic.access.nodes"
+
+	// QueueGroupLogic Logic 服务队列组名称（JetStream 模式下同时作为持久消费者名称）
 	QueueGroupLogic = "logic-group"
+
+	// SubjectLogicUpstreamDLQ 超过最大投递次数或无法解析的上行消息（JetStream 模式）
+	SubjectLogicUpstreamDLQ = "im.logic.upstream.dlq"
+)
+
+// JetStream 流定义（Logic 启用 JetStream 时创建）
+const (
+	// StreamLogicUpstream 持久化 SubjectLogicUpstream 的工作队列流
+	StreamLogicUpstream = "IM_LOGIC_UPSTREAM"
+	// StreamLogicUpstreamDLQ 持久化 SubjectLogicUpstreamDLQ 的死信流
+	StreamLogicUpstreamDLQ = "IM_LOGIC_UPSTREAM_DLQ"
+)
+
+// 死信消息头
+const (
+	HeaderDLQReason     = "Im-Dlq-Reason"     // 进入死信的原因
+	HeaderDLQDeliveries = "Im-Dlq-Deliveries" // 已投递次数
+	HeaderDLQStreamSeq  = "Im-Dlq-Stream-Seq" // 原消息在上行流中的序号
 )
 
+// BuildLogicNodeSubject 构建 Logic 节点定向上行 Subject
+func BuildLogicNodeSubject(nodeID string) string {
+	return SubjectLogicNodePrefix + nodeID + SubjectLogicNodeSuffix
+}
+
 // BuildAccessDownstreamSubject 构建 Access 节点下行 Subject
 func BuildAccessDownstreamSubject(nodeID string) string {
 	return SubjectAccessDownstreamPrefix + nodeID + SubjectAccessDownstreamSuffix
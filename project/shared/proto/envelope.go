@@ -0,0 +1,114 @@
+package proto
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// 二进制信封（schema/envelope.proto）：[1B magic][1B version][protobuf 消息体]
+// JSON 以 '{' 开头，接收方按首字节识别格式，滚动升级期间新旧节点可互通
+const (
+	// EnvelopeMagic 二进制信封首字节
+	EnvelopeMagic byte = 0xE1
+	// EnvelopeVersion 当前信封版本，仅在不兼容变更时递增（新增字段无需递增）
+	EnvelopeVersion byte = 1
+
+	envelopeHeaderSize = 2
+)
+
+var (
+	// ErrUnsupportedEnvelopeVersion 信封版本高于本节点支持的版本
+	ErrUnsupportedEnvelopeVersion = errors.New("unsupported envelope version")
+	// ErrMalformedEnvelope 既不是二进制信封也不是 JSON
+	ErrMalformedEnvelope = errors.New("malformed envelope")
+)
+
+// Codec 上下行消息的发送编码，接收方始终自动识别
+type Codec string
+
+const (
+	// CodecJSON 兼容旧版节点的 JSON 编码（默认）
+	CodecJSON Codec = "json"
+	// CodecBinary 二进制信封，全部节点升级后启用
+	CodecBinary Codec = "binary"
+)
+
+// ParseCodec 解析配置中的编码名称，空值为 CodecJSON
+func ParseCodec(name string) (Codec, error) {
+	switch Codec(name) {
+	case "", CodecJSON:
+		return CodecJSON, nil
+	case CodecBinary:
+		return CodecBinary, nil
+	default:
+		return "", fmt.Errorf("unknown envelope codec %q (want json or binary)", name)
+	}
+}
+
+// MarshalUpstream 编码上行消息
+func (c Codec) MarshalUpstream(msg *UpstreamMessage) ([]byte, error) {
+	if c == CodecBinary {
+		return appendUpstream(newEnvelope(), msg), nil
+	}
+	return json.Marshal(msg)
+}
+
+// MarshalDownstream 编码下行消息
+func (c Codec) MarshalDownstream(msg *DownstreamMessage) ([]byte, error) {
+	if c == CodecBinary {
+		return appendDownstream(newEnvelope(), msg), nil
+	}
+	return json.Marshal(msg)
+}
+
+// UnmarshalUpstream 解码上行消息，自动识别二进制信封与 JSON
+func UnmarshalUpstream(data []byte, msg *UpstreamMessage) error {
+	body, binary, err := envelopeBody(data)
+	if err != nil {
+		return err
+	}
+	if !binary {
+		return json.Unmarshal(data, msg)
+	}
+	*msg = UpstreamMessage{}
+	return decodeUpstream(body, msg)
+}
+
+// UnmarshalDownstream 解码下行消息，自动识别二进制信封与 JSON
+func UnmarshalDownstream(data []byte, msg *DownstreamMessage) error {
+	body, binary, err := envelopeBody(data)
+	if err != nil {
+		return err
+	}
+	if !binary {
+		return json.Unmarshal(data, msg)
+	}
+	*msg = DownstreamMessage{}
+	return decodeDownstream(body, msg)
+}
+
+// newEnvelope 分配信封缓冲并写入头部
+func newEnvelope() []byte {
+	buf := make([]byte, envelopeHeaderSize, 256)
+	buf[0] = EnvelopeMagic
+	buf[1] = EnvelopeVersion
+	return buf
+}
+
+// envelopeBody 识别格式：二进制信封返回消息体，否则按 JSON 处理
+func envelopeBody(data []byte) ([]byte, bool, error) {
+	if len(data) == 0 {
+		return nil, false, ErrMalformedEnvelope
+	}
+	if data[0] != EnvelopeMagic {
+		return nil, false, nil
+	}
+	if len(data) < envelopeHeaderSize {
+		return nil, false, ErrMalformedEnvelope
+	}
+	if version := data[1]; version == 0 || version > EnvelopeVersion {
+		return nil, false, fmt.Errorf("%w: %d", ErrUnsupportedEnvelopeVersion, version)
+	}
+	return data[envelopeHeaderSize:], true, nil
+}
@@ -0,0 +1,260 @@
+package proto
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func sampleUpstream() *UpstreamMessage {
+	return &UpstreamMessage{
+		AccessNodeId: "access-node-1",
+		ConnId:       1234567890123,
+		Platform:     "ios",
+		Payload: UpstreamPayload{UserMessage: &UserMessage{
+			ClientMsgId: "c3f1a2b4-5d6e-4f70-8a9b-0c1d2e3f4a5b",
+			FromUserId:  1890000000000000001,
+			ToUserId:    1890000000000000002,
+			ChatType:    "PRIVATE",
+			MsgType:     1,
+			Content:     []byte("hello, this is a typical chat message"),
+			Ext:         []KeyValue{{Key: "reply_to", Value: "1890000000000000999"}},
+			Timestamp:   1760000000000,
+		}},
+	}
+}
+
+func samplePush() *DownstreamMessage {
+	return &DownstreamMessage{
+		UserId:   1890000000000000002,
+		ConnId:   1234567890456,
+		Platform: "android",
+		Payload: DownstreamPayload{PushMessage: &PushMessage{
+			ServerMsgId: 1890000000000123456,
+			FromUserId:  1890000000000000001,
+			SenderInfo:  &UserInfo{UserId: 1890000000000000001, Nickname: "alice", Avatar: "https://example.com/a.png"},
+			ToUserId:    1890000000000000002,
+			ChatType:    "PRIVATE",
+			MsgType:     1,
+			Content:     []byte("hello, this is a typical chat message"),
+			Timestamp:   1760000000000,
+			Platform:    "android",
+			ConnId:      1234567890456,
+		}},
+	}
+}
+
+func sampleGamePush() *DownstreamMessage {
+	payload := make([]byte, 256)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+	return &DownstreamMessage{
+		UserId: 1890000000000000002,
+		Payload: DownstreamPayload{GamePush: &GamePush{
+			RoomId:          "room-100001",
+			GameType:        "HT_MAHJONG",
+			GamePayloadType: "MahjongPush",
+			GamePayload:     payload,
+			ToUserId:        1890000000000000002,
+		}},
+	}
+}
+
+// TestEnvelopeRoundTrip 所有载荷经二进制信封编解码后与原消息一致
+func TestEnvelopeRoundTrip(t *testing.T) {
+	roomInfo := &RoomInfo{
+		RoomId: "room-1", OwnerId: 1, MaxPlayers: 4, CurrentPlayers: 2, Status: "WAITING",
+		Players: []RoomPlayer{
+			{UserId: 1, Nickname: "a", Avatar: "x", IsReady: true, SeatIndex: 0, IsOnline: true},
+			{UserId: 2, Nickname: "b", SeatIndex: 3},
+		},
+	}
+	upstreams := []*UpstreamMessage{
+		sampleUpstream(),
+		{AccessNodeId: "n", Payload: UpstreamPayload{UserOnline: &UserOnline{UserId: 1, ConnId: 2, DeviceId: "d", Platform: "web"}}},
+		{AccessNodeId: "n", Payload: UpstreamPayload{UserOffline: &UserOffline{UserId: 1, ConnId: 2, Platform: "web"}}},
+		{AccessNodeId: "n", Payload: UpstreamPayload{UserOnline: &UserOnline{}}},
+		{AccessNodeId: "n", Payload: UpstreamPayload{ConversationRead: &ConversationRead{UserId: 1, PeerID: 2, GroupID: 3, LastReadMsgID: 4}}},
+		{AccessNodeId: "n", Payload: UpstreamPayload{RoomRequest: &RoomRequest{UserId: 1, ReqId: "r", Action: "JOIN", RoomId: "room-1", GameType: "HT_MAHJONG", RoomConfig: `{"a":1}`, SeatIndex: -1}}},
+		{AccessNodeId: "n", Payload: UpstreamPayload{GameRequest: &GameRequest{UserId: 1, ReqId: "r", RoomId: "room-1", GameType: "HT_MAHJONG", GamePayload: []byte{0, 1, 2}}}},
+	}
+	for _, want := range upstreams {
+		data, err := CodecBinary.MarshalUpstream(want)
+		if err != nil {
+			t.Fatalf("MarshalUpstream failed: %v", err)
+		}
+		var got UpstreamMessage
+		if err := UnmarshalUpstream(data, &got); err != nil {
+			t.Fatalf("UnmarshalUpstream failed: %v", err)
+		}
+		if !reflect.DeepEqual(&got, want) {
+			t.Fatalf("上行消息不一致:\n got  %+v\n want %+v", got, *want)
+		}
+	}
+
+	downstreams := []*DownstreamMessage{
+		samplePush(),
+		sampleGamePush(),
+		{UserId: 1, Payload: DownstreamPayload{MessageAck: &MessageAck{ClientMsgId: "c", ServerMsgId: 2, ToUserId: 1, Timestamp: 3, Platform: "ios", ConnId: 4}}},
+		{UserId: 1, ConnId: 4, Payload: DownstreamPayload{MessageAck: &MessageAck{ClientMsgId: "c", ToUserId: 1, Timestamp: 3, Code: "NOT_GROUP_MEMBER", Msg: "m"}}},
+		{UserId: 1, Payload: DownstreamPayload{RoomPush: &RoomPush{Event: "USER_JOINED", RoomId: "room-1", UserId: 2, RoomInfo: roomInfo, ToUserId: 1, Platform: "ios", ConnId: 3}}},
+		{UserId: 1, Payload: DownstreamPayload{RoomPush: &RoomPush{Event: "ERROR", ErrorCode: "ROOM_FULL", ErrorMsg: "full"}}},
+		{UserId: 1, ConnId: 2, Payload: DownstreamPayload{RoomResp: &RoomResp{ReqId: "r", Action: "CREATE", Code: "", RoomId: "room-1", RoomInfo: roomInfo}}},
+		{UserId: 1, Payload: DownstreamPayload{SystemPush: &SystemPush{Title: "t", Content: "c", Level: "INFO", ActionUrl: "https://example.com"}}},
+		{UserId: 1, ConnId: 2, Payload: DownstreamPayload{Kick: &Kick{Reason: "NEW_LOGIN", Msg: "m"}}},
+	}
+	for _, want := range downstreams {
+		data, err := CodecBinary.MarshalDownstream(want)
+		if err != nil {
+			t.Fatalf("MarshalDownstream failed: %v", err)
+		}
+		var got DownstreamMessage
+		if err := UnmarshalDownstream(data, &got); err != nil {
+			t.Fatalf("UnmarshalDownstream failed: %v", err)
+		}
+		if !reflect.DeepEqual(&got, want) {
+			t.Fatalf("下行消息不一致:\n got  %+v\n want %+v", got, *want)
+		}
+	}
+}
+
+// TestEnvelopeMixedCodecs 滚动升级：接收方同时接受旧节点的 JSON 与新节点的二进制信封
+func TestEnvelopeMixedCodecs(t *testing.T) {
+	want := samplePush()
+	legacy, _ := json.Marshal(want)
+	current, _ := CodecJSON.MarshalDownstream(want)
+	if string(legacy) != string(current) {
+		t.Fatalf("CodecJSON 必须与旧版 JSON 编码一致")
+	}
+	binary, _ := CodecBinary.MarshalDownstream(want)
+	if len(binary) >= len(legacy) {
+		t.Fatalf("二进制信封 %d 字节，应小于 JSON %d 字节", len(binary), len(legacy))
+	}
+
+	for _, data := range [][]byte{legacy, binary, append([]byte(" \n"), legacy...)} {
+		var got DownstreamMessage
+		if err := UnmarshalDownstream(data, &got); err != nil {
+			t.Fatalf("UnmarshalDownstream failed: %v", err)
+		}
+		if !reflect.DeepEqual(&got, want) {
+			t.Fatalf("消息不一致: %+v", got)
+		}
+	}
+}
+
+// TestEnvelopeUnknownFields 旧节点跳过新版本新增的字段与载荷
+func TestEnvelopeUnknownFields(t *testing.T) {
+	data, _ := CodecBinary.MarshalUpstream(sampleUpstream())
+	// 顶层追加未知 varint / bytes / fixed64 字段
+	data = protowire.AppendTag(data, 99, protowire.VarintType)
+	data = protowire.AppendVarint(data, 42)
+	data = protowire.AppendTag(data, 100, protowire.BytesType)
+	data = protowire.AppendString(data, "future payload")
+	data = protowire.AppendTag(data, 101, protowire.Fixed64Type)
+	data = protowire.AppendFixed64(data, 7)
+
+	var got UpstreamMessage
+	if err := UnmarshalUpstream(data, &got); err != nil {
+		t.Fatalf("UnmarshalUpstream failed: %v", err)
+	}
+	if !reflect.DeepEqual(&got, sampleUpstream()) {
+		t.Fatalf("消息不一致: %+v", got)
+	}
+}
+
+// TestEnvelopeRejects 拒绝更高版本与损坏的消息
+func TestEnvelopeRejects(t *testing.T) {
+	data, _ := CodecBinary.MarshalUpstream(sampleUpstream())
+	var msg UpstreamMessage
+
+	future := append([]byte(nil), data...)
+	future[1] = EnvelopeVersion + 1
+	if err := UnmarshalUpstream(future, &msg); !errors.Is(err, ErrUnsupportedEnvelopeVersion) {
+		t.Fatalf("err = %v, want ErrUnsupportedEnvelopeVersion", err)
+	}
+	if err := UnmarshalUpstream(data[:len(data)-3], &msg); !errors.Is(err, ErrMalformedEnvelope) {
+		t.Fatalf("截断消息 err = %v, want ErrMalformedEnvelope", err)
+	}
+	if err := UnmarshalUpstream(nil, &msg); !errors.Is(err, ErrMalformedEnvelope) {
+		t.Fatalf("空消息 err = %v, want ErrMalformedEnvelope", err)
+	}
+}
+
+func TestParseCodec(t *testing.T) {
+	for name, want := range map[string]Codec{"": CodecJSON, "json": CodecJSON, "binary": CodecBinary} {
+		if got, err := ParseCodec(name); err != nil || got != want {
+			t.Fatalf("ParseCodec(%q) = %q, %v", name, got, err)
+		}
+	}
+	if _, err := ParseCodec("protobuf"); err == nil {
+		t.Fatalf("未知编码应返回错误")
+	}
+}
+
+// BenchmarkEnvelope 上下行典型消息的 JSON 与二进制信封编解码开销及消息大小（bytes/msg）
+//
+//	go test ./proto -run '^$' -bench Envelope -benchmem
+func BenchmarkEnvelope(b *testing.B) {
+	upstream, push, gamePush := sampleUpstream(), samplePush(), sampleGamePush()
+	cases := []struct {
+		name      string
+		marshal   func(Codec) ([]byte, error)
+		unmarshal func([]byte) error
+	}{
+		{
+			name:    "UserMessage",
+			marshal: func(c Codec) ([]byte, error) { return c.MarshalUpstream(upstream) },
+			unmarshal: func(data []byte) error {
+				var m UpstreamMessage
+				return UnmarshalUpstream(data, &m)
+			},
+		},
+		{
+			name:    "PushMessage",
+			marshal: func(c Codec) ([]byte, error) { return c.MarshalDownstream(push) },
+			unmarshal: func(data []byte) error {
+				var m DownstreamMessage
+				return UnmarshalDownstream(data, &m)
+			},
+		},
+		{
+			name:    "GamePush",
+			marshal: func(c Codec) ([]byte, error) { return c.MarshalDownstream(gamePush) },
+			unmarshal: func(data []byte) error {
+				var m DownstreamMessage
+				return UnmarshalDownstream(data, &m)
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		for _, codec := range []Codec{CodecJSON, CodecBinary} {
+			data, err := tc.marshal(codec)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.Run(tc.name+"/"+string(codec)+"/encode", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := tc.marshal(codec); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(len(data)), "bytes/msg")
+			})
+			b.Run(tc.name+"/"+string(codec)+"/decode", func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if err := tc.unmarshal(data); err != nil {
+						b.Fatal(err)
+					}
+				}
+				b.ReportMetric(float64(len(data)), "bytes/msg")
+			})
+		}
+	}
+}
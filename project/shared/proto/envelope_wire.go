@@ -0,0 +1,786 @@
+package proto
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// 二进制信封的 protobuf 编解码，字段编号见 schema/envelope.proto
+// 手写实现以直接编解码现有结构体，避免生成代码与中间对象的转换开销
+// 编码跳过零值字段；嵌套消息非 nil 即写入（载荷类型由是否存在判断）；解码跳过未知字段
+
+// ============== 编码 ==============
+
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendInt64(b []byte, num protowire.Number, v int64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(v))
+}
+
+func appendInt32(b []byte, num protowire.Number, v int32) []byte {
+	return appendInt64(b, num, int64(v))
+}
+
+func appendBool(b []byte, num protowire.Number, v bool) []byte {
+	if !v {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, 1)
+}
+
+// beginMessage 写入嵌套消息的 tag，返回消息体起始位置
+func beginMessage(b []byte, num protowire.Number) ([]byte, int) {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return b, len(b)
+}
+
+// endMessage 消息体写完后在起始位置插入长度前缀（消息体通常小于 128 字节，只需后移一字节）
+func endMessage(b []byte, start int) []byte {
+	n := len(b) - start
+	size := protowire.SizeVarint(uint64(n))
+	for i := 0; i < size; i++ {
+		b = append(b, 0)
+	}
+	copy(b[start+size:], b[start:start+n])
+	protowire.AppendVarint(b[start:start], uint64(n))
+	return b
+}
+
+func appendUpstream(b []byte, m *UpstreamMessage) []byte {
+	b = appendString(b, 1, m.AccessNodeId)
+	b = appendInt64(b, 2, m.ConnId)
+	b = appendString(b, 3, m.Platform)
+
+	var start int
+	p := &m.Payload
+	if p.UserMessage != nil {
+		b, start = beginMessage(b, 10)
+		b = endMessage(appendUserMessage(b, p.UserMessage), start)
+	}
+	if p.UserOnline != nil {
+		b, start = beginMessage(b, 11)
+		b = endMessage(appendUserOnline(b, p.UserOnline), start)
+	}
+	if p.UserOffline != nil {
+		b, start = beginMessage(b, 12)
+		b = endMessage(appendUserOffline(b, p.UserOffline), start)
+	}
+	if p.ConversationRead != nil {
+		b, start = beginMessage(b, 13)
+		b = endMessage(appendConversationRead(b, p.ConversationRead), start)
+	}
+	if p.RoomRequest != nil {
+		b, start = beginMessage(b, 14)
+		b = endMessage(appendRoomRequest(b, p.RoomRequest), start)
+	}
+	if p.GameRequest != nil {
+		b, start = beginMessage(b, 15)
+		b = endMessage(appendGameRequest(b, p.GameRequest), start)
+	}
+	return b
+}
+
+func appendUserMessage(b []byte, m *UserMessage) []byte {
+	b = appendString(b, 1, m.ClientMsgId)
+	b = appendInt64(b, 2, m.FromUserId)
+	b = appendInt64(b, 3, m.ToUserId)
+	b = appendInt64(b, 4, m.ToGroupId)
+	b = appendString(b, 5, m.ChatType)
+	b = appendInt32(b, 6, m.MsgType)
+	b = appendBytes(b, 7, m.Content)
+	b = appendKeyValues(b, 8, m.Ext)
+	return appendInt64(b, 9, m.Timestamp)
+}
+
+func appendUserOnline(b []byte, m *UserOnline) []byte {
+	b = appendInt64(b, 1, m.UserId)
+	b = appendInt64(b, 2, m.ConnId)
+	b = appendString(b, 3, m.DeviceId)
+	return appendString(b, 4, m.Platform)
+}
+
+func appendUserOffline(b []byte, m *UserOffline) []byte {
+	b = appendInt64(b, 1, m.UserId)
+	b = appendInt64(b, 2, m.ConnId)
+	return appendString(b, 3, m.Platform)
+}
+
+func appendConversationRead(b []byte, m *ConversationRead) []byte {
+	b = appendInt64(b, 1, m.UserId)
+	b = appendInt64(b, 2, m.PeerID)
+	b = appendInt64(b, 3, m.GroupID)
+	return appendInt64(b, 4, m.LastReadMsgID)
+}
+
+func appendRoomRequest(b []byte, m *RoomRequest) []byte {
+	b = appendInt64(b, 1, m.UserId)
+	b = appendString(b, 2, m.ReqId)
+	b = appendString(b, 3, m.Action)
+	b = appendString(b, 4, m.RoomId)
+	b = appendString(b, 5, m.GameType)
+	b = appendString(b, 6, m.RoomConfig)
+	return appendInt32(b, 7, m.SeatIndex)
+}
+
+func appendGameRequest(b []byte, m *GameRequest) []byte {
+	b = appendInt64(b, 1, m.UserId)
+	b = appendString(b, 2, m.ReqId)
+	b = appendString(b, 3, m.RoomId)
+	b = appendString(b, 4, m.GameType)
+	return appendBytes(b, 5, m.GamePayload)
+}
+
+func appendDownstream(b []byte, m *DownstreamMessage) []byte {
+	b = appendInt64(b, 1, m.UserId)
+	b = appendInt64(b, 2, m.ConnId)
+	b = appendString(b, 3, m.Platform)
+
+	var start int
+	p := &m.Payload
+	if p.PushMessage != nil {
+		b, start = beginMessage(b, 10)
+		b = endMessage(appendPushMessage(b, p.PushMessage), start)
+	}
+	if p.MessageAck != nil {
+		b, start = beginMessage(b, 11)
+		b = endMessage(appendMessageAck(b, p.MessageAck), start)
+	}
+	if p.RoomPush != nil {
+		b, start = beginMessage(b, 12)
+		b = endMessage(appendRoomPush(b, p.RoomPush), start)
+	}
+	if p.RoomResp != nil {
+		b, start = beginMessage(b, 13)
+		b = endMessage(appendRoomResp(b, p.RoomResp), start)
+	}
+	if p.GamePush != nil {
+		b, start = beginMessage(b, 14)
+		b = endMessage(appendGamePush(b, p.GamePush), start)
+	}
+	if p.SystemPush != nil {
+		b, start = beginMessage(b, 15)
+		b = endMessage(appendSystemPush(b, p.SystemPush), start)
+	}
+	if p.Kick != nil {
+		b, start = beginMessage(b, 16)
+		b = endMessage(appendKick(b, p.Kick), start)
+	}
+	return b
+}
+
+func appendPushMessage(b []byte, m *PushMessage) []byte {
+	b = appendInt64(b, 1, m.ServerMsgId)
+	b = appendInt64(b, 2, m.FromUserId)
+	if m.SenderInfo != nil {
+		var start int
+		b, start = beginMessage(b, 3)
+		b = endMessage(appendUserInfo(b, m.SenderInfo), start)
+	}
+	b = appendInt64(b, 4, m.ToUserId)
+	b = appendInt64(b, 5, m.ToGroupId)
+	b = appendString(b, 6, m.ChatType)
+	b = appendInt32(b, 7, m.MsgType)
+	b = appendBytes(b, 8, m.Content)
+	b = appendKeyValues(b, 9, m.Ext)
+	b = appendInt64(b, 10, m.Timestamp)
+	b = appendString(b, 11, m.Platform)
+	return appendInt64(b, 12, m.ConnId)
+}
+
+func appendMessageAck(b []byte, m *MessageAck) []byte {
+	b = appendString(b, 1, m.ClientMsgId)
+	b = appendInt64(b, 2, m.ServerMsgId)
+	b = appendInt64(b, 3, m.ToUserId)
+	b = appendInt64(b, 4, m.Timestamp)
+	b = appendString(b, 5, m.Platform)
+	b = appendInt64(b, 6, m.ConnId)
+	b = appendString(b, 7, m.Code)
+	return appendString(b, 8, m.Msg)
+}
+
+func appendRoomPush(b []byte, m *RoomPush) []byte {
+	b = appendString(b, 1, m.Event)
+	b = appendString(b, 2, m.RoomId)
+	b = appendInt64(b, 3, m.UserId)
+	if m.RoomInfo != nil {
+		var start int
+		b, start = beginMessage(b, 4)
+		b = endMessage(appendRoomInfo(b, m.RoomInfo), start)
+	}
+	b = appendString(b, 5, m.ErrorCode)
+	b = appendString(b, 6, m.ErrorMsg)
+	b = appendInt64(b, 7, m.ToUserId)
+	b = appendString(b, 8, m.Platform)
+	return appendInt64(b, 9, m.ConnId)
+}
+
+func appendRoomResp(b []byte, m *RoomResp) []byte {
+	b = appendString(b, 1, m.ReqId)
+	b = appendString(b, 2, m.Action)
+	b = appendString(b, 3, m.Code)
+	b = appendString(b, 4, m.Msg)
+	b = appendString(b, 5, m.RoomId)
+	if m.RoomInfo != nil {
+		var start int
+		b, start = beginMessage(b, 6)
+		b = endMessage(appendRoomInfo(b, m.RoomInfo), start)
+	}
+	return b
+}
+
+func appendRoomInfo(b []byte, m *RoomInfo) []byte {
+	b = appendString(b, 1, m.RoomId)
+	b = appendInt64(b, 2, m.OwnerId)
+	b = appendInt32(b, 3, m.MaxPlayers)
+	b = appendInt32(b, 4, m.CurrentPlayers)
+	b = appendString(b, 5, m.Status)
+	for i := range m.Players {
+		var start int
+		b, start = beginMessage(b, 6)
+		b = endMessage(appendRoomPlayer(b, &m.Players[i]), start)
+	}
+	return b
+}
+
+func appendRoomPlayer(b []byte, m *RoomPlayer) []byte {
+	b = appendInt64(b, 1, m.UserId)
+	b = appendString(b, 2, m.Nickname)
+	b = appendString(b, 3, m.Avatar)
+	b = appendBool(b, 4, m.IsReady)
+	b = appendInt32(b, 5, m.SeatIndex)
+	return appendBool(b, 6, m.IsOnline)
+}
+
+func appendUserInfo(b []byte, m *UserInfo) []byte {
+	b = appendInt64(b, 1, m.UserId)
+	b = appendString(b, 2, m.Nickname)
+	return appendString(b, 3, m.Avatar)
+}
+
+func appendKeyValues(b []byte, num protowire.Number, kvs []KeyValue) []byte {
+	for i := range kvs {
+		var start int
+		b, start = beginMessage(b, num)
+		b = appendString(b, 1, kvs[i].Key)
+		b = appendString(b, 2, kvs[i].Value)
+		b = endMessage(b, start)
+	}
+	return b
+}
+
+func appendGamePush(b []byte, m *GamePush) []byte {
+	b = appendString(b, 1, m.RoomId)
+	b = appendString(b, 2, m.GameType)
+	b = appendString(b, 3, m.GamePayloadType)
+	b = appendBytes(b, 4, m.GamePayload)
+	b = appendInt64(b, 5, m.ToUserId)
+	b = appendString(b, 6, m.Platform)
+	return appendInt64(b, 7, m.ConnId)
+}
+
+func appendSystemPush(b []byte, m *SystemPush) []byte {
+	b = appendString(b, 1, m.Title)
+	b = appendString(b, 2, m.Content)
+	b = appendString(b, 3, m.Level)
+	return appendString(b, 4, m.ActionUrl)
+}
+
+func appendKick(b []byte, m *Kick) []byte {
+	b = appendString(b, 1, m.Reason)
+	return appendString(b, 2, m.Msg)
+}
+
+// ============== 解码 ==============
+
+// wireField 解码出的单个字段，varint 存于 v，length-delimited 存于 b（引用输入缓冲）
+type wireField struct {
+	num protowire.Number
+	typ protowire.Type
+	v   uint64
+	b   []byte
+}
+
+// isVarint / isBytes 字段类型与 schema 一致才解码，否则按未知字段跳过
+func (f *wireField) isVarint() bool { return f.typ == protowire.VarintType }
+func (f *wireField) isBytes() bool  { return f.typ == protowire.BytesType }
+
+func (f *wireField) int64() int64   { return int64(f.v) }
+func (f *wireField) int32() int32   { return int32(f.v) }
+func (f *wireField) bool() bool     { return f.v != 0 }
+func (f *wireField) string() string { return string(f.b) }
+
+// bytes 复制字节字段，解码结果不引用消息总线的接收缓冲
+func (f *wireField) bytes() []byte { return append([]byte(nil), f.b...) }
+
+// decodeFields 依次解码消息中的字段，未知编号或类型由 fn 忽略即可跳过
+func decodeFields(b []byte, fn func(f *wireField) error) error {
+	var f wireField
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("%w: %w", ErrMalformedEnvelope, protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		f = wireField{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			f.v, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			f.b, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return fmt.Errorf("%w: field %d: %w", ErrMalformedEnvelope, num, protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		if err := fn(&f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeUpstream(b []byte, m *UpstreamMessage) error {
+	p := &m.Payload
+	return decodeFields(b, func(f *wireField) error {
+		switch {
+		case f.num == 1 && f.isBytes():
+			m.AccessNodeId = f.string()
+		case f.num == 2 && f.isVarint():
+			m.ConnId = f.int64()
+		case f.num == 3 && f.isBytes():
+			m.Platform = f.string()
+		case f.num == 10 && f.isBytes():
+			p.UserMessage = &UserMessage{}
+			return decodeUserMessage(f.b, p.UserMessage)
+		case f.num == 11 && f.isBytes():
+			p.UserOnline = &UserOnline{}
+			return decodeUserOnline(f.b, p.UserOnline)
+		case f.num == 12 && f.isBytes():
+			p.UserOffline = &UserOffline{}
+			return decodeUserOffline(f.b, p.UserOffline)
+		case f.num == 13 && f.isBytes():
+			p.ConversationRead = &ConversationRead{}
+			return decodeConversationRead(f.b, p.ConversationRead)
+		case f.num == 14 && f.isBytes():
+			p.RoomRequest = &RoomRequest{}
+			return decodeRoomRequest(f.b, p.RoomRequest)
+		case f.num == 15 && f.isBytes():
+			p.GameRequest = &GameRequest{}
+			return decodeGameRequest(f.b, p.GameRequest)
+		}
+		return nil
+	})
+}
+
+func decodeUserMessage(b []byte, m *UserMessage) error {
+	return decodeFields(b, func(f *wireField) error {
+		switch {
+		case f.num == 1 && f.isBytes():
+			m.ClientMsgId = f.string()
+		case f.num == 2 && f.isVarint():
+			m.FromUserId = f.int64()
+		case f.num == 3 && f.isVarint():
+			m.ToUserId = f.int64()
+		case f.num == 4 && f.isVarint():
+			m.ToGroupId = f.int64()
+		case f.num == 5 && f.isBytes():
+			m.ChatType = f.string()
+		case f.num == 6 && f.isVarint():
+			m.MsgType = f.int32()
+		case f.num == 7 && f.isBytes():
+			m.Content = f.bytes()
+		case f.num == 8 && f.isBytes():
+			return decodeKeyValue(f.b, &m.Ext)
+		case f.num == 9 && f.isVarint():
+			m.Timestamp = f.int64()
+		}
+		return nil
+	})
+}
+
+func decodeUserOnline(b []byte, m *UserOnline) error {
+	return decodeFields(b, func(f *wireField) error {
+		switch {
+		case f.num == 1 && f.isVarint():
+			m.UserId = f.int64()
+		case f.num == 2 && f.isVarint():
+			m.ConnId = f.int64()
+		case f.num == 3 && f.isBytes():
+			m.DeviceId = f.string()
+		case f.num == 4 && f.isBytes():
+			m.Platform = f.string()
+		}
+		return nil
+	})
+}
+
+func decodeUserOffline(b []byte, m *UserOffline) error {
+	return decodeFields(b, func(f *wireField) error {
+		switch {
+		case f.num == 1 && f.isVarint():
+			m.UserId = f.int64()
+		case f.num == 2 && f.isVarint():
+			m.ConnId = f.int64()
+		case f.num == 3 && f.isBytes():
+			m.Platform = f.string()
+		}
+		return nil
+	})
+}
+
+func decodeConversationRead(b []byte, m *ConversationRead) error {
+	return decodeFields(b, func(f *wireField) error {
+		if !f.isVarint() {
+			return nil
+		}
+		switch f.num {
+		case 1:
+			m.UserId = f.int64()
+		case 2:
+			m.PeerID = f.int64()
+		case 3:
+			m.GroupID = f.int64()
+		case 4:
+			m.LastReadMsgID = f.int64()
+		}
+		return nil
+	})
+}
+
+func decodeRoomRequest(b []byte, m *RoomRequest) error {
+	return decodeFields(b, func(f *wireField) error {
+		switch {
+		case f.num == 1 && f.isVarint():
+			m.UserId = f.int64()
+		case f.num == 2 && f.isBytes():
+			m.ReqId = f.string()
+		case f.num == 3 && f.isBytes():
+			m.Action = f.string()
+		case f.num == 4 && f.isBytes():
+			m.RoomId = f.string()
+		case f.num == 5 && f.isBytes():
+			m.GameType = f.string()
+		case f.num == 6 && f.isBytes():
+			m.RoomConfig = f.string()
+		case f.num == 7 && f.isVarint():
+			m.SeatIndex = f.int32()
+		}
+		return nil
+	})
+}
+
+func decodeGameRequest(b []byte, m *GameRequest) error {
+	return decodeFields(b, func(f *wireField) error {
+		switch {
+		case f.num == 1 && f.isVarint():
+			m.UserId = f.int64()
+		case f.num == 2 && f.isBytes():
+			m.ReqId = f.string()
+		case f.num == 3 && f.isBytes():
+			m.RoomId = f.string()
+		case f.num == 4 && f.isBytes():
+			m.GameType = f.string()
+		case f.num == 5 && f.isBytes():
+			m.GamePayload = f.bytes()
+		}
+		return nil
+	})
+}
+
+func decodeDownstream(b []byte, m *DownstreamMessage) error {
+	p := &m.Payload
+	return decodeFields(b, func(f *wireField) error {
+		switch {
+		case f.num == 1 && f.isVarint():
+			m.UserId = f.int64()
+		case f.num == 2 && f.isVarint():
+			m.ConnId = f.int64()
+		case f.num == 3 && f.isBytes():
+			m.Platform = f.string()
+		case f.num == 10 && f.isBytes():
+			p.PushMessage = &PushMessage{}
+			return decodePushMessage(f.b, p.PushMessage)
+		case f.num == 11 && f.isBytes():
+			p.MessageAck = &MessageAck{}
+			return decodeMessageAck(f.b, p.MessageAck)
+		case f.num == 12 && f.isBytes():
+			p.RoomPush = &RoomPush{}
+			return decodeRoomPush(f.b, p.RoomPush)
+		case f.num == 13 && f.isBytes():
+			p.RoomResp = &RoomResp{}
+			return decodeRoomResp(f.b, p.RoomResp)
+		case f.num == 14 && f.isBytes():
+			p.GamePush = &GamePush{}
+			return decodeGamePush(f.b, p.GamePush)
+		case f.num == 15 && f.isBytes():
+			p.SystemPush = &SystemPush{}
+			return decodeSystemPush(f.b, p.SystemPush)
+		case f.num == 16 && f.isBytes():
+			p.Kick = &Kick{}
+			return decodeKick(f.b, p.Kick)
+		}
+		return nil
+	})
+}
+
+func decodePushMessage(b []byte, m *PushMessage) error {
+	return decodeFields(b, func(f *wireField) error {
+		switch {
+		case f.num == 1 && f.isVarint():
+			m.ServerMsgId = f.int64()
+		case f.num == 2 && f.isVarint():
+			m.FromUserId = f.int64()
+		case f.num == 3 && f.isBytes():
+			m.SenderInfo = &UserInfo{}
+			return decodeUserInfo(f.b, m.SenderInfo)
+		case f.num == 4 && f.isVarint():
+			m.ToUserId = f.int64()
+		case f.num == 5 && f.isVarint():
+			m.ToGroupId = f.int64()
+		case f.num == 6 && f.isBytes():
+			m.ChatType = f.string()
+		case f.num == 7 && f.isVarint():
+			m.MsgType = f.int32()
+		case f.num == 8 && f.isBytes():
+			m.Content = f.bytes()
+		case f.num == 9 && f.isBytes():
+			return decodeKeyValue(f.b, &m.Ext)
+		case f.num == 10 && f.isVarint():
+			m.Timestamp = f.int64()
+		case f.num == 11 && f.isBytes():
+			m.Platform = f.string()
+		case f.num == 12 && f.isVarint():
+			m.ConnId = f.int64()
+		}
+		return nil
+	})
+}
+
+func decodeMessageAck(b []byte, m *MessageAck) error {
+	return decodeFields(b, func(f *wireField) error {
+		switch {
+		case f.num == 1 && f.isBytes():
+			m.ClientMsgId = f.string()
+		case f.num == 2 && f.isVarint():
+			m.ServerMsgId = f.int64()
+		case f.num == 3 && f.isVarint():
+			m.ToUserId = f.int64()
+		case f.num == 4 && f.isVarint():
+			m.Timestamp = f.int64()
+		case f.num == 5 && f.isBytes():
+			m.Platform = f.string()
+		case f.num == 6 && f.isVarint():
+			m.ConnId = f.int64()
+		case f.num == 7 && f.isBytes():
+			m.Code = f.string()
+		case f.num == 8 && f.isBytes():
+			m.Msg = f.string()
+		}
+		return nil
+	})
+}
+
+func decodeRoomPush(b []byte, m *RoomPush) error {
+	return decodeFields(b, func(f *wireField) error {
+		switch {
+		case f.num == 1 && f.isBytes():
+			m.Event = f.string()
+		case f.num == 2 && f.isBytes():
+			m.RoomId = f.string()
+		case f.num == 3 && f.isVarint():
+			m.UserId = f.int64()
+		case f.num == 4 && f.isBytes():
+			m.RoomInfo = &RoomInfo{}
+			return decodeRoomInfo(f.b, m.RoomInfo)
+		case f.num == 5 && f.isBytes():
+			m.ErrorCode = f.string()
+		case f.num == 6 && f.isBytes():
+			m.ErrorMsg = f.string()
+		case f.num == 7 && f.isVarint():
+			m.ToUserId = f.int64()
+		case f.num == 8 && f.isBytes():
+			m.Platform = f.string()
+		case f.num == 9 && f.isVarint():
+			m.ConnId = f.int64()
+		}
+		return nil
+	})
+}
+
+func decodeRoomResp(b []byte, m *RoomResp) error {
+	return decodeFields(b, func(f *wireField) error {
+		if !f.isBytes() {
+			return nil
+		}
+		switch f.num {
+		case 1:
+			m.ReqId = f.string()
+		case 2:
+			m.Action = f.string()
+		case 3:
+			m.Code = f.string()
+		case 4:
+			m.Msg = f.string()
+		case 5:
+			m.RoomId = f.string()
+		case 6:
+			m.RoomInfo = &RoomInfo{}
+			return decodeRoomInfo(f.b, m.RoomInfo)
+		}
+		return nil
+	})
+}
+
+func decodeRoomInfo(b []byte, m *RoomInfo) error {
+	return decodeFields(b, func(f *wireField) error {
+		switch {
+		case f.num == 1 && f.isBytes():
+			m.RoomId = f.string()
+		case f.num == 2 && f.isVarint():
+			m.OwnerId = f.int64()
+		case f.num == 3 && f.isVarint():
+			m.MaxPlayers = f.int32()
+		case f.num == 4 && f.isVarint():
+			m.CurrentPlayers = f.int32()
+		case f.num == 5 && f.isBytes():
+			m.Status = f.string()
+		case f.num == 6 && f.isBytes():
+			m.Players = append(m.Players, RoomPlayer{})
+			return decodeRoomPlayer(f.b, &m.Players[len(m.Players)-1])
+		}
+		return nil
+	})
+}
+
+func decodeRoomPlayer(b []byte, m *RoomPlayer) error {
+	return decodeFields(b, func(f *wireField) error {
+		switch {
+		case f.num == 1 && f.isVarint():
+			m.UserId = f.int64()
+		case f.num == 2 && f.isBytes():
+			m.Nickname = f.string()
+		case f.num == 3 && f.isBytes():
+			m.Avatar = f.string()
+		case f.num == 4 && f.isVarint():
+			m.IsReady = f.bool()
+		case f.num == 5 && f.isVarint():
+			m.SeatIndex = f.int32()
+		case f.num == 6 && f.isVarint():
+			m.IsOnline = f.bool()
+		}
+		return nil
+	})
+}
+
+func decodeUserInfo(b []byte, m *UserInfo) error {
+	return decodeFields(b, func(f *wireField) error {
+		switch {
+		case f.num == 1 && f.isVarint():
+			m.UserId = f.int64()
+		case f.num == 2 && f.isBytes():
+			m.Nickname = f.string()
+		case f.num == 3 && f.isBytes():
+			m.Avatar = f.string()
+		}
+		return nil
+	})
+}
+
+// decodeKeyValue 解码一个 repeated KeyValue 元素并追加到 kvs
+func decodeKeyValue(b []byte, kvs *[]KeyValue) error {
+	var kv KeyValue
+	err := decodeFields(b, func(f *wireField) error {
+		switch {
+		case f.num == 1 && f.isBytes():
+			kv.Key = f.string()
+		case f.num == 2 && f.isBytes():
+			kv.Value = f.string()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	*kvs = append(*kvs, kv)
+	return nil
+}
+
+func decodeGamePush(b []byte, m *GamePush) error {
+	return decodeFields(b, func(f *wireField) error {
+		switch {
+		case f.num == 1 && f.isBytes():
+			m.RoomId = f.string()
+		case f.num == 2 && f.isBytes():
+			m.GameType = f.string()
+		case f.num == 3 && f.isBytes():
+			m.GamePayloadType = f.string()
+		case f.num == 4 && f.isBytes():
+			m.GamePayload = f.bytes()
+		case f.num == 5 && f.isVarint():
+			m.ToUserId = f.int64()
+		case f.num == 6 && f.isBytes():
+			m.Platform = f.string()
+		case f.num == 7 && f.isVarint():
+			m.ConnId = f.int64()
+		}
+		return nil
+	})
+}
+
+func decodeSystemPush(b []byte, m *SystemPush) error {
+	return decodeFields(b, func(f *wireField) error {
+		if !f.isBytes() {
+			return nil
+		}
+		switch f.num {
+		case 1:
+			m.Title = f.string()
+		case 2:
+			m.Content = f.string()
+		case 3:
+			m.Level = f.string()
+		case 4:
+			m.ActionUrl = f.string()
+		}
+		return nil
+	})
+}
+
+func decodeKick(b []byte, m *Kick) error {
+	return decodeFields(b, func(f *wireField) error {
+		if !f.isBytes() {
+			return nil
+		}
+		switch f.num {
+		case 1:
+			m.Reason = f.string()
+		case 2:
+			m.Msg = f.string()
+		}
+		return nil
+	})
+}
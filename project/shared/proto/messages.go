@@ -22,13 +22,15 @@ type UpstreamPayload struct {
 
 // UserMessage 用户消息
 type UserMessage struct {
-	ClientMsgId string `json:"ClientMsgId"`
-	FromUserId  int64  `json:"FromUserId,string"`
-	ToUserId    int64  `json:"ToUserId,string"`
-	ToGroupId   int64  `json:"ToGroupId,string"`
-	MsgType     int32  `json:"MsgType"`
-	Content     []byte `json:"Content"`
-	Timestamp   int64  `json:"Timestamp"`
+	ClientMsgId string     `json:"ClientMsgId"`
+	FromUserId  int64      `json:"FromUserId,string"`
+	ToUserId    int64      `json:"ToUserId,string"`
+	ToGroupId   int64      `json:"ToGroupId,string"`
+	ChatType    string     `json:"ChatType,omitempty"` // PRIVATE / GROUP 等（与 FlatBuffers ChatType 名称一致）
+	MsgType     int32      `json:"MsgType"`
+	Content     []byte     `json:"Content"`
+	Ext         []KeyValue `json:"Ext,omitempty"` // 扩展属性（原样透传给接收方）
+	Timestamp   int64      `json:"Timestamp"`
 }
 
 // UserOnline 用户上线事件
@@ -41,8 +43,9 @@ type UserOnline struct {
 
 // UserOffline 用户下线事件
 type UserOffline struct {
-	UserId int64 `json:"UserId,string"`
-	ConnId int64 `json:"ConnId,string"`
+	UserId   int64  `json:"UserId,string"`
+	ConnId   int64  `json:"ConnId,string"`
+	Platform string `json:"Platform,omitempty"`
 }
 
 // ConversationRead 会话已读请求
@@ -87,21 +90,27 @@ type DownstreamMessage struct {
 type DownstreamPayload struct {
 	PushMessage *PushMessage `json:"PushMessage,omitempty"`
 	MessageAck  *MessageAck  `json:"MessageAck,omitempty"`
-	RoomPush    *RoomPush    `json:"RoomPush,omitempty"` // 房间推送
-	GamePush    *GamePush    `json:"GamePush,omitempty"` // 游戏推送
+	RoomPush    *RoomPush    `json:"RoomPush,omitempty"`   // 房间推送
+	RoomResp    *RoomResp    `json:"RoomResp,omitempty"`   // 房间操作响应（ConnId 指定的发起连接）
+	GamePush    *GamePush    `json:"GamePush,omitempty"`   // 游戏推送
+	SystemPush  *SystemPush  `json:"SystemPush,omitempty"` // 系统公告
+	Kick        *Kick        `json:"Kick,omitempty"`       // 踢下线（ConnId 指定的连接）
 }
 
 // PushMessage 推送消息
 type PushMessage struct {
-	ServerMsgId int64  `json:"ServerMsgId,string"`
-	FromUserId  int64  `json:"FromUserId,string"`
-	ToUserId    int64  `json:"ToUserId,string"`
-	ToGroupId   int64  `json:"ToGroupId,string"`
-	MsgType     int32  `json:"MsgType"`
-	Content     []byte `json:"Content"`
-	Timestamp   int64  `json:"Timestamp"`
-	Platform    string `json:"Platform,omitempty"`      // 目标平台（用于 Access 路由）
-	ConnId      int64  `json:"ConnId,string,omitempty"` // 目标连接 ID（用于 Access 直接路由）
+	ServerMsgId int64      `json:"ServerMsgId,string"`
+	FromUserId  int64      `json:"FromUserId,string"`
+	SenderInfo  *UserInfo  `json:"SenderInfo,omitempty"` // 发送者资料（来自 user:info 缓存）
+	ToUserId    int64      `json:"ToUserId,string"`
+	ToGroupId   int64      `json:"ToGroupId,string"`
+	ChatType    string     `json:"ChatType,omitempty"`
+	MsgType     int32      `json:"MsgType"`
+	Content     []byte     `json:"Content"`
+	Ext         []KeyValue `json:"Ext,omitempty"`
+	Timestamp   int64      `json:"Timestamp"`
+	Platform    string     `json:"Platform,omitempty"`      // 目标平台（用于 Access 路由）
+	ConnId      int64      `json:"ConnId,string,omitempty"` // 目标连接 ID（用于 Access 直接路由）
 }
 
 // MessageAck 消息确认
@@ -112,25 +121,109 @@ type MessageAck struct {
 	Timestamp   int64  `json:"Timestamp"`
 	Platform    string `json:"Platform,omitempty"`      // 目标平台（发送消息的平台）
 	ConnId      int64  `json:"ConnId,string,omitempty"` // 目标连接 ID（用于 Access 直接路由）
+	Code        string `json:"Code,omitempty"`          // FlatBuffers ErrorCode 枚举名，空表示发送成功
+	Msg         string `json:"Msg,omitempty"`           // 失败原因
 }
 
 // RoomPush 房间推送
 type RoomPush struct {
-	Event    string `json:"Event"`                     // USER_JOINED, USER_LEFT, USER_READY, GAME_START, GAME_OVER, ROOM_DISMISSED
-	RoomId   string `json:"RoomId"`                    // 房间ID
-	UserId   int64  `json:"UserId,string,omitempty"`   // 触发事件的用户ID
-	RoomInfo []byte `json:"RoomInfo"`                  // FlatBuffers RoomInfo 数据
-	ToUserId int64  `json:"ToUserId,string,omitempty"` // 目标用户ID（可选，为空则广播给房间所有人）
-	Platform string `json:"Platform,omitempty"`        // 目标平台（可选）
-	ConnId   int64  `json:"ConnId,string,omitempty"`   // 目标连接 ID（可选）
+	Event     string    `json:"Event"`                     // USER_JOINED, USER_LEFT, USER_READY, SEAT_CHANGED, GAME_START, GAME_OVER, ROOM_DISMISSED, ERROR
+	RoomId    string    `json:"RoomId"`                    // 房间ID
+	UserId    int64     `json:"UserId,string,omitempty"`   // 触发事件的用户ID
+	RoomInfo  *RoomInfo `json:"RoomInfo,omitempty"`        // 房间快照（Access 转换为 FlatBuffers RoomInfo）
+	ErrorCode string    `json:"ErrorCode,omitempty"`       // 错误码（仅 ERROR 事件）
+	ErrorMsg  string    `json:"ErrorMsg,omitempty"`        // 错误信息（仅 ERROR 事件）
+	ToUserId  int64     `json:"ToUserId,string,omitempty"` // 目标用户ID（可选，为空则广播给房间所有人）
+	Platform  string    `json:"Platform,omitempty"`        // 目标平台（可选）
+	ConnId    int64     `json:"ConnId,string,omitempty"`   // 目标连接 ID（可选）
+}
+
+// RoomResp 房间操作响应，回复发起请求的连接
+type RoomResp struct {
+	ReqId    string    `json:"ReqId"`              // 对应 RoomRequest.ReqId
+	Action   string    `json:"Action"`             // CREATE, JOIN, LEAVE, READY, CHANGE_SEAT, START_GAME
+	Code     string    `json:"Code,omitempty"`     // FlatBuffers ErrorCode 枚举名，空表示成功
+	Msg      string    `json:"Msg,omitempty"`      // 错误信息
+	RoomId   string    `json:"RoomId,omitempty"`   // 房间ID
+	RoomInfo *RoomInfo `json:"RoomInfo,omitempty"` // 操作成功后的房间快照
+}
+
+// RoomInfo 房间快照（字段与 FlatBuffers RoomInfo 对应）
+type RoomInfo struct {
+	RoomId         string       `json:"RoomId"`
+	OwnerId        int64        `json:"OwnerId,string"`
+	MaxPlayers     int32        `json:"MaxPlayers"`
+	CurrentPlayers int32        `json:"CurrentPlayers"`
+	Status         string       `json:"Status"` // WAITING, PLAYING, SETTLING
+	Players        []RoomPlayer `json:"Players"`
+}
+
+// UserInfo 用户资料（字段与 FlatBuffers UserInfo 对应）
+type UserInfo struct {
+	UserId   int64  `json:"UserId,string"`
+	Nickname string `json:"Nickname"`
+	Avatar   string `json:"Avatar"`
+}
+
+// KeyValue 扩展属性（字段与 FlatBuffers KeyValue 对应）
+type KeyValue struct {
+	Key   string `json:"Key"`
+	Value string `json:"Value"`
+}
+
+// RoomPlayer 房间玩家（字段与 FlatBuffers RoomPlayer 对应）
+type RoomPlayer struct {
+	UserId    int64  `json:"UserId,string"`
+	Nickname  string `json:"Nickname"`
+	Avatar    string `json:"Avatar"`
+	IsReady   bool   `json:"IsReady"`
+	SeatIndex int32  `json:"SeatIndex"`
+	IsOnline  bool   `json:"IsOnline"`
 }
 
 // GamePush 游戏推送
 type GamePush struct {
-	RoomId      string `json:"RoomId"`
-	GameType    string `json:"GameType"`
-	GamePayload []byte `json:"GamePayload"`               // FlatBuffers 游戏推送数据
-	ToUserId    int64  `json:"ToUserId,string,omitempty"` // 目标用户ID（可选，为空则广播）
-	Platform    string `json:"Platform,omitempty"`        // 目标平台（可选）
-	ConnId      int64  `json:"ConnId,string,omitempty"`   // 目标连接 ID（可选）
+	RoomId          string `json:"RoomId"`
+	GameType        string `json:"GameType"`
+	GamePayloadType string `json:"GamePayloadType"`           // FlatBuffers GamePayload 枚举名，如 MahjongPush
+	GamePayload     []byte `json:"GamePayload"`               // FlatBuffers 游戏推送数据
+	ToUserId        int64  `json:"ToUserId,string,omitempty"` // 目标用户ID（可选，为空则广播）
+	Platform        string `json:"Platform,omitempty"`        // 目标平台（可选）
+	ConnId          int64  `json:"ConnId,string,omitempty"`   // 目标连接 ID（可选）
+}
+
+// SystemPush 系统公告推送（字段与 FlatBuffers SystemPush 对应）
+type SystemPush struct {
+	Title     string `json:"Title"`
+	Content   string `json:"Content"`
+	Level     string `json:"Level"` // INFO, WARNING, ERROR
+	ActionUrl string `json:"ActionUrl,omitempty"`
+}
+
+// Kick 踢下线通知
+type Kick struct {
+	Reason string `json:"Reason"` // NEW_LOGIN, LOGOUT, BANNED, ADMIN（与 FlatBuffers KickReason 名称一致）
+	Msg    string `json:"Msg,omitempty"`
+}
+
+// ============== 系统公告 (运维 -> Logic) ==============
+
+// SystemAnnouncement 系统公告请求
+// 目标三选一：UserIds 指定用户；All 为 true 时推送所有人；Platform 可与前两者组合按平台过滤
+type SystemAnnouncement struct {
+	UserIds    []int64 `json:"UserIds,omitempty"`
+	Platform   string  `json:"Platform,omitempty"`
+	All        bool    `json:"All,omitempty"`
+	Title      string  `json:"Title"`
+	Content    string  `json:"Content"`
+	Level      string  `json:"Level,omitempty"` // 默认 INFO
+	ActionUrl  string  `json:"ActionUrl,omitempty"`
+	TTLSeconds int64   `json:"TTLSeconds,omitempty"` // 有效期，大于 0 时持久化，期间上线的用户也会收到
+}
+
+// SystemAnnouncementResult 系统公告请求结果
+type SystemAnnouncementResult struct {
+	AnnouncementId string `json:"AnnouncementId,omitempty"` // 持久化时的公告 ID
+	Delivered      int    `json:"Delivered"`                // 在线推送的设备数（广播时为 -1，由各 Access 节点投递）
+	Error          string `json:"Error,omitempty"`
 }
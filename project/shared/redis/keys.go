@@ -2,6 +2,7 @@ package redis
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -82,6 +83,12 @@ func BuildTokenInfoKey(accessToken string) string {
 
 // ============== 房间相关 Key ==============
 
+const (
+	// RoomTTL 房间状态 TTL（room / room_users / user_room），每次写入刷新
+	// 房间正常由归属节点淘汰时删除，TTL 仅清理节点宕机且无人接管的残留状态
+	RoomTTL = 24 * time.Hour
+)
+
 // BuildRoomKey 构建房间信息 Key
 // Key: room:{roomId}
 // Value: JSON{Room}
@@ -108,3 +115,117 @@ func BuildRoomUsersKey(roomId string) string {
 func BuildRoomLockKey(roomId string) string {
 	return fmt.Sprintf("room_lock:%s", roomId)
 }
+
+// BuildRoomOwnerKey 构建房间归属 Key，房间与游戏请求只由归属的 Logic 节点处理
+// Key: room_owner:{roomId}
+// Value: Logic nodeId
+func BuildRoomOwnerKey(roomId string) string {
+	return fmt.Sprintf("room_owner:%s", roomId)
+}
+
+// ============== Logic 节点相关 Key ==============
+
+const (
+	// LogicNodeKeyPrefix Logic 节点存活 Key 前缀
+	LogicNodeKeyPrefix = "im:logic:node:"
+
+	// LogicNodeHeartbeatInterval Logic 节点心跳间隔
+	LogicNodeHeartbeatInterval = 5 * time.Second
+
+	// LogicNodeTTL 超过该时长未心跳的 Logic 节点视为宕机，其房间由其他节点接管
+	LogicNodeTTL = 3 * LogicNodeHeartbeatInterval
+)
+
+// BuildLogicNodeKey 构建 Logic 节点存活 Key（TTL 为 LogicNodeTTL）
+// Key: im:logic:node:{nodeId}
+// Value: 启动时间（毫秒）
+func BuildLogicNodeKey(nodeId string) string {
+	return LogicNodeKeyPrefix + nodeId
+}
+
+// LogicNodeRoomsKeySuffix Logic 节点房间索引 Key 后缀
+const LogicNodeRoomsKeySuffix = ":rooms"
+
+// BuildLogicNodeRoomsKey 构建 Logic 节点归属房间索引 Key (Set)，节点重启后据此恢复房间
+// Key: im:logic:node:{nodeId}:rooms
+// Member: roomId
+func BuildLogicNodeRoomsKey(nodeId string) string {
+	return LogicNodeKeyPrefix + nodeId + LogicNodeRoomsKeySuffix
+}
+
+// ============== 系统公告相关 Key ==============
+
+const (
+	// SystemAnnouncementsKey 面向全体/平台的有效公告索引 (ZSet)
+	// Member: announcementId, Score: 过期时间（毫秒）
+	SystemAnnouncementsKey = "system:announcements"
+)
+
+// BuildSystemAnnouncementKey 构建公告内容 Key（TTL 为公告有效期）
+// Key: system:announcement:{announcementId}
+// Value: JSON{Announcement}
+func BuildSystemAnnouncementKey(announcementId string) string {
+	return fmt.Sprintf("system:announcement:%s", announcementId)
+}
+
+// BuildUserAnnouncementsKey 构建面向指定用户的有效公告索引 (ZSet)
+// Key: system:announcements:user:{userId}
+// Member: announcementId, Score: 过期时间（毫秒）
+func BuildUserAnnouncementsKey(userId int64) string {
+	return fmt.Sprintf("system:announcements:user:%d", userId)
+}
+
+// BuildAnnouncementCursorKey 构建用户设备的公告投递游标 Key
+// Key: system:announcement:cursor:{userId}:{platform}
+// Value: 毫秒时间戳，此前创建的公告已投递到该设备
+func BuildAnnouncementCursorKey(userId int64, platform string) string {
+	return fmt.Sprintf("system:announcement:cursor:%d:%s", userId, strings.ToLower(platform))
+}
+
+// ============== Access 节点注册相关 Key ==============
+
+const (
+	// AccessNodesKey 已注册的 Access 节点索引 (ZSet)
+	// Member: nodeId, Score: 最近一次心跳时间（毫秒）
+	AccessNodesKey = "im:access:nodes"
+
+	// AccessNodeHeartbeatInterval Access 节点心跳间隔
+	AccessNodeHeartbeatInterval = 5 * time.Second
+
+	// AccessNodeTTL 超过该时长未心跳的节点视为宕机
+	AccessNodeTTL = 3 * AccessNodeHeartbeatInterval
+)
+
+// BuildAccessNodeKey 构建 Access 节点信息 Key（TTL 为 AccessNodeTTL）
+// Key: im:access:node:{nodeId}
+// Value: JSON{AccessNode}
+func BuildAccessNodeKey(nodeId string) string {
+	return fmt.Sprintf("im:access:node:%s", nodeId)
+}
+
+// BuildAccessNodeLocationsKey 构建 Access 节点上的用户位置索引 Key (Set)，节点宕机后据此清理位置
+// Key: im:access:node:{nodeId}:locations
+// Member: {userId}:{platform}:{connId}
+func BuildAccessNodeLocationsKey(nodeId string) string {
+	return fmt.Sprintf("im:access:node:%s:locations", nodeId)
+}
+
+// BuildNodeLocationMember 构建节点位置索引 Member
+func BuildNodeLocationMember(userId int64, platform string, connId int64) string {
+	return fmt.Sprintf("%d:%s:%d", userId, strings.ToLower(platform), connId)
+}
+
+// ParseNodeLocationMember 解析节点位置索引 Member
+func ParseNodeLocationMember(member string) (userId int64, platform string, connId int64, err error) {
+	parts := strings.Split(member, ":")
+	if len(parts) != 3 {
+		return 0, "", 0, fmt.Errorf("invalid node location member: %q", member)
+	}
+	if userId, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+		return 0, "", 0, err
+	}
+	if connId, err = strconv.ParseInt(parts[2], 10, 64); err != nil {
+		return 0, "", 0, err
+	}
+	return userId, parts[1], connId, nil
+}
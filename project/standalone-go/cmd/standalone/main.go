@@ -0,0 +1,85 @@
+// standalone 单进程模式：Access 与 Logic 运行在同一进程，通过进程内消息总线通信，无需 NATS
+// 仍依赖 Redis 与 PostgreSQL；Web 服务的踢人通知经 NATS 下发，此模式下不生效
+//
+// 用法（在 project/standalone-go 目录下）：
+//
+//	TLS_CERT_FILE=../access-go/localhost.crt TLS_KEY_FILE=../access-go/localhost.key go run ./cmd/standalone
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	accessApp "sudooom.im.access/pkg/app"
+	logicApp "sudooom.im.logic/pkg/app"
+	"sudooom.im.shared/bus"
+)
+
+func main() {
+	accessConfig := flag.String("access-config", "../access-go/configs/config.yaml", "Access 配置文件")
+	logicConfig := flag.String("logic-config", "../logic-go/configs/config.yaml", "Logic 配置文件")
+	pendingLimit := flag.Int("pending-limit", bus.DefaultPendingLimit, "每个订阅的待处理消息上限，超出后丢弃")
+	flag.Parse()
+
+	// 初始化日志
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	}))
+	slog.SetDefault(logger)
+
+	// 收到退出信号或任一服务异常退出时取消上下文，触发两者优雅关闭
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	msgBus := bus.NewMemoryBus(*pendingLimit)
+	defer msgBus.Close()
+
+	var (
+		wg     sync.WaitGroup
+		failed bool
+		mu     sync.Mutex
+	)
+	run := func(name string, fn func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(); err != nil {
+				logger.Error("Service exited", "service", name, "error", err)
+				mu.Lock()
+				failed = true
+				mu.Unlock()
+			}
+			cancel()
+		}()
+	}
+
+	run("logic", func() error {
+		return logicApp.Run(ctx, logicApp.Options{
+			ConfigPath: *logicConfig,
+			Bus:        msgBus,
+			Logger:     logger.With("service", "logic"),
+		})
+	})
+	run("access", func() error {
+		return accessApp.Run(ctx, accessApp.Options{
+			ConfigPath: *accessConfig,
+			Bus:        msgBus,
+			Logger:     logger.With("service", "access"),
+		})
+	})
+
+	logger.Info("Standalone mode started")
+	wg.Wait()
+
+	if failed {
+		os.Exit(1)
+	}
+	logger.Info("Standalone mode stopped")
+}
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/nats-io/nats.go"
 	"github.com/redis/go-redis/v9"
 
 	"sudooom.im.shared/jwt"
@@ -71,6 +72,18 @@ func main() {
 	}(redisClient)
 	logger.Info("Connected to Redis", "host", cfg.Redis.Host)
 
+	// 连接 NATS（用于登出时踢下线，不可用时仅降级）
+	var nc *nats.Conn
+	if cfg.NATS.URL != "" {
+		nc, err = nats.Connect(cfg.NATS.URL, nats.Name(cfg.App.Name), nats.MaxReconnects(-1))
+		if err != nil {
+			logger.Warn("Failed to connect to NATS, kick on logout disabled", "error", err)
+		} else {
+			defer nc.Close()
+			logger.Info("Connected to NATS", "url", cfg.NATS.URL)
+		}
+	}
+
 	// 初始化 JWT 服务
 	jwtService := jwt.NewService(
 		cfg.JWT.SecretKey,
@@ -89,9 +102,11 @@ func main() {
 	userRepo := repository.NewUserRepository(db)
 	friendRepo := repository.NewFriendRepository(db)
 	tokenRepo := repository.NewTokenRepository(redisClient)
+	locationRepo := repository.NewLocationRepository(redisClient)
 
 	// 初始化 Service
-	authService := service.NewAuthService(userRepo, tokenRepo, jwtService, sfNode)
+	kickService := service.NewKickService(locationRepo, nc)
+	authService := service.NewAuthService(userRepo, tokenRepo, jwtService, sfNode, kickService)
 	userService := service.NewUserService(userRepo)
 	friendService := service.NewFriendService(friendRepo, userRepo, sfNode)
 
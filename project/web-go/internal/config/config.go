@@ -15,6 +15,7 @@ type Config struct {
 	Redis     RedisConfig     `mapstructure:"redis"`
 	CORS      CORSConfig      `mapstructure:"cors"`
 	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	NATS      NATSConfig      `mapstructure:"nats"`
 }
 
 type AppConfig struct {
@@ -60,6 +61,11 @@ type RateLimitConfig struct {
 	RequestsPerMinute int  `mapstructure:"requests_per_minute"`
 }
 
+// NATSConfig 用于通知 Access 节点踢下线，URL 为空时不启用
+type NATSConfig struct {
+	URL string `mapstructure:"url"`
+}
+
 func Load(path string) (*Config, error) {
 	viper.SetConfigFile(path)
 	viper.SetConfigType("yaml")
@@ -105,4 +111,7 @@ func (c *Config) applyEnv() {
 	c.Redis.Password = sharedConfig.GetEnv("REDIS_PASSWORD", c.Redis.Password)
 	c.Redis.DB = sharedConfig.GetEnvInt("REDIS_DB", c.Redis.DB)
 	c.Redis.PoolSize = sharedConfig.GetEnvInt("REDIS_POOL_SIZE", c.Redis.PoolSize)
+
+	// NATS
+	c.NATS.URL = sharedConfig.GetEnv("NATS_URL", c.NATS.URL)
 }
@@ -99,7 +99,7 @@ func setupIntegrationTest(t *testing.T) *testDeps {
 
 	userRepo := repository.NewUserRepository(db)
 	tokenRepo := repository.NewTokenRepository(redisClient)
-	authService := service.NewAuthService(userRepo, tokenRepo, jwtService, sfNode)
+	authService := service.NewAuthService(userRepo, tokenRepo, jwtService, sfNode, nil)
 	authHandler := NewAuthHandler(authService)
 
 	// 创建路由
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+	sharedModel "sudooom.im.shared/model"
+	sharedRedis "sudooom.im.shared/redis"
+)
+
+// LocationRepository 用户在线位置（由 access-go 维护）只读访问层
+type LocationRepository struct {
+	rdb *redis.Client
+}
+
+// NewLocationRepository 创建 Location Repository
+func NewLocationRepository(rdb *redis.Client) *LocationRepository {
+	return &LocationRepository{rdb: rdb}
+}
+
+// GetUserLocation 获取用户某平台的在线位置，不在线时返回 nil
+func (r *LocationRepository) GetUserLocation(ctx context.Context, userID int64, platform string) (*sharedModel.UserLocation, error) {
+	data, err := r.rdb.Get(ctx, sharedRedis.BuildUserLocationKeyWithPlatform(userID, platform)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var location sharedModel.UserLocation
+	if err := json.Unmarshal([]byte(data), &location); err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
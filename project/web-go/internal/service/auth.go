@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"log/slog"
 
 	"golang.org/x/crypto/bcrypt"
 
@@ -42,19 +43,21 @@ type LoginResponse struct {
 
 // AuthService 认证服务
 type AuthService struct {
-	userRepo   *repository.UserRepository
-	tokenRepo  *repository.TokenRepository
-	jwtService *jwt.Service
-	snowflake  *snowflake.Node
+	userRepo    *repository.UserRepository
+	tokenRepo   *repository.TokenRepository
+	jwtService  *jwt.Service
+	snowflake   *snowflake.Node
+	kickService *KickService
 }
 
 // NewAuthService 创建认证服务
-func NewAuthService(userRepo *repository.UserRepository, tokenRepo *repository.TokenRepository, jwtService *jwt.Service, sf *snowflake.Node) *AuthService {
+func NewAuthService(userRepo *repository.UserRepository, tokenRepo *repository.TokenRepository, jwtService *jwt.Service, sf *snowflake.Node, kickService *KickService) *AuthService {
 	return &AuthService{
-		userRepo:   userRepo,
-		tokenRepo:  tokenRepo,
-		jwtService: jwtService,
-		snowflake:  sf,
+		userRepo:    userRepo,
+		tokenRepo:   tokenRepo,
+		jwtService:  jwtService,
+		snowflake:   sf,
+		kickService: kickService,
 	}
 }
 
@@ -117,6 +120,7 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*LoginRespo
 
 	// 检查用户状态
 	if user.Status != model.UserStatusNormal {
+		s.revokeDisabledUser(ctx, user.ID)
 		return nil, ErrUserDisabled
 	}
 
@@ -171,6 +175,7 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*L
 
 	// 检查用户状态
 	if user.Status != model.UserStatusNormal {
+		s.revokeDisabledUser(ctx, user.ID)
 		return nil, ErrUserDisabled
 	}
 
@@ -190,5 +195,26 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*L
 
 // Logout 用户登出
 func (s *AuthService) Logout(ctx context.Context, userID int64, platform, accessToken string) error {
-	return s.tokenRepo.DeleteToken(ctx, userID, platform, accessToken)
+	if err := s.tokenRepo.DeleteToken(ctx, userID, platform, accessToken); err != nil {
+		return err
+	}
+
+	// 断开该平台仍在线的长连接，失败不影响登出
+	if err := s.kickService.KickUser(ctx, userID, platform, KickReasonLogout, "logged out"); err != nil {
+		slog.Warn("Failed to kick connection on logout", "userId", userID, "platform", platform, "error", err)
+	}
+	return nil
+}
+
+// revokeDisabledUser 删除被禁用用户所有平台的 Token，并以 BANNED 原因断开其所有在线长连接，失败只记录日志
+// 禁用用户目前直接修改数据库状态，由用户下一次登录或刷新 Token 时触发
+func (s *AuthService) revokeDisabledUser(ctx context.Context, userID int64) {
+	for _, platform := range allPlatforms {
+		if err := s.tokenRepo.DeleteOldToken(ctx, userID, string(platform)); err != nil {
+			slog.Warn("Failed to revoke token of disabled user", "userId", userID, "platform", platform, "error", err)
+		}
+	}
+	if err := s.kickService.KickUserAllPlatforms(ctx, userID, KickReasonBanned, "account disabled"); err != nil {
+		slog.Warn("Failed to kick disabled user", "userId", userID, "error", err)
+	}
 }
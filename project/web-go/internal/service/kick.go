@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/nats-io/nats.go"
+
+	"sudooom.im.shared/jwt"
+	sharedNats "sudooom.im.shared/nats"
+	"sudooom.im.shared/proto"
+	"sudooom.im.web/internal/repository"
+)
+
+// 踢下线原因（与 FlatBuffers KickReason 名称一致）
+const (
+	KickReasonLogout = "LOGOUT"
+	KickReasonBanned = "BANNED"
+)
+
+// allPlatforms 用户可能在线的所有平台
+var allPlatforms = []jwt.Platform{jwt.PlatformAndroid, jwt.PlatformIOS, jwt.PlatformWeb, jwt.PlatformDesktop, jwt.PlatformWechat, jwt.PlatformUnknown}
+
+// KickService 踢下线服务：通知用户所在的 Access 节点发送 KICKED 通知并关闭连接
+type KickService struct {
+	locationRepo *repository.LocationRepository
+	nc           *nats.Conn
+	logger       *slog.Logger
+}
+
+// NewKickService 创建踢下线服务，nc 为 nil 时不发送踢下线通知
+func NewKickService(locationRepo *repository.LocationRepository, nc *nats.Conn) *KickService {
+	return &KickService{
+		locationRepo: locationRepo,
+		nc:           nc,
+		logger:       slog.Default(),
+	}
+}
+
+// KickUser 踢掉用户在指定平台的在线连接，不在线时忽略
+func (s *KickService) KickUser(ctx context.Context, userID int64, platform, reason, msg string) error {
+	if s == nil || s.nc == nil {
+		return nil
+	}
+
+	location, err := s.locationRepo.GetUserLocation(ctx, userID, platform)
+	if err != nil || location == nil {
+		return err
+	}
+
+	data, err := json.Marshal(&proto.DownstreamMessage{
+		UserId:   userID,
+		ConnId:   location.ConnId,
+		Platform: platform,
+		Payload: proto.DownstreamPayload{
+			Kick: &proto.Kick{Reason: reason, Msg: msg},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.nc.Publish(sharedNats.BuildAccessDownstreamSubject(location.AccessNodeId), data); err != nil {
+		return err
+	}
+
+	s.logger.Info("Kick published",
+		"userId", userID,
+		"platform", platform,
+		"accessNodeId", location.AccessNodeId,
+		"connId", location.ConnId,
+		"reason", reason)
+	return nil
+}
+
+// KickUserAllPlatforms 踢掉用户在所有平台的在线连接，单个平台失败不影响其他平台，返回首个错误
+func (s *KickService) KickUserAllPlatforms(ctx context.Context, userID int64, reason, msg string) error {
+	var firstErr error
+	for _, platform := range allPlatforms {
+		if err := s.KickUser(ctx, userID, string(platform), reason, msg); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}